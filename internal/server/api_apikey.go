@@ -0,0 +1,243 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 密钥轮换后，旧密钥默认的宽限时间
+const apiKeyDefaultRotateGrace = time.Hour
+
+// ApiKeyAPI 业务api访问密钥管理
+type ApiKeyAPI struct {
+	s *Server
+	wklog.Log
+}
+
+// NewApiKeyAPI NewApiKeyAPI
+func NewApiKeyAPI(s *Server) *ApiKeyAPI {
+	return &ApiKeyAPI{
+		s:   s,
+		Log: wklog.NewWKLog("ApiKeyAPI"),
+	}
+}
+
+// Route 路由
+func (a *ApiKeyAPI) Route(r *wkhttp.WKHttp) {
+	r.POST("/apikeys", a.create)            // 创建密钥
+	r.GET("/apikeys", a.list)               // 密钥列表
+	r.DELETE("/apikeys/:id", a.revoke)      // 吊销密钥
+	r.POST("/apikeys/:id/rotate", a.rotate) // 轮换密钥
+}
+
+func (a *ApiKeyAPI) create(c *wkhttp.Context) {
+	var req apiKeyCreateReq
+	if err := c.BindJSON(&req); err != nil {
+		a.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if err := req.Check(); err != nil {
+		c.ResponseError(err)
+		return
+	}
+	if strings.TrimSpace(a.s.opts.ManagerToken) == "" {
+		c.ResponseError(errors.New("没有配置managerToken，访问密钥不会生效！"))
+		return
+	}
+
+	key := newApiKeySecret()
+	now := time.Now()
+	apiKey := &pb.ApiKey{
+		Id:              wkutil.GenUUID(),
+		Name:            req.Name,
+		KeyHash:         pb.HashApiKey(key),
+		Scopes:          req.Scopes,
+		ChannelPrefixes: req.ChannelPrefixes,
+		CreatedAt:       now.Unix(),
+	}
+	if req.ExpireIn > 0 {
+		apiKey.ExpireAt = now.Add(time.Duration(req.ExpireIn) * time.Second).Unix()
+	}
+	if !a.checkCovers(c, apiKey) {
+		return
+	}
+	err := a.s.clusterServer.ProposeApiKeySave(apiKey)
+	if err != nil {
+		a.Error("创建访问密钥失败！", zap.Error(err), zap.String("name", req.Name))
+		c.ResponseError(err)
+		return
+	}
+	resp := newApiKeyResp(apiKey, nil)
+	resp.Key = key
+	c.JSON(http.StatusOK, resp)
+}
+
+func (a *ApiKeyAPI) list(c *wkhttp.Context) {
+	apiKeys := a.s.clusterServer.GetConfig().ApiKeys
+	resps := make([]*apiKeyResp, 0, len(apiKeys))
+	caller := requestApiKey(c)
+	for _, apiKey := range apiKeys {
+		if caller != nil && !caller.Covers(apiKey) { // 只返回不超出自身权限的密钥
+			continue
+		}
+		resps = append(resps, newApiKeyResp(apiKey, a.s.apiServer.apiKeyAuth.usage(apiKey.Id)))
+	}
+	c.JSON(http.StatusOK, resps)
+}
+
+func (a *ApiKeyAPI) revoke(c *wkhttp.Context) {
+	id := c.Param("id")
+	apiKey := a.s.clusterServer.GetConfig().ApiKeyById(id)
+	if apiKey == nil {
+		c.ResponseError(errors.New("访问密钥不存在！"))
+		return
+	}
+	if !a.checkCovers(c, apiKey) {
+		return
+	}
+	err := a.s.clusterServer.ProposeApiKeyRemove(id)
+	if err != nil {
+		a.Error("吊销访问密钥失败！", zap.Error(err), zap.String("id", id))
+		c.ResponseError(err)
+		return
+	}
+	a.s.apiServer.apiKeyAuth.removeUsage(id)
+	c.ResponseOK()
+}
+
+// rotate 生成新的密钥，旧密钥在宽限时间内仍然有效，方便调用方无需重启即可切换
+func (a *ApiKeyAPI) rotate(c *wkhttp.Context) {
+	var req struct {
+		Grace int64 `json:"grace"` // 旧密钥的宽限时间（单位秒），默认1小时，小于0表示旧密钥立即失效
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.BindJSON(&req); err != nil {
+			a.Error("数据格式有误！", zap.Error(err))
+			c.ResponseError(err)
+			return
+		}
+	}
+	id := c.Param("id")
+	old := a.s.clusterServer.GetConfig().ApiKeyById(id)
+	if old == nil {
+		c.ResponseError(errors.New("访问密钥不存在！"))
+		return
+	}
+	if !a.checkCovers(c, old) { // 不能轮换权限更大的密钥来获取它的明文
+		return
+	}
+	grace := apiKeyDefaultRotateGrace
+	if req.Grace != 0 {
+		grace = time.Duration(req.Grace) * time.Second
+	}
+
+	key := newApiKeySecret()
+	now := time.Now()
+	apiKey := old.Clone()
+	apiKey.KeyHash = pb.HashApiKey(key)
+	apiKey.RotatedAt = now.Unix()
+	if grace > 0 {
+		apiKey.PrevKeyHash = old.KeyHash
+		apiKey.PrevExpireAt = now.Add(grace).Unix()
+	} else {
+		apiKey.PrevKeyHash = ""
+		apiKey.PrevExpireAt = 0
+	}
+	err := a.s.clusterServer.ProposeApiKeySave(apiKey)
+	if err != nil {
+		a.Error("轮换访问密钥失败！", zap.Error(err), zap.String("id", id))
+		c.ResponseError(err)
+		return
+	}
+	resp := newApiKeyResp(apiKey, a.s.apiServer.apiKeyAuth.usage(id))
+	resp.Key = key
+	c.JSON(http.StatusOK, resp)
+}
+
+// checkCovers 使用访问密钥调用时，只能创建和管理不超出自身权限的密钥，否则返回403
+func (a *ApiKeyAPI) checkCovers(c *wkhttp.Context, target *pb.ApiKey) bool {
+	caller := requestApiKey(c)
+	if caller == nil || caller.Covers(target) {
+		return true
+	}
+	a.s.apiServer.apiKeyAuth.usage(caller.Id).denied.Inc()
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"msg":    "api key scope exceeds the caller's scopes",
+		"status": http.StatusForbidden,
+	})
+	return false
+}
+
+// newApiKeySecret 生成密钥明文，明文只在创建和轮换时返回一次
+func newApiKeySecret() string {
+	return "wk_" + wkutil.GenUUID() + wkutil.GenUUID()
+}
+
+type apiKeyCreateReq struct {
+	Name            string   `json:"name"`             // 密钥名称
+	Scopes          []string `json:"scopes"`           // 允许访问的路由分组，*表示全部
+	ChannelPrefixes []string `json:"channel_prefixes"` // 允许操作的频道id前缀，为空表示不限制
+	ExpireIn        int64    `json:"expire_in"`        // 有效期（单位秒），0表示不过期
+}
+
+func (r apiKeyCreateReq) Check() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return errors.New("name不能为空！")
+	}
+	if len(r.Scopes) == 0 {
+		return errors.New("scopes不能为空！")
+	}
+	for _, scope := range r.Scopes {
+		if scope != pb.ApiKeyScopeAll && !wkutil.ArrayContains(ApiKeyScopes, scope) {
+			return errors.New("不支持的scope：" + scope)
+		}
+	}
+	if r.ExpireIn < 0 {
+		return errors.New("expire_in不能小于0！")
+	}
+	return nil
+}
+
+type apiKeyResp struct {
+	Id              string   `json:"id"`
+	Name            string   `json:"name"`
+	Key             string   `json:"key,omitempty"` // 密钥明文，只在创建和轮换时返回
+	Scopes          []string `json:"scopes"`
+	ChannelPrefixes []string `json:"channel_prefixes"`
+	CreatedAt       int64    `json:"created_at"`
+	ExpireAt        int64    `json:"expire_at"`
+	RotatedAt       int64    `json:"rotated_at"`
+	PrevExpireAt    int64    `json:"prev_expire_at"` // 轮换前的旧密钥失效时间
+	Requests        int64    `json:"requests"`       // 本节点的请求次数
+	Denied          int64    `json:"denied"`         // 本节点因未授权被拒绝的次数
+	LastUsedAt      int64    `json:"last_used_at"`   // 本节点最后使用时间
+}
+
+func newApiKeyResp(apiKey *pb.ApiKey, usage *apiKeyUsage) *apiKeyResp {
+	resp := &apiKeyResp{
+		Id:              apiKey.Id,
+		Name:            apiKey.Name,
+		Scopes:          apiKey.Scopes,
+		ChannelPrefixes: apiKey.ChannelPrefixes,
+		CreatedAt:       apiKey.CreatedAt,
+		ExpireAt:        apiKey.ExpireAt,
+		RotatedAt:       apiKey.RotatedAt,
+		PrevExpireAt:    apiKey.PrevExpireAt,
+	}
+	if usage != nil {
+		resp.Requests = usage.requests.Load()
+		resp.Denied = usage.denied.Load()
+		resp.LastUsedAt = usage.lastUsedAt.Load()
+	}
+	return resp
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/WuKongIM/WuKongIM/pkg/auth"
+	"github.com/WuKongIM/WuKongIM/pkg/auth/resource"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"go.uber.org/zap"
+)
+
+// AuditAPI 审计日志查询
+type AuditAPI struct {
+	s       *Server
+	manager bool // 是否是管理后台的接口，管理后台需要校验用户权限
+	wklog.Log
+}
+
+// NewAuditAPI NewAuditAPI
+func NewAuditAPI(s *Server, manager bool) *AuditAPI {
+	return &AuditAPI{
+		s:       s,
+		manager: manager,
+		Log:     wklog.NewWKLog("AuditAPI"),
+	}
+}
+
+// Route 路由
+func (a *AuditAPI) Route(r *wkhttp.WKHttp) {
+	r.GET("/audit", a.list) // 审计日志列表
+}
+
+func (a *AuditAPI) list(c *wkhttp.Context) {
+	if a.manager && !a.s.opts.Auth.HasPermissionWithContext(c, resource.Manager.Audit, auth.ActionRead) {
+		c.ResponseStatus(http.StatusUnauthorized)
+		return
+	}
+
+	// 审计日志保存在接收请求的节点上，查询其他节点的日志需要转发
+	nodeIdStr := c.Query("node_id")
+	var nodeId uint64
+	if strings.TrimSpace(nodeIdStr) != "" {
+		nodeId, _ = strconv.ParseUint(nodeIdStr, 10, 64)
+	}
+	if nodeId > 0 && nodeId != a.s.opts.Cluster.NodeId {
+		nodeInfo, err := a.s.cluster.NodeInfoById(nodeId)
+		if err != nil {
+			a.Error("获取节点信息失败！", zap.Error(err), zap.Uint64("nodeId", nodeId))
+			c.ResponseError(err)
+			return
+		}
+		if nodeInfo == nil {
+			a.Error("节点不存在！", zap.Uint64("nodeId", nodeId))
+			c.ResponseError(fmt.Errorf("节点不存在！"))
+			return
+		}
+		c.ForwardWithBody(fmt.Sprintf("%s%s", nodeInfo.ApiServerAddr, c.Request.URL.Path), nil)
+		return
+	}
+
+	startTime, _ := strconv.ParseInt(c.Query("start_time"), 10, 64)
+	endTime, _ := strconv.ParseInt(c.Query("end_time"), 10, 64)
+	offsetId, _ := strconv.ParseUint(c.Query("offset_id"), 10, 64)
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	logs, err := a.s.store.DB().SearchAuditLogs(wkdb.AuditLogSearchReq{
+		Actor:     c.Query("actor"),
+		ApiKeyId:  c.Query("api_key_id"),
+		Path:      c.Query("path"),
+		Target:    c.Query("target"),
+		StartTime: startTime,
+		EndTime:   endTime,
+		OffsetId:  offsetId,
+		Limit:     limit,
+	})
+	if err != nil {
+		a.Error("查询审计日志失败！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	resps := make([]*auditLogResp, 0, len(logs))
+	for _, log := range logs {
+		resps = append(resps, newAuditLogResp(log))
+	}
+	c.JSON(http.StatusOK, resps)
+}
+
+type auditLogResp struct {
+	Id         string `json:"id"` // 分页时作为offset_id传入
+	ActorType  string `json:"actor_type"`
+	Actor      string `json:"actor"`
+	ApiKeyId   string `json:"api_key_id,omitempty"`
+	ClientIp   string `json:"client_ip"`
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	Target     string `json:"target"`
+	StatusCode int    `json:"status_code"`
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	NodeId     uint64 `json:"node_id"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+func newAuditLogResp(log wkdb.AuditLog) *auditLogResp {
+	return &auditLogResp{
+		Id:         strconv.FormatUint(log.Id, 10),
+		ActorType:  log.ActorType,
+		Actor:      log.Actor,
+		ApiKeyId:   log.ApiKeyId,
+		ClientIp:   log.ClientIp,
+		Method:     log.Method,
+		Path:       log.Path,
+		Target:     log.Target,
+		StatusCode: log.StatusCode,
+		Success:    log.Success(),
+		Error:      log.Error,
+		NodeId:     log.NodeId,
+		CreatedAt:  log.CreatedAt,
+	}
+}
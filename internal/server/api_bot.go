@@ -0,0 +1,142 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"go.uber.org/zap"
+)
+
+// BotAPI 服务端机器人管理
+type BotAPI struct {
+	s *Server
+	wklog.Log
+}
+
+// NewBotAPI NewBotAPI
+func NewBotAPI(s *Server) *BotAPI {
+	return &BotAPI{
+		s:   s,
+		Log: wklog.NewWKLog("BotAPI"),
+	}
+}
+
+// Route 路由
+func (b *BotAPI) Route(r *wkhttp.WKHttp) {
+	r.POST("/bot/register", b.register)     // 注册机器人（新增或修改）
+	r.POST("/bot/unregister", b.unregister) // 移除机器人
+	r.GET("/bot/list", b.list)              // 机器人列表
+}
+
+type botRegisterReq struct {
+	Uid           string  `json:"uid"`            // 机器人uid
+	CallbackUrl   string  `json:"callback_url"`   // 回调地址，为空表示使用进程内注册的处理器
+	RateLimit     float64 `json:"rate_limit"`     // 每秒最多处理的消息数量，0表示使用默认配置
+	CommandPrefix string  `json:"command_prefix"` // 命令前缀，为空表示使用默认配置
+	Description   string  `json:"description"`    // 描述
+}
+
+func (r botRegisterReq) Check() error {
+	if strings.TrimSpace(r.Uid) == "" {
+		return errors.New("uid不能为空！")
+	}
+	if IsSpecialChar(r.Uid) {
+		return errors.New("uid不能包含特殊字符！")
+	}
+	if r.CallbackUrl != "" && !strings.HasPrefix(r.CallbackUrl, "http://") && !strings.HasPrefix(r.CallbackUrl, "https://") {
+		return errors.New("callback_url必须以http://或https://开头！")
+	}
+	if r.RateLimit < 0 {
+		return errors.New("rate_limit不能小于0！")
+	}
+	return nil
+}
+
+func (b *BotAPI) register(c *wkhttp.Context) {
+	var req botRegisterReq
+	if err := c.BindJSON(&req); err != nil {
+		b.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if err := req.Check(); err != nil {
+		c.ResponseError(err)
+		return
+	}
+	now := time.Now().Unix()
+	bot := &pb.Bot{
+		Uid:           req.Uid,
+		CallbackUrl:   req.CallbackUrl,
+		RateLimit:     req.RateLimit,
+		CommandPrefix: req.CommandPrefix,
+		Description:   req.Description,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if exist := b.s.clusterServer.GetConfig().Bot(req.Uid); exist != nil {
+		bot.CreatedAt = exist.CreatedAt
+	}
+	if err := b.s.clusterServer.ProposeBotSave(bot); err != nil {
+		b.Error("注册机器人失败！", zap.Error(err), zap.String("uid", req.Uid))
+		c.ResponseError(err)
+		return
+	}
+	c.ResponseOK()
+}
+
+func (b *BotAPI) unregister(c *wkhttp.Context) {
+	var req struct {
+		Uid string `json:"uid"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		b.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if b.s.clusterServer.GetConfig().Bot(req.Uid) == nil {
+		c.ResponseError(errors.New("机器人不存在！"))
+		return
+	}
+	if err := b.s.clusterServer.ProposeBotRemove(req.Uid); err != nil {
+		b.Error("移除机器人失败！", zap.Error(err), zap.String("uid", req.Uid))
+		c.ResponseError(err)
+		return
+	}
+	c.ResponseOK()
+}
+
+func (b *BotAPI) list(c *wkhttp.Context) {
+	bots := b.s.clusterServer.GetConfig().Bots
+	resps := make([]*botResp, 0, len(bots))
+	for _, bot := range bots {
+		resps = append(resps, newBotResp(bot))
+	}
+	c.JSON(http.StatusOK, resps)
+}
+
+type botResp struct {
+	Uid           string  `json:"uid"`
+	CallbackUrl   string  `json:"callback_url"`
+	RateLimit     float64 `json:"rate_limit"`
+	CommandPrefix string  `json:"command_prefix"`
+	Description   string  `json:"description"`
+	CreatedAt     int64   `json:"created_at"`
+	UpdatedAt     int64   `json:"updated_at"`
+}
+
+func newBotResp(bot *pb.Bot) *botResp {
+	return &botResp{
+		Uid:           bot.Uid,
+		CallbackUrl:   bot.CallbackUrl,
+		RateLimit:     bot.RateLimit,
+		CommandPrefix: bot.CommandPrefix,
+		Description:   bot.Description,
+		CreatedAt:     bot.CreatedAt,
+		UpdatedAt:     bot.UpdatedAt,
+	}
+}
@@ -72,6 +72,9 @@ func (ch *ChannelAPI) channelCreateOrUpdate(c *wkhttp.Context) {
 		c.ResponseError(errors.Wrap(err, "数据格式有误！"))
 		return
 	}
+	if !ch.s.checkApiKeyChannel(c, req.ChannelID) {
+		return
+	}
 	if err := req.Check(); err != nil {
 		c.ResponseError(err)
 		return
@@ -124,6 +127,7 @@ func (ch *ChannelAPI) channelCreateOrUpdate(c *wkhttp.Context) {
 	if cacheChannel != nil {
 		cacheChannel.info = channelInfo
 	}
+	ch.s.webhook.invalidateChannelWebhook(req.ChannelID, req.ChannelType)
 
 	c.ResponseOK()
 }
@@ -137,6 +141,13 @@ func (ch *ChannelAPI) updateOrAddChannelInfo(c *wkhttp.Context) {
 		c.ResponseError(errors.New("数据格式有误！"))
 		return
 	}
+	if !ch.s.checkApiKeyChannel(c, req.ChannelID) {
+		return
+	}
+	if err := req.checkWebhook(); err != nil {
+		c.ResponseError(err)
+		return
+	}
 
 	if ch.s.opts.ClusterOn() {
 		leaderInfo, err := ch.s.cluster.SlotLeaderOfChannel(req.ChannelID, req.ChannelType) // 获取频道的领导节点
@@ -165,6 +176,7 @@ func (ch *ChannelAPI) updateOrAddChannelInfo(c *wkhttp.Context) {
 	if cacheChannel != nil {
 		cacheChannel.info = channelInfo
 	}
+	ch.s.webhook.invalidateChannelWebhook(req.ChannelID, req.ChannelType)
 	c.ResponseOK()
 }
 
@@ -178,6 +190,9 @@ func (ch *ChannelAPI) addSubscriber(c *wkhttp.Context) {
 		c.ResponseError(errors.Wrap(err, "数据格式有误！"))
 		return
 	}
+	if !ch.s.checkApiKeyChannel(c, req.ChannelId) {
+		return
+	}
 	if err := req.Check(); err != nil {
 		c.ResponseError(err)
 		return
@@ -398,6 +413,9 @@ func (ch *ChannelAPI) removeSubscriber(c *wkhttp.Context) {
 		c.ResponseError(errors.Wrap(err, "数据格式有误！"))
 		return
 	}
+	if !ch.s.checkApiKeyChannel(c, req.ChannelId) {
+		return
+	}
 	if err := req.Check(); err != nil {
 		c.ResponseError(err)
 		return
@@ -445,6 +463,9 @@ func (ch *ChannelAPI) setTmpSubscriber(c *wkhttp.Context) {
 		c.ResponseError(errors.Wrap(err, "数据格式有误！"))
 		return
 	}
+	if !ch.s.checkApiKeyChannel(c, req.ChannelId) {
+		return
+	}
 	if err := req.Check(); err != nil {
 		c.ResponseError(err)
 		return
@@ -485,6 +506,9 @@ func (ch *ChannelAPI) blacklistAdd(c *wkhttp.Context) {
 		c.ResponseError(err)
 		return
 	}
+	if !ch.s.checkApiKeyChannel(c, req.ChannelId) {
+		return
+	}
 	if err := req.Check(); err != nil {
 		c.ResponseError(err)
 		return
@@ -538,6 +562,9 @@ func (ch *ChannelAPI) blacklistSet(c *wkhttp.Context) {
 		c.ResponseError(err)
 		return
 	}
+	if !ch.s.checkApiKeyChannel(c, req.ChannelId) {
+		return
+	}
 	if strings.TrimSpace(req.ChannelId) == "" {
 		c.ResponseError(errors.New("频道ID不能为空！"))
 		return
@@ -596,6 +623,9 @@ func (ch *ChannelAPI) blacklistRemove(c *wkhttp.Context) {
 		c.ResponseError(err)
 		return
 	}
+	if !ch.s.checkApiKeyChannel(c, req.ChannelId) {
+		return
+	}
 	if err := req.Check(); err != nil {
 		c.ResponseError(err)
 		return
@@ -633,6 +663,9 @@ func (ch *ChannelAPI) channelDelete(c *wkhttp.Context) {
 		c.ResponseError(errors.Wrap(err, "数据格式有误！"))
 		return
 	}
+	if !ch.s.checkApiKeyChannel(c, req.ChannelId) {
+		return
+	}
 	if req.ChannelType == wkproto.ChannelTypePerson {
 		c.ResponseError(errors.New("个人频道不支持添加订阅者！"))
 		return
@@ -695,6 +728,9 @@ func (ch *ChannelAPI) whitelistAdd(c *wkhttp.Context) {
 		c.ResponseError(err)
 		return
 	}
+	if !ch.s.checkApiKeyChannel(c, req.ChannelId) {
+		return
+	}
 	if err := req.Check(); err != nil {
 		c.ResponseError(err)
 		return
@@ -747,6 +783,9 @@ func (ch *ChannelAPI) whitelistSet(c *wkhttp.Context) {
 		c.ResponseError(err)
 		return
 	}
+	if !ch.s.checkApiKeyChannel(c, req.ChannelId) {
+		return
+	}
 	if strings.TrimSpace(req.ChannelId) == "" {
 		c.ResponseError(errors.New("频道ID不能为空！"))
 		return
@@ -804,6 +843,9 @@ func (ch *ChannelAPI) whitelistRemove(c *wkhttp.Context) {
 		c.ResponseError(err)
 		return
 	}
+	if !ch.s.checkApiKeyChannel(c, req.ChannelId) {
+		return
+	}
 	if err := req.Check(); err != nil {
 		c.ResponseError(err)
 		return
@@ -836,6 +878,9 @@ func (ch *ChannelAPI) whitelistRemove(c *wkhttp.Context) {
 func (ch *ChannelAPI) whitelistGet(c *wkhttp.Context) {
 	channelId := c.Query("channel_id")
 	channelType := wkutil.ParseUint8(c.Query("channel_type"))
+	if !ch.s.checkApiKeyChannel(c, channelId) {
+		return
+	}
 
 	if ch.s.opts.ClusterOn() {
 		leaderInfo, err := ch.s.cluster.SlotLeaderOfChannel(channelId, channelType) // 获取频道的领导节点
@@ -891,6 +936,8 @@ func (ch *ChannelAPI) syncMessages(c *wkhttp.Context) {
 		EndMessageSeq   uint64   `json:"end_message_seq"`   // 结束消息列号（结果不包含end_message_seq的消息）
 		Limit           int      `json:"limit"`             // 每次同步数量限制
 		PullMode        PullMode `json:"pull_mode"`         // 拉取模式 0:向下拉取 1:向上拉取
+		KnownMessageSeq uint64   `json:"known_message_seq"` // 客户端已知的频道最新消息序号（跟随者读时本地副本需要应用到此序号）
+		StrictRead      int      `json:"strict_read"`       // 跟随者读时是否严格一致（向领导确认读索引） 0.否 1.是
 	}
 	bodyBytes, err := BindJSON(&req, c)
 	if err != nil {
@@ -898,6 +945,9 @@ func (ch *ChannelAPI) syncMessages(c *wkhttp.Context) {
 		c.ResponseError(err)
 		return
 	}
+	if !ch.s.checkApiKeyChannel(c, req.ChannelID) {
+		return
+	}
 
 	if strings.TrimSpace(req.ChannelID) == "" {
 		ch.Error("channel_id不能为空！", zap.Any("req", req))
@@ -924,7 +974,7 @@ func (ch *ChannelAPI) syncMessages(c *wkhttp.Context) {
 		fakeChannelID = GetFakeChannelIDWith(req.LoginUID, req.ChannelID)
 	}
 	if ch.s.opts.ClusterOn() {
-		leaderInfo, err := ch.s.cluster.LeaderOfChannelForRead(fakeChannelID, req.ChannelType) // 获取频道的领导节点
+		leaderInfo, err := ch.s.nodeOfChannelForRead(fakeChannelID, req.ChannelType, req.KnownMessageSeq, wkutil.IntToBool(req.StrictRead)) // 获取频道的领导节点（开启跟随者读时可能是本地副本）
 		if errors.Is(err, cluster.ErrChannelClusterConfigNotFound) {
 			ch.Info("空频道，返回空消息.", zap.String("channelID", req.ChannelID), zap.Uint8("channelType", req.ChannelType))
 			c.JSON(http.StatusOK, emptySyncMessageResp)
@@ -955,6 +1005,12 @@ func (ch *ChannelAPI) syncMessages(c *wkhttp.Context) {
 		c.ResponseError(err)
 		return
 	}
+	messages, err = ch.s.trimUnappliedMessages(fakeChannelID, req.ChannelType, messages)
+	if err != nil {
+		ch.Error("去掉未应用的消息失败！", zap.Error(err), zap.Any("req", req))
+		c.ResponseError(err)
+		return
+	}
 	messageResps := make([]*MessageResp, 0, len(messages))
 	if len(messages) > 0 {
 		for _, message := range messages {
@@ -996,6 +1052,9 @@ func (ch *ChannelAPI) syncMessages(c *wkhttp.Context) {
 func (ch *ChannelAPI) getChannelMaxMessageSeq(c *wkhttp.Context) {
 	channelId := c.Query("channel_id")
 	channelType := wkutil.StringToUint8(c.Query("channel_type"))
+	if !ch.s.checkApiKeyChannel(c, channelId) {
+		return
+	}
 
 	if channelId == "" {
 		c.ResponseError(errors.New("channel_id不能为空"))
@@ -11,10 +11,12 @@ import (
 	"time"
 
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkhook"
 	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
 	"github.com/WuKongIM/WuKongIM/pkg/wklog"
 	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
 	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/gin-gonic/gin"
 	"github.com/sendgrid/rest"
 	"go.uber.org/zap"
 )
@@ -39,6 +41,7 @@ func (s *ConversationAPI) Route(r *wkhttp.WKHttp) {
 	r.POST("/conversations/clearUnread", s.clearConversationUnread) // 清空会话未读数量
 	r.POST("/conversations/setUnread", s.setConversationUnread)     // 设置会话未读数量
 	r.POST("/conversations/delete", s.deleteConversation)           // 删除会话
+	r.GET("/conversations/unread_total", s.unreadTotal)             // 获取用户的未读消息总数
 	r.POST("/conversation/sync", s.syncUserConversation)            // 同步会话
 	r.POST("/conversation/syncMessages", s.syncRecentMessages)      // 同步会话最近消息
 }
@@ -95,6 +98,9 @@ func (s *ConversationAPI) clearConversationUnread(c *wkhttp.Context) {
 		c.ResponseError(err)
 		return
 	}
+	if !s.s.checkApiKeyChannel(c, req.ChannelID) {
+		return
+	}
 
 	if s.s.opts.ClusterOn() {
 		leaderInfo, err := s.s.cluster.SlotLeaderOfChannel(req.UID, wkproto.ChannelTypePerson) // 获取频道的领导节点
@@ -117,6 +123,10 @@ func (s *ConversationAPI) clearConversationUnread(c *wkhttp.Context) {
 
 	}
 
+	// 修改期间不保存此频道的最近会话，避免被正在保存的旧未读数覆盖
+	unlock := s.s.conversationManager.LockPropose(fakeChannelId, req.ChannelType)
+	defer unlock()
+
 	conversation, err := s.s.store.GetConversation(req.UID, fakeChannelId, req.ChannelType)
 	if err != nil && err != wkdb.ErrNotFound {
 		s.Error("Failed to query conversation", zap.Error(err))
@@ -148,6 +158,7 @@ func (s *ConversationAPI) clearConversationUnread(c *wkhttp.Context) {
 		conversation.ReadToMsgSeq = msgSeq
 
 	}
+	conversation.UnreadCount = 0
 
 	err = s.s.store.AddOrUpdateUserConversations(req.UID, []wkdb.Conversation{conversation})
 	if err != nil {
@@ -158,6 +169,17 @@ func (s *ConversationAPI) clearConversationUnread(c *wkhttp.Context) {
 
 	s.s.conversationManager.DeleteUserConversationFromCache(req.UID, fakeChannelId, req.ChannelType)
 
+	s.s.webhook.TriggerEvent(&Event{
+		Event: EventConversationRead,
+		Data: &wkhook.ConversationEvent{
+			Uid:         req.UID,
+			ChannelId:   req.ChannelID,
+			ChannelType: uint32(req.ChannelType),
+			MessageSeq:  conversation.ReadToMsgSeq,
+			Timestamp:   time.Now().Unix(),
+		},
+	})
+
 	c.ResponseOK()
 }
 
@@ -183,6 +205,9 @@ func (s *ConversationAPI) setConversationUnread(c *wkhttp.Context) {
 		c.ResponseError(errors.New("channel_id or channel_type cannot be empty"))
 		return
 	}
+	if !s.s.checkApiKeyChannel(c, req.ChannelID) {
+		return
+	}
 
 	if s.s.opts.ClusterOn() {
 		leaderInfo, err := s.s.cluster.SlotLeaderOfChannel(req.UID, wkproto.ChannelTypePerson) // 获取频道的领导节点
@@ -204,6 +229,11 @@ func (s *ConversationAPI) setConversationUnread(c *wkhttp.Context) {
 		fakeChannelId = GetFakeChannelIDWith(req.UID, req.ChannelID)
 
 	}
+
+	// 修改期间不保存此频道的最近会话，避免被正在保存的旧未读数覆盖
+	unlock := s.s.conversationManager.LockPropose(fakeChannelId, req.ChannelType)
+	defer unlock()
+
 	// 获取此频道最新的消息
 	msgSeq, err := s.s.store.GetLastMsgSeq(fakeChannelId, req.ChannelType)
 	if err != nil {
@@ -271,6 +301,9 @@ func (s *ConversationAPI) deleteConversation(c *wkhttp.Context) {
 		c.ResponseError(err)
 		return
 	}
+	if !s.s.checkApiKeyChannel(c, req.ChannelID) {
+		return
+	}
 
 	if s.s.opts.ClusterOn() {
 		leaderInfo, err := s.s.cluster.SlotLeaderOfChannel(req.UID, wkproto.ChannelTypePerson) // 获取频道的领导节点
@@ -293,6 +326,10 @@ func (s *ConversationAPI) deleteConversation(c *wkhttp.Context) {
 
 	}
 
+	// 删除期间不保存此频道的最近会话，避免删除后又被正在保存的最近会话写回
+	unlock := s.s.conversationManager.LockPropose(fakeChannelId, req.ChannelType)
+	defer unlock()
+
 	err = s.s.store.DeleteConversation(req.UID, fakeChannelId, req.ChannelType)
 	if err != nil {
 		s.Error("删除会话！", zap.Error(err))
@@ -302,16 +339,21 @@ func (s *ConversationAPI) deleteConversation(c *wkhttp.Context) {
 
 	s.s.conversationManager.DeleteUserConversationFromCache(req.UID, fakeChannelId, req.ChannelType)
 
+	s.s.webhook.TriggerEvent(&Event{
+		Event: EventConversationCleared,
+		Data: &wkhook.ConversationEvent{
+			Uid:         req.UID,
+			ChannelId:   req.ChannelID,
+			ChannelType: uint32(req.ChannelType),
+			Timestamp:   time.Now().Unix(),
+		},
+	})
+
 	c.ResponseOK()
 }
 
 func (s *ConversationAPI) syncUserConversation(c *wkhttp.Context) {
-	var req struct {
-		UID         string `json:"uid"`
-		Version     int64  `json:"version"`       // 当前客户端的会话最大版本号(客户端最新会话的时间戳)
-		LastMsgSeqs string `json:"last_msg_seqs"` // 客户端所有会话的最后一条消息序列号 格式： channelID:channelType:last_msg_seq|channelID:channelType:last_msg_seq
-		MsgCount    int64  `json:"msg_count"`     // 每个会话消息数量
-	}
+	var req syncUserConversationReq
 	bodyBytes, err := BindJSON(&req, c)
 	if err != nil {
 		s.Error("数据格式有误！", zap.Error(err))
@@ -333,6 +375,11 @@ func (s *ConversationAPI) syncUserConversation(c *wkhttp.Context) {
 		return
 	}
 
+	if req.Limit > 0 {
+		s.syncUserConversationPage(c, req)
+		return
+	}
+
 	var (
 		channelLastMsgMap        = s.getChannelLastMsgSeqMap(req.LastMsgSeqs) // 获取频道对应的最后一条消息的messageSeq
 		channelRecentMessageReqs = make([]*channelRecentMessageReq, 0, len(channelLastMsgMap))
@@ -352,26 +399,8 @@ func (s *ConversationAPI) syncUserConversation(c *wkhttp.Context) {
 		return
 	}
 
-	// 获取用户缓存的最近会话
-	cacheConversations := s.s.conversationManager.GetUserConversationFromCache(req.UID, wkdb.ConversationTypeChat)
-
-	for _, cacheConversation := range cacheConversations {
-		exist := false
-		for i, conversation := range conversations {
-			if cacheConversation.ChannelId == conversation.ChannelId && cacheConversation.ChannelType == conversation.ChannelType {
-				if cacheConversation.ReadToMsgSeq > conversation.ReadToMsgSeq {
-					conversations[i].ReadToMsgSeq = cacheConversation.ReadToMsgSeq
-				}
-				exist = true
-				break
-			}
-		}
-		if !exist {
-			conversations = append(conversations, cacheConversation)
-		}
-	}
-
-	// conversations里去掉重复的
+	// 合并用户缓存的最近会话
+	conversations = s.mergeCacheConversations(req.UID, conversations)
 
 	// 获取真实的频道ID
 	getRealChannelId := func(fakeChannelId string, channelType uint8) string {
@@ -387,9 +416,6 @@ func (s *ConversationAPI) syncUserConversation(c *wkhttp.Context) {
 		return realChannelId
 	}
 
-	// 去掉重复的会话
-	conversations = removeDuplicates(conversations)
-
 	// 设置最近会话已读至的消息序列号
 	for _, conversation := range conversations {
 
@@ -415,7 +441,7 @@ func (s *ConversationAPI) syncUserConversation(c *wkhttp.Context) {
 		var channelRecentMessages []*channelRecentMessage
 
 		// 获取用户最近会话的最近消息
-		channelRecentMessages, err = s.s.getRecentMessagesForCluster(req.UID, int(req.MsgCount), channelRecentMessageReqs, true)
+		channelRecentMessages, err = s.s.getRecentMessagesForCluster(req.UID, int(req.MsgCount), channelRecentMessageReqs, true, wkutil.IntToBool(req.StrictRead))
 		if err != nil {
 			s.Error("获取最近消息失败！", zap.Error(err), zap.String("uid", req.UID))
 			c.ResponseError(errors.New("获取最近消息失败！"))
@@ -428,6 +454,9 @@ func (s *ConversationAPI) syncUserConversation(c *wkhttp.Context) {
 			if conversation.ChannelType == wkproto.ChannelTypePerson && realChannelId == s.s.opts.SystemUID { // 系统消息不返回
 				continue
 			}
+			if !apiKeyAllowChannel(c, realChannelId) { // 访问密钥不允许的频道不返回
+				continue
+			}
 			resp := newSyncUserConversationResp(conversation)
 
 			for _, channelRecentMessage := range channelRecentMessages {
@@ -437,9 +466,7 @@ func (s *ConversationAPI) syncUserConversation(c *wkhttp.Context) {
 						resp.LastMsgSeq = uint32(lastMsg.MessageSeq)
 						resp.LastClientMsgNo = lastMsg.ClientMsgNo
 						resp.Timestamp = int64(lastMsg.Timestamp)
-						if lastMsg.MessageSeq > uint64(resp.ReadedToMsgSeq) {
-							resp.Unread = int(lastMsg.MessageSeq - uint64(resp.ReadedToMsgSeq))
-						}
+						resp.fillDerivedUnread()
 
 						resp.Version = time.Unix(int64(lastMsg.Timestamp), 0).UnixNano()
 					}
@@ -464,6 +491,338 @@ func (s *ConversationAPI) syncUserConversation(c *wkhttp.Context) {
 	c.JSON(http.StatusOK, resps)
 }
 
+// syncUserConversationPage 按会话版本号分页增量同步最近会话，返回版本号大于conversation_version的新增、更新和删除的会话
+// 传递了last_msg_seqs时，最后一页还会返回版本号没有变化但频道有新消息的会话
+func (s *ConversationAPI) syncUserConversationPage(c *wkhttp.Context, req syncUserConversationReq) {
+	limit := req.Limit
+	if s.s.opts.Conversation.SyncPageMaxSize > 0 && limit > s.s.opts.Conversation.SyncPageMaxSize {
+		limit = s.s.opts.Conversation.SyncPageMaxSize
+	}
+	msgCount := int(req.MsgCount)
+	if s.s.opts.Conversation.SyncMaxMsgCount > 0 && msgCount > s.s.opts.Conversation.SyncMaxMsgCount {
+		msgCount = s.s.opts.Conversation.SyncMaxMsgCount
+	}
+
+	delta, err := s.s.store.GetConversationDelta(req.UID, wkdb.ConversationTypeChat, req.ConversationVersion, limit)
+	if err != nil {
+		s.Error("获取conversation失败！", zap.Error(err), zap.String("uid", req.UID), zap.Uint64("conversationVersion", req.ConversationVersion))
+		c.ResponseError(errors.New("获取conversation失败！"))
+		return
+	}
+	conversations := delta.Conversations
+
+	// 合并缓存里还没有保存的会话数据，缓存里的会话保存后会分配新的版本号，客户端下次同步时会再次收到
+	cacheConversations := s.s.conversationManager.GetUserConversationFromCache(req.UID, wkdb.ConversationTypeChat)
+	for _, cacheConversation := range cacheConversations {
+		exist := false
+		for i, conversation := range conversations {
+			if cacheConversation.ChannelId == conversation.ChannelId && cacheConversation.ChannelType == conversation.ChannelType {
+				mergeCacheConversation(&conversations[i], cacheConversation)
+				exist = true
+				break
+			}
+		}
+		if exist || delta.More { // 缓存里其他的会话在最后一页返回
+			continue
+		}
+		conversation, err := s.s.store.GetConversation(req.UID, cacheConversation.ChannelId, cacheConversation.ChannelType)
+		if err != nil && err != wkdb.ErrNotFound {
+			s.Error("获取conversation失败！", zap.Error(err), zap.String("uid", req.UID), zap.String("channelId", cacheConversation.ChannelId))
+			c.ResponseError(errors.New("获取conversation失败！"))
+			return
+		}
+		if err == wkdb.ErrNotFound {
+			conversation = cacheConversation
+		} else {
+			mergeCacheConversation(&conversation, cacheConversation)
+		}
+		conversations = append(conversations, conversation)
+	}
+	s.s.conversationManager.FillUserUnreadFromCache(req.UID, conversations)
+
+	// 会话的版本号只在会话数据变化时递增，频道有新消息时版本号不一定变化（超大频道不维护未读数、回执只更新已读位置）
+	// 最后一页再按客户端传递的last_msg_seqs比较频道最新的消息序号，把有新消息的会话一起返回
+	if !delta.More && req.LastMsgSeqs != "" {
+		conversations, err = s.appendAdvancedConversations(req, conversations, delta.Tombstones)
+		if err != nil {
+			s.Error("获取有新消息的会话失败！", zap.Error(err), zap.String("uid", req.UID))
+			c.ResponseError(errors.New("获取conversation失败！"))
+			return
+		}
+	}
+
+	resp := &syncUserConversationPageResp{
+		Conversations: make([]*syncUserConversationResp, 0, len(conversations)),
+		Deleted:       make([]*syncUserConversationDeletedResp, 0, len(delta.Tombstones)),
+		Version:       delta.Version,
+		More:          wkutil.BoolToInt(delta.More),
+		Reset:         wkutil.BoolToInt(delta.Reset),
+	}
+
+	for _, tombstone := range delta.Tombstones {
+		if !apiKeyAllowChannel(c, s.realChannelId(req.UID, tombstone.ChannelId, tombstone.ChannelType)) {
+			continue
+		}
+		resp.Deleted = append(resp.Deleted, &syncUserConversationDeletedResp{
+			ChannelId:           s.realChannelId(req.UID, tombstone.ChannelId, tombstone.ChannelType),
+			ChannelType:         tombstone.ChannelType,
+			ConversationVersion: tombstone.Version,
+		})
+	}
+
+	// ==================== 获取会话的最近的消息列表 ====================
+	var channelRecentMessages []*channelRecentMessage
+	if msgCount > 0 && len(conversations) > 0 {
+		channelLastMsgMap := s.getChannelLastMsgSeqMap(req.LastMsgSeqs)
+		channelRecentMessageReqs := make([]*channelRecentMessageReq, 0, len(conversations))
+		for _, conversation := range conversations {
+			msgSeq := channelLastMsgMap[fmt.Sprintf("%s-%d", s.realChannelId(req.UID, conversation.ChannelId, conversation.ChannelType), conversation.ChannelType)]
+			if msgSeq != 0 {
+				msgSeq = msgSeq + 1 // 如果客户端传递了messageSeq，则需要获取这个messageSeq之后的消息
+			}
+			channelRecentMessageReqs = append(channelRecentMessageReqs, &channelRecentMessageReq{
+				ChannelId:   conversation.ChannelId,
+				ChannelType: conversation.ChannelType,
+				LastMsgSeq:  msgSeq,
+			})
+		}
+		channelRecentMessages, err = s.s.getRecentMessagesForCluster(req.UID, msgCount, channelRecentMessageReqs, true, wkutil.IntToBool(req.StrictRead))
+		if err != nil {
+			s.Error("获取最近消息失败！", zap.Error(err), zap.String("uid", req.UID))
+			c.ResponseError(errors.New("获取最近消息失败！"))
+			return
+		}
+	}
+
+	for _, conversation := range conversations {
+		realChannelId := s.realChannelId(req.UID, conversation.ChannelId, conversation.ChannelType)
+		if conversation.ChannelType == wkproto.ChannelTypePerson && realChannelId == s.s.opts.SystemUID { // 系统消息不返回
+			continue
+		}
+		if !apiKeyAllowChannel(c, realChannelId) { // 访问密钥不允许的频道不返回
+			continue
+		}
+		conversationResp := newSyncUserConversationResp(conversation)
+		for _, channelRecentMessage := range channelRecentMessages {
+			if conversation.ChannelId == channelRecentMessage.ChannelId && conversation.ChannelType == channelRecentMessage.ChannelType {
+				if len(channelRecentMessage.Messages) > 0 {
+					lastMsg := channelRecentMessage.Messages[0]
+					conversationResp.LastMsgSeq = uint32(lastMsg.MessageSeq)
+					conversationResp.LastClientMsgNo = lastMsg.ClientMsgNo
+					conversationResp.Timestamp = int64(lastMsg.Timestamp)
+					conversationResp.fillDerivedUnread()
+					conversationResp.Version = time.Unix(int64(lastMsg.Timestamp), 0).UnixNano()
+				}
+				conversationResp.Recents = channelRecentMessage.Messages
+				break
+			}
+		}
+		resp.Conversations = append(resp.Conversations, conversationResp)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// appendAdvancedConversations 追加频道最新消息序号大于客户端已知序号（last_msg_seqs）的会话，已经在增量里的会话和已删除的会话不重复返回
+func (s *ConversationAPI) appendAdvancedConversations(req syncUserConversationReq, conversations []wkdb.Conversation, tombstones []wkdb.ConversationTombstone) ([]wkdb.Conversation, error) {
+	channelLastMsgMap := s.getChannelLastMsgSeqMap(req.LastMsgSeqs)
+	if len(channelLastMsgMap) == 0 {
+		return conversations, nil
+	}
+	exists := make(map[string]struct{}, len(conversations)+len(tombstones))
+	for _, conversation := range conversations {
+		exists[wkutil.ChannelToKey(conversation.ChannelId, conversation.ChannelType)] = struct{}{}
+	}
+	for _, tombstone := range tombstones {
+		exists[wkutil.ChannelToKey(tombstone.ChannelId, tombstone.ChannelType)] = struct{}{}
+	}
+
+	userConversations, err := s.s.store.GetLastConversations(req.UID, wkdb.ConversationTypeChat, 0, s.s.opts.Conversation.UserMaxCount)
+	if err != nil && err != wkdb.ErrNotFound {
+		return nil, err
+	}
+	candidates := make(map[string]wkdb.Conversation)
+	knownSeqs := make(map[string]uint64)
+	channelRecentMessageReqs := make([]*channelRecentMessageReq, 0)
+	for _, conversation := range userConversations {
+		channelKey := wkutil.ChannelToKey(conversation.ChannelId, conversation.ChannelType)
+		if _, ok := exists[channelKey]; ok {
+			continue
+		}
+		knownSeq := channelLastMsgMap[fmt.Sprintf("%s-%d", s.realChannelId(req.UID, conversation.ChannelId, conversation.ChannelType), conversation.ChannelType)]
+		if knownSeq == 0 { // 客户端没有这个会话的消息序号，无法比较
+			continue
+		}
+		candidates[channelKey] = conversation
+		knownSeqs[channelKey] = knownSeq
+		channelRecentMessageReqs = append(channelRecentMessageReqs, &channelRecentMessageReq{
+			ChannelId:   conversation.ChannelId,
+			ChannelType: conversation.ChannelType,
+			LastMsgSeq:  knownSeq + 1,
+		})
+	}
+	if len(channelRecentMessageReqs) == 0 {
+		return conversations, nil
+	}
+	channelRecentMessages, err := s.s.getRecentMessagesForCluster(req.UID, 1, channelRecentMessageReqs, true, wkutil.IntToBool(req.StrictRead))
+	if err != nil {
+		return nil, err
+	}
+	for _, channelRecentMessage := range channelRecentMessages {
+		if len(channelRecentMessage.Messages) == 0 {
+			continue
+		}
+		channelKey := wkutil.ChannelToKey(channelRecentMessage.ChannelId, channelRecentMessage.ChannelType)
+		conversation, ok := candidates[channelKey]
+		if !ok || uint64(channelRecentMessage.Messages[0].MessageSeq) <= knownSeqs[channelKey] {
+			continue
+		}
+		conversations = append(conversations, conversation)
+	}
+	return conversations, nil
+}
+
+// realChannelId 获取会话真实的频道ID，单聊为对方的uid
+func (s *ConversationAPI) realChannelId(uid string, channelId string, channelType uint8) string {
+	if channelType != wkproto.ChannelTypePerson {
+		return channelId
+	}
+	from, to := GetFromUIDAndToUIDWith(channelId)
+	if uid == from {
+		return to
+	}
+	return from
+}
+
+// unreadTotal 获取用户所有最近会话的未读消息总数（不包括命令会话）
+func (s *ConversationAPI) unreadTotal(c *wkhttp.Context) {
+	uid := c.Query("uid")
+	if strings.TrimSpace(uid) == "" {
+		c.ResponseError(errors.New("uid不能为空！"))
+		return
+	}
+
+	leaderInfo, err := s.s.cluster.SlotLeaderOfChannel(uid, wkproto.ChannelTypePerson) // 获取频道的领导节点
+	if err != nil {
+		s.Error("获取频道所在节点失败！", zap.Error(err), zap.String("channelID", uid), zap.Uint8("channelType", wkproto.ChannelTypePerson))
+		c.ResponseError(errors.New("获取频道所在节点失败！"))
+		return
+	}
+	if leaderInfo.Id != s.s.opts.Cluster.NodeId {
+		s.Debug("转发请求：", zap.String("url", fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path)))
+		c.ForwardWithBody(fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path), nil)
+		return
+	}
+
+	conversations, err := s.s.store.GetConversationsByType(uid, wkdb.ConversationTypeChat)
+	if err != nil && err != wkdb.ErrNotFound {
+		s.Error("获取conversation失败！", zap.Error(err), zap.String("uid", uid))
+		c.ResponseError(errors.New("获取conversation失败！"))
+		return
+	}
+	conversations = s.mergeCacheConversations(uid, conversations)
+
+	var (
+		unread            int // 未读消息总数
+		unreadChannels    int // 有未读消息的会话数量
+		mentionedChannels int // 有未读@消息的会话数量
+	)
+	counted := make([]wkdb.Conversation, 0, len(conversations))
+	untrackedReqs := make([]*channelRecentMessageReq, 0)
+	for _, conversation := range conversations {
+		if conversation.ChannelType == wkproto.ChannelTypePerson {
+			from, to := GetFromUIDAndToUIDWith(conversation.ChannelId)
+			if from == s.s.opts.SystemUID || to == s.s.opts.SystemUID { // 系统消息不计入
+				continue
+			}
+		}
+		if !apiKeyAllowChannel(c, s.realChannelId(uid, conversation.ChannelId, conversation.ChannelType)) { // 访问密钥不允许的频道不计入
+			continue
+		}
+		counted = append(counted, conversation)
+		if !conversation.UnreadTracked {
+			untrackedReqs = append(untrackedReqs, &channelRecentMessageReq{
+				ChannelId:   conversation.ChannelId,
+				ChannelType: conversation.ChannelType,
+			})
+		}
+	}
+
+	// 没有维护未读数的会话由最后一条消息序号和已读位置推算，最后一条消息序号按频道领导节点分批获取
+	lastMsgSeqs := make(map[string]uint64, len(untrackedReqs))
+	if len(untrackedReqs) > 0 {
+		channelRecentMessages, err := s.s.getRecentMessagesForCluster(uid, 1, untrackedReqs, true, false)
+		if err != nil {
+			s.Warn("获取频道最后一条消息序号失败！", zap.Error(err), zap.String("uid", uid))
+		}
+		for _, channelRecentMessage := range channelRecentMessages {
+			if len(channelRecentMessage.Messages) == 0 {
+				continue
+			}
+			lastMsgSeqs[wkutil.ChannelToKey(channelRecentMessage.ChannelId, channelRecentMessage.ChannelType)] = uint64(channelRecentMessage.Messages[0].MessageSeq)
+		}
+	}
+
+	for _, conversation := range counted {
+		unreadCount := int(conversation.UnreadCount)
+		if !conversation.UnreadTracked {
+			unreadCount = 0
+			lastMsgSeq := lastMsgSeqs[wkutil.ChannelToKey(conversation.ChannelId, conversation.ChannelType)]
+			if lastMsgSeq > conversation.ReadToMsgSeq {
+				unreadCount = int(lastMsgSeq - conversation.ReadToMsgSeq)
+			}
+		}
+		if unreadCount > 0 {
+			unread += unreadCount
+			unreadChannels++
+		}
+		if conversation.MentionMsgSeq > conversation.ReadToMsgSeq {
+			mentionedChannels++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"uid":                uid,
+		"unread":             unread,
+		"unread_channels":    unreadChannels,
+		"mentioned_channels": mentionedChannels,
+	})
+}
+
+// mergeCacheConversations 合并缓存里还没有保存的最近会话（包括未读数和@位置），并去掉重复的会话
+func (s *ConversationAPI) mergeCacheConversations(uid string, conversations []wkdb.Conversation) []wkdb.Conversation {
+	cacheConversations := s.s.conversationManager.GetUserConversationFromCache(uid, wkdb.ConversationTypeChat)
+	for _, cacheConversation := range cacheConversations {
+		exist := false
+		for i, conversation := range conversations {
+			if cacheConversation.ChannelId == conversation.ChannelId && cacheConversation.ChannelType == conversation.ChannelType {
+				mergeCacheConversation(&conversations[i], cacheConversation)
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			conversations = append(conversations, cacheConversation)
+		}
+	}
+
+	// 去掉重复的会话
+	conversations = removeDuplicates(conversations)
+
+	// 加上还没有保存的未读数
+	s.s.conversationManager.FillUserUnreadFromCache(uid, conversations)
+	return conversations
+}
+
+// mergeCacheConversation 会话合并缓存里更新的已读位置和@位置
+func mergeCacheConversation(conversation *wkdb.Conversation, cacheConversation wkdb.Conversation) {
+	if cacheConversation.ReadToMsgSeq > conversation.ReadToMsgSeq {
+		conversation.ReadToMsgSeq = cacheConversation.ReadToMsgSeq
+	}
+	if cacheConversation.MentionMsgSeq > conversation.MentionMsgSeq {
+		conversation.MentionMsgSeq = cacheConversation.MentionMsgSeq
+	}
+}
+
 func removeDuplicates(conversations []wkdb.Conversation) []wkdb.Conversation {
 	seen := make(map[string]bool)
 	result := []wkdb.Conversation{}
@@ -506,6 +865,11 @@ func (s *ConversationAPI) syncRecentMessages(c *wkhttp.Context) {
 		c.ResponseError(errors.New("数据格式有误！"))
 		return
 	}
+	for _, channel := range req.Channels {
+		if !s.s.checkApiKeyChannel(c, s.realChannelId(req.UID, channel.ChannelId, channel.ChannelType)) {
+			return
+		}
+	}
 	msgCount := req.MsgCount
 	if msgCount <= 0 {
 		msgCount = 15
@@ -519,7 +883,8 @@ func (s *ConversationAPI) syncRecentMessages(c *wkhttp.Context) {
 	c.JSON(http.StatusOK, channelRecentMessages)
 }
 
-func (s *Server) getRecentMessagesForCluster(uid string, msgCount int, channels []*channelRecentMessageReq, orderByLast bool) ([]*channelRecentMessage, error) {
+// strict: 开启跟随者读时，是否需要严格一致（本地副本需要应用到领导的读索引）
+func (s *Server) getRecentMessagesForCluster(uid string, msgCount int, channels []*channelRecentMessageReq, orderByLast bool, strict bool) ([]*channelRecentMessage, error) {
 	if len(channels) == 0 {
 		return nil, nil
 	}
@@ -532,7 +897,11 @@ func (s *Server) getRecentMessagesForCluster(uid string, msgCount int, channels
 	peerChannelRecentMessageReqsMap := make(map[uint64][]*channelRecentMessageReq)
 	for _, channelRecentMsgReq := range channels {
 		fakeChannelId := channelRecentMsgReq.ChannelId
-		leaderInfo, err := s.cluster.LeaderOfChannelForRead(fakeChannelId, channelRecentMsgReq.ChannelType) // 获取频道的领导节点
+		var knownSeq uint64 // 客户端已知的消息序号
+		if channelRecentMsgReq.LastMsgSeq > 0 {
+			knownSeq = channelRecentMsgReq.LastMsgSeq - 1
+		}
+		leaderInfo, err := s.nodeOfChannelForRead(fakeChannelId, channelRecentMsgReq.ChannelType, knownSeq, strict) // 获取频道的领导节点（开启跟随者读时可能是本地副本）
 		if err != nil {
 			s.Warn("getRecentMessagesForCluster: 获取频道所在节点失败！", zap.Error(err), zap.String("channelId", fakeChannelId), zap.Uint8("channelType", channelRecentMsgReq.ChannelType))
 			continue
@@ -595,15 +964,17 @@ func (s *Server) requestSyncMessage(nodeID uint64, reqs []*channelRecentMessageR
 		return nil, err
 	}
 	reqURL := fmt.Sprintf("%s/%s", nodeInfo.ApiServerAddr, "conversation/syncMessages")
+	body := []byte(wkutil.ToJSON(map[string]interface{}{
+		"uid":           uid,
+		"msg_count":     msgCount,
+		"channels":      reqs,
+		"order_by_last": wkutil.BoolToInt(orderByLast),
+	}))
 	request := rest.Request{
 		Method:  rest.Method("POST"),
 		BaseURL: reqURL,
-		Body: []byte(wkutil.ToJSON(map[string]interface{}{
-			"uid":           uid,
-			"msg_count":     msgCount,
-			"channels":      reqs,
-			"order_by_last": wkutil.BoolToInt(orderByLast),
-		})),
+		Headers: s.apiServer.nodeRequestHeaders(http.MethodPost, reqURL, body),
+		Body:    body,
 	}
 	s.Debug("同步会话消息!", zap.String("apiURL", reqURL), zap.String("uid", uid), zap.Any("channels", reqs))
 	resp, err := rest.API(request)
@@ -644,6 +1015,10 @@ func (s *Server) getRecentMessages(uid string, msgCount int, channels []*channel
 					s.Error("查询最近消息失败！", zap.Error(err), zap.String("uid", uid), zap.String("fakeChannelID", fakeChannelID), zap.Uint8("channelType", channel.ChannelType), zap.Uint64("LastMsgSeq", channel.LastMsgSeq))
 					return nil, err
 				}
+				recentMessages, err = s.trimUnappliedMessages(fakeChannelID, channel.ChannelType, recentMessages)
+				if err != nil {
+					return nil, err
+				}
 				if len(recentMessages) > 0 {
 					for _, recentMessage := range recentMessages {
 						messageResp := &MessageResp{}
@@ -658,6 +1033,10 @@ func (s *Server) getRecentMessages(uid string, msgCount int, channels []*channel
 					s.Error("查询最近消息失败！", zap.Error(err), zap.String("uid", uid), zap.String("fakeChannelID", fakeChannelID), zap.Uint8("channelType", channel.ChannelType), zap.Uint64("LastMsgSeq", channel.LastMsgSeq))
 					return nil, err
 				}
+				recentMessages, err = s.trimUnappliedMessages(fakeChannelID, channel.ChannelType, recentMessages)
+				if err != nil {
+					return nil, err
+				}
 				if len(recentMessages) > 0 {
 					for _, recentMessage := range recentMessages {
 						messageResp := &MessageResp{}
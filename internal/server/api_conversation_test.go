@@ -2,13 +2,16 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/WuKongIM/WuKongIM/pkg/client"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
 	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -66,3 +69,141 @@ func TestSyncUserConversation(t *testing.T) {
 	assert.Equal(t, "u1", conversations[0].ChannelId)
 	assert.Equal(t, 1, conversations[0].Unread)
 }
+
+func TestSyncUserConversationPage(t *testing.T) {
+	s := NewTestServer(t, WithHTTPAddr(testFreeAddr(t)), WithManagerAddr(testFreeAddr(t)), WithDemoOn(false))
+	err := s.Start()
+	assert.NoError(t, err)
+	defer func() {
+		_ = s.Stop()
+	}()
+
+	s.MustWaitAllSlotsReady(time.Second * 10)
+
+	uid := "u1"
+	err = s.store.AddOrUpdateUserConversations(uid, []wkdb.Conversation{
+		{Uid: uid, ChannelId: "g1", ChannelType: wkproto.ChannelTypeGroup},
+		{Uid: uid, ChannelId: "g2", ChannelType: wkproto.ChannelTypeGroup},
+		{Uid: uid, ChannelId: "g3", ChannelType: wkproto.ChannelTypeGroup},
+	})
+	assert.NoError(t, err)
+
+	syncPage := func(version uint64, limit int) *syncUserConversationPageResp {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/conversation/sync", bytes.NewReader([]byte(wkutil.ToJson(map[string]interface{}{
+			"uid":                  uid,
+			"conversation_version": version,
+			"limit":                limit,
+		}))))
+		s.apiServer.r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp *syncUserConversationPageResp
+		err := wkutil.ReadJSONByByte(w.Body.Bytes(), &resp)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	// 第一页
+	resp := syncPage(0, 2)
+	assert.Equal(t, 2, len(resp.Conversations))
+	assert.Equal(t, "g1", resp.Conversations[0].ChannelId)
+	assert.Equal(t, "g2", resp.Conversations[1].ChannelId)
+	assert.Equal(t, 1, resp.More)
+
+	// 第二页
+	resp = syncPage(resp.Version, 2)
+	assert.Equal(t, 1, len(resp.Conversations))
+	assert.Equal(t, "g3", resp.Conversations[0].ChannelId)
+	assert.Equal(t, 0, resp.More)
+	version := resp.Version
+
+	// 没有变化
+	resp = syncPage(version, 2)
+	assert.Equal(t, 0, len(resp.Conversations))
+	assert.Equal(t, 0, len(resp.Deleted))
+	assert.Equal(t, version, resp.Version)
+
+	// 删除会话后增量同步返回删除记录
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/conversations/delete", bytes.NewReader([]byte(wkutil.ToJson(map[string]interface{}{
+		"uid":          uid,
+		"channel_id":   "g2",
+		"channel_type": wkproto.ChannelTypeGroup,
+	}))))
+	s.apiServer.r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	resp = syncPage(version, 2)
+	assert.Equal(t, 0, len(resp.Conversations))
+	assert.Equal(t, 1, len(resp.Deleted))
+	assert.Equal(t, "g2", resp.Deleted[0].ChannelId)
+	assert.Equal(t, resp.Version, resp.Deleted[0].ConversationVersion)
+	assert.Greater(t, resp.Version, version)
+}
+
+func TestSyncUserConversationPageNewMessages(t *testing.T) {
+	s := NewTestServer(t, WithHTTPAddr(testFreeAddr(t)), WithManagerAddr(testFreeAddr(t)), WithDemoOn(false))
+	err := s.Start()
+	assert.NoError(t, err)
+	defer func() {
+		_ = s.Stop()
+	}()
+
+	s.MustWaitAllSlotsReady(time.Second * 10)
+
+	uid := "u1"
+	now := time.Now()
+	err = s.store.AddOrUpdateUserConversations(uid, []wkdb.Conversation{
+		{Uid: uid, ChannelId: "g1", ChannelType: wkproto.ChannelTypeGroup, UpdatedAt: &now},
+		{Uid: uid, ChannelId: "g2", ChannelType: wkproto.ChannelTypeGroup, UpdatedAt: &now},
+	})
+	assert.NoError(t, err)
+
+	syncPage := func(version uint64, lastMsgSeqs string) *syncUserConversationPageResp {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/conversation/sync", bytes.NewReader([]byte(wkutil.ToJson(map[string]interface{}{
+			"uid":                  uid,
+			"conversation_version": version,
+			"limit":                10,
+			"last_msg_seqs":        lastMsgSeqs,
+		}))))
+		s.apiServer.r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp *syncUserConversationPageResp
+		err := wkutil.ReadJSONByByte(w.Body.Bytes(), &resp)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	resp := syncPage(0, "")
+	assert.Equal(t, 2, len(resp.Conversations))
+	version := resp.Version
+
+	// 频道有新消息但会话版本号没有变化（例如超大频道不维护未读数）
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	_, err = s.clusterServer.LoadOrCreateChannel(timeoutCtx, "g1", wkproto.ChannelTypeGroup)
+	cancel()
+	assert.NoError(t, err)
+	for i := 1; i <= 2; i++ {
+		err = s.store.DB().AppendMessages("g1", wkproto.ChannelTypeGroup, []wkdb.Message{
+			{RecvPacket: wkproto.RecvPacket{ChannelID: "g1", ChannelType: wkproto.ChannelTypeGroup, MessageSeq: uint32(i), Payload: []byte("hello")}},
+		})
+		assert.NoError(t, err)
+	}
+	err = s.store.DB().SetChannelLastMessageSeq("g1", wkproto.ChannelTypeGroup, 2)
+	assert.NoError(t, err)
+
+	// 没有传递last_msg_seqs只按版本号同步
+	resp = syncPage(version, "")
+	assert.Equal(t, 0, len(resp.Conversations))
+
+	// 客户端已知g1的消息序号为1，返回有新消息的g1
+	resp = syncPage(version, "g1:2:1|g2:2:1")
+	assert.Equal(t, 1, len(resp.Conversations))
+	assert.Equal(t, "g1", resp.Conversations[0].ChannelId)
+	assert.Equal(t, version, resp.Version)
+
+	// 已经是最新的消息
+	resp = syncPage(version, "g1:2:2|g2:2:1")
+	assert.Equal(t, 0, len(resp.Conversations))
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	"github.com/WuKongIM/WuKongIM/pkg/network"
+	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// DatasourceAPI 第三方数据源的缓存管理
+type DatasourceAPI struct {
+	s *Server
+	wklog.Log
+}
+
+// NewDatasourceAPI NewDatasourceAPI
+func NewDatasourceAPI(s *Server) *DatasourceAPI {
+	return &DatasourceAPI{
+		s:   s,
+		Log: wklog.NewWKLog("DatasourceAPI"),
+	}
+}
+
+// Route 路由
+func (d *DatasourceAPI) Route(r *wkhttp.WKHttp) {
+	r.POST("/datasource/cache_invalidate", d.cacheInvalidate)            // 让所有节点的数据源缓存失效
+	r.POST("/datasource/cache_invalidate_local", d.cacheInvalidateLocal) // 仅仅让本节点的数据源缓存失效
+}
+
+type datasourceCacheInvalidateReq struct {
+	ChannelId   string `json:"channel_id"` // 为空表示所有频道
+	ChannelType uint8  `json:"channel_type"`
+}
+
+func (d *DatasourceAPI) cacheInvalidate(c *wkhttp.Context) {
+	var req datasourceCacheInvalidateReq
+	if err := c.BindJSON(&req); err != nil {
+		d.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(errors.Wrap(err, "数据格式有误！"))
+		return
+	}
+	if req.ChannelId != "" && !d.s.checkApiKeyChannel(c, req.ChannelId) {
+		return
+	}
+
+	d.invalidateLocal(req)
+
+	if d.s.opts.ClusterOn() {
+		nodes := d.s.clusterServer.GetConfig().Nodes
+		timeoutCtx, cancel := context.WithTimeout(context.Background(), d.s.opts.Cluster.ReqTimeout)
+		defer cancel()
+		requestGroup, _ := errgroup.WithContext(timeoutCtx)
+		for _, node := range nodes {
+			if node.Id == d.s.opts.Cluster.NodeId || !node.Online {
+				continue
+			}
+			requestGroup.Go(func(n *pb.Node) func() error {
+				return func() error {
+					return d.requestCacheInvalidate(n, req)
+				}
+			}(node))
+		}
+		if err := requestGroup.Wait(); err != nil {
+			d.Error("数据源缓存失效失败！", zap.Error(err))
+			c.ResponseError(errors.New("数据源缓存失效失败！"))
+			return
+		}
+	}
+	c.ResponseOK()
+}
+
+func (d *DatasourceAPI) cacheInvalidateLocal(c *wkhttp.Context) {
+	var req datasourceCacheInvalidateReq
+	if err := c.BindJSON(&req); err != nil {
+		d.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(errors.Wrap(err, "数据格式有误！"))
+		return
+	}
+	d.invalidateLocal(req)
+	c.ResponseOK()
+}
+
+// invalidateLocal 让本节点的缓存失效，并重新生成本节点上频道的接收者标签
+func (d *DatasourceAPI) invalidateLocal(req datasourceCacheInvalidateReq) {
+	if req.ChannelId == "" {
+		d.s.datasource.invalidateAll()
+		return
+	}
+	d.s.datasource.invalidate(req.ChannelId, req.ChannelType)
+	if req.ChannelType == wkproto.ChannelTypePerson {
+		return
+	}
+	for _, channelId := range []string{req.ChannelId, d.s.opts.OrginalConvertCmdChannel(req.ChannelId)} {
+		channelKey := wkutil.ChannelToKey(channelId, req.ChannelType)
+		ch := d.s.channelReactor.reactorSub(channelKey).channel(channelKey)
+		if ch == nil || ch.receiverTagKey.Load() == "" { // 没有接收者标签的频道投递时会重新生成
+			continue
+		}
+		if _, err := ch.makeReceiverTag(); err != nil {
+			d.Warn("重新生成接收者标签失败！", zap.Error(err), zap.String("channelId", channelId), zap.Uint8("channelType", req.ChannelType))
+		}
+	}
+}
+
+func (d *DatasourceAPI) requestCacheInvalidate(nodeInfo *pb.Node, req datasourceCacheInvalidateReq) error {
+	reqURL := fmt.Sprintf("%s/datasource/cache_invalidate_local", nodeInfo.ApiServerAddr)
+	body := []byte(wkutil.ToJSON(req))
+	// 带上节点签名，开启访问密钥后节点之间的请求也能通过认证
+	resp, err := network.Post(reqURL, body, d.s.apiServer.nodeRequestHeaders(http.MethodPost, reqURL, body))
+	if err != nil {
+		d.Error("数据源缓存失效请求失败！", zap.Error(err), zap.String("reqURL", reqURL))
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		d.Error("数据源缓存失效请求状态错误！", zap.Int("status", resp.StatusCode), zap.String("body", resp.Body), zap.String("reqURL", reqURL))
+		return fmt.Errorf("数据源缓存失效请求状态错误！[%d]", resp.StatusCode)
+	}
+	return nil
+}
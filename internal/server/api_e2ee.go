@@ -0,0 +1,284 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"go.uber.org/zap"
+)
+
+// 每个设备最多保存的一次性预共享公钥数量
+const e2eeMaxOneTimePrekeys = 200
+
+// E2EEAPI 端到端加密的公钥目录
+// 服务端只负责保存和分发客户端上传的公钥，消息内容的加解密都在客户端完成
+type E2EEAPI struct {
+	s *Server
+	wklog.Log
+
+	bundleMu sync.Mutex // 公钥包的读改写（上传、获取、删除）串行执行，保证一次性预共享公钥只下发一次
+}
+
+// NewE2EEAPI NewE2EEAPI
+func NewE2EEAPI(s *Server) *E2EEAPI {
+	return &E2EEAPI{
+		s:   s,
+		Log: wklog.NewWKLog("E2EEAPI"),
+	}
+}
+
+// Route 路由
+func (e *E2EEAPI) Route(r *wkhttp.WKHttp) {
+	r.POST("/user/e2ee/keys_upload", e.upload) // 上传设备的公钥包
+	r.POST("/user/e2ee/keys_fetch", e.fetch)   // 获取用户设备的公钥包（每个设备附带一个一次性预共享公钥）
+	r.POST("/user/e2ee/keys_count", e.count)   // 设备剩余的一次性预共享公钥数量
+	r.POST("/user/e2ee/keys_delete", e.delete) // 删除设备的公钥包
+}
+
+type e2eeSignedPrekey struct {
+	Id        uint32 `json:"id"`
+	Key       string `json:"key"`
+	Signature string `json:"signature"` // 身份密钥对公钥的签名
+}
+
+type e2eePrekey struct {
+	Id  uint32 `json:"id"`
+	Key string `json:"key"`
+}
+
+type e2eeKeyBundleResp struct {
+	UID           string           `json:"uid"`
+	DeviceId      string           `json:"device_id"`
+	IdentityKey   string           `json:"identity_key"`
+	SignedPrekey  e2eeSignedPrekey `json:"signed_prekey"`
+	OneTimePrekey *e2eePrekey      `json:"one_time_prekey,omitempty"` // 没有剩余的一次性预共享公钥时为空
+	UpdatedAt     int64            `json:"updated_at"`
+}
+
+func (e *E2EEAPI) upload(c *wkhttp.Context) {
+	var req struct {
+		UID            string           `json:"uid"`
+		DeviceId       string           `json:"device_id"`
+		IdentityKey    string           `json:"identity_key"`     // 身份公钥
+		SignedPrekey   e2eeSignedPrekey `json:"signed_prekey"`    // 签名预共享公钥，不传则沿用之前的
+		OneTimePrekeys []e2eePrekey     `json:"one_time_prekeys"` // 一次性预共享公钥，追加到已有的公钥后面
+		ReplacePrekeys int              `json:"replace_prekeys"`  // 是否替换掉已有的一次性预共享公钥
+	}
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		e.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if strings.TrimSpace(req.UID) == "" || strings.TrimSpace(req.DeviceId) == "" {
+		c.ResponseError(errors.New("uid和device_id不能为空！"))
+		return
+	}
+	if strings.TrimSpace(req.IdentityKey) == "" {
+		c.ResponseError(errors.New("identity_key不能为空！"))
+		return
+	}
+	if e.forwardToLeader(c, req.UID, bodyBytes) {
+		return
+	}
+
+	// 和获取公钥包互斥，避免把已经下发并移除的一次性预共享公钥重新保存
+	e.bundleMu.Lock()
+	defer e.bundleMu.Unlock()
+
+	bundle, err := e.s.store.GetE2EEKeyBundle(req.UID, req.DeviceId)
+	if err != nil && err != wkdb.ErrNotFound {
+		e.Error("获取公钥包失败！", zap.Error(err), zap.String("uid", req.UID), zap.String("deviceId", req.DeviceId))
+		c.ResponseError(err)
+		return
+	}
+	// 身份公钥变了说明设备重新生成了密钥，之前的公钥全部作废
+	if err == wkdb.ErrNotFound || bundle.IdentityKey != req.IdentityKey || req.ReplacePrekeys == 1 {
+		bundle.OneTimePrekeys = nil
+	}
+	if req.SignedPrekey.Key != "" {
+		bundle.SignedPrekeyId = req.SignedPrekey.Id
+		bundle.SignedPrekey = req.SignedPrekey.Key
+		bundle.SignedPrekeySignature = req.SignedPrekey.Signature
+	} else if bundle.IdentityKey != req.IdentityKey {
+		c.ResponseError(errors.New("signed_prekey不能为空！"))
+		return
+	}
+	exists := make(map[uint32]bool, len(bundle.OneTimePrekeys))
+	for _, prekey := range bundle.OneTimePrekeys {
+		exists[prekey.Id] = true
+	}
+	for _, prekey := range req.OneTimePrekeys {
+		if prekey.Key == "" || exists[prekey.Id] {
+			continue
+		}
+		exists[prekey.Id] = true
+		bundle.OneTimePrekeys = append(bundle.OneTimePrekeys, wkdb.E2EEPrekey{Id: prekey.Id, Key: prekey.Key})
+	}
+	if len(bundle.OneTimePrekeys) > e2eeMaxOneTimePrekeys {
+		c.ResponseError(fmt.Errorf("一次性预共享公钥数量不能超过%d个！", e2eeMaxOneTimePrekeys))
+		return
+	}
+	bundle.Uid = req.UID
+	bundle.DeviceId = req.DeviceId
+	bundle.IdentityKey = req.IdentityKey
+	bundle.UpdatedAt = 0
+
+	if err = e.s.store.SaveE2EEKeyBundle(bundle); err != nil {
+		e.Error("保存公钥包失败！", zap.Error(err), zap.String("uid", req.UID), zap.String("deviceId", req.DeviceId))
+		c.ResponseError(err)
+		return
+	}
+	c.ResponseOK()
+}
+
+func (e *E2EEAPI) fetch(c *wkhttp.Context) {
+	var req struct {
+		UID      string `json:"uid"`
+		DeviceId string `json:"device_id"` // 不传则返回用户所有设备的公钥包
+	}
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		e.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if strings.TrimSpace(req.UID) == "" {
+		c.ResponseError(errors.New("uid不能为空！"))
+		return
+	}
+	if e.forwardToLeader(c, req.UID, bodyBytes) {
+		return
+	}
+
+	e.bundleMu.Lock()
+	defer e.bundleMu.Unlock()
+
+	var bundles []wkdb.E2EEKeyBundle
+	if req.DeviceId != "" {
+		bundle, err := e.s.store.GetE2EEKeyBundle(req.UID, req.DeviceId)
+		if err != nil && err != wkdb.ErrNotFound {
+			e.Error("获取公钥包失败！", zap.Error(err), zap.String("uid", req.UID), zap.String("deviceId", req.DeviceId))
+			c.ResponseError(err)
+			return
+		}
+		if err == nil {
+			bundles = append(bundles, bundle)
+		}
+	} else {
+		bundles, err = e.s.store.GetE2EEKeyBundles(req.UID)
+		if err != nil {
+			e.Error("获取公钥包失败！", zap.Error(err), zap.String("uid", req.UID))
+			c.ResponseError(err)
+			return
+		}
+	}
+
+	resps := make([]*e2eeKeyBundleResp, 0, len(bundles))
+	for _, bundle := range bundles {
+		resp := &e2eeKeyBundleResp{
+			UID:         bundle.Uid,
+			DeviceId:    bundle.DeviceId,
+			IdentityKey: bundle.IdentityKey,
+			SignedPrekey: e2eeSignedPrekey{
+				Id:        bundle.SignedPrekeyId,
+				Key:       bundle.SignedPrekey,
+				Signature: bundle.SignedPrekeySignature,
+			},
+			UpdatedAt: bundle.UpdatedAt,
+		}
+		if len(bundle.OneTimePrekeys) > 0 {
+			prekey := bundle.OneTimePrekeys[0]
+			if err = e.s.store.RemoveE2EEOneTimePrekey(bundle.Uid, bundle.DeviceId, prekey.Id); err != nil {
+				e.Error("移除一次性预共享公钥失败！", zap.Error(err), zap.String("uid", bundle.Uid), zap.String("deviceId", bundle.DeviceId))
+				c.ResponseError(err)
+				return
+			}
+			resp.OneTimePrekey = &e2eePrekey{Id: prekey.Id, Key: prekey.Key}
+		}
+		resps = append(resps, resp)
+	}
+	c.JSON(http.StatusOK, resps)
+}
+
+func (e *E2EEAPI) count(c *wkhttp.Context) {
+	var req struct {
+		UID      string `json:"uid"`
+		DeviceId string `json:"device_id"`
+	}
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		e.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if strings.TrimSpace(req.UID) == "" || strings.TrimSpace(req.DeviceId) == "" {
+		c.ResponseError(errors.New("uid和device_id不能为空！"))
+		return
+	}
+	if e.forwardToLeader(c, req.UID, bodyBytes) {
+		return
+	}
+	bundle, err := e.s.store.GetE2EEKeyBundle(req.UID, req.DeviceId)
+	if err != nil && err != wkdb.ErrNotFound {
+		e.Error("获取公钥包失败！", zap.Error(err), zap.String("uid", req.UID), zap.String("deviceId", req.DeviceId))
+		c.ResponseError(err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"count": len(bundle.OneTimePrekeys),
+	})
+}
+
+func (e *E2EEAPI) delete(c *wkhttp.Context) {
+	var req struct {
+		UID      string `json:"uid"`
+		DeviceId string `json:"device_id"`
+	}
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		e.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if strings.TrimSpace(req.UID) == "" || strings.TrimSpace(req.DeviceId) == "" {
+		c.ResponseError(errors.New("uid和device_id不能为空！"))
+		return
+	}
+	if e.forwardToLeader(c, req.UID, bodyBytes) {
+		return
+	}
+	e.bundleMu.Lock()
+	defer e.bundleMu.Unlock()
+	if err = e.s.store.DeleteE2EEKeyBundle(req.UID, req.DeviceId); err != nil {
+		e.Error("删除公钥包失败！", zap.Error(err), zap.String("uid", req.UID), zap.String("deviceId", req.DeviceId))
+		c.ResponseError(err)
+		return
+	}
+	c.ResponseOK()
+}
+
+// forwardToLeader 公钥包和用户数据在同一个槽位，请求统一由槽位的领导节点处理，返回true表示已经转发
+func (e *E2EEAPI) forwardToLeader(c *wkhttp.Context, uid string, bodyBytes []byte) bool {
+	if !e.s.opts.ClusterOn() {
+		return false
+	}
+	leaderInfo, err := e.s.cluster.SlotLeaderOfChannel(uid, wkproto.ChannelTypePerson)
+	if err != nil {
+		e.Error("获取用户所在节点失败！", zap.Error(err), zap.String("uid", uid))
+		c.ResponseError(errors.New("获取用户所在节点失败！"))
+		return true
+	}
+	if leaderInfo.Id == e.s.opts.Cluster.NodeId {
+		return false
+	}
+	c.ForwardWithBody(fmt.Sprintf("%s%s", leaderInfo.ApiServerAddr, c.Request.URL.Path), bodyBytes)
+	return true
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"go.uber.org/zap"
+)
+
+// EncryptionAPI 本节点数据库的静态加密管理，每个节点的数据密钥独立，需要分别在各节点调用
+type EncryptionAPI struct {
+	s *Server
+	wklog.Log
+}
+
+// NewEncryptionAPI NewEncryptionAPI
+func NewEncryptionAPI(s *Server) *EncryptionAPI {
+	return &EncryptionAPI{
+		s:   s,
+		Log: wklog.NewWKLog("EncryptionAPI"),
+	}
+}
+
+// Route 路由
+func (e *EncryptionAPI) Route(r *wkhttp.WKHttp) {
+	r.GET("/db/encryption", e.status)         // 静态加密的状态
+	r.POST("/db/encryption/rotate", e.rotate) // 轮换数据密钥
+}
+
+func (e *EncryptionAPI) status(c *wkhttp.Context) {
+	c.JSON(http.StatusOK, e.s.store.DB().EncryptionStatus())
+}
+
+func (e *EncryptionAPI) rotate(c *wkhttp.Context) {
+	keyId, err := e.s.store.DB().RotateEncryptionKey()
+	if err != nil {
+		e.Error("轮换数据密钥失败！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"key_id": keyId,
+	})
+}
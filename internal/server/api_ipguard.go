@@ -0,0 +1,42 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+)
+
+// IPGuardAPI 连接层IP防护的封禁管理
+type IPGuardAPI struct {
+	s *Server
+	wklog.Log
+}
+
+// NewIPGuardAPI NewIPGuardAPI
+func NewIPGuardAPI(s *Server) *IPGuardAPI {
+	return &IPGuardAPI{
+		s:   s,
+		Log: wklog.NewWKLog("IPGuardAPI"),
+	}
+}
+
+// Route 路由
+func (a *IPGuardAPI) Route(r *wkhttp.WKHttp) {
+	r.GET("/ipguard/bans", a.bans)         // 本节点被临时封禁的IP
+	r.DELETE("/ipguard/bans/:ip", a.unban) // 解除封禁
+}
+
+func (a *IPGuardAPI) bans(c *wkhttp.Context) {
+	c.JSON(http.StatusOK, a.s.ipGuard.bans())
+}
+
+func (a *IPGuardAPI) unban(c *wkhttp.Context) {
+	ip := c.Param("ip")
+	if !a.s.ipGuard.unban(ip) {
+		c.ResponseError(errors.New("IP没有被封禁！"))
+		return
+	}
+	c.ResponseOK()
+}
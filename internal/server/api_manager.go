@@ -6,6 +6,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/WuKongIM/WuKongIM/pkg/auth"
+	"github.com/WuKongIM/WuKongIM/pkg/auth/resource"
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
 	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
 	"github.com/WuKongIM/WuKongIM/pkg/wklog"
 	"github.com/gin-gonic/gin"
@@ -30,6 +33,13 @@ func (m *ManagerAPI) Route(r *wkhttp.WKHttp) {
 
 	r.POST("/manager/login", m.login) // 登录
 
+	r.GET("/manager/users", m.userList)                // 用户列表
+	r.POST("/manager/users", m.userSave)               // 新增或修改用户
+	r.DELETE("/manager/users/:username", m.userDelete) // 删除用户
+	r.GET("/manager/roles", m.roleList)                // 角色列表
+	r.POST("/manager/roles", m.roleSave)               // 新增或修改角色
+	r.DELETE("/manager/roles/:name", m.roleDelete)     // 删除角色
+
 }
 
 func (m *ManagerAPI) login(c *wkhttp.Context) {
@@ -93,3 +103,200 @@ func (m *ManagerAPI) login(c *wkhttp.Context) {
 	})
 
 }
+
+func (m *ManagerAPI) userList(c *wkhttp.Context) {
+	if !m.s.opts.Auth.HasPermissionWithContext(c, resource.Manager.User, auth.ActionRead) {
+		c.ResponseStatus(http.StatusUnauthorized)
+		return
+	}
+	resps := make([]*managerUserResp, 0)
+	for _, user := range m.s.opts.Auth.Users {
+		resps = append(resps, &managerUserResp{
+			Username:    user.Username,
+			FromConfig:  true,
+			Permissions: user.Permissions.Format(),
+		})
+	}
+	for _, user := range m.s.clusterServer.GetConfig().ManagerUsers {
+		resps = append(resps, &managerUserResp{
+			Username:    user.Username,
+			Roles:       user.Roles,
+			Permissions: m.s.opts.Auth.Persmissions(user.Username).Format(),
+			CreatedAt:   user.CreatedAt,
+			UpdatedAt:   user.UpdatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, resps)
+}
+
+func (m *ManagerAPI) userSave(c *wkhttp.Context) {
+	if !m.s.opts.Auth.HasPermissionWithContext(c, resource.Manager.User, auth.ActionWrite) {
+		c.ResponseStatus(http.StatusUnauthorized)
+		return
+	}
+	var req struct {
+		Username string   `json:"username"`
+		Password string   `json:"password"` // 修改用户时为空表示不修改密码
+		Roles    []string `json:"roles"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.ResponseError(err)
+		return
+	}
+	if strings.TrimSpace(req.Username) == "" {
+		c.ResponseError(errors.New("用户名不能为空"))
+		return
+	}
+	if req.Username == m.s.opts.ManagerUID || m.s.opts.Auth.IsConfigUser(req.Username) {
+		c.ResponseError(errors.New("配置文件中的用户不能修改"))
+		return
+	}
+	cfg := m.s.clusterServer.GetConfig()
+	for _, role := range req.Roles {
+		if cfg.ManagerRole(role) == nil {
+			c.ResponseError(errors.New("角色不存在：" + role))
+			return
+		}
+	}
+
+	now := time.Now().Unix()
+	user := &pb.ManagerUser{
+		Username:  req.Username,
+		CreatedAt: now,
+	}
+	if old := cfg.ManagerUser(req.Username); old != nil {
+		user = old.Clone()
+	}
+	if strings.TrimSpace(req.Password) != "" {
+		passwordHash, err := auth.HashPassword(req.Password)
+		if err != nil {
+			c.ResponseError(err)
+			return
+		}
+		user.PasswordHash = passwordHash
+	}
+	if user.PasswordHash == "" {
+		c.ResponseError(errors.New("密码不能为空"))
+		return
+	}
+	user.Roles = req.Roles
+	user.UpdatedAt = now
+
+	err := m.s.clusterServer.ProposeManagerUserSave(user)
+	if err != nil {
+		m.Error("保存管理用户失败", zap.Error(err), zap.String("username", req.Username))
+		c.ResponseError(err)
+		return
+	}
+	c.ResponseOK()
+}
+
+func (m *ManagerAPI) userDelete(c *wkhttp.Context) {
+	if !m.s.opts.Auth.HasPermissionWithContext(c, resource.Manager.User, auth.ActionWrite) {
+		c.ResponseStatus(http.StatusUnauthorized)
+		return
+	}
+	username := c.Param("username")
+	if m.s.clusterServer.GetConfig().ManagerUser(username) == nil {
+		c.ResponseError(errors.New("用户不存在"))
+		return
+	}
+	err := m.s.clusterServer.ProposeManagerUserRemove(username)
+	if err != nil {
+		m.Error("删除管理用户失败", zap.Error(err), zap.String("username", username))
+		c.ResponseError(err)
+		return
+	}
+	c.ResponseOK()
+}
+
+func (m *ManagerAPI) roleList(c *wkhttp.Context) {
+	if !m.s.opts.Auth.HasPermissionWithContext(c, resource.Manager.Role, auth.ActionRead) {
+		c.ResponseStatus(http.StatusUnauthorized)
+		return
+	}
+	c.JSON(http.StatusOK, m.s.clusterServer.GetConfig().ManagerRoles)
+}
+
+func (m *ManagerAPI) roleSave(c *wkhttp.Context) {
+	if !m.s.opts.Auth.HasPermissionWithContext(c, resource.Manager.Role, auth.ActionWrite) {
+		c.ResponseStatus(http.StatusUnauthorized)
+		return
+	}
+	var req struct {
+		Name        string                  `json:"name"`
+		Description string                  `json:"description"`
+		Permissions []*pb.ManagerPermission `json:"permissions"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		c.ResponseError(err)
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		c.ResponseError(errors.New("角色名称不能为空"))
+		return
+	}
+	for _, permission := range req.Permissions {
+		if strings.TrimSpace(permission.Resource) == "" || strings.Trim(permission.Actions, "rw*") != "" || permission.Actions == "" {
+			c.ResponseError(errors.New("权限格式有误"))
+			return
+		}
+	}
+
+	now := time.Now().Unix()
+	role := &pb.ManagerRole{
+		Name:      req.Name,
+		CreatedAt: now,
+	}
+	if old := m.s.clusterServer.GetConfig().ManagerRole(req.Name); old != nil {
+		role.CreatedAt = old.CreatedAt
+	}
+	role.Description = req.Description
+	role.Permissions = req.Permissions
+	role.UpdatedAt = now
+
+	err := m.s.clusterServer.ProposeManagerRoleSave(role)
+	if err != nil {
+		m.Error("保存管理角色失败", zap.Error(err), zap.String("name", req.Name))
+		c.ResponseError(err)
+		return
+	}
+	c.ResponseOK()
+}
+
+func (m *ManagerAPI) roleDelete(c *wkhttp.Context) {
+	if !m.s.opts.Auth.HasPermissionWithContext(c, resource.Manager.Role, auth.ActionWrite) {
+		c.ResponseStatus(http.StatusUnauthorized)
+		return
+	}
+	name := c.Param("name")
+	cfg := m.s.clusterServer.GetConfig()
+	if cfg.ManagerRole(name) == nil {
+		c.ResponseError(errors.New("角色不存在"))
+		return
+	}
+	for _, user := range cfg.ManagerUsers {
+		for _, role := range user.Roles {
+			if role == name {
+				c.ResponseError(errors.New("角色正在被用户使用：" + user.Username))
+				return
+			}
+		}
+	}
+	err := m.s.clusterServer.ProposeManagerRoleRemove(name)
+	if err != nil {
+		m.Error("删除管理角色失败", zap.Error(err), zap.String("name", name))
+		c.ResponseError(err)
+		return
+	}
+	c.ResponseOK()
+}
+
+type managerUserResp struct {
+	Username    string   `json:"username"`
+	Roles       []string `json:"roles"`
+	Permissions string   `json:"permissions"` // 用户的全部权限
+	FromConfig  bool     `json:"from_config"` // 是否是配置文件中的用户（不能通过接口修改）
+	CreatedAt   int64    `json:"created_at"`
+	UpdatedAt   int64    `json:"updated_at"`
+}
@@ -69,6 +69,9 @@ func (m *MessageAPI) send(c *wkhttp.Context) {
 
 	channelId := req.ChannelID
 	channelType := req.ChannelType
+	if !m.s.checkApiKeyChannel(c, channelId) { // 只指定订阅者时频道id为空，限制了频道前缀的密钥不允许发送
+		return
+	}
 
 	m.Debug("发送消息内容：", zap.String("msg", wkutil.ToJSON(req)))
 	if strings.TrimSpace(channelId) == "" && len(req.Subscribers) == 0 { //指定了频道 才能正常发送
@@ -148,13 +151,15 @@ func (m *MessageAPI) requestSetSubscribersForTmpChannel(tmpChannelId string, uid
 		return nil
 	}
 	reqURL := fmt.Sprintf("%s/%s", nodeInfo.ApiServerAddr, "tmpchannel/subscriber_set")
+	body := []byte(wkutil.ToJSON(map[string]interface{}{
+		"channel_id": tmpChannelId,
+		"uids":       uids,
+	}))
 	request := rest.Request{
 		Method:  rest.Method("POST"),
 		BaseURL: reqURL,
-		Body: []byte(wkutil.ToJSON(map[string]interface{}{
-			"channel_id": tmpChannelId,
-			"uids":       uids,
-		})),
+		Headers: m.s.apiServer.nodeRequestHeaders(http.MethodPost, reqURL, body),
+		Body:    body,
 	}
 	resp, err := rest.API(request)
 	if err != nil {
@@ -196,6 +201,9 @@ func sendMessageToChannel(s *Server, req MessageSendReq, channelId string, chann
 	if len(strings.TrimSpace(req.StreamNo)) > 0 {
 		setting = setting.Set(wkproto.SettingStream)
 	}
+	if req.E2EE == 1 { // 端到端加密的消息
+		setting = setting.Set(wkproto.SettingSignal)
+	}
 
 	// 将消息提交到频道
 	messageId := s.channelReactor.messageIDGen.Generate().Int64()
@@ -237,6 +245,9 @@ func (m *MessageAPI) sendBatch(c *wkhttp.Context) {
 		c.ResponseError(errors.New("from_uid不能为空！"))
 		return
 	}
+	if !m.s.checkApiKeyChannel(c, "") { // 批量发送不指定频道，限制了频道前缀的密钥不允许发送
+		return
+	}
 	if len(req.Subscribers) == 0 {
 		c.ResponseError(errors.New("subscribers不能为空！"))
 		return
@@ -359,7 +370,7 @@ func (m *MessageAPI) sync(c *wkhttp.Context) {
 	messageResps := make([]*MessageResp, 0)
 
 	if len(channelRecentMessageReqs) > 0 {
-		channelRecentMessages, err := m.s.getRecentMessagesForCluster(req.UID, req.Limit, channelRecentMessageReqs, false)
+		channelRecentMessages, err := m.s.getRecentMessagesForCluster(req.UID, req.Limit, channelRecentMessageReqs, false, false)
 		if err != nil {
 			m.Error("获取最近消息失败！", zap.Error(err), zap.String("uid", req.UID))
 			c.ResponseError(errors.New("获取最近消息失败！"))
@@ -526,6 +537,9 @@ func (m *MessageAPI) searchMessages(c *wkhttp.Context) {
 		c.ResponseError(errors.New("channel_id不能为空！"))
 		return
 	}
+	if !m.s.checkApiKeyChannel(c, req.ChannelID) {
+		return
+	}
 
 	fakeChannelId := req.ChannelID
 	if req.ChannelType == wkproto.ChannelTypePerson {
@@ -626,6 +640,9 @@ func (m *MessageAPI) searchMessage(c *wkhttp.Context) {
 		c.ResponseError(errors.New("channel_id不能为空！"))
 		return
 	}
+	if !m.s.checkApiKeyChannel(c, req.ChannelId) {
+		return
+	}
 
 	if req.ChannelType == 0 {
 		c.ResponseError(errors.New("channel_type不能为0"))
@@ -38,9 +38,10 @@ func (a *RouteAPI) routeUserIMAddr(c *wkhttp.Context) {
 	intranet := wkutil.IntToBool(wkutil.ParseInt(c.Query("intranet"))) // 是否返回内网地址
 
 	var (
-		tcpAddr string
-		wsAddr  string
-		wssAddr string
+		tcpAddr  string
+		wsAddr   string
+		wssAddr  string
+		quicAddr string
 	)
 
 	if intranet {
@@ -49,12 +50,14 @@ func (a *RouteAPI) routeUserIMAddr(c *wkhttp.Context) {
 		tcpAddr = a.s.opts.External.TCPAddr
 		wsAddr = a.s.opts.External.WSAddr
 		wssAddr = a.s.opts.External.WSSAddr
+		quicAddr = a.s.opts.External.QUICAddr
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"tcp_addr": tcpAddr,
-		"ws_addr":  wsAddr,
-		"wss_addr": wssAddr,
+		"tcp_addr":  tcpAddr,
+		"ws_addr":   wsAddr,
+		"wss_addr":  wssAddr,
+		"quic_addr": quicAddr,
 	})
 }
 
@@ -71,9 +74,10 @@ func (a *RouteAPI) routeUserIMAddrOfBatch(c *wkhttp.Context) {
 	}
 
 	var (
-		tcpAddr string
-		wsAddr  string
-		wssAddr string
+		tcpAddr  string
+		wsAddr   string
+		wssAddr  string
+		quicAddr string
 	)
 
 	if intranet {
@@ -82,22 +86,25 @@ func (a *RouteAPI) routeUserIMAddrOfBatch(c *wkhttp.Context) {
 		tcpAddr = a.s.opts.External.TCPAddr
 		wsAddr = a.s.opts.External.WSAddr
 		wssAddr = a.s.opts.External.WSSAddr
+		quicAddr = a.s.opts.External.QUICAddr
 	}
 
 	c.JSON(http.StatusOK, []userAddrResp{
 		{
-			UIDs:    uids,
-			TCPAddr: tcpAddr,
-			WSAddr:  wsAddr,
-			WSSAddr: wssAddr,
+			UIDs:     uids,
+			TCPAddr:  tcpAddr,
+			WSAddr:   wsAddr,
+			WSSAddr:  wssAddr,
+			QUICAddr: quicAddr,
 		},
 	})
 
 }
 
 type userAddrResp struct {
-	TCPAddr string   `json:"tcp_addr"`
-	WSAddr  string   `json:"ws_addr"`
-	WSSAddr string   `json:"wss_addr"`
-	UIDs    []string `json:"uids"`
+	TCPAddr  string   `json:"tcp_addr"`
+	WSAddr   string   `json:"ws_addr"`
+	WSSAddr  string   `json:"wss_addr"`
+	QUICAddr string   `json:"quic_addr"`
+	UIDs     []string `json:"uids"`
 }
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkhook"
 	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
 	"github.com/WuKongIM/WuKongIM/pkg/wklog"
 	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
@@ -52,6 +53,9 @@ func (s *StreamAPI) start(c *wkhttp.Context) {
 		c.ResponseError(errors.New("无法处理发送消息请求！"))
 		return
 	}
+	if !s.s.checkApiKeyChannel(c, channelId) {
+		return
+	}
 
 	fakeChannelId := channelId
 	if channelType == wkproto.ChannelTypePerson {
@@ -137,6 +141,20 @@ func (s *StreamAPI) start(c *wkhttp.Context) {
 		c.ResponseError(err)
 		return
 	}
+
+	s.s.webhook.TriggerEvent(&Event{
+		Event: EventStreamStart,
+		Data: &wkhook.StreamEvent{
+			StreamNo:    streamNo,
+			ChannelId:   channelId,
+			ChannelType: uint32(channelType),
+			FromUid:     req.FromUid,
+			ClientMsgNo: clientMsgNo,
+			MessageId:   messageId,
+			Timestamp:   time.Now().Unix(),
+		},
+	})
+
 	c.JSON(http.StatusOK, gin.H{
 		"stream_no": streamNo,
 	})
@@ -144,7 +162,43 @@ func (s *StreamAPI) start(c *wkhttp.Context) {
 }
 
 func (s *StreamAPI) end(c *wkhttp.Context) {
+	var req streamEndReq
+	if err := c.BindJSON(&req); err != nil {
+		s.Error("数据格式有误！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if strings.TrimSpace(req.StreamNo) == "" {
+		c.ResponseError(errors.New("流编号不能为空！"))
+		return
+	}
+	if !s.s.checkApiKeyChannel(c, req.ChannelId) {
+		return
+	}
+	streamMeta, err := s.s.store.GetStreamMeta(req.StreamNo)
+	if err != nil {
+		s.Error("获取流元数据失败！", zap.Error(err), zap.String("streamNo", req.StreamNo))
+		c.ResponseError(errors.New("获取流元数据失败！"))
+		return
+	}
+	if streamMeta == nil || streamMeta.ChannelId != req.ChannelId || streamMeta.ChannelType != req.ChannelType {
+		c.ResponseError(errors.New("流不存在！"))
+		return
+	}
 
+	s.s.webhook.TriggerEvent(&Event{
+		Event: EventStreamEnd,
+		Data: &wkhook.StreamEvent{
+			StreamNo:    streamMeta.StreamNo,
+			ChannelId:   streamMeta.ChannelId,
+			ChannelType: uint32(streamMeta.ChannelType),
+			FromUid:     streamMeta.FromUid,
+			ClientMsgNo: streamMeta.ClientMsgNo,
+			MessageId:   streamMeta.MessageId,
+			Timestamp:   time.Now().Unix(),
+		},
+	})
+	c.ResponseOK()
 }
 
 type streamStartReq struct {
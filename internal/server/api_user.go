@@ -10,6 +10,7 @@ import (
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
 	"github.com/WuKongIM/WuKongIM/pkg/network"
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkhook"
 	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
 	"github.com/WuKongIM/WuKongIM/pkg/wklog"
 	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
@@ -114,6 +115,9 @@ func (u *UserAPI) quitUserDevice(uid string, deviceFlag wkproto.DeviceFlag) erro
 		u.Error("清空用户token失败！", zap.Error(err), zap.String("uid", uid), zap.Uint8("deviceFlag", deviceFlag.ToUint8()))
 		return err
 	}
+	// 作废设备的会话恢复票据，避免跳过token校验重连
+	u.s.resumeManager.revoke(uid, deviceFlag)
+
 	oldConns := u.s.userReactor.getConnsByDeviceFlag(uid, deviceFlag)
 	if len(oldConns) > 0 {
 		for _, oldConn := range oldConns {
@@ -215,7 +219,8 @@ func (u *UserAPI) requestOnlineStatus(nodeID uint64, uids []string) ([]*Onlinest
 		return nil, errors.New("获取频道所在节点失败！")
 	}
 	reqURL := fmt.Sprintf("%s/user/onlinestatus", nodeInfo.ApiServerAddr)
-	resp, err := network.Post(reqURL, []byte(wkutil.ToJSON(uids)), nil)
+	body := []byte(wkutil.ToJSON(uids))
+	resp, err := network.Post(reqURL, body, u.s.apiServer.nodeRequestHeaders(http.MethodPost, reqURL, body))
 	if err != nil {
 		u.Error("获取在线用户状态失败！", zap.Error(err), zap.String("reqURL", reqURL))
 		return nil, err
@@ -362,6 +367,19 @@ func (u *UserAPI) updateToken(c *wkhttp.Context) {
 		}
 	}
 
+	// token已变更，作废设备的会话恢复票据
+	u.s.resumeManager.revoke(req.UID, req.DeviceFlag)
+
+	u.s.webhook.TriggerEvent(&Event{
+		Event: EventUserDeviceToken,
+		Data: &wkhook.DeviceTokenEvent{
+			Uid:         req.UID,
+			DeviceFlag:  uint32(req.DeviceFlag),
+			DeviceLevel: uint32(req.DeviceLevel),
+			Timestamp:   time.Now().Unix(),
+		},
+	})
+
 	if req.DeviceLevel == wkproto.DeviceLevelMaster {
 		// 如果存在旧连接，则发起踢出请求
 		oldConns := u.s.userReactor.getConnsByDeviceFlag(req.UID, req.DeviceFlag)
@@ -459,9 +477,10 @@ func (u *UserAPI) systemUidsAdd(c *wkhttp.Context) {
 
 func (u *UserAPI) requestSystemUidsAddToCache(nodeInfo *pb.Node, uids []string) error {
 	reqURL := fmt.Sprintf("%s/user/systemuids_add_to_cache", nodeInfo.ApiServerAddr)
-	resp, err := network.Post(reqURL, []byte(wkutil.ToJSON(map[string]interface{}{
+	body := []byte(wkutil.ToJSON(map[string]interface{}{
 		"uids": uids,
-	})), nil)
+	}))
+	resp, err := network.Post(reqURL, body, u.s.apiServer.nodeRequestHeaders(http.MethodPost, reqURL, body))
 	if err != nil {
 		u.Error("添加系统账号到缓存失败！", zap.Error(err), zap.String("reqURL", reqURL))
 		return err
@@ -554,9 +573,10 @@ func (u *UserAPI) systemUidsRemove(c *wkhttp.Context) {
 
 func (u *UserAPI) requestSystemUidsRemoveFromCache(nodeInfo *pb.Node, uids []string) error {
 	reqURL := fmt.Sprintf("%s/user/systemuids_remove_from_cache", nodeInfo.ApiServerAddr)
-	resp, err := network.Post(reqURL, []byte(wkutil.ToJSON(map[string]interface{}{
+	body := []byte(wkutil.ToJSON(map[string]interface{}{
 		"uids": uids,
-	})), nil)
+	}))
+	resp, err := network.Post(reqURL, body, u.s.apiServer.nodeRequestHeaders(http.MethodPost, reqURL, body))
 	if err != nil {
 		u.Error("移除系统账号从缓存失败！", zap.Error(err), zap.String("reqURL", reqURL))
 		return err
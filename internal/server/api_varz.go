@@ -118,10 +118,12 @@ func CreateVarz(s *Server) *Varz {
 		InBytes:              inBytes,
 		OutBytes:             outBytes,
 		RetryQueue:           int64(s.retryManager.retryMessageCount()),
+		ResumeSessions:       s.resumeManager.sessionCount(),
 
 		TCPAddr:     opts.External.TCPAddr,
 		WSAddr:      opts.External.WSAddr,
 		WSSAddr:     opts.External.WSSAddr,
+		QUICAddr:    opts.External.QUICAddr,
 		ManagerAddr: opts.External.ManagerAddr,
 		ManagerOn:   wkutil.BoolToInt(opts.Manager.On),
 
@@ -155,9 +157,12 @@ type Varz struct {
 	SlowClients int64 `json:"slow_clients"` // 慢客户端数量
 	RetryQueue  int64 `json:"retry_queue"`  // 重试队列数量
 
+	ResumeSessions int `json:"resume_sessions"` // 可恢复的会话数量
+
 	TCPAddr     string `json:"tcp_addr"`     // tcp地址
 	WSAddr      string `json:"ws_addr"`      // ws地址
 	WSSAddr     string `json:"wss_addr"`     // wss地址
+	QUICAddr    string `json:"quic_addr"`    // quic地址
 	ManagerAddr string `json:"manager_addr"` // 管理地址
 	ManagerOn   int    `json:"manager_on"`   // 管理是否开启
 	Commit      string `json:"commit"`       // git commit id
@@ -0,0 +1,193 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// WebhookAPI webhook死信管理
+type WebhookAPI struct {
+	s *Server
+	wklog.Log
+}
+
+// NewWebhookAPI NewWebhookAPI
+func NewWebhookAPI(s *Server) *WebhookAPI {
+	return &WebhookAPI{
+		s:   s,
+		Log: wklog.NewWKLog("WebhookAPI"),
+	}
+}
+
+// Route 路由
+func (w *WebhookAPI) Route(r *wkhttp.WKHttp) {
+	r.GET("/webhook/dead_letters", w.deadLetters)               // 死信列表
+	r.POST("/webhook/dead_letters/replay", w.replayDeadLetters) // 重放死信
+	r.POST("/webhook/dead_letters/purge", w.purgeDeadLetters)   // 清除死信
+}
+
+// forwardIfNeed 死信保存在推送失败的节点上，操作其他节点的死信需要转发
+func (w *WebhookAPI) forwardIfNeed(c *wkhttp.Context, body []byte) bool {
+	nodeIdStr := c.Query("node_id")
+	var nodeId uint64
+	if strings.TrimSpace(nodeIdStr) != "" {
+		nodeId, _ = strconv.ParseUint(nodeIdStr, 10, 64)
+	}
+	if nodeId == 0 || nodeId == w.s.opts.Cluster.NodeId {
+		return false
+	}
+	nodeInfo, err := w.s.cluster.NodeInfoById(nodeId)
+	if err != nil {
+		w.Error("获取节点信息失败！", zap.Error(err), zap.Uint64("nodeId", nodeId))
+		c.ResponseError(err)
+		return true
+	}
+	if nodeInfo == nil {
+		w.Error("节点不存在！", zap.Uint64("nodeId", nodeId))
+		c.ResponseError(fmt.Errorf("节点不存在！"))
+		return true
+	}
+	c.ForwardWithBody(fmt.Sprintf("%s%s", nodeInfo.ApiServerAddr, c.Request.URL.Path), body)
+	return true
+}
+
+func (w *WebhookAPI) deadLetters(c *wkhttp.Context) {
+	if w.forwardIfNeed(c, nil) {
+		return
+	}
+	offsetId, _ := strconv.ParseUint(c.Query("offset_id"), 10, 64)
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	deadLetters, err := w.s.store.DB().GetWebhookDeadLetters(offsetId, limit)
+	if err != nil {
+		w.Error("查询webhook死信失败！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	resps := make([]*webhookDeadLetterResp, 0, len(deadLetters))
+	for _, deadLetter := range deadLetters {
+		resps = append(resps, newWebhookDeadLetterResp(deadLetter))
+	}
+	c.JSON(http.StatusOK, resps)
+}
+
+type webhookDeadLettersReq struct {
+	Ids []string `json:"ids"` // 死信id，purge时为空表示清除所有死信
+}
+
+func (r webhookDeadLettersReq) parseIds() ([]uint64, error) {
+	ids := make([]uint64, 0, len(r.Ids))
+	for _, idStr := range r.Ids {
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("死信id[%s]格式有误！", idStr)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (w *WebhookAPI) bindDeadLettersReq(c *wkhttp.Context) ([]uint64, bool) {
+	bodyBytes, err := c.GetRawData()
+	if err != nil {
+		c.ResponseError(errors.Wrap(err, "读取请求数据失败！"))
+		return nil, false
+	}
+	if w.forwardIfNeed(c, bodyBytes) {
+		return nil, false
+	}
+	var req webhookDeadLettersReq
+	if len(bodyBytes) > 0 {
+		if err := json.Unmarshal(bodyBytes, &req); err != nil {
+			w.Error("数据格式有误！", zap.Error(err))
+			c.ResponseError(errors.Wrap(err, "数据格式有误！"))
+			return nil, false
+		}
+	}
+	ids, err := req.parseIds()
+	if err != nil {
+		c.ResponseError(err)
+		return nil, false
+	}
+	return ids, true
+}
+
+func (w *WebhookAPI) replayDeadLetters(c *wkhttp.Context) {
+	ids, ok := w.bindDeadLettersReq(c)
+	if !ok {
+		return
+	}
+	if len(ids) == 0 {
+		c.ResponseError(errors.New("死信id不能为空！"))
+		return
+	}
+	successIds, err := w.s.webhook.replayDeadLetters(ids)
+	if err != nil {
+		w.Error("重放webhook死信失败！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	successIdStrs := make([]string, 0, len(successIds))
+	for _, id := range successIds {
+		successIdStrs = append(successIdStrs, strconv.FormatUint(id, 10))
+	}
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"success_ids": successIdStrs, // 重放成功的死信id，重放成功的死信会被删除
+	})
+}
+
+func (w *WebhookAPI) purgeDeadLetters(c *wkhttp.Context) {
+	ids, ok := w.bindDeadLettersReq(c)
+	if !ok {
+		return
+	}
+	var err error
+	if len(ids) == 0 {
+		err = w.s.store.DB().PurgeWebhookDeadLetters()
+	} else {
+		err = w.s.store.DB().RemoveWebhookDeadLetters(ids)
+	}
+	if err != nil {
+		w.Error("清除webhook死信失败！", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	c.ResponseOK()
+}
+
+type webhookDeadLetterResp struct {
+	Id        string      `json:"id"` // 分页时作为offset_id传入
+	Event     string      `json:"event"`
+	Addr      string      `json:"addr,omitempty"` // 为空表示全局webhook地址
+	Data      interface{} `json:"data"`           // json数据原样返回，其他数据（例如grpc方式的protobuf数据）为base64编码
+	Attempts  int         `json:"attempts"`
+	Error     string      `json:"error,omitempty"`
+	CreatedAt int64       `json:"created_at"`
+}
+
+func newWebhookDeadLetterResp(deadLetter wkdb.WebhookDeadLetter) *webhookDeadLetterResp {
+	var data interface{} = deadLetter.Data
+	if json.Valid(deadLetter.Data) {
+		data = json.RawMessage(deadLetter.Data)
+	}
+	return &webhookDeadLetterResp{
+		Id:        strconv.FormatUint(deadLetter.Id, 10),
+		Event:     deadLetter.Event,
+		Addr:      deadLetter.Addr,
+		Data:      data,
+		Attempts:  deadLetter.Attempts,
+		Error:     deadLetter.Error,
+		CreatedAt: deadLetter.CreatedAt,
+	}
+}
@@ -0,0 +1,190 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/atomic"
+)
+
+// 业务api的路由分组，访问密钥按分组授权
+const (
+	ApiKeyScopeMessage      = "message"      // 消息
+	ApiKeyScopeChannel      = "channel"      // 频道
+	ApiKeyScopeConversation = "conversation" // 最近会话
+	ApiKeyScopeUser         = "user"         // 用户
+	ApiKeyScopeRoute        = "route"        // 路由
+	ApiKeyScopeStream       = "stream"       // 流消息
+	ApiKeyScopeSystem       = "system"       // 系统信息（connz、varz等）
+	ApiKeyScopeCluster      = "cluster"      // 分布式
+	ApiKeyScopeStress       = "stress"       // 压测
+	ApiKeyScopeApiKey       = "apikey"       // 访问密钥管理
+	ApiKeyScopeAudit        = "audit"        // 审计日志
+)
+
+var ApiKeyScopes = []string{
+	ApiKeyScopeMessage,
+	ApiKeyScopeChannel,
+	ApiKeyScopeConversation,
+	ApiKeyScopeUser,
+	ApiKeyScopeRoute,
+	ApiKeyScopeStream,
+	ApiKeyScopeSystem,
+	ApiKeyScopeCluster,
+	ApiKeyScopeStress,
+	ApiKeyScopeApiKey,
+	ApiKeyScopeAudit,
+}
+
+// 路由第一段路径对应的分组
+var apiKeyScopeOfSegment = map[string]string{
+	"message":       ApiKeyScopeMessage,
+	"messages":      ApiKeyScopeMessage,
+	"channel":       ApiKeyScopeChannel,
+	"tmpchannel":    ApiKeyScopeChannel,
+	"conversation":  ApiKeyScopeConversation,
+	"conversations": ApiKeyScopeConversation,
+	"user":          ApiKeyScopeUser,
+	"route":         ApiKeyScopeRoute,
+	"stream":        ApiKeyScopeStream,
+	"connz":         ApiKeyScopeSystem,
+	"varz":          ApiKeyScopeSystem,
+	"migrate":       ApiKeyScopeSystem,
+	"ipguard":       ApiKeyScopeSystem,
+	"datasource":    ApiKeyScopeSystem,
+	"db":            ApiKeyScopeSystem,
+	"webhook":       ApiKeyScopeSystem,
+	"bot":           ApiKeyScopeSystem,
+	"cluster":       ApiKeyScopeCluster,
+	"stress":        ApiKeyScopeStress,
+	"apikeys":       ApiKeyScopeApiKey,
+	"audit":         ApiKeyScopeAudit,
+}
+
+const apiKeyContextKey = "apiKey"
+
+// apiKeyScopeOfPath 获取请求路径所属的路由分组
+func apiKeyScopeOfPath(path string) string {
+	segment := strings.TrimPrefix(path, "/")
+	if idx := strings.Index(segment, "/"); idx >= 0 {
+		segment = segment[:idx]
+	}
+	if scope, ok := apiKeyScopeOfSegment[segment]; ok {
+		return scope
+	}
+	return segment
+}
+
+// apiKeyUsage 访问密钥在本节点的使用统计
+type apiKeyUsage struct {
+	requests   atomic.Int64 // 请求次数
+	denied     atomic.Int64 // 因分组或频道未授权被拒绝的次数
+	lastUsedAt atomic.Int64 // 最后使用时间
+}
+
+// apiKeyAuth 业务api的访问密钥认证
+// 请求头token为管理者token时拥有全部权限，否则按集群配置中的访问密钥校验路由分组
+// 访问密钥需要配置了管理者token才生效，集群内节点之间的请求（没有token）通过节点签名认证
+type apiKeyAuth struct {
+	s      *Server
+	usages sync.Map // 密钥id -> *apiKeyUsage
+	wklog.Log
+}
+
+func newApiKeyAuth(s *Server) *apiKeyAuth {
+	return &apiKeyAuth{
+		s:   s,
+		Log: wklog.NewWKLog("apiKeyAuth"),
+	}
+}
+
+func (a *apiKeyAuth) middleware() wkhttp.HandlerFunc {
+	return func(c *wkhttp.Context) {
+		if c.Request.URL.Path == "/health" { // 健康检查不需要认证
+			c.Next()
+			return
+		}
+		managerToken := strings.TrimSpace(a.s.opts.ManagerToken)
+		if managerToken == "" { // 没有配置管理者token不做认证
+			c.Next()
+			return
+		}
+		token := c.GetHeader("token")
+		if token == managerToken {
+			c.Next()
+			return
+		}
+		if strings.TrimSpace(token) == "" {
+			if c.IsNodeRequest() { // 集群内节点之间的请求
+				c.Next()
+				return
+			}
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		cfg := a.s.clusterServer.GetConfig()
+		apiKey := cfg.ApiKeyByHash(pb.HashApiKey(token), time.Now().Unix())
+		if apiKey == nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		usage := a.usage(apiKey.Id)
+		usage.lastUsedAt.Store(time.Now().Unix())
+		if !apiKey.HasScope(apiKeyScopeOfPath(c.Request.URL.Path)) {
+			usage.denied.Inc()
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"msg":    "api key scope not allowed",
+				"status": http.StatusForbidden,
+			})
+			return
+		}
+		usage.requests.Inc()
+		c.Set(apiKeyContextKey, apiKey)
+		c.Next()
+	}
+}
+
+func (a *apiKeyAuth) usage(id string) *apiKeyUsage {
+	v, _ := a.usages.LoadOrStore(id, &apiKeyUsage{})
+	return v.(*apiKeyUsage)
+}
+
+func (a *apiKeyAuth) removeUsage(id string) {
+	a.usages.Delete(id)
+}
+
+// requestApiKey 请求使用的访问密钥，使用管理者token、节点之间的请求或者没有开启认证时返回nil
+func requestApiKey(c *wkhttp.Context) *pb.ApiKey {
+	v, ok := c.Get(apiKeyContextKey)
+	if !ok {
+		return nil
+	}
+	return v.(*pb.ApiKey)
+}
+
+// apiKeyAllowChannel 请求的访问密钥是否允许操作此频道（不中断请求，用于过滤返回数据）
+func apiKeyAllowChannel(c *wkhttp.Context, channelId string) bool {
+	apiKey := requestApiKey(c)
+	return apiKey == nil || apiKey.AllowChannel(channelId)
+}
+
+// checkApiKeyChannel 校验请求的访问密钥是否允许操作此频道，不允许时直接返回403
+// 使用管理者token或者没有开启认证时不做限制
+func (s *Server) checkApiKeyChannel(c *wkhttp.Context, channelId string) bool {
+	apiKey := requestApiKey(c)
+	if apiKey == nil || apiKey.AllowChannel(channelId) {
+		return true
+	}
+	s.apiServer.apiKeyAuth.usage(apiKey.Id).denied.Inc()
+	c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+		"msg":    "api key channel not allowed",
+		"status": http.StatusForbidden,
+	})
+	return false
+}
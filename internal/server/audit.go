@@ -0,0 +1,299 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/gin-gonic/gin"
+	"github.com/lni/goutils/syncutil"
+	"go.uber.org/zap"
+)
+
+// 需要记录审计日志的路由前缀
+var auditPathPrefixes = []string{
+	"/channel",
+	"/tmpchannel",
+	"/user",
+	"/conversation",
+	"/cluster",
+	"/manager",
+	"/apikeys",
+	"/ipguard",
+	"/datasource",
+	"/db",
+	"/webhook",
+	"/bot",
+}
+
+// 虽然是POST请求但是只读的路由，不记录审计日志
+var auditIgnorePaths = map[string]bool{
+	"/channel/messagesync":       true,
+	"/user/onlinestatus":         true,
+	"/user/e2ee/keys_count":      true,
+	"/conversation/sync":         true,
+	"/conversation/syncMessages": true,
+	"/cluster/channel/status":    true,
+	"/manager/login":             true,
+}
+
+const (
+	auditQueueSize     = 1024 // 待写入审计日志的队列大小
+	auditMaxTargetLen  = 256  // 操作对象的最大长度
+	auditMaxRespBody   = 4096 // 用于解析失败原因的响应内容最大长度
+	auditMaxRequestLen = 1024 * 1024
+)
+
+// auditLogger 审计日志，记录修改类接口的操作者、路由、操作对象和结果
+// 审计日志保存在接收请求的节点上，节点之间转发的请求不重复记录
+type auditLogger struct {
+	s          *Server
+	logC       chan wkdb.AuditLog
+	stopper    *syncutil.Stopper
+	nodeSigner *wkhttp.NodeSigner // 校验节点之间转发请求的签名
+	wklog.Log
+}
+
+func newAuditLogger(s *Server) *auditLogger {
+	return &auditLogger{
+		s:          s,
+		logC:       make(chan wkdb.AuditLog, auditQueueSize),
+		stopper:    syncutil.NewStopper(),
+		nodeSigner: wkhttp.NewNodeSigner(s.opts.NodeRequestSecret()),
+		Log:        wklog.NewWKLog("auditLogger"),
+	}
+}
+
+func (a *auditLogger) start() {
+	if !a.s.opts.Audit.On {
+		return
+	}
+	a.stopper.RunWorker(a.loop)
+	a.stopper.RunWorker(a.cleanLoop)
+}
+
+func (a *auditLogger) stop() {
+	a.stopper.Stop()
+}
+
+// middleware 需要放在认证中间件之前，这样被拒绝的请求也能记录下来
+func (a *auditLogger) middleware() wkhttp.HandlerFunc {
+	return func(c *wkhttp.Context) {
+		if !a.s.opts.Audit.On || !a.shouldAudit(c.Request) {
+			c.Next()
+			return
+		}
+		target := auditTargetOfBody(c.Request)
+
+		blw := &auditResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = blw
+		c.Next()
+
+		statusCode, errMsg := parseAuditResponse(blw.Status(), blw.body.Bytes())
+		if statusCode == http.StatusUnauthorized { // 未认证的请求不记录，避免被刷日志
+			return
+		}
+		if target == "" {
+			target = auditTargetOfParams(c.Params)
+		}
+		log := wkdb.AuditLog{
+			ClientIp:   c.ClientIP(),
+			Method:     c.Request.Method,
+			Path:       c.Request.URL.Path,
+			Target:     target,
+			StatusCode: statusCode,
+			Error:      errMsg,
+			NodeId:     a.s.opts.Cluster.NodeId,
+			CreatedAt:  time.Now().Unix(),
+		}
+		a.fillActor(c, &log)
+		a.append(log)
+	}
+}
+
+func (a *auditLogger) shouldAudit(r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+		return false
+	}
+	// 已经在接收请求的节点记录过，只信任带有节点签名的转发请求，客户端伪造的转发头仍然记录
+	if r.Header.Get(wkhttp.HeaderForwarded) != "" && a.nodeSigner.Verify(r) {
+		return false
+	}
+	path := r.URL.Path
+	if auditIgnorePaths[path] {
+		return false
+	}
+	for _, prefix := range auditPathPrefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") || strings.HasPrefix(path, prefix+"s/") {
+			return true
+		}
+	}
+	return false
+}
+
+// fillActor 填充操作者信息
+func (a *auditLogger) fillActor(c *wkhttp.Context, log *wkdb.AuditLog) {
+	if v, ok := c.Get(apiKeyContextKey); ok {
+		apiKey := v.(*pb.ApiKey)
+		log.ActorType = wkdb.AuditActorApiKey
+		log.Actor = apiKey.Name
+		log.ApiKeyId = apiKey.Id
+		return
+	}
+	managerToken := strings.TrimSpace(a.s.opts.ManagerToken)
+	if managerToken != "" && c.GetHeader("token") == managerToken {
+		log.ActorType = wkdb.AuditActorManagerToken
+		log.Actor = a.s.opts.ManagerUID
+		return
+	}
+	if username := c.Username(); username != "" {
+		log.ActorType = wkdb.AuditActorManagerUser
+		log.Actor = username
+		return
+	}
+	log.ActorType = wkdb.AuditActorAnonymous
+}
+
+func (a *auditLogger) append(log wkdb.AuditLog) {
+	select {
+	case a.logC <- log:
+	default:
+		a.Warn("audit log queue is full, drop log", zap.String("path", log.Path), zap.String("actor", log.Actor), zap.String("target", log.Target))
+	}
+}
+
+func (a *auditLogger) loop() {
+	for {
+		select {
+		case log := <-a.logC:
+			if err := a.s.store.DB().AppendAuditLog(log); err != nil {
+				a.Error("append audit log failed", zap.Error(err), zap.String("path", log.Path), zap.String("actor", log.Actor))
+			}
+		case <-a.stopper.ShouldStop():
+			return
+		}
+	}
+}
+
+// cleanLoop 定时清理超过保留时间的审计日志
+func (a *auditLogger) cleanLoop() {
+	if a.s.opts.Audit.Retention <= 0 {
+		return
+	}
+	tk := time.NewTicker(a.s.opts.Audit.CleanInterval)
+	defer tk.Stop()
+	for {
+		select {
+		case <-tk.C:
+			before := time.Now().Add(-a.s.opts.Audit.Retention)
+			if err := a.s.store.DB().RemoveAuditLogsBefore(before); err != nil {
+				a.Error("remove expired audit logs failed", zap.Error(err))
+			}
+		case <-a.stopper.ShouldStop():
+			return
+		}
+	}
+}
+
+// auditTargetOfBody 从请求内容中获取操作对象，读取后恢复请求内容供后续处理
+func auditTargetOfBody(r *http.Request) string {
+	if r.Body == nil || r.ContentLength == 0 || r.ContentLength > auditMaxRequestLen {
+		return ""
+	}
+	body, err := io.ReadAll(r.Body)
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+	var req struct {
+		ChannelId   string   `json:"channel_id"`
+		ChannelType uint8    `json:"channel_type"`
+		Uid         string   `json:"uid"`
+		Uids        []string `json:"uids"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return ""
+	}
+	var target string
+	switch {
+	case req.ChannelId != "":
+		target = fmt.Sprintf("channel:%s:%d", req.ChannelId, req.ChannelType)
+	case req.Uid != "":
+		target = "uid:" + req.Uid
+	case len(req.Uids) > 0:
+		target = "uid:" + strings.Join(req.Uids, ",")
+	}
+	return truncateAuditTarget(target)
+}
+
+// auditTargetOfParams 从路由参数中获取操作对象，例如 /cluster/nodes/:id/drain
+func auditTargetOfParams(params gin.Params) string {
+	if len(params) == 0 {
+		return ""
+	}
+	channelId := params.ByName("channel_id")
+	if channelId != "" {
+		return truncateAuditTarget(fmt.Sprintf("channel:%s:%s", channelId, params.ByName("channel_type")))
+	}
+	parts := make([]string, 0, len(params))
+	for _, p := range params {
+		parts = append(parts, p.Key+":"+p.Value)
+	}
+	return truncateAuditTarget(strings.Join(parts, ","))
+}
+
+func truncateAuditTarget(target string) string {
+	if len(target) > auditMaxTargetLen {
+		return target[:auditMaxTargetLen]
+	}
+	return target
+}
+
+// parseAuditResponse 解析操作结果，业务错误可能以http 200返回并在内容中携带status和msg
+func parseAuditResponse(httpStatus int, body []byte) (int, string) {
+	var resp struct {
+		Status int    `json:"status"`
+		Msg    string `json:"msg"`
+		Error  string `json:"error"`
+	}
+	if len(body) == 0 || json.Unmarshal(body, &resp) != nil {
+		return httpStatus, ""
+	}
+	statusCode := httpStatus
+	if httpStatus == http.StatusOK && resp.Status != 0 {
+		statusCode = resp.Status
+	}
+	if statusCode >= 200 && statusCode < 300 {
+		return statusCode, ""
+	}
+	if resp.Msg != "" {
+		return statusCode, resp.Msg
+	}
+	return statusCode, resp.Error
+}
+
+// auditResponseWriter 记录响应内容的前一部分，用于解析操作结果
+type auditResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	if remain := auditMaxRespBody - w.body.Len(); remain > 0 {
+		if len(b) > remain {
+			w.body.Write(b[:remain])
+		} else {
+			w.body.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
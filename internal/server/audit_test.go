@@ -0,0 +1,65 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditShouldAudit(t *testing.T) {
+	a := &auditLogger{nodeSigner: wkhttp.NewNodeSigner("secret")}
+
+	newReq := func(method, path string) *http.Request {
+		return httptest.NewRequest(method, path, nil)
+	}
+	assert.True(t, a.shouldAudit(newReq(http.MethodPost, "/channel/blacklist_add")))
+	assert.True(t, a.shouldAudit(newReq(http.MethodPost, "/conversations/delete")))
+	assert.True(t, a.shouldAudit(newReq(http.MethodPost, "/user/device_quit")))
+	assert.True(t, a.shouldAudit(newReq(http.MethodDelete, "/cluster/nodes/2/drain")))
+	assert.False(t, a.shouldAudit(newReq(http.MethodGet, "/channel/blacklist")))
+	assert.False(t, a.shouldAudit(newReq(http.MethodPost, "/conversation/sync")))
+	assert.False(t, a.shouldAudit(newReq(http.MethodPost, "/message/send")))
+
+	// 没有节点签名的转发头不可信
+	forwarded := newReq(http.MethodPost, "/channel/delete")
+	forwarded.Header.Set(wkhttp.HeaderForwarded, "1")
+	assert.True(t, a.shouldAudit(forwarded))
+
+	for k, v := range a.nodeSigner.SignHeaders(nil, http.MethodPost, "http://127.0.0.1:5001/channel/delete", nil) {
+		forwarded.Header.Set(k, v)
+	}
+	assert.False(t, a.shouldAudit(forwarded))
+}
+
+func TestAuditTargetOfBody(t *testing.T) {
+	body := `{"channel_id":"g1","channel_type":2,"subscribers":["u1"]}`
+	req := httptest.NewRequest(http.MethodPost, "/channel/subscriber_remove", strings.NewReader(body))
+	assert.Equal(t, "channel:g1:2", auditTargetOfBody(req))
+
+	// 读取后请求内容需要恢复
+	data, err := io.ReadAll(req.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, body, string(data))
+
+	req = httptest.NewRequest(http.MethodPost, "/user/device_quit", strings.NewReader(`{"uid":"u1","device_flag":1}`))
+	assert.Equal(t, "uid:u1", auditTargetOfBody(req))
+}
+
+func TestParseAuditResponse(t *testing.T) {
+	status, errMsg := parseAuditResponse(http.StatusOK, []byte(`{"status":200}`))
+	assert.Equal(t, http.StatusOK, status)
+	assert.Empty(t, errMsg)
+
+	status, errMsg = parseAuditResponse(http.StatusBadRequest, []byte(`{"msg":"频道不存在","status":400}`))
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "频道不存在", errMsg)
+
+	// 业务权限不足以http 200返回
+	status, _ = parseAuditResponse(http.StatusOK, []byte(`{"status":401}`))
+	assert.Equal(t, http.StatusUnauthorized, status)
+}
@@ -0,0 +1,408 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/lni/goutils/syncutil"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// EventBotMessage 推送给机器人回调地址的事件
+const EventBotMessage = "bot.message"
+
+// BotHandler 进程内的机器人处理器
+type BotHandler func(ctx *BotContext)
+
+// BotContext 发给机器人的消息（个人频道的消息和群里@机器人的消息）
+type BotContext struct {
+	BotUid      string   `json:"bot_uid"`      // 机器人uid
+	FromUid     string   `json:"from_uid"`     // 发送者
+	ChannelId   string   `json:"channel_id"`   // 回复的频道，个人频道为发送者uid
+	ChannelType uint8    `json:"channel_type"` // 回复的频道类型
+	MessageId   int64    `json:"message_id"`
+	MessageSeq  uint32   `json:"message_seq"`
+	ClientMsgNo string   `json:"client_msg_no"`
+	Payload     []byte   `json:"payload"`   // 消息内容
+	Content     string   `json:"content"`   // 消息的文本内容（payload里的content字段）
+	Command     string   `json:"command"`   // 命令名（不含前缀），不是命令为空
+	Args        []string `json:"args"`      // 命令参数
+	Mentioned   bool     `json:"mentioned"` // 是否被@
+
+	s *Server
+}
+
+// Reply 以机器人的身份回复消息到消息所在的频道
+func (c *BotContext) Reply(payload []byte) error {
+	return c.s.botManager.send(c.BotUid, c.ChannelId, c.ChannelType, payload)
+}
+
+// ReplyText 回复文本消息
+func (c *BotContext) ReplyText(text string) error {
+	return c.Reply([]byte(wkutil.ToJSON(map[string]interface{}{
+		"type":    1,
+		"content": text,
+	})))
+}
+
+// RegisterBot 注册进程内的机器人处理器，发给此uid的消息会回调handler
+// 机器人的频率限制和命令前缀可以通过 /bot/register 接口配置，没有配置使用默认配置
+// 注意：消息只在投递给机器人uid的节点（uid所在slot的领导节点）上分发，处理器只在注册的节点上生效，
+// 集群部署时需要在每个节点上注册处理器（slot领导切换后由新的领导节点分发），或者使用 /bot/register 配置回调地址
+func (s *Server) RegisterBot(uid string, handler BotHandler) {
+	s.botManager.registerHandler(uid, handler)
+}
+
+// UnregisterBot 移除进程内的机器人处理器
+func (s *Server) UnregisterBot(uid string) {
+	s.botManager.unregisterHandler(uid)
+}
+
+// botManager 服务端机器人，直接从投递流程接收发给机器人的消息，不需要机器人建立长连接
+// 机器人配置（回调地址、频率限制等）保存在分布式配置中，进程内的处理器注册在各自的节点上
+// 消息在投递给机器人uid的节点上分发，该节点没有注册处理器也没有配置回调地址时消息不会转发到其他节点
+type botManager struct {
+	s *Server
+
+	handlerLock sync.RWMutex
+	handlers    map[string]BotHandler
+
+	workerLock sync.Mutex
+	workers    map[string]*botWorker
+
+	httpClient *http.Client
+	stopper    *syncutil.Stopper
+	wklog.Log
+}
+
+func newBotManager(s *Server) *botManager {
+	return &botManager{
+		s:          s,
+		handlers:   make(map[string]BotHandler),
+		workers:    make(map[string]*botWorker),
+		httpClient: &http.Client{Timeout: s.opts.Bot.CallbackTimeout},
+		stopper:    syncutil.NewStopper(),
+		Log:        wklog.NewWKLog("botManager"),
+	}
+}
+
+func (b *botManager) stop() {
+	b.stopper.Stop()
+}
+
+func (b *botManager) registerHandler(uid string, handler BotHandler) {
+	b.handlerLock.Lock()
+	b.handlers[uid] = handler
+	b.handlerLock.Unlock()
+}
+
+func (b *botManager) unregisterHandler(uid string) {
+	b.handlerLock.Lock()
+	delete(b.handlers, uid)
+	b.handlerLock.Unlock()
+
+	b.removeWorker(uid)
+}
+
+// removeWorker 停止并移除机器人的处理协程，之后有新消息时会重新创建
+func (b *botManager) removeWorker(uid string) {
+	b.workerLock.Lock()
+	worker := b.workers[uid]
+	delete(b.workers, uid)
+	b.workerLock.Unlock()
+	if worker != nil {
+		worker.stop()
+	}
+}
+
+// botInfo 机器人的配置
+type botInfo struct {
+	uid           string
+	callbackUrl   string
+	handler       BotHandler
+	rateLimit     float64
+	commandPrefix string
+}
+
+// bot 获取uid对应的机器人，不是机器人返回nil
+func (b *botManager) bot(uid string) *botInfo {
+	b.handlerLock.RLock()
+	handler := b.handlers[uid]
+	b.handlerLock.RUnlock()
+
+	var cfgBot *pb.Bot
+	if b.s.clusterServer != nil {
+		cfgBot = b.s.clusterServer.GetConfig().Bot(uid)
+	}
+	if handler == nil && (cfgBot == nil || cfgBot.CallbackUrl == "") {
+		return nil
+	}
+	info := &botInfo{
+		uid:           uid,
+		handler:       handler,
+		rateLimit:     b.s.opts.Bot.RateLimit,
+		commandPrefix: b.s.opts.Bot.CommandPrefix,
+	}
+	if cfgBot != nil {
+		info.callbackUrl = cfgBot.CallbackUrl
+		if cfgBot.RateLimit > 0 {
+			info.rateLimit = cfgBot.RateLimit
+		}
+		if cfgBot.CommandPrefix != "" {
+			info.commandPrefix = cfgBot.CommandPrefix
+		}
+	}
+	return info
+}
+
+// dispatch 将投递给本节点用户的消息中发给机器人的消息分发给机器人
+func (b *botManager) dispatch(req *deliverReq, uids []string) {
+	if !b.s.opts.Bot.On || req.isStream || b.s.opts.IsCmdChannel(req.channelId) {
+		return
+	}
+	for _, uid := range uids {
+		bot := b.bot(uid)
+		if bot == nil {
+			continue
+		}
+		for _, msg := range req.messages {
+			if msg.FromUid == uid || msg.SendPacket == nil {
+				continue
+			}
+			if msg.SendPacket.Setting.IsSet(wkproto.SettingSignal) { // 端到端加密的消息服务端无法解析
+				continue
+			}
+			ctx := newBotContext(b.s, bot, req.channelId, req.channelType, msg)
+			if req.channelType != wkproto.ChannelTypePerson && !ctx.Mentioned {
+				continue
+			}
+			b.push(bot, ctx)
+		}
+	}
+}
+
+func (b *botManager) push(bot *botInfo, ctx *BotContext) {
+	b.workerLock.Lock()
+	worker := b.workers[bot.uid]
+	if worker == nil {
+		worker = newBotWorker(b, bot.uid)
+		b.workers[bot.uid] = worker
+		b.stopper.RunWorker(worker.loop)
+	}
+	b.workerLock.Unlock()
+
+	if !worker.allow(bot.rateLimit) {
+		b.Warn("机器人消息超过频率限制，丢弃消息！", zap.String("bot", bot.uid), zap.Int64("messageId", ctx.MessageId))
+		return
+	}
+	select {
+	case worker.queueC <- ctx:
+	default:
+		b.Warn("机器人消息队列已满，丢弃消息！", zap.String("bot", bot.uid), zap.Int64("messageId", ctx.MessageId))
+	}
+}
+
+// handle 回调机器人
+func (b *botManager) handle(ctx *BotContext) {
+	bot := b.bot(ctx.BotUid)
+	if bot == nil { // 机器人已移除，停止处理协程
+		b.removeWorker(ctx.BotUid)
+		return
+	}
+	if bot.handler != nil {
+		bot.handler(ctx)
+		return
+	}
+	if err := b.callback(bot.callbackUrl, ctx); err != nil {
+		b.Warn("回调机器人失败！", zap.Error(err), zap.String("bot", bot.uid), zap.String("callbackUrl", bot.callbackUrl))
+	}
+}
+
+// botCallbackResp 机器人回调地址的返回，replies里的消息会以机器人身份回复
+type botCallbackResp struct {
+	Replies []struct {
+		Payload []byte `json:"payload"`
+	} `json:"replies"`
+}
+
+func (b *botManager) callback(callbackUrl string, ctx *BotContext) error {
+	data, err := json.Marshal(ctx)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, callbackUrl, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range b.s.webhook.signHeaders(EventBotMessage, data) {
+		req.Header.Set(key, value)
+	}
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("机器人回调地址返回状态错误[%d]！", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	var callbackResp botCallbackResp
+	if err = json.Unmarshal(body, &callbackResp); err != nil {
+		return err
+	}
+	for _, reply := range callbackResp.Replies {
+		if len(reply.Payload) == 0 {
+			continue
+		}
+		if err = ctx.Reply(reply.Payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// send 以机器人的身份发送消息
+func (b *botManager) send(botUid string, channelId string, channelType uint8, payload []byte) error {
+	if len(payload) == 0 {
+		return errors.New("payload不能为空！")
+	}
+	_, err := sendMessageToChannel(b.s, MessageSendReq{
+		Header: MessageHeader{
+			RedDot: 1,
+		},
+		FromUID: botUid,
+		Payload: payload,
+	}, channelId, channelType, wkutil.GenUUID(), wkproto.StreamFlagIng)
+	return err
+}
+
+// botWorker 每个机器人一个处理协程，按顺序处理机器人的消息
+type botWorker struct {
+	b       *botManager
+	uid     string
+	queueC  chan *BotContext
+	limiter *rate.Limiter
+	mu      sync.Mutex
+	stopC   chan struct{}
+	stopped sync.Once
+}
+
+func newBotWorker(b *botManager, uid string) *botWorker {
+	return &botWorker{
+		b:      b,
+		uid:    uid,
+		queueC: make(chan *BotContext, b.s.opts.Bot.QueueSize),
+		stopC:  make(chan struct{}),
+	}
+}
+
+func (w *botWorker) stop() {
+	w.stopped.Do(func() {
+		close(w.stopC)
+	})
+}
+
+// allow 是否在频率限制内，rateLimit小于等于0表示不限制
+func (w *botWorker) allow(rateLimit float64) bool {
+	if rateLimit <= 0 {
+		return true
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.limiter == nil {
+		w.limiter = rate.NewLimiter(rate.Limit(rateLimit), w.b.s.opts.Bot.RateBurst)
+	} else if w.limiter.Limit() != rate.Limit(rateLimit) {
+		w.limiter.SetLimit(rate.Limit(rateLimit))
+	}
+	return w.limiter.Allow()
+}
+
+func (w *botWorker) loop() {
+	for {
+		select {
+		case ctx := <-w.queueC:
+			w.b.handle(ctx)
+		case <-w.stopC:
+			return
+		case <-w.b.stopper.ShouldStop():
+			return
+		}
+	}
+}
+
+func newBotContext(s *Server, bot *botInfo, channelId string, channelType uint8, msg ReactorChannelMessage) *BotContext {
+	ctx := &BotContext{
+		BotUid:      bot.uid,
+		FromUid:     msg.FromUid,
+		ChannelId:   channelId,
+		ChannelType: channelType,
+		MessageId:   msg.MessageId,
+		MessageSeq:  msg.MessageSeq,
+		ClientMsgNo: msg.SendPacket.ClientMsgNo,
+		Payload:     msg.SendPacket.Payload,
+		s:           s,
+	}
+	if channelType == wkproto.ChannelTypePerson {
+		ctx.ChannelId = msg.FromUid // 个人频道回复给发送者
+	}
+	ctx.Content = parseBotContent(msg.SendPacket.Payload)
+	// @信息和最近会话、离线推送使用同样的解析，@所有人也算被@
+	if mention := parseMessageMention(msg.SendPacket); mention != nil {
+		ctx.Mentioned = len(mention.filter([]string{bot.uid}, msg.FromUid)) > 0
+	}
+	ctx.Command, ctx.Args = parseBotCommand(ctx.Content, bot.commandPrefix)
+	return ctx
+}
+
+// parseBotContent 解析消息内容的文本
+// payload为json时取content字段，否则整个payload作为文本
+func parseBotContent(payload []byte) string {
+	var content struct {
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(payload, &content); err == nil {
+		return content.Content
+	}
+	if utf8.Valid(payload) {
+		return string(payload)
+	}
+	return ""
+}
+
+// parseBotCommand 解析命令，例如前缀为/时 "@bot /weather beijing" 解析为 weather 和 [beijing]
+func parseBotCommand(content string, prefix string) (string, []string) {
+	if prefix == "" {
+		return "", nil
+	}
+	fields := strings.Fields(content)
+	for len(fields) > 0 && strings.HasPrefix(fields[0], "@") { // 去掉开头的@
+		fields = fields[1:]
+	}
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], prefix) {
+		return "", nil
+	}
+	command := strings.TrimPrefix(fields[0], prefix)
+	if command == "" {
+		return "", nil
+	}
+	return command, fields[1:]
+}
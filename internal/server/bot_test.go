@@ -0,0 +1,138 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/client"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBotCommand(t *testing.T) {
+	command, args := parseBotCommand("@bot1 /weather beijing today", "/")
+	assert.Equal(t, "weather", command)
+	assert.Equal(t, []string{"beijing", "today"}, args)
+
+	command, _ = parseBotCommand("hello /weather", "/")
+	assert.Equal(t, "", command)
+
+	command, args = parseBotCommand("!help", "!")
+	assert.Equal(t, "help", command)
+	assert.Empty(t, args)
+
+	content := parseBotContent([]byte(`{"type":1,"content":"@bot1 /help","mention":{"uids":["bot1"]}}`))
+	assert.Equal(t, "@bot1 /help", content)
+
+	content = parseBotContent([]byte("plain text"))
+	assert.Equal(t, "plain text", content)
+}
+
+func TestBotContextMentioned(t *testing.T) {
+	s := &Server{opts: NewOptions()}
+	bot := &botInfo{uid: "bot1", commandPrefix: "/"}
+	newCtx := func(fromUid string, payload string) *BotContext {
+		return newBotContext(s, bot, "g1", wkproto.ChannelTypeGroup, ReactorChannelMessage{
+			FromUid:    fromUid,
+			SendPacket: &wkproto.SendPacket{Payload: []byte(payload)},
+		})
+	}
+
+	ctx := newCtx("u1", `{"type":1,"content":"@bot1 /help","mention":{"uids":["bot1"]}}`)
+	assert.True(t, ctx.Mentioned)
+	assert.Equal(t, "help", ctx.Command)
+
+	// @所有人
+	ctx = newCtx("u1", `{"type":1,"content":"@all hi","mention":{"all":1}}`)
+	assert.True(t, ctx.Mentioned)
+
+	ctx = newCtx("u1", `{"type":1,"content":"@u2 hi","mention":{"uids":["u2"]}}`)
+	assert.False(t, ctx.Mentioned)
+
+	// 机器人自己发的@所有人不算被@
+	ctx = newCtx("bot1", `{"type":1,"content":"@all hi","mention":{"all":1}}`)
+	assert.False(t, ctx.Mentioned)
+}
+
+func TestBot(t *testing.T) {
+	s := NewTestServer(t, WithHTTPAddr(testFreeAddr(t)), WithManagerAddr(testFreeAddr(t)), WithDemoOn(false))
+	s.opts.Mode = TestMode
+
+	ctxC := make(chan *BotContext, 1)
+	s.RegisterBot("bot1", func(ctx *BotContext) {
+		ctxC <- ctx
+		assert.NoError(t, ctx.ReplyText("pong"))
+	})
+
+	err := s.Start()
+	assert.Nil(t, err)
+	defer s.StopNoErr()
+
+	s.MustWaitAllSlotsReady(time.Second * 10) // 等待服务准备好
+
+	cli := client.New(s.opts.External.TCPAddr, client.WithUID("test1"))
+	err = cli.Connect()
+	assert.Nil(t, err)
+	defer cli.Close()
+
+	recvC := make(chan *wkproto.RecvPacket, 1)
+	cli.SetOnRecv(func(recv *wkproto.RecvPacket) error {
+		recvC <- recv
+		return nil
+	})
+
+	err = cli.SendMessage(client.NewChannel("bot1", wkproto.ChannelTypePerson), []byte(`{"type":1,"content":"/ping now"}`))
+	assert.Nil(t, err)
+
+	select {
+	case ctx := <-ctxC:
+		assert.Equal(t, "test1", ctx.FromUid)
+		assert.Equal(t, "test1", ctx.ChannelId)
+		assert.Equal(t, "ping", ctx.Command)
+		assert.Equal(t, []string{"now"}, ctx.Args)
+	case <-time.After(time.Second * 10):
+		t.Fatal("bot not receive message")
+	}
+
+	select {
+	case recv := <-recvC:
+		assert.Equal(t, "bot1", recv.FromUID)
+		var payload map[string]interface{}
+		assert.NoError(t, json.Unmarshal(recv.Payload, &payload))
+		assert.Equal(t, "pong", payload["content"])
+	case <-time.After(time.Second * 10):
+		t.Fatal("not receive bot reply")
+	}
+}
+
+func TestBotWorkerStopOnUnregister(t *testing.T) {
+	b := newBotManager(&Server{opts: NewOptions()})
+	defer b.stop()
+
+	handledC := make(chan struct{}, 1)
+	b.registerHandler("bot1", func(ctx *BotContext) {
+		handledC <- struct{}{}
+	})
+	b.push(&botInfo{uid: "bot1"}, &BotContext{BotUid: "bot1"})
+	select {
+	case <-handledC:
+	case <-time.After(time.Second * 5):
+		t.Fatal("bot not handle message")
+	}
+
+	b.workerLock.Lock()
+	worker := b.workers["bot1"]
+	b.workerLock.Unlock()
+	assert.NotNil(t, worker)
+
+	b.unregisterHandler("bot1")
+	b.workerLock.Lock()
+	assert.Len(t, b.workers, 0)
+	b.workerLock.Unlock()
+	select {
+	case <-worker.stopC:
+	default:
+		t.Fatal("bot worker not stopped")
+	}
+}
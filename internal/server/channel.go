@@ -557,6 +557,11 @@ func (c *channel) makeReceiverTag() (*tag, error) {
 // requestSubscribers 请求订阅者
 func (c *channel) requestSubscribers(channelId string, channelType uint8) ([]string, error) {
 
+	// 开启了数据源的订阅者获取，订阅者以数据源为准
+	if c.r.s.opts.DatasourceSubscriberOn() {
+		return c.r.s.datasource.GetSubscribers(channelId, channelType)
+	}
+
 	leaderNode, err := c.r.s.cluster.LeaderOfChannelForRead(channelId, channelType)
 	if err != nil {
 		return nil, err
@@ -595,6 +595,14 @@ func (r *channelReactor) hasPermission(channelId string, channelType uint8, from
 	}
 
 	channelInfo := ch.info
+	if r.opts.HasDatasource() && r.opts.Datasource.ChannelInfoOn {
+		dsChannelInfo, err := r.s.datasource.GetChannelInfo(realFakeChannelId, channelType)
+		if err != nil {
+			r.Error("datasource GetChannelInfo error", zap.Error(err), zap.String("channelId", realFakeChannelId), zap.Uint8("channelType", channelType))
+			return wkproto.ReasonSystemError, err
+		}
+		channelInfo = dsChannelInfo
+	}
 
 	if channelInfo.Ban { // 频道被封禁
 		return wkproto.ReasonBan, nil
@@ -604,6 +612,11 @@ func (r *channelReactor) hasPermission(channelId string, channelType uint8, from
 		return wkproto.ReasonDisband, nil
 	}
 
+	// 开启了数据源的订阅者获取，成员和黑白名单以数据源为准
+	if r.opts.DatasourceSubscriberOn() {
+		return r.hasPermissionOfDatasource(realFakeChannelId, channelType, fromUid)
+	}
+
 	// 判断是否是黑名单内
 	isDenylist, err := r.s.store.ExistDenylist(realFakeChannelId, channelType, fromUid)
 	if err != nil {
@@ -647,6 +660,37 @@ func (r *channelReactor) hasPermission(channelId string, channelType uint8, from
 	return wkproto.ReasonSuccess, nil
 }
 
+// hasPermissionOfDatasource 通过数据源判断是否有发送权限
+func (r *channelReactor) hasPermissionOfDatasource(channelId string, channelType uint8, fromUid string) (wkproto.ReasonCode, error) {
+	isDenylist, err := r.s.datasource.existBlacklist(channelId, channelType, fromUid)
+	if err != nil {
+		r.Error("datasource existBlacklist error", zap.Error(err), zap.String("channelId", channelId), zap.Uint8("channelType", channelType))
+		return wkproto.ReasonSystemError, err
+	}
+	if isDenylist {
+		return wkproto.ReasonInBlacklist, nil
+	}
+
+	isSubscriber, err := r.s.datasource.existSubscriber(channelId, channelType, fromUid)
+	if err != nil {
+		r.Error("datasource existSubscriber error", zap.Error(err), zap.String("channelId", channelId), zap.Uint8("channelType", channelType))
+		return wkproto.ReasonSystemError, err
+	}
+	if !isSubscriber {
+		return wkproto.ReasonSubscriberNotExist, nil
+	}
+
+	inWhitelist, err := r.s.datasource.inWhitelist(channelId, channelType, fromUid)
+	if err != nil {
+		r.Error("datasource inWhitelist error", zap.Error(err), zap.String("channelId", channelId), zap.Uint8("channelType", channelType))
+		return wkproto.ReasonSystemError, err
+	}
+	if !inWhitelist {
+		return wkproto.ReasonNotInWhitelist, nil
+	}
+	return wkproto.ReasonSuccess, nil
+}
+
 func (r *channelReactor) requestAllowSend(from, to string) (wkproto.ReasonCode, error) {
 
 	leaderNode, err := r.s.cluster.SlotLeaderOfChannel(to, wkproto.ChannelTypePerson)
@@ -683,6 +727,9 @@ func (r *channelReactor) requestAllowSend(from, to string) (wkproto.ReasonCode,
 }
 
 func (r *channelReactor) allowSend(from, to string) (wkproto.ReasonCode, error) {
+	if r.opts.DatasourceSubscriberOn() {
+		return r.allowSendOfDatasource(from, to)
+	}
 	// 判断是否是黑名单内
 	isDenylist, err := r.s.store.ExistDenylist(to, wkproto.ChannelTypePerson, from)
 	if err != nil {
@@ -708,6 +755,30 @@ func (r *channelReactor) allowSend(from, to string) (wkproto.ReasonCode, error)
 	return wkproto.ReasonSuccess, nil
 }
 
+// allowSendOfDatasource 通过数据源判断接收者是否允许发送者发送消息
+func (r *channelReactor) allowSendOfDatasource(from, to string) (wkproto.ReasonCode, error) {
+	isDenylist, err := r.s.datasource.existBlacklist(to, wkproto.ChannelTypePerson, from)
+	if err != nil {
+		r.Error("datasource existBlacklist error", zap.String("from", from), zap.String("to", to), zap.Error(err))
+		return wkproto.ReasonSystemError, err
+	}
+	if isDenylist {
+		return wkproto.ReasonInBlacklist, nil
+	}
+
+	if !r.opts.WhitelistOffOfPerson {
+		isAllowlist, err := r.s.datasource.existWhitelist(to, wkproto.ChannelTypePerson, from)
+		if err != nil {
+			r.Error("datasource existWhitelist error", zap.String("from", from), zap.String("to", to), zap.Error(err))
+			return wkproto.ReasonSystemError, err
+		}
+		if !isAllowlist {
+			return wkproto.ReasonNotInWhitelist, nil
+		}
+	}
+	return wkproto.ReasonSuccess, nil
+}
+
 type permissionReq struct {
 	ch       *channel
 	messages []ReactorChannelMessage
@@ -881,7 +952,7 @@ func (r *channelReactor) processStorage(req *storageReq) {
 		}
 	}
 
-	if r.opts.WebhookOn(EventMsgNotify) && reason == ReasonSuccess {
+	if (r.opts.WebhookOn(EventMsgNotify) || r.opts.WebhookChannelOn(EventMsgNotify)) && reason == ReasonSuccess {
 		// 赋值messageeq
 		for i, msg := range messages {
 			for _, cmsg := range req.messages {
@@ -899,6 +970,22 @@ func (r *channelReactor) processStorage(req *storageReq) {
 			r.Error("AppendMessageOfNotifyQueue error", zap.Error(err), zap.Int("msgCount", len(messages)), zap.String("channelId", req.ch.channelId), zap.Uint8("channelType", req.ch.channelType))
 		}
 	}
+	if r.opts.EventSink.On && reason == ReasonSuccess {
+		// 通知事件流投递新存储的消息
+		var firstSeq, lastSeq uint64
+		for _, msg := range req.messages {
+			if msg.MessageSeq == 0 {
+				continue
+			}
+			if firstSeq == 0 || uint64(msg.MessageSeq) < firstSeq {
+				firstSeq = uint64(msg.MessageSeq)
+			}
+			if uint64(msg.MessageSeq) > lastSeq {
+				lastSeq = uint64(msg.MessageSeq)
+			}
+		}
+		r.s.eventSink.notify(req.ch.channelId, req.ch.channelType, firstSeq, lastSeq)
+	}
 	// 返回存储结果 TODO: 这里一直返回ReasonSuccess，如果存储失败，应该返回ReasonError，会导致死循环
 	r.respStoreResult(req, ReasonSuccess)
 }
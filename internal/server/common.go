@@ -353,6 +353,7 @@ type ConnKey string
 
 const (
 	ConnKeyParseProxyProto = "parseProxyProto" // 解析代理协议
+	ConnKeyGuardIp         = "guardIp"         // 连接占用连接数的IP
 )
 
 const (
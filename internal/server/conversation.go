@@ -178,11 +178,12 @@ func (c *ConversationManager) GetUserConversationFromCache(uid string, conversat
 
 	for _, update := range updates {
 		conversations = append(conversations, wkdb.Conversation{
-			Uid:          uid,
-			Type:         conversationType,
-			ChannelId:    update.channelId,
-			ChannelType:  update.channelType,
-			ReadToMsgSeq: update.getUserMessageSeq(uid),
+			Uid:           uid,
+			Type:          conversationType,
+			ChannelId:     update.channelId,
+			ChannelType:   update.channelType,
+			ReadToMsgSeq:  update.getUserMessageSeq(uid),
+			MentionMsgSeq: update.getMentionMsgSeq(uid),
 		})
 	}
 
@@ -190,6 +191,28 @@ func (c *ConversationManager) GetUserConversationFromCache(uid string, conversat
 
 }
 
+// FillUserUnreadFromCache 最近会话的未读数加上缓存里还没有保存的未读数
+func (c *ConversationManager) FillUserUnreadFromCache(uid string, conversations []wkdb.Conversation) {
+	for i, conversation := range conversations {
+		worker := c.worker(conversation.ChannelId, conversation.ChannelType)
+		worker.RLock()
+		update := worker.getConversationUpdate(conversation.ChannelId, conversation.ChannelType)
+		worker.RUnlock()
+		if update == nil {
+			continue
+		}
+		conversations[i].UnreadCount = update.getUnread(uid).unreadCount(conversation.UnreadCount)
+	}
+}
+
+// LockPropose 锁住频道所在工作者的最近会话保存，用户清空、设置未读数或删除最近会话时使用，返回解锁函数
+// 持有锁期间工作者不会保存最近会话，修改完成并调用DeleteUserConversationFromCache后再解锁
+func (c *ConversationManager) LockPropose(channelId string, channelType uint8) func() {
+	worker := c.worker(channelId, channelType)
+	worker.proposeLock.Lock()
+	return worker.proposeLock.Unlock
+}
+
 func (c *ConversationManager) DeleteUserConversationFromCache(uid string, channelId string, channelType uint8) {
 	worker := c.worker(channelId, channelType)
 
@@ -228,6 +251,7 @@ type conversationWorker struct {
 	stopper *syncutil.Stopper
 
 	sync.RWMutex
+	proposeLock sync.Mutex // 保存最近会话期间持有，用户修改未读数时也需要持有，避免被正在保存的旧未读数覆盖
 
 	updates []*conversationUpdate // 需要更新的集合
 
@@ -320,6 +344,19 @@ func (c *conversationWorker) handleReq(req *conversationReq) {
 		update.addOrUpdateUser(msg.FromUid, uint64(msg.MessageSeq))
 	}
 
+	// 更新本节点接收者的未读数和被@的用户
+	if !c.s.opts.IsCmdChannel(req.channelId) {
+		receivers := c.localReceivers(req)
+		// 接收者过多时不维护未读数，避免每次保存都重写所有成员的最近会话，未读数由消息序号推算
+		maxReceivers := c.s.opts.Conversation.UnreadMaxReceivers
+		unreadTracked := maxReceivers <= 0 || len(receivers) <= maxReceivers
+		update.setUnreadTracked(unreadTracked)
+		if unreadTracked {
+			c.updateUnread(update, receivers, messages)
+		}
+		c.updateMention(update, receivers, messages)
+	}
+
 	if req.channelType == wkproto.ChannelTypePerson {
 		// 如果是个人频道并且不是第一条消息，则不需要更新最近会话
 		if firstMsg.MessageSeq > 1 {
@@ -351,6 +388,65 @@ func (c *conversationWorker) handleReq(req *conversationReq) {
 
 }
 
+// localReceivers 返回频道在本节点的接收者
+func (c *conversationWorker) localReceivers(req *conversationReq) []string {
+	if req.channelType == wkproto.ChannelTypePerson {
+		u1, u2 := GetFromUIDAndToUIDWith(req.channelId)
+		uids := make([]string, 0, 2)
+		for _, uid := range []string{u1, u2} {
+			if len(uids) > 0 && uids[0] == uid { // 自己给自己发消息
+				continue
+			}
+			leaderId, err := c.s.cluster.SlotLeaderIdOfChannel(uid, wkproto.ChannelTypePerson)
+			if err != nil {
+				c.Error("localReceivers failed, SlotLeaderIdOfChannel is err", zap.Error(err), zap.String("uid", uid))
+				continue
+			}
+			if leaderId == c.s.opts.Cluster.NodeId {
+				uids = append(uids, uid)
+			}
+		}
+		return uids
+	}
+	tag := c.s.tagManager.getReceiverTag(req.tagKey)
+	if tag == nil {
+		c.Warn("localReceivers: getReceiverTag is nil", zap.String("tagKey", req.tagKey))
+		return nil
+	}
+	nodeUser := tag.getNodeUsers(c.s.opts.Cluster.NodeId)
+	if nodeUser == nil {
+		return nil
+	}
+	return nodeUser.uids
+}
+
+// updateUnread 更新接收者的未读数，用户自己发的消息表示已读至此消息，不显示红点的消息和命令消息不计入未读数
+func (c *conversationWorker) updateUnread(update *conversationUpdate, receivers []string, messages []ReactorChannelMessage) {
+	if len(receivers) == 0 {
+		return
+	}
+	for _, msg := range messages {
+		incr := msg.SendPacket != nil && msg.SendPacket.RedDot && !msg.SendPacket.SyncOnce
+		update.updateUnread(receivers, msg.FromUid, uint64(msg.MessageSeq), incr)
+	}
+}
+
+// updateMention 记录消息里被@的接收者，@所有人时为所有接收者
+func (c *conversationWorker) updateMention(update *conversationUpdate, receivers []string, messages []ReactorChannelMessage) {
+	if len(receivers) == 0 {
+		return
+	}
+	for _, msg := range messages {
+		mention := parseMessageMention(msg.SendPacket)
+		if mention == nil {
+			continue
+		}
+		for _, uid := range mention.filter(receivers, msg.FromUid) {
+			update.addMention(uid, uint64(msg.MessageSeq))
+		}
+	}
+}
+
 func (c *conversationWorker) loopPropose() {
 	tk := time.NewTicker(c.s.opts.Conversation.SyncInterval)
 	defer tk.Stop()
@@ -370,60 +466,98 @@ func (c *conversationWorker) loopPropose() {
 }
 
 func (c *conversationWorker) propose() {
+	c.proposeLock.Lock()
+	defer c.proposeLock.Unlock()
 
 	c.Lock()
-	conversations := make([]wkdb.Conversation, 0)
-
+	proposals := make([]*conversationProposal, 0, len(c.updates))
+	count := 0
 	for _, update := range c.updates {
 		conversationsWithUpdater, err := c.getConversationWithUpdater(update)
 		if err != nil {
+			update.resetProposingUnreads() // 没有保存，未读数留在缓存里下次保存
 			c.Error("getConversationWithUpdater err", zap.Error(err))
 			continue
 		}
-		conversations = append(conversations, conversationsWithUpdater...)
+		proposals = append(proposals, &conversationProposal{update: update, conversations: conversationsWithUpdater})
+		count += len(conversationsWithUpdater)
 	}
 	c.Unlock()
 
-	if len(conversations) == 0 {
+	if count == 0 {
 		return
 	}
 
-	c.Info("conversations update", zap.Int("count", len(conversations)))
+	c.Info("conversations update", zap.Int("count", count))
 
-	// 如果conversations的数据超过500则分批提交
-	if len(conversations) > 500 {
+	// 按频道分批提交，每批最多500条（单个频道超过500条时单独分批）
+	written := make([]*conversationProposal, 0, len(proposals))
+	batch := make([]*conversationProposal, 0)
+	batchCount := 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		conversations := make([]wkdb.Conversation, 0, batchCount)
+		for _, proposal := range batch {
+			conversations = append(conversations, proposal.conversations...)
+		}
 		for i := 0; i < len(conversations); i += 500 {
 			end := i + 500
 			if end > len(conversations) {
 				end = len(conversations)
 			}
-			err := c.s.store.AddOrUpdateConversations(conversations[i:end])
-			if err != nil {
-				c.Error("propose: AddOrUpdateConversations err", zap.Error(err))
-				return
+			if err := c.s.store.AddOrUpdateConversations(conversations[i:end]); err != nil {
+				return err
 			}
 		}
-	} else {
-		err := c.s.store.AddOrUpdateConversations(conversations)
-		if err != nil {
-			c.Error("propose: AddOrUpdateConversations err", zap.Error(err))
-			return
+		written = append(written, batch...)
+		batch = batch[:0]
+		batchCount = 0
+		return nil
+	}
+	var err error
+	for _, proposal := range proposals {
+		if batchCount > 0 && batchCount+len(proposal.conversations) > 500 {
+			if err = flush(); err != nil {
+				break
+			}
 		}
+		batch = append(batch, proposal)
+		batchCount += len(proposal.conversations)
+	}
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		c.Error("propose: AddOrUpdateConversations err", zap.Error(err))
 	}
 
 	c.Lock()
+	defer c.Unlock()
 
-	for _, conversation := range conversations {
-		for _, update := range c.updates {
-			if update.channelId == conversation.ChannelId && update.channelType == conversation.ChannelType {
-				update.removeUserIfSeqLE(conversation.Uid, conversation.ReadToMsgSeq)
-			}
-			update.shouldNotUpdateAll()
+	// 只确认已经保存的更新，没有保存的更新留在缓存里下次保存
+	writtenUpdates := make(map[*conversationUpdate]struct{}, len(written))
+	for _, proposal := range written {
+		writtenUpdates[proposal.update] = struct{}{}
+		for _, conversation := range proposal.conversations {
+			proposal.update.removeUserIfSeqLE(conversation.Uid, conversation.ReadToMsgSeq)
+			proposal.update.removeMentionIfSeqLE(conversation.Uid, conversation.MentionMsgSeq)
 		}
+		proposal.update.shouldNotUpdateAll()
+		proposal.update.ackUnreads()
 	}
+	for _, proposal := range proposals {
+		if _, ok := writtenUpdates[proposal.update]; !ok {
+			proposal.update.resetProposingUnreads()
+		}
+	}
+}
 
-	c.Unlock()
-
+// conversationProposal 一个频道待保存的最近会话
+type conversationProposal struct {
+	update        *conversationUpdate
+	conversations []wkdb.Conversation
 }
 
 func (c *conversationWorker) cleanUpdate() {
@@ -433,7 +567,7 @@ func (c *conversationWorker) cleanUpdate() {
 	for i := 0; i < len(c.updates); {
 
 		udpate := c.updates[i]
-		if !udpate.isUpdateAll() && len(udpate.users) == 0 && time.Since(udpate.activeTime) > c.s.opts.Conversation.CacheExpire {
+		if !udpate.isUpdateAll() && len(udpate.users) == 0 && !udpate.hasMention() && !udpate.hasUnread() && time.Since(udpate.activeTime) > c.s.opts.Conversation.CacheExpire {
 			c.updates = append(c.updates[:i], c.updates[i+1:]...)
 		} else {
 			i++
@@ -446,19 +580,41 @@ func (c *conversationWorker) getConversationWithUpdater(update *conversationUpda
 	updatedAt := time.Now()
 	conversations := make([]wkdb.Conversation, 0)
 
+	mentions := update.getMentions()    // 被@的用户，已经在下面更新的用户会从这里移除
+	unreads := update.snapshotUnreads() // 未读数有变化的用户，已经在下面更新的用户会从这里移除
+	unreadTracked := update.isUnreadTracked()
+
 	// 指定要更新的最近会话
 	for _, user := range update.users {
 		id := c.s.store.NextPrimaryKey()
+		unread := unreads[user.uid]
+		var storedUnreadCount uint32
+		tracked := unreadTracked
+		if unread.readSeq == 0 { // 需要在已保存的未读数上累加
+			conversation, err := c.s.store.GetConversation(user.uid, update.channelId, update.channelType)
+			if err != nil && err != wkdb.ErrNotFound {
+				return nil, err
+			}
+			storedUnreadCount = conversation.UnreadCount
+			if !wkdb.IsEmptyConversation(conversation) {
+				tracked = tracked && conversation.UnreadTracked
+			}
+		}
 		conversations = append(conversations, wkdb.Conversation{
-			Id:           id,
-			Uid:          user.uid,
-			ChannelId:    update.channelId,
-			ChannelType:  update.channelType,
-			Type:         update.conversationType,
-			ReadToMsgSeq: user.messageSeq,
-			CreatedAt:    &createdAt,
-			UpdatedAt:    &updatedAt,
+			Id:            id,
+			Uid:           user.uid,
+			ChannelId:     update.channelId,
+			ChannelType:   update.channelType,
+			Type:          update.conversationType,
+			UnreadCount:   unread.unreadCount(storedUnreadCount),
+			UnreadTracked: tracked,
+			ReadToMsgSeq:  user.messageSeq,
+			MentionMsgSeq: mentions[user.uid],
+			CreatedAt:     &createdAt,
+			UpdatedAt:     &updatedAt,
 		})
+		delete(mentions, user.uid)
+		delete(unreads, user.uid)
 	}
 
 	var willUpdateUids []string // 将要更新最近会话的用户集合
@@ -523,15 +679,61 @@ func (c *conversationWorker) getConversationWithUpdater(update *conversationUpda
 			continue
 		}
 		conversations = append(conversations, wkdb.Conversation{
-			Id:           id,
-			Uid:          uid,
-			Type:         update.conversationType,
-			ChannelId:    update.channelId,
-			ChannelType:  update.channelType,
-			ReadToMsgSeq: sugguestSeq,
-			CreatedAt:    &createdAt,
-			UpdatedAt:    &updatedAt,
+			Id:            id,
+			Uid:           uid,
+			Type:          update.conversationType,
+			ChannelId:     update.channelId,
+			ChannelType:   update.channelType,
+			UnreadCount:   unreads[uid].unreadCount(0),
+			UnreadTracked: unreadTracked,
+			ReadToMsgSeq:  sugguestSeq,
+			MentionMsgSeq: mentions[uid],
+			CreatedAt:     &createdAt,
+			UpdatedAt:     &updatedAt,
 		})
+		delete(mentions, uid)
+		delete(unreads, uid)
+	}
+
+	// 剩下的用户只更新未读数和@位置，已读位置保持不变
+	remainUids := make([]string, 0, len(mentions)+len(unreads))
+	for uid := range mentions {
+		remainUids = append(remainUids, uid)
+	}
+	for uid := range unreads {
+		if _, ok := mentions[uid]; !ok {
+			remainUids = append(remainUids, uid)
+		}
+	}
+	for _, uid := range remainUids {
+		mentionMsgSeq := mentions[uid]
+		conversation, err := c.s.store.GetConversation(uid, update.channelId, update.channelType)
+		if err != nil && err != wkdb.ErrNotFound {
+			return nil, err
+		}
+		if wkdb.IsEmptyConversation(conversation) {
+			if mentionMsgSeq == 0 { // 没有最近会话的用户只有被@时才创建最近会话
+				continue
+			}
+			conversation = wkdb.Conversation{
+				Id:            c.s.store.NextPrimaryKey(),
+				Uid:           uid,
+				Type:          update.conversationType,
+				ChannelId:     update.channelId,
+				ChannelType:   update.channelType,
+				ReadToMsgSeq:  mentionMsgSeq - 1,
+				UnreadTracked: unreadTracked,
+				CreatedAt:     &createdAt,
+			}
+		} else if !unreadTracked {
+			conversation.UnreadTracked = false // 频道不再维护未读数，已保存的未读数不再准确
+		}
+		if mentionMsgSeq > 0 {
+			conversation.MentionMsgSeq = mentionMsgSeq
+		}
+		conversation.UnreadCount = unreads[uid].unreadCount(conversation.UnreadCount)
+		conversation.UpdatedAt = &updatedAt
+		conversations = append(conversations, conversation)
 	}
 	return conversations, nil
 }
@@ -592,7 +794,11 @@ type conversationUpdate struct {
 	updateAll        bool                  // 是否需要更新整个频道的订阅者的最近会话
 	s                *Server
 	sync.RWMutex
-	suggestMessageSeq uint64 // 更新所有的时候建议使用的messageSeq
+	suggestMessageSeq uint64                // 更新所有的时候建议使用的messageSeq
+	mentions          map[string]uint64     // 被@的用户和最近一条@此用户的消息序号
+	unreads           map[string]userUnread // 未读数有变化的用户
+	proposingUnreads  map[string]userUnread // 正在保存的未读数
+	unreadTracked     bool                  // 本节点是否维护此频道的未读数（接收者未超过UnreadMaxReceivers）
 
 	activeTime time.Time // 最后一次更新时间
 }
@@ -602,6 +808,20 @@ type userUpdate struct {
 	uid        string
 }
 
+// userUnread 用户还没有保存的未读数
+type userUnread struct {
+	count   uint32 // readSeq为0时是在已保存的未读数上增加的数量，否则为用户的实际未读数
+	readSeq uint64 // 用户自己发的消息的序号（表示已读至此消息），为0表示没有发过消息
+}
+
+// unreadCount 返回用户实际的未读数，storedCount为已保存的未读数
+func (u userUnread) unreadCount(storedCount uint32) uint32 {
+	if u.readSeq > 0 {
+		return u.count
+	}
+	return storedCount + u.count
+}
+
 func newConversationUpdate(s *Server, channelId string, channelType uint8, lastTagKey string, suggestMessageSeq uint64) *conversationUpdate {
 
 	conversationType := wkdb.ConversationTypeChat
@@ -611,6 +831,8 @@ func newConversationUpdate(s *Server, channelId string, channelType uint8, lastT
 
 	return &conversationUpdate{
 		deleted:           make(map[string]struct{}),
+		mentions:          make(map[string]uint64),
+		unreads:           make(map[string]userUnread),
 		s:                 s,
 		channelId:         channelId,
 		channelType:       channelType,
@@ -649,6 +871,8 @@ func (c *conversationUpdate) deleteUser(uid string) {
 		}
 	}
 	c.deleted[uid] = struct{}{}
+	delete(c.unreads, uid) // 用户自己设置了未读数或删除了最近会话，缓存的未读数和@位置不再有效
+	delete(c.mentions, uid)
 
 }
 
@@ -681,6 +905,10 @@ func (c *conversationUpdate) exist(uid string) bool {
 		}
 	}
 
+	if _, ok := c.mentions[uid]; ok {
+		return true
+	}
+
 	if c.updateAll {
 		if c.channelType != wkproto.ChannelTypePerson && c.lastTagKey != "" {
 
@@ -703,6 +931,151 @@ func (c *conversationUpdate) getUserMessageSeq(uid string) uint64 {
 	}
 	return 0
 }
+
+// updateUnread fromUid已读至messageSeq，其他用户的未读数加1（incr为true时）
+func (c *conversationUpdate) updateUnread(uids []string, fromUid string, messageSeq uint64, incr bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, uid := range uids {
+		if uid == fromUid {
+			c.unreads[uid] = userUnread{readSeq: messageSeq}
+			continue
+		}
+		if !incr {
+			continue
+		}
+		unread := c.unreads[uid]
+		unread.count++
+		c.unreads[uid] = unread
+	}
+}
+
+func (c *conversationUpdate) setUnreadTracked(tracked bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.unreadTracked = tracked
+}
+
+func (c *conversationUpdate) isUnreadTracked() bool {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.unreadTracked
+}
+
+func (c *conversationUpdate) getUnread(uid string) userUnread {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.unreads[uid]
+}
+
+// snapshotUnreads 返回未读数的副本，并记录为正在保存的未读数
+func (c *conversationUpdate) snapshotUnreads() map[string]userUnread {
+	c.Lock()
+	defer c.Unlock()
+
+	c.proposingUnreads = c.copyUnreads(c.unreads)
+	return c.copyUnreads(c.unreads)
+}
+
+func (c *conversationUpdate) copyUnreads(unreads map[string]userUnread) map[string]userUnread {
+	cp := make(map[string]userUnread, len(unreads))
+	for uid, unread := range unreads {
+		cp[uid] = unread
+	}
+	return cp
+}
+
+// resetProposingUnreads 未读数没有保存成功，缓存的未读数保持不变
+func (c *conversationUpdate) resetProposingUnreads() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.proposingUnreads = nil
+}
+
+// ackUnreads 未读数保存成功后，从缓存的未读数里减去已保存的部分
+func (c *conversationUpdate) ackUnreads() {
+	c.Lock()
+	defer c.Unlock()
+
+	for uid, saved := range c.proposingUnreads {
+		unread, ok := c.unreads[uid]
+		if !ok || unread.readSeq != saved.readSeq { // 保存期间用户又发了消息，以新的为准
+			continue
+		}
+		if unread.count > saved.count {
+			unread.count -= saved.count
+		} else {
+			unread.count = 0
+		}
+		unread.readSeq = 0 // 剩下的都是在已保存的未读数上增加的
+		if unread.count == 0 {
+			delete(c.unreads, uid)
+		} else {
+			c.unreads[uid] = unread
+		}
+	}
+	c.proposingUnreads = nil
+}
+
+func (c *conversationUpdate) hasUnread() bool {
+	c.RLock()
+	defer c.RUnlock()
+
+	return len(c.unreads) > 0
+}
+
+func (c *conversationUpdate) addMention(uid string, messageSeq uint64) {
+	c.Lock()
+	defer c.Unlock()
+
+	delete(c.deleted, uid) // 被@了需要重新显示最近会话
+
+	if messageSeq > c.mentions[uid] {
+		c.mentions[uid] = messageSeq
+	}
+}
+
+func (c *conversationUpdate) getMentionMsgSeq(uid string) uint64 {
+	c.RLock()
+	defer c.RUnlock()
+
+	return c.mentions[uid]
+}
+
+// getMentions 返回被@的用户的副本
+func (c *conversationUpdate) getMentions() map[string]uint64 {
+	c.RLock()
+	defer c.RUnlock()
+
+	mentions := make(map[string]uint64, len(c.mentions))
+	for uid, messageSeq := range c.mentions {
+		mentions[uid] = messageSeq
+	}
+	return mentions
+}
+
+func (c *conversationUpdate) hasMention() bool {
+	c.RLock()
+	defer c.RUnlock()
+
+	return len(c.mentions) > 0
+}
+
+// 移除@位置小于等于messageSeq的指定用户
+func (c *conversationUpdate) removeMentionIfSeqLE(uid string, messageSeq uint64) {
+	c.Lock()
+	defer c.Unlock()
+
+	if mentionMsgSeq, ok := c.mentions[uid]; ok && mentionMsgSeq <= messageSeq {
+		delete(c.mentions, uid)
+	}
+}
+
 func (c *conversationUpdate) shouldUpdateAll() {
 	c.Lock()
 	defer c.Unlock()
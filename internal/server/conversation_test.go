@@ -1,10 +1,16 @@
 package server
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
 	wkproto "github.com/WuKongIM/WuKongIMGoProto"
 	"github.com/stretchr/testify/assert"
 )
@@ -245,3 +251,280 @@ func TestConversationUpdateForPropose(t *testing.T) {
 	assert.Equal(t, uint64(2), conversations2[0].ReadToMsgSeq)
 
 }
+
+func TestConversationUpdateForMention(t *testing.T) {
+	s := NewTestServer(t, WithHTTPAddr(testFreeAddr(t)), WithManagerAddr(testFreeAddr(t)), WithDemoOn(false))
+	err := s.Start()
+	assert.NoError(t, err)
+	defer func() {
+		_ = s.Stop()
+	}()
+
+	s.MustWaitAllSlotsReady(time.Second * 10)
+
+	channelId := "g1"
+	channelType := wkproto.ChannelTypeGroup
+
+	tagKey := "mentionTag"
+	s.tagManager.addOrUpdateReceiverTag(tagKey, []*nodeUsers{
+		{
+			uids:   []string{"u1", "u2", "u3"},
+			nodeId: s.opts.Cluster.NodeId,
+		},
+	}, channelId, channelType)
+
+	req := &conversationReq{
+		channelId:   channelId,
+		channelType: channelType,
+		tagKey:      tagKey,
+		messages: []ReactorChannelMessage{
+			{
+				FromUid:    "u1",
+				MessageSeq: 1,
+				SendPacket: &wkproto.SendPacket{Payload: []byte(`{"content":"@u2 hello","mention":{"uids":["u2"]}}`)},
+			},
+			{
+				FromUid:    "u1",
+				MessageSeq: 2,
+				SendPacket: &wkproto.SendPacket{Payload: []byte(`{"content":"hello"}`)},
+			},
+		},
+	}
+	s.conversationManager.Push(req)
+
+	time.Sleep(time.Millisecond * 100)
+
+	conversations := s.conversationManager.GetUserConversationFromCache("u2", wkdb.ConversationTypeChat)
+	assert.Equal(t, 1, len(conversations))
+	assert.Equal(t, uint64(1), conversations[0].MentionMsgSeq)
+
+	s.conversationManager.ForcePropose()
+
+	conversation, err := s.store.GetConversation("u2", channelId, channelType)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), conversation.MentionMsgSeq)
+	assert.Equal(t, uint64(0), conversation.ReadToMsgSeq)
+
+	conversation, err = s.store.GetConversation("u3", channelId, channelType)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), conversation.MentionMsgSeq)
+
+	// 新的@不覆盖已读位置
+	err = s.store.AddOrUpdateUserConversations("u2", []wkdb.Conversation{{
+		Id:           conversation.Id,
+		Uid:          "u2",
+		Type:         wkdb.ConversationTypeChat,
+		ChannelId:    channelId,
+		ChannelType:  channelType,
+		ReadToMsgSeq: 2,
+	}})
+	assert.NoError(t, err)
+
+	req.messages = []ReactorChannelMessage{
+		{
+			FromUid:    "u1",
+			MessageSeq: 3,
+			SendPacket: &wkproto.SendPacket{Payload: []byte(`{"content":"@all","mention":{"all":1}}`)},
+		},
+	}
+	s.conversationManager.Push(req)
+	s.conversationManager.ForcePropose()
+
+	conversation, err = s.store.GetConversation("u2", channelId, channelType)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), conversation.MentionMsgSeq)
+	assert.Equal(t, uint64(2), conversation.ReadToMsgSeq)
+
+	conversation, err = s.store.GetConversation("u1", channelId, channelType)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), conversation.MentionMsgSeq) // 发送者自己不算被@
+}
+
+func TestConversationUpdateForUnread(t *testing.T) {
+	s := NewTestServer(t, WithHTTPAddr(testFreeAddr(t)), WithManagerAddr(testFreeAddr(t)), WithDemoOn(false))
+	err := s.Start()
+	assert.NoError(t, err)
+	defer func() {
+		_ = s.Stop()
+	}()
+
+	s.MustWaitAllSlotsReady(time.Second * 10)
+
+	channelId := "g1"
+	channelType := wkproto.ChannelTypeGroup
+
+	tagKey := "unreadTag"
+	s.tagManager.addOrUpdateReceiverTag(tagKey, []*nodeUsers{
+		{
+			uids:   []string{"u1", "u2", "u3"},
+			nodeId: s.opts.Cluster.NodeId,
+		},
+	}, channelId, channelType)
+
+	req := &conversationReq{
+		channelId:   channelId,
+		channelType: channelType,
+		tagKey:      tagKey,
+		messages: []ReactorChannelMessage{
+			{
+				FromUid:    "u1",
+				MessageSeq: 1,
+				SendPacket: &wkproto.SendPacket{Framer: wkproto.Framer{RedDot: true}},
+			},
+			{
+				FromUid:    "u2",
+				MessageSeq: 2,
+				SendPacket: &wkproto.SendPacket{Framer: wkproto.Framer{RedDot: true}},
+			},
+			{
+				FromUid:    "u2",
+				MessageSeq: 3,
+				SendPacket: &wkproto.SendPacket{}, // 不显示红点的消息不计入未读数
+			},
+			{
+				FromUid:    "u2",
+				MessageSeq: 4,
+				SendPacket: &wkproto.SendPacket{Framer: wkproto.Framer{RedDot: true, SyncOnce: true}}, // 命令消息不计入未读数
+			},
+		},
+	}
+	s.conversationManager.Push(req)
+	s.conversationManager.ForcePropose()
+
+	unreads := map[string]uint32{"u1": 1, "u2": 0, "u3": 2}
+	for uid, unread := range unreads {
+		conversation, err := s.store.GetConversation(uid, channelId, channelType)
+		assert.NoError(t, err)
+		assert.Equal(t, unread, conversation.UnreadCount, uid)
+		assert.True(t, conversation.UnreadTracked, uid)
+	}
+
+	getUnreadTotal := func(uid string) map[string]interface{} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/conversations/unread_total?uid="+uid, nil)
+		s.apiServer.r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resultMap map[string]interface{}
+		err := wkutil.ReadJSONByByte(w.Body.Bytes(), &resultMap)
+		assert.NoError(t, err)
+		return resultMap
+	}
+
+	// 还没有保存的未读数也要算上
+	req.messages = []ReactorChannelMessage{
+		{
+			FromUid:    "u1",
+			MessageSeq: 5,
+			SendPacket: &wkproto.SendPacket{Framer: wkproto.Framer{RedDot: true}},
+		},
+	}
+	s.conversationManager.Push(req)
+	resultMap := getUnreadTotal("u3")
+	assert.Equal(t, json.Number("3"), resultMap["unread"])
+	assert.Equal(t, json.Number("1"), resultMap["unread_channels"])
+
+	s.conversationManager.ForcePropose()
+	conversation, err := s.store.GetConversation("u3", channelId, channelType)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(3), conversation.UnreadCount)
+	resultMap = getUnreadTotal("u3")
+	assert.Equal(t, json.Number("3"), resultMap["unread"])
+
+	// 清空未读数
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/conversations/clearUnread", bytes.NewReader([]byte(wkutil.ToJSON(map[string]interface{}{
+		"uid":          "u3",
+		"channel_id":   channelId,
+		"channel_type": channelType,
+	}))))
+	s.apiServer.r.ServeHTTP(w, httpReq)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	resultMap = getUnreadTotal("u3")
+	assert.Equal(t, json.Number("0"), resultMap["unread"])
+}
+
+func TestConversationUnreadTotalUntracked(t *testing.T) {
+	s := NewTestServer(t, WithHTTPAddr(testFreeAddr(t)), WithManagerAddr(testFreeAddr(t)), WithDemoOn(false), WithConversationUnreadMaxReceivers(2))
+	err := s.Start()
+	assert.NoError(t, err)
+	defer func() {
+		_ = s.Stop()
+	}()
+
+	s.MustWaitAllSlotsReady(time.Second * 10)
+
+	channelId := "g1"
+	channelType := wkproto.ChannelTypeGroup
+
+	// 频道的最后一条消息序号为3
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	_, err = s.clusterServer.LoadOrCreateChannel(timeoutCtx, channelId, channelType)
+	cancel()
+	assert.NoError(t, err)
+	for i := 1; i <= 3; i++ {
+		err = s.store.DB().AppendMessages(channelId, channelType, []wkdb.Message{
+			{RecvPacket: wkproto.RecvPacket{ChannelID: channelId, ChannelType: channelType, MessageSeq: uint32(i), Payload: []byte("hello")}},
+		})
+		assert.NoError(t, err)
+	}
+	err = s.store.DB().SetChannelLastMessageSeq(channelId, channelType, 3)
+	assert.NoError(t, err)
+
+	// 接收者超过UnreadMaxReceivers，不维护未读数
+	tagKey := "untrackedTag"
+	s.tagManager.addOrUpdateReceiverTag(tagKey, []*nodeUsers{
+		{
+			uids:   []string{"u1", "u2", "u3"},
+			nodeId: s.opts.Cluster.NodeId,
+		},
+	}, channelId, channelType)
+	s.conversationManager.Push(&conversationReq{
+		channelId:   channelId,
+		channelType: channelType,
+		tagKey:      tagKey,
+		messages: []ReactorChannelMessage{
+			{
+				FromUid:    "u1",
+				MessageSeq: 3,
+				SendPacket: &wkproto.SendPacket{Framer: wkproto.Framer{RedDot: true}},
+			},
+		},
+	})
+	s.conversationManager.ForcePropose()
+
+	conversation, err := s.store.GetConversation("u2", channelId, channelType)
+	assert.NoError(t, err)
+	assert.False(t, conversation.UnreadTracked)
+	assert.Equal(t, uint32(0), conversation.UnreadCount)
+	assert.Equal(t, uint64(2), conversation.ReadToMsgSeq)
+
+	// 未读数由频道的最后一条消息序号和已读位置推算
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/conversations/unread_total?uid=u2", nil)
+	s.apiServer.r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resultMap map[string]interface{}
+	err = wkutil.ReadJSONByByte(w.Body.Bytes(), &resultMap)
+	assert.NoError(t, err)
+	assert.Equal(t, json.Number("1"), resultMap["unread"])
+	assert.Equal(t, json.Number("1"), resultMap["unread_channels"])
+}
+
+func TestConversationUpdateUnreadNotAckedWhenNotSaved(t *testing.T) {
+	update := newConversationUpdate(&Server{opts: NewOptions()}, "g1", wkproto.ChannelTypeGroup, "", 0)
+	update.updateUnread([]string{"u1", "u2"}, "u1", 1, true)
+
+	// 保存失败，未读数保留在缓存里
+	update.snapshotUnreads()
+	update.resetProposingUnreads()
+	update.ackUnreads()
+	assert.Equal(t, uint32(1), update.getUnread("u2").count)
+
+	// 保存成功，已保存的未读数从缓存里移除
+	update.snapshotUnreads()
+	update.updateUnread([]string{"u1", "u2"}, "u1", 2, true)
+	update.ackUnreads()
+	assert.Equal(t, uint32(1), update.getUnread("u2").count)
+	assert.Equal(t, uint64(0), update.getUnread("u2").readSeq)
+}
@@ -3,10 +3,13 @@ package server
 import (
 	"fmt"
 	"net/http"
+	"time"
 
-	"github.com/WuKongIM/WuKongIM/pkg/network"
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
 	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	"github.com/sendgrid/rest"
+	"go.uber.org/zap"
 )
 
 // IDatasource 数据源第三方应用可以提供
@@ -23,16 +26,30 @@ type IDatasource interface {
 	GetChannelInfo(channelID string, channelType uint8) (wkdb.ChannelInfo, error)
 }
 
-// Datasource Datasource
+// Datasource http数据源
 type Datasource struct {
-	s *Server
+	s      *Server
+	client *rest.Client
+	wklog.Log
 }
 
-// NewDatasource 创建一个数据源
+// NewDatasource 创建一个数据源，数据源地址以grpc://开头则使用grpc数据源
 func NewDatasource(s *Server) IDatasource {
-
+	if isDatasourceGRPCAddr(s.opts.Datasource.Addr) {
+		ds, err := newGRPCDatasource(s.opts.Datasource.Addr, s.opts.Datasource.Timeout, s.opts.Datasource.MaxRetries, s.opts.Datasource.BatchWindow, s.opts.Datasource.BatchMaxSize)
+		if err != nil {
+			panic(err)
+		}
+		return ds
+	}
 	return &Datasource{
 		s: s,
+		client: &rest.Client{
+			HTTPClient: &http.Client{
+				Timeout: s.opts.Datasource.Timeout,
+			},
+		},
+		Log: wklog.NewWKLog("Datasource"),
 	}
 }
 
@@ -52,7 +69,7 @@ func (d *Datasource) GetChannelInfo(channelID string, channelType uint8) (wkdb.C
 	channelInfo := channelInfoResp.ToChannelInfo()
 	channelInfo.ChannelId = channelID
 	channelInfo.ChannelType = channelType
-	return wkdb.EmptyChannelInfo, nil
+	return *channelInfo, nil
 
 }
 
@@ -125,6 +142,7 @@ func (d *Datasource) GetSystemUIDs() ([]string, error) {
 	return uids, nil
 }
 
+// requestCMD 请求数据源，失败后按配置重试
 func (d *Datasource) requestCMD(cmd string, param map[string]interface{}) (string, error) {
 	dataMap := map[string]interface{}{
 		"cmd": cmd,
@@ -132,7 +150,30 @@ func (d *Datasource) requestCMD(cmd string, param map[string]interface{}) (strin
 	if param != nil {
 		dataMap["data"] = param
 	}
-	resp, err := network.Post(d.s.opts.Datasource.Addr, []byte(wkutil.ToJSON(dataMap)), nil)
+	body := []byte(wkutil.ToJSON(dataMap))
+	var (
+		result string
+		err    error
+	)
+	for i := 0; i <= d.s.opts.Datasource.MaxRetries; i++ {
+		if i > 0 {
+			time.Sleep(time.Millisecond * 100 * time.Duration(i))
+		}
+		result, err = d.requestCMDOnce(body)
+		if err == nil {
+			return result, nil
+		}
+		d.Warn("请求数据源失败！", zap.Error(err), zap.String("cmd", cmd), zap.Int("retry", i))
+	}
+	return "", err
+}
+
+func (d *Datasource) requestCMDOnce(body []byte) (string, error) {
+	resp, err := d.client.Send(rest.Request{
+		Method:  rest.Post,
+		BaseURL: d.s.opts.Datasource.Addr,
+		Body:    body,
+	})
 	if err != nil {
 		return "", err
 	}
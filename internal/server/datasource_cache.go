@@ -0,0 +1,259 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	"github.com/lni/goutils/syncutil"
+)
+
+type datasourceCacheKind uint8
+
+const (
+	datasourceCacheSubscribers datasourceCacheKind = iota
+	datasourceCacheBlacklist
+	datasourceCacheWhitelist
+	datasourceCacheChannelInfo
+)
+
+var datasourceCacheKinds = []datasourceCacheKind{
+	datasourceCacheSubscribers,
+	datasourceCacheBlacklist,
+	datasourceCacheWhitelist,
+	datasourceCacheChannelInfo,
+}
+
+type datasourceCacheKey struct {
+	kind       datasourceCacheKind
+	channelKey string
+}
+
+type datasourceCacheEntry struct {
+	uids        []string
+	uidSet      map[string]struct{}
+	channelInfo wkdb.ChannelInfo
+	expireAt    time.Time
+}
+
+// datasourceCache 第三方数据源的本地缓存
+// 订阅者、黑名单、白名单和频道信息按频道缓存，过期后重新请求数据源，
+// 数据源的数据变更后可以通过 /datasource/cache_invalidate 主动让缓存失效
+type datasourceCache struct {
+	ds  IDatasource
+	ttl time.Duration // 为0表示不缓存
+
+	mu      sync.RWMutex
+	entries map[datasourceCacheKey]*datasourceCacheEntry
+	version uint64 // 每次失效都会加1，请求数据源期间缓存被失效则不保存请求结果，避免缓存旧数据
+
+	stopper *syncutil.Stopper
+	wklog.Log
+}
+
+func newDatasourceCache(ds IDatasource, ttl time.Duration) *datasourceCache {
+	return &datasourceCache{
+		ds:      ds,
+		ttl:     ttl,
+		entries: make(map[datasourceCacheKey]*datasourceCacheEntry),
+		stopper: syncutil.NewStopper(),
+		Log:     wklog.NewWKLog("datasourceCache"),
+	}
+}
+
+func (d *datasourceCache) start() {
+	if d.ttl <= 0 {
+		return
+	}
+	d.stopper.RunWorker(d.cleanLoop)
+}
+
+func (d *datasourceCache) stop() {
+	d.stopper.Stop()
+	if closer, ok := d.ds.(interface{ close() }); ok {
+		closer.close()
+	}
+}
+
+func (d *datasourceCache) GetSubscribers(channelID string, channelType uint8) ([]string, error) {
+	entry, err := d.getList(datasourceCacheSubscribers, channelID, channelType, d.ds.GetSubscribers)
+	if err != nil {
+		return nil, err
+	}
+	return entry.uids, nil
+}
+
+func (d *datasourceCache) GetBlacklist(channelID string, channelType uint8) ([]string, error) {
+	entry, err := d.getList(datasourceCacheBlacklist, channelID, channelType, d.ds.GetBlacklist)
+	if err != nil {
+		return nil, err
+	}
+	return entry.uids, nil
+}
+
+func (d *datasourceCache) GetWhitelist(channelID string, channelType uint8) ([]string, error) {
+	entry, err := d.getList(datasourceCacheWhitelist, channelID, channelType, d.ds.GetWhitelist)
+	if err != nil {
+		return nil, err
+	}
+	return entry.uids, nil
+}
+
+// GetSystemUIDs 系统账号由SystemUIDManager缓存，这里不再缓存
+func (d *datasourceCache) GetSystemUIDs() ([]string, error) {
+	return d.ds.GetSystemUIDs()
+}
+
+func (d *datasourceCache) GetChannelInfo(channelID string, channelType uint8) (wkdb.ChannelInfo, error) {
+	key := datasourceCacheKey{kind: datasourceCacheChannelInfo, channelKey: wkutil.ChannelToKey(channelID, channelType)}
+	if entry := d.get(key); entry != nil {
+		return entry.channelInfo, nil
+	}
+	version := d.currentVersion()
+	channelInfo, err := d.ds.GetChannelInfo(channelID, channelType)
+	if err != nil {
+		return wkdb.EmptyChannelInfo, err
+	}
+	d.set(key, &datasourceCacheEntry{channelInfo: channelInfo}, version)
+	return channelInfo, nil
+}
+
+// existSubscriber 是否是频道的订阅者
+func (d *datasourceCache) existSubscriber(channelID string, channelType uint8, uid string) (bool, error) {
+	return d.exist(datasourceCacheSubscribers, channelID, channelType, uid, d.ds.GetSubscribers)
+}
+
+// existBlacklist 是否在频道的黑名单内
+func (d *datasourceCache) existBlacklist(channelID string, channelType uint8, uid string) (bool, error) {
+	return d.exist(datasourceCacheBlacklist, channelID, channelType, uid, d.ds.GetBlacklist)
+}
+
+// existWhitelist 是否在频道的白名单内
+func (d *datasourceCache) existWhitelist(channelID string, channelType uint8, uid string) (bool, error) {
+	return d.exist(datasourceCacheWhitelist, channelID, channelType, uid, d.ds.GetWhitelist)
+}
+
+// inWhitelist 频道没有白名单或者在白名单内返回true
+func (d *datasourceCache) inWhitelist(channelID string, channelType uint8, uid string) (bool, error) {
+	entry, err := d.getList(datasourceCacheWhitelist, channelID, channelType, d.ds.GetWhitelist)
+	if err != nil {
+		return false, err
+	}
+	if len(entry.uids) == 0 {
+		return true, nil
+	}
+	_, ok := entry.uidSet[uid]
+	return ok, nil
+}
+
+// invalidate 让频道的缓存失效
+func (d *datasourceCache) invalidate(channelID string, channelType uint8) {
+	channelKey := wkutil.ChannelToKey(channelID, channelType)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.version++
+	for _, kind := range datasourceCacheKinds {
+		delete(d.entries, datasourceCacheKey{kind: kind, channelKey: channelKey})
+	}
+}
+
+// invalidateAll 让所有的缓存失效
+func (d *datasourceCache) invalidateAll() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.version++
+	d.entries = make(map[datasourceCacheKey]*datasourceCacheEntry)
+}
+
+func (d *datasourceCache) exist(kind datasourceCacheKind, channelID string, channelType uint8, uid string, load func(string, uint8) ([]string, error)) (bool, error) {
+	entry, err := d.getList(kind, channelID, channelType, load)
+	if err != nil {
+		return false, err
+	}
+	_, ok := entry.uidSet[uid]
+	return ok, nil
+}
+
+func (d *datasourceCache) getList(kind datasourceCacheKind, channelID string, channelType uint8, load func(string, uint8) ([]string, error)) (*datasourceCacheEntry, error) {
+	key := datasourceCacheKey{kind: kind, channelKey: wkutil.ChannelToKey(channelID, channelType)}
+	if entry := d.get(key); entry != nil {
+		return entry, nil
+	}
+	version := d.currentVersion()
+	uids, err := load(channelID, channelType)
+	if err != nil {
+		return nil, err
+	}
+	entry := &datasourceCacheEntry{
+		uids:   uids,
+		uidSet: make(map[string]struct{}, len(uids)),
+	}
+	for _, uid := range uids {
+		entry.uidSet[uid] = struct{}{}
+	}
+	d.set(key, entry, version)
+	return entry, nil
+}
+
+func (d *datasourceCache) get(key datasourceCacheKey) *datasourceCacheEntry {
+	if d.ttl <= 0 {
+		return nil
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	entry := d.entries[key]
+	if entry == nil || time.Now().After(entry.expireAt) {
+		return nil
+	}
+	return entry
+}
+
+func (d *datasourceCache) set(key datasourceCacheKey, entry *datasourceCacheEntry, version uint64) {
+	if d.ttl <= 0 {
+		return
+	}
+	entry.expireAt = time.Now().Add(d.ttl)
+	d.mu.Lock()
+	if d.version == version {
+		d.entries[key] = entry
+	}
+	d.mu.Unlock()
+}
+
+func (d *datasourceCache) currentVersion() uint64 {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.version
+}
+
+// cleanLoop 定时清理过期的缓存
+func (d *datasourceCache) cleanLoop() {
+	tk := time.NewTicker(d.ttl)
+	defer tk.Stop()
+	for {
+		select {
+		case <-tk.C:
+			d.clean(time.Now())
+		case <-d.stopper.ShouldStop():
+			return
+		}
+	}
+}
+
+func (d *datasourceCache) clean(now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for key, entry := range d.entries {
+		if now.After(entry.expireAt) {
+			delete(d.entries, key)
+		}
+	}
+}
+
+func (d *datasourceCache) count() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.entries)
+}
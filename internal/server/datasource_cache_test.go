@@ -0,0 +1,90 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/stretchr/testify/assert"
+)
+
+type testDatasource struct {
+	requests    int
+	subscribers []string
+	whitelist   []string
+}
+
+func (t *testDatasource) GetSubscribers(channelID string, channelType uint8) ([]string, error) {
+	t.requests++
+	return t.subscribers, nil
+}
+
+func (t *testDatasource) GetBlacklist(channelID string, channelType uint8) ([]string, error) {
+	t.requests++
+	return []string{"u3"}, nil
+}
+
+func (t *testDatasource) GetWhitelist(channelID string, channelType uint8) ([]string, error) {
+	t.requests++
+	return t.whitelist, nil
+}
+
+func (t *testDatasource) GetSystemUIDs() ([]string, error) {
+	return nil, nil
+}
+
+func (t *testDatasource) GetChannelInfo(channelID string, channelType uint8) (wkdb.ChannelInfo, error) {
+	t.requests++
+	return wkdb.ChannelInfo{ChannelId: channelID, ChannelType: channelType, Ban: true}, nil
+}
+
+func TestDatasourceCache(t *testing.T) {
+	ds := &testDatasource{subscribers: []string{"u1", "u2"}}
+	cache := newDatasourceCache(ds, time.Minute)
+
+	ok, err := cache.existSubscriber("g1", 2, "u1")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = cache.existSubscriber("g1", 2, "u3")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 1, ds.requests)
+
+	ok, err = cache.existBlacklist("g1", 2, "u3")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// 没有白名单
+	ok, err = cache.inWhitelist("g1", 2, "u1")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	channelInfo, err := cache.GetChannelInfo("g1", 2)
+	assert.NoError(t, err)
+	assert.True(t, channelInfo.Ban)
+	assert.Equal(t, 4, ds.requests)
+
+	// 失效后重新请求数据源
+	ds.subscribers = []string{"u2"}
+	cache.invalidate("g1", 2)
+	ok, err = cache.existSubscriber("g1", 2, "u1")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, 5, ds.requests)
+
+	cache.clean(time.Now().Add(time.Minute * 2))
+	assert.Equal(t, 0, cache.count())
+}
+
+func TestDatasourceCacheOff(t *testing.T) {
+	ds := &testDatasource{whitelist: []string{"u1"}}
+	cache := newDatasourceCache(ds, 0)
+
+	ok, err := cache.inWhitelist("g1", 2, "u2")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	_, err = cache.GetWhitelist("g1", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, ds.requests)
+	assert.Equal(t, 0, cache.count())
+}
@@ -0,0 +1,303 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/grpcpool"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdatasource"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+)
+
+// datasourceGRPCScheme grpc数据源的地址前缀，例如 grpc://127.0.0.1:6979
+const datasourceGRPCScheme = "grpc://"
+
+// isDatasourceGRPCAddr 数据源地址是否是grpc地址
+func isDatasourceGRPCAddr(addr string) bool {
+	return strings.HasPrefix(strings.TrimSpace(addr), datasourceGRPCScheme)
+}
+
+// grpcDatasource 通过grpc请求第三方数据源
+// 同一时间窗口内的单频道请求会合并成一次批量请求
+type grpcDatasource struct {
+	pool       *grpcpool.Pool
+	timeout    time.Duration
+	maxRetries int
+
+	subscribersBatcher *datasourceBatcher
+	blacklistBatcher   *datasourceBatcher
+	whitelistBatcher   *datasourceBatcher
+	channelInfoBatcher *datasourceBatcher
+
+	wklog.Log
+}
+
+func newGRPCDatasource(addr string, timeout time.Duration, maxRetries int, batchWindow time.Duration, batchMaxSize int) (*grpcDatasource, error) {
+	target := strings.TrimPrefix(strings.TrimSpace(addr), datasourceGRPCScheme)
+	pool, err := grpcpool.New(func() (*grpc.ClientConn, error) {
+		return grpc.Dial(target, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    5 * time.Minute, // send pings every 5 minute if there is no activity
+			Timeout: 2 * time.Second, // wait 1 second for ping ack before considering the connection dead
+		}))
+	}, 2, 20, time.Minute*5) // 初始化2个连接 最多20个连接
+	if err != nil {
+		return nil, err
+	}
+	g := &grpcDatasource{
+		pool:       pool,
+		timeout:    timeout,
+		maxRetries: maxRetries,
+		Log:        wklog.NewWKLog("grpcDatasource"),
+	}
+	g.subscribersBatcher = newDatasourceBatcher(batchWindow, batchMaxSize, g.requestUids(func(cli wkdatasource.DatasourceServiceClient) func(context.Context, *wkdatasource.ChannelsReq, ...grpc.CallOption) (*wkdatasource.ChannelUidsResp, error) {
+		return cli.GetSubscribers
+	}))
+	g.blacklistBatcher = newDatasourceBatcher(batchWindow, batchMaxSize, g.requestUids(func(cli wkdatasource.DatasourceServiceClient) func(context.Context, *wkdatasource.ChannelsReq, ...grpc.CallOption) (*wkdatasource.ChannelUidsResp, error) {
+		return cli.GetBlacklist
+	}))
+	g.whitelistBatcher = newDatasourceBatcher(batchWindow, batchMaxSize, g.requestUids(func(cli wkdatasource.DatasourceServiceClient) func(context.Context, *wkdatasource.ChannelsReq, ...grpc.CallOption) (*wkdatasource.ChannelUidsResp, error) {
+		return cli.GetWhitelist
+	}))
+	g.channelInfoBatcher = newDatasourceBatcher(batchWindow, batchMaxSize, g.requestChannelInfos)
+	return g, nil
+}
+
+func (g *grpcDatasource) GetSubscribers(channelID string, channelType uint8) ([]string, error) {
+	result, err := g.subscribersBatcher.get(channelID, channelType)
+	if err != nil {
+		return nil, err
+	}
+	return result.uids, nil
+}
+
+func (g *grpcDatasource) GetBlacklist(channelID string, channelType uint8) ([]string, error) {
+	result, err := g.blacklistBatcher.get(channelID, channelType)
+	if err != nil {
+		return nil, err
+	}
+	return result.uids, nil
+}
+
+func (g *grpcDatasource) GetWhitelist(channelID string, channelType uint8) ([]string, error) {
+	result, err := g.whitelistBatcher.get(channelID, channelType)
+	if err != nil {
+		return nil, err
+	}
+	return result.uids, nil
+}
+
+func (g *grpcDatasource) GetChannelInfo(channelID string, channelType uint8) (wkdb.ChannelInfo, error) {
+	result, err := g.channelInfoBatcher.get(channelID, channelType)
+	if err != nil {
+		return wkdb.EmptyChannelInfo, err
+	}
+	channelInfo := result.channelInfo
+	channelInfo.ChannelId = channelID
+	channelInfo.ChannelType = channelType
+	return channelInfo, nil
+}
+
+func (g *grpcDatasource) GetSystemUIDs() ([]string, error) {
+	var uids []string
+	err := g.invoke(func(ctx context.Context, cli wkdatasource.DatasourceServiceClient) error {
+		resp, err := cli.GetSystemUIDs(ctx, &wkdatasource.SystemUIDsReq{})
+		if err != nil {
+			return err
+		}
+		uids = resp.Uids
+		return nil
+	})
+	return uids, err
+}
+
+func (g *grpcDatasource) close() {
+	g.pool.Close()
+}
+
+func (g *grpcDatasource) requestUids(method func(cli wkdatasource.DatasourceServiceClient) func(context.Context, *wkdatasource.ChannelsReq, ...grpc.CallOption) (*wkdatasource.ChannelUidsResp, error)) datasourceBatchFunc {
+	return func(channels []*wkdatasource.Channel) (map[string]*datasourceBatchResult, error) {
+		results := make(map[string]*datasourceBatchResult, len(channels))
+		err := g.invoke(func(ctx context.Context, cli wkdatasource.DatasourceServiceClient) error {
+			resp, err := method(cli)(ctx, &wkdatasource.ChannelsReq{Channels: channels})
+			if err != nil {
+				return err
+			}
+			for _, channelUids := range resp.Channels {
+				if channelUids.Channel == nil {
+					continue
+				}
+				channelKey := wkutil.ChannelToKey(channelUids.Channel.ChannelId, uint8(channelUids.Channel.ChannelType))
+				results[channelKey] = &datasourceBatchResult{uids: channelUids.Uids}
+			}
+			return nil
+		})
+		return results, err
+	}
+}
+
+func (g *grpcDatasource) requestChannelInfos(channels []*wkdatasource.Channel) (map[string]*datasourceBatchResult, error) {
+	results := make(map[string]*datasourceBatchResult, len(channels))
+	err := g.invoke(func(ctx context.Context, cli wkdatasource.DatasourceServiceClient) error {
+		resp, err := cli.GetChannelInfos(ctx, &wkdatasource.ChannelsReq{Channels: channels})
+		if err != nil {
+			return err
+		}
+		for _, info := range resp.Infos {
+			if info.Channel == nil {
+				continue
+			}
+			channelKey := wkutil.ChannelToKey(info.Channel.ChannelId, uint8(info.Channel.ChannelType))
+			results[channelKey] = &datasourceBatchResult{
+				channelInfo: wkdb.ChannelInfo{
+					Large:   info.Large,
+					Ban:     info.Ban,
+					Disband: info.Disband,
+				},
+			}
+		}
+		return nil
+	})
+	return results, err
+}
+
+// invoke 从连接池获取连接发起请求，失败后按配置重试
+func (g *grpcDatasource) invoke(f func(ctx context.Context, cli wkdatasource.DatasourceServiceClient) error) error {
+	var err error
+	for i := 0; i <= g.maxRetries; i++ {
+		if i > 0 {
+			time.Sleep(time.Millisecond * 100 * time.Duration(i))
+		}
+		err = g.invokeOnce(f)
+		if err == nil {
+			return nil
+		}
+		g.Warn("请求grpc数据源失败！", zap.Error(err), zap.Int("retry", i))
+	}
+	return err
+}
+
+func (g *grpcDatasource) invokeOnce(f func(ctx context.Context, cli wkdatasource.DatasourceServiceClient) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), g.timeout)
+	defer cancel()
+	clientConn, err := g.pool.Get(ctx)
+	if err != nil {
+		return err
+	}
+	defer clientConn.Close()
+	return f(ctx, wkdatasource.NewDatasourceServiceClient(clientConn))
+}
+
+type datasourceBatchResult struct {
+	uids        []string
+	channelInfo wkdb.ChannelInfo
+}
+
+// datasourceBatchFunc 批量请求数据源，返回的结果以channelKey为key，数据源没有返回的频道视为空数据
+type datasourceBatchFunc func(channels []*wkdatasource.Channel) (map[string]*datasourceBatchResult, error)
+
+type datasourceBatch struct {
+	channels []*wkdatasource.Channel
+	exists   map[string]struct{}
+	results  map[string]*datasourceBatchResult
+	err      error
+	done     chan struct{}
+}
+
+// datasourceBatcher 合并时间窗口内的请求
+type datasourceBatcher struct {
+	window  time.Duration // 合并窗口，为0表示不合并
+	maxSize int           // 每批最多频道数量
+	do      datasourceBatchFunc
+
+	mu      sync.Mutex
+	pending *datasourceBatch
+}
+
+func newDatasourceBatcher(window time.Duration, maxSize int, do datasourceBatchFunc) *datasourceBatcher {
+	return &datasourceBatcher{
+		window:  window,
+		maxSize: maxSize,
+		do:      do,
+	}
+}
+
+func (b *datasourceBatcher) get(channelID string, channelType uint8) (*datasourceBatchResult, error) {
+	channelKey := wkutil.ChannelToKey(channelID, channelType)
+	channel := &wkdatasource.Channel{ChannelId: channelID, ChannelType: uint32(channelType)}
+
+	var batch *datasourceBatch
+	if b.window <= 0 {
+		batch = newDatasourceBatch()
+		batch.add(channelKey, channel)
+		b.flush(batch)
+	} else {
+		batch = b.join(channelKey, channel)
+		<-batch.done
+	}
+	if batch.err != nil {
+		return nil, batch.err
+	}
+	result := batch.results[channelKey]
+	if result == nil {
+		return &datasourceBatchResult{}, nil
+	}
+	return result, nil
+}
+
+// join 加入当前等待中的批次，批次满了立即发送，否则等待窗口结束后发送
+func (b *datasourceBatcher) join(channelKey string, channel *wkdatasource.Channel) *datasourceBatch {
+	b.mu.Lock()
+	batch := b.pending
+	if batch == nil {
+		batch = newDatasourceBatch()
+		b.pending = batch
+		time.AfterFunc(b.window, func() {
+			b.mu.Lock()
+			if b.pending != batch { // 已因批次满了提前发送
+				b.mu.Unlock()
+				return
+			}
+			b.pending = nil
+			b.mu.Unlock()
+			b.flush(batch)
+		})
+	}
+	batch.add(channelKey, channel)
+	full := b.maxSize > 0 && len(batch.channels) >= b.maxSize
+	if full {
+		b.pending = nil
+	}
+	b.mu.Unlock()
+	if full {
+		go b.flush(batch)
+	}
+	return batch
+}
+
+func (b *datasourceBatcher) flush(batch *datasourceBatch) {
+	batch.results, batch.err = b.do(batch.channels)
+	close(batch.done)
+}
+
+func newDatasourceBatch() *datasourceBatch {
+	return &datasourceBatch{
+		exists: make(map[string]struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+func (d *datasourceBatch) add(channelKey string, channel *wkdatasource.Channel) {
+	if _, ok := d.exists[channelKey]; ok {
+		return
+	}
+	d.exists[channelKey] = struct{}{}
+	d.channels = append(d.channels, channel)
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdatasource"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+type testDatasourceService struct {
+	wkdatasource.UnimplementedDatasourceServiceServer
+	requests atomic.Int32
+}
+
+func (t *testDatasourceService) GetSubscribers(ctx context.Context, req *wkdatasource.ChannelsReq) (*wkdatasource.ChannelUidsResp, error) {
+	t.requests.Add(1)
+	resp := &wkdatasource.ChannelUidsResp{}
+	for _, channel := range req.Channels {
+		if channel.ChannelId == "empty" {
+			continue
+		}
+		resp.Channels = append(resp.Channels, &wkdatasource.ChannelUids{
+			Channel: channel,
+			Uids:    []string{channel.ChannelId + "-u1", channel.ChannelId + "-u2"},
+		})
+	}
+	return resp, nil
+}
+
+func (t *testDatasourceService) GetChannelInfos(ctx context.Context, req *wkdatasource.ChannelsReq) (*wkdatasource.ChannelInfosResp, error) {
+	t.requests.Add(1)
+	resp := &wkdatasource.ChannelInfosResp{}
+	for _, channel := range req.Channels {
+		resp.Infos = append(resp.Infos, &wkdatasource.ChannelInfo{Channel: channel, Ban: true, Disband: true})
+	}
+	return resp, nil
+}
+
+func TestGRPCDatasource(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	service := &testDatasourceService{}
+	grpcServer := grpc.NewServer()
+	wkdatasource.RegisterDatasourceServiceServer(grpcServer, service)
+	go func() {
+		_ = grpcServer.Serve(lis)
+	}()
+	defer grpcServer.Stop()
+
+	assert.True(t, isDatasourceGRPCAddr("grpc://"+lis.Addr().String()))
+	ds, err := newGRPCDatasource("grpc://"+lis.Addr().String(), time.Second*5, 1, time.Millisecond*50, 100)
+	assert.NoError(t, err)
+	defer ds.close()
+
+	// 并发的单频道请求合并成一次批量请求
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			channelId := fmt.Sprintf("g%d", i)
+			subscribers, err := ds.GetSubscribers(channelId, 2)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{channelId + "-u1", channelId + "-u2"}, subscribers)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), service.requests.Load())
+
+	// 数据源没有返回的频道为空数据
+	subscribers, err := ds.GetSubscribers("empty", 2)
+	assert.NoError(t, err)
+	assert.Empty(t, subscribers)
+
+	channelInfo, err := ds.GetChannelInfo("g1", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "g1", channelInfo.ChannelId)
+	assert.True(t, channelInfo.Ban)
+	assert.True(t, channelInfo.Disband)
+
+	// 未实现的方法返回错误
+	_, err = ds.GetBlacklist("g1", 2)
+	assert.Error(t, err)
+}
@@ -283,6 +283,9 @@ func (d *deliverr) handleDeliverReq(req *deliverReq) {
 				})
 			}
 
+			// 发给机器人的消息交给机器人处理
+			d.dm.s.botManager.dispatch(req, nodeUser.uids)
+
 			// 投递消息
 			d.deliver(req, nodeUser.uids)
 
@@ -345,12 +348,14 @@ type deliverUserSlice struct {
 	offlineUids []string       // 离线用户(只要主设备不在线就算离线)
 	toConns     []*connContext // 在线接受用户的连接对象
 	onlineUsers []string       // 在线用户数量（只要一个客户端在线就算在线）
+	resumeConns []*connContext // 已断开但会话可恢复的连接，消息只缓存到会话中
 }
 
 func (d *deliverUserSlice) reset() {
 	d.offlineUids = d.offlineUids[:0]
 	d.toConns = d.toConns[:0]
 	d.onlineUsers = d.onlineUsers[:0]
+	d.resumeConns = d.resumeConns[:0]
 }
 
 var deliverSlicePool = &sync.Pool{
@@ -359,6 +364,7 @@ var deliverSlicePool = &sync.Pool{
 			offlineUids: make([]string, 0),
 			toConns:     make([]*connContext, 0),
 			onlineUsers: make([]string, 0),
+			resumeConns: make([]*connContext, 0),
 		}
 	},
 }
@@ -380,6 +386,9 @@ func (d *deliverr) deliver(req *deliverReq, uids []string) {
 	// onlineUsers := make([]string, 0)                   // 在线用户数量（只要一个客户端在线就算在线）
 
 	for _, toUid := range uids {
+		if d.dm.s.opts.SessionResume.On {
+			slices.resumeConns = append(slices.resumeConns, d.dm.s.resumeManager.disconnectedConns(toUid)...)
+		}
 		userHandler := d.dm.s.userReactor.getUserHandler(toUid)
 		if userHandler == nil { // 用户不在线
 			slices.offlineUids = append(slices.offlineUids, toUid)
@@ -489,10 +498,18 @@ func (d *deliverr) deliver(req *deliverReq, uids []string) {
 			recvPacket.Payload = recvPacket.Payload[:0]
 		}
 
-		for _, conn := range slices.toConns {
+		conns := slices.toConns
+		if len(slices.resumeConns) > 0 {
+			conns = append(conns[:len(conns):len(conns)], slices.resumeConns...)
+		}
+		for i, conn := range conns {
 			if conn.uid == message.FromUid && conn.deviceId == message.FromDeviceId { // 自己发的不处理
 				continue
 			}
+			resumeConn := i >= len(slices.toConns) // 断开中的可恢复会话，只缓存不写入
+			if resumeConn && recvPacket.NoPersist {
+				continue
+			}
 
 			// 这里需要把channelID改成fromUID 比如A给B发消息，B收到的消息channelID应该是A A收到的消息channelID应该是B
 			recvPacket.ChannelID = sendPacket.ChannelID
@@ -549,6 +566,18 @@ func (d *deliverr) deliver(req *deliverReq, uids []string) {
 			recvPacketData := make([]byte, len(recvPacketBuffer.B))
 			copy(recvPacketData, recvPacketBuffer.Bytes())
 
+			if resumeConn { // 缓存到会话中，等待会话恢复后投递
+				d.dm.s.resumeManager.buffer(&retryMessage{
+					uid:            conn.uid,
+					connId:         conn.connId,
+					messageId:      message.MessageId,
+					recvPacketData: recvPacketData,
+					channelId:      req.channelId,
+					channelType:    req.channelType,
+				})
+				continue
+			}
+
 			if !recvPacket.NoPersist { // 只有存储的消息才重试
 				d.dm.s.retryManager.addRetry(&retryMessage{
 					uid:            conn.uid,
@@ -0,0 +1,328 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/WuKongIM/WuKongIM/pkg/wkserver/proto"
+	"github.com/WuKongIM/WuKongIM/pkg/wksink"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/lni/goutils/syncutil"
+	"go.uber.org/zap"
+)
+
+// EventMsgStored 事件流中的消息存储事件
+const EventMsgStored = "msg.stored"
+
+// eventSink 事件流，消息存储成功后按频道有序地投递到各个Sink
+// 每个频道每个Sink已投递到的消息序号通过频道所在的槽位保存（各个槽位副本都有），投递时从投递位置之后的消息开始加载，
+// 所以投递失败、通知丢失（例如重启）或频道领导切换都会在频道下次有新消息时从投递位置继续投递
+// 每个Sink单独记录投递失败，某个Sink不可用时只暂停这个Sink，不影响其他Sink的投递
+type eventSink struct {
+	s          *Server
+	sinks      []wksink.Sink
+	sinkStates []*eventSinkState // 与sinks一一对应
+	workers    []*eventSinkWorker
+	stopper    *syncutil.Stopper
+	wklog.Log
+}
+
+// eventSinkState Sink的投递状态
+type eventSinkState struct {
+	mu       sync.Mutex
+	failures int       // 连续失败次数
+	retryAt  time.Time // 失败后暂停投递到此时间
+}
+
+// paused 是否处于失败后的暂停期
+func (st *eventSinkState) paused(now time.Time) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return now.Before(st.retryAt)
+}
+
+func (st *eventSinkState) fail(retryAt time.Time) int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.failures++
+	st.retryAt = retryAt
+	return st.failures
+}
+
+func (st *eventSinkState) ok() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.failures = 0
+	st.retryAt = time.Time{}
+}
+
+func newEventSink(s *Server) *eventSink {
+	return &eventSink{
+		s:       s,
+		stopper: syncutil.NewStopper(),
+		Log:     wklog.NewWKLog("eventSink"),
+	}
+}
+
+func (e *eventSink) start() error {
+	if !e.s.opts.EventSink.On {
+		return nil
+	}
+	opts := e.s.opts.EventSink
+	if opts.FileOn {
+		fileDir := opts.FileDir
+		if strings.TrimSpace(fileDir) == "" {
+			fileDir = filepath.Join(e.s.opts.DataDir, "eventsink")
+		}
+		fileSink, err := wksink.NewFileSink(fileDir, opts.FileMaxSize, opts.FileMaxBackups)
+		if err != nil {
+			return err
+		}
+		e.sinks = append(e.sinks, fileSink)
+	}
+	if strings.TrimSpace(opts.HTTPAddr) != "" {
+		e.sinks = append(e.sinks, wksink.NewHTTPSink(opts.HTTPAddr, opts.Timeout))
+	}
+	if strings.TrimSpace(opts.TCPAddr) != "" {
+		e.sinks = append(e.sinks, wksink.NewTCPSink(opts.TCPAddr, opts.Timeout))
+	}
+	e.sinks = append(e.sinks, opts.Sinks...)
+	if len(e.sinks) == 0 {
+		e.Warn("事件流已开启，但是没有配置任何Sink！")
+		return nil
+	}
+	e.sinkStates = make([]*eventSinkState, 0, len(e.sinks))
+	for range e.sinks {
+		e.sinkStates = append(e.sinkStates, &eventSinkState{})
+	}
+
+	workerCount := opts.WorkerCount
+	if workerCount <= 0 {
+		workerCount = 1
+	}
+	e.workers = make([]*eventSinkWorker, 0, workerCount)
+	for i := 0; i < workerCount; i++ {
+		worker := newEventSinkWorker(e)
+		e.workers = append(e.workers, worker)
+		e.stopper.RunWorker(worker.loop)
+	}
+	return nil
+}
+
+func (e *eventSink) stop() {
+	e.stopper.Stop()
+	for _, sink := range e.sinks {
+		if err := sink.Close(); err != nil {
+			e.Warn("关闭Sink失败！", zap.Error(err), zap.String("sink", sink.Name()))
+		}
+	}
+}
+
+// notify 通知频道有新存储的消息，firstSeq和lastSeq为本次存储的消息序号范围
+func (e *eventSink) notify(channelId string, channelType uint8, firstSeq, lastSeq uint64) {
+	if len(e.workers) == 0 || lastSeq == 0 {
+		return
+	}
+	channelKey := wkutil.ChannelToKey(channelId, channelType)
+	h := fnv.New32a()
+	h.Write([]byte(channelKey))
+	worker := e.workers[h.Sum32()%uint32(len(e.workers))]
+	worker.add(channelKey, &eventSinkChannel{
+		channelId:   channelId,
+		channelType: channelType,
+		firstSeq:    firstSeq,
+		lastSeq:     lastSeq,
+	})
+}
+
+// deliver 将频道投递位置之后到lastSeq的消息投递到所有Sink，返回是否全部投递完成
+// 某个Sink投递失败时继续投递其他Sink，失败的Sink在重试间隔内暂停投递，频道等待重试
+func (e *eventSink) deliver(ch *eventSinkChannel) bool {
+	done := true
+	now := time.Now()
+	for i, sink := range e.sinks {
+		state := e.sinkStates[i]
+		if state.paused(now) {
+			done = false
+			continue
+		}
+		if err := e.deliverToSink(sink, ch); err != nil {
+			failures := state.fail(time.Now().Add(e.s.opts.EventSink.RetryInterval))
+			e.Warn("投递事件失败！", zap.Error(err), zap.String("sink", sink.Name()), zap.Int("failures", failures), zap.String("channelId", ch.channelId), zap.Uint8("channelType", ch.channelType))
+			done = false
+			continue
+		}
+		state.ok()
+	}
+	return done
+}
+
+func (e *eventSink) deliverToSink(sink wksink.Sink, ch *eventSinkChannel) error {
+	checkpoint, err := e.checkpoint(sink.Name(), ch.channelId, ch.channelType)
+	if err != nil {
+		return err
+	}
+	startSeq := checkpoint + 1
+	if checkpoint == 0 {
+		// 没有投递过的频道从本次通知的消息开始投递，不投递历史消息
+		// 频道领导切换到没有投递过的节点时，旧领导节点未投递的事件也不会补投
+		startSeq = ch.firstSeq
+	}
+	batchSize := e.s.opts.EventSink.BatchSize
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	for startSeq <= ch.lastSeq {
+		messages, err := e.s.store.LoadNextRangeMsgs(ch.channelId, ch.channelType, startSeq, ch.lastSeq+1, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+		events := make([]*wksink.Event, 0, len(messages))
+		now := time.Now().UnixMilli()
+		for _, message := range messages {
+			resp := &MessageResp{}
+			resp.from(message, e.s)
+			data, err := json.Marshal(resp)
+			if err != nil {
+				return err
+			}
+			events = append(events, &wksink.Event{
+				Event:       EventMsgStored,
+				ChannelId:   ch.channelId,
+				ChannelType: ch.channelType,
+				Offset:      uint64(message.MessageSeq),
+				NodeId:      e.s.opts.Cluster.NodeId,
+				Timestamp:   now,
+				Data:        data,
+			})
+		}
+		if err = sink.Write(events); err != nil {
+			return err
+		}
+		lastSeq := uint64(messages[len(messages)-1].MessageSeq)
+		if err = e.s.store.SetEventSinkCheckpoint(sink.Name(), ch.channelId, ch.channelType, lastSeq); err != nil {
+			return err
+		}
+		startSeq = lastSeq + 1
+	}
+	return nil
+}
+
+// checkpoint 获取频道的投递位置，从频道所在槽位的领导节点读取（本节点不一定是该槽位的副本）
+func (e *eventSink) checkpoint(sink string, channelId string, channelType uint8) (uint64, error) {
+	leaderId, err := e.s.cluster.SlotLeaderIdOfChannel(channelId, channelType)
+	if err != nil {
+		return 0, err
+	}
+	if leaderId == e.s.opts.Cluster.NodeId {
+		return e.s.store.GetEventSinkCheckpoint(sink, channelId, channelType)
+	}
+	timeoutCtx, cancel := context.WithTimeout(e.s.ctx, time.Second*5)
+	defer cancel()
+	req := &eventSinkCheckpointReq{
+		Sink:        sink,
+		ChannelId:   channelId,
+		ChannelType: channelType,
+	}
+	resp, err := e.s.cluster.RequestWithContext(timeoutCtx, leaderId, "/wk/getEventSinkCheckpoint", req.Marshal())
+	if err != nil {
+		return 0, err
+	}
+	if resp.Status != proto.StatusOK {
+		return 0, fmt.Errorf("checkpoint: response status code is %d", resp.Status)
+	}
+	return wkproto.NewDecoder(resp.Body).Uint64()
+}
+
+// eventSinkChannel 待投递的频道
+type eventSinkChannel struct {
+	channelId   string
+	channelType uint8
+	firstSeq    uint64 // 待投递的最小消息序号，频道没有投递位置时从这里开始投递
+	lastSeq     uint64 // 待投递的最大消息序号
+}
+
+func (c *eventSinkChannel) merge(other *eventSinkChannel) {
+	if other.firstSeq < c.firstSeq {
+		c.firstSeq = other.firstSeq
+	}
+	if other.lastSeq > c.lastSeq {
+		c.lastSeq = other.lastSeq
+	}
+}
+
+// eventSinkWorker 投递协程，同一频道由同一个协程投递，保证频道内的事件有序
+type eventSinkWorker struct {
+	e       *eventSink
+	mu      sync.Mutex
+	pending map[string]*eventSinkChannel
+	notifyC chan struct{}
+}
+
+func newEventSinkWorker(e *eventSink) *eventSinkWorker {
+	return &eventSinkWorker{
+		e:       e,
+		pending: make(map[string]*eventSinkChannel),
+		notifyC: make(chan struct{}, 1),
+	}
+}
+
+func (w *eventSinkWorker) add(channelKey string, ch *eventSinkChannel) {
+	w.requeue(channelKey, ch)
+	select {
+	case w.notifyC <- struct{}{}:
+	default:
+	}
+}
+
+// requeue 放回待投递的频道，不触发投递
+func (w *eventSinkWorker) requeue(channelKey string, ch *eventSinkChannel) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if exist := w.pending[channelKey]; exist != nil {
+		exist.merge(ch)
+	} else {
+		w.pending[channelKey] = ch
+	}
+}
+
+func (w *eventSinkWorker) loop() {
+	var retryC <-chan time.Time
+	for {
+		select {
+		case <-w.notifyC:
+		case <-retryC:
+		case <-w.e.stopper.ShouldStop():
+			return
+		}
+		retryC = nil
+
+		w.mu.Lock()
+		pending := w.pending
+		w.pending = make(map[string]*eventSinkChannel)
+		w.mu.Unlock()
+
+		retry := false
+		for channelKey, ch := range pending {
+			if !w.e.deliver(ch) {
+				retry = true
+				w.requeue(channelKey, ch) // 放回去等待重试
+			}
+		}
+		if retry {
+			// 有没有投递完成的频道，等待重试间隔后再投递，期间有新消息的频道正常投递到可用的Sink
+			retryC = time.After(w.e.s.opts.EventSink.RetryInterval)
+		}
+	}
+}
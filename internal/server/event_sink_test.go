@@ -0,0 +1,134 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/client"
+	"github.com/WuKongIM/WuKongIM/pkg/wksink"
+	"github.com/stretchr/testify/assert"
+)
+
+type testMemorySink struct {
+	mu     sync.Mutex
+	events []*wksink.Event
+}
+
+func (m *testMemorySink) Name() string {
+	return "memory"
+}
+
+func (m *testMemorySink) Write(events []*wksink.Event) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, events...)
+	return nil
+}
+
+func (m *testMemorySink) Close() error {
+	return nil
+}
+
+func (m *testMemorySink) getEvents() []*wksink.Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*wksink.Event(nil), m.events...)
+}
+
+// testFailSink 总是投递失败的Sink
+type testFailSink struct {
+	writes atomic.Int32
+}
+
+func (f *testFailSink) Name() string {
+	return "fail"
+}
+
+func (f *testFailSink) Write(events []*wksink.Event) error {
+	f.writes.Add(1)
+	return errors.New("sink unavailable")
+}
+
+func (f *testFailSink) Close() error {
+	return nil
+}
+
+func TestEventSink(t *testing.T) {
+	sink := &testMemorySink{}
+	s := NewTestServer(t, WithHTTPAddr(testFreeAddr(t)), WithManagerAddr(testFreeAddr(t)), WithDemoOn(false), WithEventSinkOn(true), WithEventSinks(sink))
+	s.opts.Mode = TestMode
+	err := s.Start()
+	assert.Nil(t, err)
+	defer s.StopNoErr()
+
+	s.MustWaitAllSlotsReady(time.Second * 10) // 等待服务准备好
+
+	cli := client.New(s.opts.External.TCPAddr, client.WithUID("test1"))
+	err = cli.Connect()
+	assert.Nil(t, err)
+	defer cli.Close()
+
+	for _, payload := range []string{"hello1", "hello2", "hello3"} {
+		err = cli.SendMessage(client.NewChannel("test2", 1), []byte(payload))
+		assert.Nil(t, err)
+	}
+
+	assert.Eventually(t, func() bool {
+		return len(sink.getEvents()) == 3
+	}, time.Second*10, time.Millisecond*20)
+
+	// 同一频道的事件按消息序号有序投递
+	fakeChannelId := GetFakeChannelIDWith("test1", "test2")
+	events := sink.getEvents()
+	for i, event := range events {
+		assert.Equal(t, EventMsgStored, event.Event)
+		assert.Equal(t, fakeChannelId, event.ChannelId)
+		assert.Equal(t, uint64(i+1), event.Offset)
+		var resp MessageResp
+		assert.NoError(t, json.Unmarshal(event.Data, &resp))
+		assert.Equal(t, uint64(i+1), resp.MessageSeq)
+	}
+
+	// 投递位置通过槽位保存
+	assert.Eventually(t, func() bool {
+		checkpoint, err := s.store.GetEventSinkCheckpoint(sink.Name(), fakeChannelId, 1)
+		return err == nil && checkpoint == 3
+	}, time.Second*10, time.Millisecond*20)
+}
+
+func TestEventSinkFailedSinkNotBlockOthers(t *testing.T) {
+	sink := &testMemorySink{}
+	failSink := &testFailSink{}
+	s := NewTestServer(t, WithHTTPAddr(testFreeAddr(t)), WithManagerAddr(testFreeAddr(t)), WithDemoOn(false), WithEventSinkOn(true), WithEventSinks(failSink, sink), WithEventSinkRetryInterval(time.Hour))
+	s.opts.Mode = TestMode
+	err := s.Start()
+	assert.Nil(t, err)
+	defer s.StopNoErr()
+
+	s.MustWaitAllSlotsReady(time.Second * 10) // 等待服务准备好
+
+	cli := client.New(s.opts.External.TCPAddr, client.WithUID("test1"))
+	err = cli.Connect()
+	assert.Nil(t, err)
+	defer cli.Close()
+
+	// 失败的Sink暂停投递，不影响其他Sink，后面的消息也能及时投递
+	for _, payload := range []string{"hello1", "hello2", "hello3"} {
+		err = cli.SendMessage(client.NewChannel("test2", 1), []byte(payload))
+		assert.Nil(t, err)
+		time.Sleep(time.Millisecond * 50)
+	}
+	assert.Eventually(t, func() bool {
+		return len(sink.getEvents()) == 3
+	}, time.Second*10, time.Millisecond*20)
+	assert.Equal(t, int32(1), failSink.writes.Load())
+
+	fakeChannelId := GetFakeChannelIDWith("test1", "test2")
+	checkpoint, err := s.store.GetEventSinkCheckpoint(failSink.Name(), fakeChannelId, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), checkpoint)
+}
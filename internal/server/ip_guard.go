@@ -0,0 +1,369 @@
+package server
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/WuKongIM/WuKongIM/pkg/wknet"
+	"github.com/lni/goutils/syncutil"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// ipGuard 连接层的防护，限制单个IP的连接数和连接频率，认证连续失败的IP临时封禁
+// 来自受信任代理的连接，在解析出真实IP（代理协议或websocket请求头）后再按真实IP校验
+type ipGuard struct {
+	opts           *Options
+	allowlist      []*net.IPNet // 白名单，不受任何限制
+	denylist       []*net.IPNet // 黑名单，直接拒绝
+	trustedProxies []*net.IPNet // 受信任的代理
+
+	mu      sync.Mutex
+	entries map[string]*ipGuardEntry // ip -> 状态
+
+	stopper *syncutil.Stopper
+	wklog.Log
+}
+
+type ipGuardEntry struct {
+	conns       int           // 当前连接数
+	limiter     *rate.Limiter // 连接频率限制
+	authFails   int           // 窗口内认证失败次数
+	authFailAt  time.Time     // 认证失败窗口开始时间
+	bannedUntil time.Time     // 封禁截止时间
+	activeAt    time.Time     // 最后活跃时间
+}
+
+// ipGuardBan 被临时封禁的IP
+type ipGuardBan struct {
+	Ip          string `json:"ip"`
+	Conns       int    `json:"conns"`        // 当前连接数
+	AuthFails   int    `json:"auth_fails"`   // 窗口内认证失败次数
+	BannedUntil int64  `json:"banned_until"` // 封禁截止时间
+}
+
+func newIPGuard(opts *Options) (*ipGuard, error) {
+	g := &ipGuard{
+		opts:    opts,
+		entries: make(map[string]*ipGuardEntry),
+		stopper: syncutil.NewStopper(),
+		Log:     wklog.NewWKLog("ipGuard"),
+	}
+	var err error
+	if g.allowlist, err = parseCIDRs(opts.IPGuard.Allowlist); err != nil {
+		return nil, err
+	}
+	if g.denylist, err = parseCIDRs(opts.IPGuard.Denylist); err != nil {
+		return nil, err
+	}
+	if g.trustedProxies, err = parseCIDRs(opts.IPGuard.TrustedProxies); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (g *ipGuard) start() {
+	if !g.opts.IPGuard.On {
+		return
+	}
+	g.stopper.RunWorker(g.cleanLoop)
+}
+
+func (g *ipGuard) stop() {
+	g.stopper.Stop()
+}
+
+// allowAccept 新连接建立前的校验（黑白名单、封禁、连接频率）
+func (g *ipGuard) allowAccept(ip net.IP) bool {
+	if !g.opts.IPGuard.On || ip == nil || g.isTrustedProxy(ip) {
+		return true
+	}
+	if containsIP(g.allowlist, ip) {
+		return true
+	}
+	if containsIP(g.denylist, ip) {
+		g.Debug("ip is in denylist", zap.String("ip", ip.String()))
+		return false
+	}
+
+	now := time.Now()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	entry := g.entry(ip.String(), now)
+	if now.Before(entry.bannedUntil) {
+		return false
+	}
+	if entry.limiter != nil && !entry.limiter.AllowN(now, 1) {
+		g.Debug("ip connect rate exceeded", zap.String("ip", ip.String()))
+		return false
+	}
+	return true
+}
+
+// acquire 占用一个IP的连接数，超过单个IP的最大连接数返回false
+func (g *ipGuard) acquire(ip net.IP) bool {
+	if !g.opts.IPGuard.On || containsIP(g.allowlist, ip) {
+		return true
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	entry := g.entry(ip.String(), time.Now())
+	if g.opts.IPGuard.MaxConnsPerIp > 0 && entry.conns >= g.opts.IPGuard.MaxConnsPerIp {
+		g.Debug("ip max conns exceeded", zap.String("ip", ip.String()), zap.Int("conns", entry.conns))
+		return false
+	}
+	entry.conns++
+	return true
+}
+
+// release 释放一个IP的连接数
+func (g *ipGuard) release(ip string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	entry := g.entries[ip]
+	if entry == nil {
+		return
+	}
+	if entry.conns > 0 {
+		entry.conns--
+	}
+	entry.activeAt = time.Now()
+}
+
+// authFailed 记录一次认证失败，窗口内失败次数达到阈值后封禁此IP
+func (g *ipGuard) authFailed(ip net.IP) {
+	if !g.opts.IPGuard.On || ip == nil || g.opts.IPGuard.AuthFailThreshold <= 0 || g.isTrustedProxy(ip) || containsIP(g.allowlist, ip) {
+		return
+	}
+	now := time.Now()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	entry := g.entry(ip.String(), now)
+	if now.Sub(entry.authFailAt) > g.opts.IPGuard.AuthFailWindow {
+		entry.authFails = 0
+		entry.authFailAt = now
+	}
+	entry.authFails++
+	if entry.authFails >= g.opts.IPGuard.AuthFailThreshold {
+		entry.bannedUntil = now.Add(g.opts.IPGuard.BanDuration)
+		entry.authFails = 0
+		g.Warn("ip is temporarily banned because of repeated auth failures", zap.String("ip", ip.String()), zap.Duration("banDuration", g.opts.IPGuard.BanDuration))
+	}
+}
+
+// bans 当前被封禁的IP
+func (g *ipGuard) bans() []*ipGuardBan {
+	now := time.Now()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	bans := make([]*ipGuardBan, 0)
+	for ip, entry := range g.entries {
+		if now.Before(entry.bannedUntil) {
+			bans = append(bans, &ipGuardBan{
+				Ip:          ip,
+				Conns:       entry.conns,
+				AuthFails:   entry.authFails,
+				BannedUntil: entry.bannedUntil.Unix(),
+			})
+		}
+	}
+	return bans
+}
+
+// unban 解除封禁
+func (g *ipGuard) unban(ip string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	entry := g.entries[ip]
+	if entry == nil || !time.Now().Before(entry.bannedUntil) {
+		return false
+	}
+	entry.bannedUntil = time.Time{}
+	entry.authFails = 0
+	return true
+}
+
+func (g *ipGuard) isTrustedProxy(ip net.IP) bool {
+	return containsIP(g.trustedProxies, ip)
+}
+
+// trustForwarded 是否信任来自此socket地址的代理协议和websocket请求头中的真实IP
+// 开启防护或者配置了受信任代理后，只信任受信任代理传递的真实IP，否则真实IP可以被客户端伪造
+func (g *ipGuard) trustForwarded(remoteAddr net.Addr) bool {
+	if !g.opts.IPGuard.On && len(g.trustedProxies) == 0 {
+		return true
+	}
+	return g.isTrustedProxy(addrIP(remoteAddr))
+}
+
+// entry 获取IP的状态，不存在则创建，调用方需要持有锁
+func (g *ipGuard) entry(ip string, now time.Time) *ipGuardEntry {
+	entry := g.entries[ip]
+	if entry == nil {
+		entry = &ipGuardEntry{}
+		if g.opts.IPGuard.ConnectRate > 0 {
+			burst := g.opts.IPGuard.ConnectBurst
+			if burst <= 0 {
+				burst = int(g.opts.IPGuard.ConnectRate)
+			}
+			if burst <= 0 {
+				burst = 1
+			}
+			entry.limiter = rate.NewLimiter(rate.Limit(g.opts.IPGuard.ConnectRate), burst)
+		}
+		g.entries[ip] = entry
+	}
+	entry.activeAt = now
+	return entry
+}
+
+// cleanLoop 定时清理没有连接且不在封禁中的IP状态
+func (g *ipGuard) cleanLoop() {
+	tk := time.NewTicker(time.Minute)
+	defer tk.Stop()
+	for {
+		select {
+		case <-tk.C:
+			g.clean(time.Now())
+		case <-g.stopper.ShouldStop():
+			return
+		}
+	}
+}
+
+func (g *ipGuard) clean(now time.Time) {
+	idle := g.opts.IPGuard.AuthFailWindow
+	if idle < time.Minute {
+		idle = time.Minute
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for ip, entry := range g.entries {
+		if entry.conns > 0 || now.Before(entry.bannedUntil) || now.Sub(entry.activeAt) < idle {
+			continue
+		}
+		delete(g.entries, ip)
+	}
+}
+
+// ---------------------- server ----------------------
+
+// onAccept 连接建立前按socket的IP校验
+func (s *Server) onAccept(remoteAddr net.Addr) bool {
+	return s.ipGuard.allowAccept(addrIP(remoteAddr))
+}
+
+// guardConn 连接建立后占用socket的IP的连接数，受信任代理的连接等解析出真实IP后再校验
+// 返回false表示连接被拒绝，需要关闭连接
+func (s *Server) guardConn(conn wknet.Conn) bool {
+	if !s.opts.IPGuard.On {
+		return true
+	}
+	ip := addrIP(conn.RemoteAddr())
+	if ip == nil || s.ipGuard.isTrustedProxy(ip) {
+		return true
+	}
+	if !s.ipGuard.acquire(ip) {
+		return false
+	}
+	conn.SetValue(ConnKeyGuardIp, ip.String())
+	return true
+}
+
+// guardRealIp 受信任代理的连接解析出真实IP后（代理协议或websocket请求头），按真实IP校验
+func (s *Server) guardRealIp(conn wknet.Conn) bool {
+	if !s.opts.IPGuard.On || conn.Value(ConnKeyGuardIp) != nil {
+		return true
+	}
+	ip := addrIP(conn.RemoteAddr())
+	if ip == nil || s.ipGuard.isTrustedProxy(ip) { // 代理没有传递真实IP
+		return true
+	}
+	if !s.ipGuard.allowAccept(ip) || !s.ipGuard.acquire(ip) {
+		return false
+	}
+	conn.SetValue(ConnKeyGuardIp, ip.String())
+	return true
+}
+
+// unguardConn 连接关闭时释放连接数
+func (s *Server) unguardConn(conn wknet.Conn) {
+	ip, ok := conn.Value(ConnKeyGuardIp).(string)
+	if ok && ip != "" {
+		s.ipGuard.release(ip)
+	}
+}
+
+// guardAuthFailed 记录连接认证失败，按连接占用连接数的IP记录，和连接数、封禁校验使用同一个IP
+// 受信任代理没有传递真实IP的连接不记录，避免封禁代理本身
+func (s *Server) guardAuthFailed(conn wknet.Conn) {
+	if conn == nil {
+		return
+	}
+	ip, ok := conn.Value(ConnKeyGuardIp).(string)
+	if !ok || ip == "" {
+		return
+	}
+	s.ipGuard.authFailed(net.ParseIP(ip))
+}
+
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return a.IP
+	case *net.UDPAddr:
+		return a.IP
+	case nil:
+		return nil
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
+
+// parseCIDRs 解析CIDR列表，单个IP按/32（IPv6为/128）处理
+func parseCIDRs(values []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if !strings.Contains(v, "/") {
+			ip := net.ParseIP(v)
+			if ip == nil {
+				return nil, &net.ParseError{Type: "IP address", Text: v}
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestIPGuard(t *testing.T, f func(opts *Options)) *ipGuard {
+	opts := NewOptions()
+	opts.IPGuard.On = true
+	if f != nil {
+		f(opts)
+	}
+	g, err := newIPGuard(opts)
+	assert.NoError(t, err)
+	return g
+}
+
+func TestIPGuardMaxConns(t *testing.T) {
+	g := newTestIPGuard(t, func(opts *Options) {
+		opts.IPGuard.MaxConnsPerIp = 2
+	})
+	ip := net.ParseIP("10.0.0.1")
+	assert.True(t, g.acquire(ip))
+	assert.True(t, g.acquire(ip))
+	assert.False(t, g.acquire(ip))
+
+	g.release(ip.String())
+	assert.True(t, g.acquire(ip))
+}
+
+func TestIPGuardConnectRate(t *testing.T) {
+	g := newTestIPGuard(t, func(opts *Options) {
+		opts.IPGuard.ConnectRate = 1
+		opts.IPGuard.ConnectBurst = 2
+	})
+	ip := net.ParseIP("10.0.0.1")
+	assert.True(t, g.allowAccept(ip))
+	assert.True(t, g.allowAccept(ip))
+	assert.False(t, g.allowAccept(ip))
+	assert.True(t, g.allowAccept(net.ParseIP("10.0.0.2")))
+}
+
+func TestIPGuardAuthFailBan(t *testing.T) {
+	g := newTestIPGuard(t, func(opts *Options) {
+		opts.IPGuard.ConnectRate = 0
+		opts.IPGuard.AuthFailThreshold = 3
+	})
+	ip := net.ParseIP("10.0.0.1")
+	for i := 0; i < 3; i++ {
+		assert.True(t, g.allowAccept(ip))
+		g.authFailed(ip)
+	}
+	assert.False(t, g.allowAccept(ip))
+	assert.Len(t, g.bans(), 1)
+
+	assert.True(t, g.unban(ip.String()))
+	assert.True(t, g.allowAccept(ip))
+}
+
+func TestIPGuardLists(t *testing.T) {
+	g := newTestIPGuard(t, func(opts *Options) {
+		opts.IPGuard.MaxConnsPerIp = 1
+		opts.IPGuard.Allowlist = []string{"192.168.0.0/16"}
+		opts.IPGuard.Denylist = []string{"10.1.0.0/16", "172.16.0.9"}
+		opts.IPGuard.TrustedProxies = []string{"127.0.0.1"}
+	})
+	assert.False(t, g.allowAccept(net.ParseIP("10.1.2.3")))
+	assert.False(t, g.allowAccept(net.ParseIP("172.16.0.9")))
+	assert.True(t, g.allowAccept(net.ParseIP("172.16.0.10")))
+
+	allowed := net.ParseIP("192.168.1.1")
+	assert.True(t, g.acquire(allowed))
+	assert.True(t, g.acquire(allowed))
+
+	assert.True(t, g.isTrustedProxy(net.ParseIP("127.0.0.1")))
+	assert.True(t, g.allowAccept(net.ParseIP("127.0.0.1")))
+}
+
+func TestIPGuardTrustForwarded(t *testing.T) {
+	g := newTestIPGuard(t, func(opts *Options) {
+		opts.IPGuard.TrustedProxies = []string{"127.0.0.1"}
+	})
+	assert.True(t, g.trustForwarded(&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1000}))
+	assert.False(t, g.trustForwarded(&net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1000}))
+
+	// 开启防护但没有配置受信任代理，不信任任何代理协议和请求头
+	g = newTestIPGuard(t, nil)
+	assert.False(t, g.trustForwarded(&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1000}))
+
+	// 未开启防护也未配置受信任代理，保持原来的行为
+	g = newTestIPGuard(t, func(opts *Options) {
+		opts.IPGuard.On = false
+	})
+	assert.True(t, g.trustForwarded(&net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1000}))
+}
+
+func TestIPGuardClean(t *testing.T) {
+	g := newTestIPGuard(t, nil)
+	ip := net.ParseIP("10.0.0.1")
+	assert.True(t, g.acquire(ip))
+	g.clean(time.Now().Add(time.Hour))
+	assert.Len(t, g.entries, 1) // 还有连接不清理
+
+	g.release(ip.String())
+	g.clean(time.Now().Add(time.Hour))
+	assert.Len(t, g.entries, 0)
+}
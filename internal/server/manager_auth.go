@@ -0,0 +1,58 @@
+package server
+
+import (
+	"github.com/WuKongIM/WuKongIM/pkg/auth"
+	"github.com/WuKongIM/WuKongIM/pkg/auth/resource"
+)
+
+// managerAuthStore 从集群配置中读取管理后台的用户和角色
+type managerAuthStore struct {
+	s *Server
+}
+
+func newManagerAuthStore(s *Server) *managerAuthStore {
+	return &managerAuthStore{s: s}
+}
+
+func (m *managerAuthStore) User(username string) *auth.StoredUser {
+	if m.s.clusterServer == nil {
+		return nil
+	}
+	user := m.s.clusterServer.GetConfig().ManagerUser(username)
+	if user == nil {
+		return nil
+	}
+	return &auth.StoredUser{
+		Username:     user.Username,
+		PasswordHash: user.PasswordHash,
+		Roles:        user.Roles,
+	}
+}
+
+func (m *managerAuthStore) Role(name string) *auth.Role {
+	if m.s.clusterServer == nil {
+		return nil
+	}
+	role := m.s.clusterServer.GetConfig().ManagerRole(name)
+	if role == nil {
+		return nil
+	}
+	permissions := make(auth.PermissionConfigs, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		permissions = append(permissions, auth.PermissionConfig{
+			Resource: resource.Id(permission.Resource),
+			Actions:  auth.ParseActions(permission.Actions),
+		})
+	}
+	return &auth.Role{
+		Name:        role.Name,
+		Permissions: permissions,
+	}
+}
+
+func (m *managerAuthStore) Version() uint64 {
+	if m.s.clusterServer == nil {
+		return 0
+	}
+	return m.s.clusterServer.GetConfig().Version
+}
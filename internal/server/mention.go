@@ -0,0 +1,58 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+)
+
+// messageMention 消息的@信息，放在消息内容（payload）的mention字段里
+// 例如 {"content":"@u1 @u2 你好","mention":{"uids":["u1","u2"]}}，@所有人为 {"mention":{"all":1}}
+type messageMention struct {
+	Uids []string `json:"uids,omitempty"` // 被@的用户
+	All  int      `json:"all,omitempty"`  // 是否@所有人 0.否 1.是
+}
+
+// parseMessageMention 解析消息的@信息，没有@任何人（或者消息内容服务端无法解析）返回nil
+func parseMessageMention(sendPacket *wkproto.SendPacket) *messageMention {
+	if sendPacket == nil || sendPacket.Setting.IsSet(wkproto.SettingSignal) { // 端到端加密的消息服务端无法解析
+		return nil
+	}
+	if !bytes.Contains(sendPacket.Payload, []byte(`"mention"`)) {
+		return nil
+	}
+	var content struct {
+		Mention *messageMention `json:"mention"`
+	}
+	if err := json.Unmarshal(sendPacket.Payload, &content); err != nil || content.Mention == nil {
+		return nil
+	}
+	if content.Mention.All != 1 && len(content.Mention.Uids) == 0 {
+		return nil
+	}
+	return content.Mention
+}
+
+// filter 返回uids里被@的用户，发送者自己除外
+func (m *messageMention) filter(uids []string, fromUid string) []string {
+	var mentionUids []string
+	for _, uid := range uids {
+		if uid == fromUid {
+			continue
+		}
+		if m.All == 1 || m.contains(uid) {
+			mentionUids = append(mentionUids, uid)
+		}
+	}
+	return mentionUids
+}
+
+func (m *messageMention) contains(uid string) bool {
+	for _, mentionUid := range m.Uids {
+		if mentionUid == uid {
+			return true
+		}
+	}
+	return false
+}
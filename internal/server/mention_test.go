@@ -0,0 +1,29 @@
+package server
+
+import (
+	"testing"
+
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMessageMention(t *testing.T) {
+	mention := parseMessageMention(&wkproto.SendPacket{Payload: []byte(`{"content":"@u1 @u2 hello","mention":{"uids":["u1","u2"]}}`)})
+	assert.NotNil(t, mention)
+	assert.Equal(t, []string{"u1", "u2"}, mention.Uids)
+	assert.Equal(t, []string{"u2"}, mention.filter([]string{"u2", "u3"}, "u1"))
+
+	mention = parseMessageMention(&wkproto.SendPacket{Payload: []byte(`{"content":"@all","mention":{"all":1}}`)})
+	assert.NotNil(t, mention)
+	assert.Equal(t, []string{"u2", "u3"}, mention.filter([]string{"u1", "u2", "u3"}, "u1"))
+
+	// 没有@任何人
+	assert.Nil(t, parseMessageMention(&wkproto.SendPacket{Payload: []byte(`{"content":"hello"}`)}))
+	assert.Nil(t, parseMessageMention(&wkproto.SendPacket{Payload: []byte(`{"content":"hello","mention":{}}`)}))
+	assert.Nil(t, parseMessageMention(&wkproto.SendPacket{Payload: []byte(`"mention"`)}))
+
+	// 端到端加密的消息不解析
+	sendPacket := &wkproto.SendPacket{Payload: []byte(`{"mention":{"all":1}}`)}
+	sendPacket.Setting = sendPacket.Setting.Set(wkproto.SettingSignal)
+	assert.Nil(t, parseMessageMention(sendPacket))
+}
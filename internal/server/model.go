@@ -373,6 +373,7 @@ type MessageResp struct {
 	Expire       uint32             `json:"expire"`                // 消息过期时间
 	Timestamp    int32              `json:"timestamp"`             // 服务器消息时间戳(10位，到秒)
 	Payload      []byte             `json:"payload"`               // 消息内容
+	E2EE         int                `json:"e2ee,omitempty"`        // 消息内容是否是端到端加密的密文，是密文时不要解析内容
 	// Streams      []*StreamItemResp  `json:"streams,omitempty"`     // 消息流内容
 }
 
@@ -414,6 +415,7 @@ func (m *MessageResp) from(messageD wkdb.Message, s *Server) {
 	m.ChannelType = messageD.ChannelType
 	m.Topic = messageD.Topic
 	m.Payload = messageD.Payload
+	m.E2EE = wkutil.BoolToInt(messageD.IsOpaque())
 }
 
 type MessageOfflineNotify struct {
@@ -422,6 +424,7 @@ type MessageOfflineNotify struct {
 	Compress        string   `json:"compress,omitempty"`         // 压缩ToUIDs 如果为空 表示不压缩 为gzip则采用gzip压缩
 	CompresssToUIDs []byte   `json:"compress_to_uids,omitempty"` // 已压缩的to_uids
 	SourceID        int64    `json:"source_id,omitempty"`        // 来源节点ID
+	MentionUIDs     []string `json:"mention_uids,omitempty"`     // 被@的离线用户（@所有人时为所有离线用户），即使会话设置了免打扰也应该推送给这些用户
 }
 
 // MessageHeader Message header
@@ -439,8 +442,9 @@ type ChannelInfoResp struct {
 
 func (c ChannelInfoResp) ToChannelInfo() *wkdb.ChannelInfo {
 	return &wkdb.ChannelInfo{
-		Large: c.Large == 1,
-		Ban:   c.Ban == 1,
+		Large:   c.Large == 1,
+		Ban:     c.Ban == 1,
+		Disband: c.Disband == 1,
 	}
 }
 
@@ -619,6 +623,17 @@ func (f *FowardWriteReq) Unmarshal(data []byte) error {
 	return nil
 }
 
+type syncUserConversationReq struct {
+	UID         string `json:"uid"`
+	Version     int64  `json:"version"`       // 当前客户端的会话最大版本号(客户端最新会话的时间戳)
+	LastMsgSeqs string `json:"last_msg_seqs"` // 客户端所有会话的最后一条消息序列号 格式： channelID:channelType:last_msg_seq|channelID:channelType:last_msg_seq
+	MsgCount    int64  `json:"msg_count"`     // 每个会话消息数量
+	StrictRead  int    `json:"strict_read"`   // 跟随者读时是否严格一致（向领导确认读索引） 0.否 1.是
+
+	ConversationVersion uint64 `json:"conversation_version"` // 分页同步时客户端已同步到的会话版本号（上次返回的version），首次同步传0
+	Limit               int    `json:"limit"`                // 分页同步每页数量，大于0时按会话版本号分页增量同步
+}
+
 type clearConversationUnreadReq struct {
 	UID         string `json:"uid"`
 	ChannelID   string `json:"channel_id"`
@@ -678,6 +693,37 @@ func (c *channelReq) Unmarshal(data []byte) error {
 	return nil
 }
 
+// eventSinkCheckpointReq 获取事件流频道投递位置的请求
+type eventSinkCheckpointReq struct {
+	Sink        string
+	ChannelId   string
+	ChannelType uint8
+}
+
+func (r *eventSinkCheckpointReq) Marshal() []byte {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+	enc.WriteString(r.Sink)
+	enc.WriteString(r.ChannelId)
+	enc.WriteUint8(r.ChannelType)
+	return enc.Bytes()
+}
+
+func (r *eventSinkCheckpointReq) Unmarshal(data []byte) error {
+	dec := wkproto.NewDecoder(data)
+	var err error
+	if r.Sink, err = dec.String(); err != nil {
+		return err
+	}
+	if r.ChannelId, err = dec.String(); err != nil {
+		return err
+	}
+	if r.ChannelType, err = dec.Uint8(); err != nil {
+		return err
+	}
+	return nil
+}
+
 type syncUserConversationResp struct {
 	ChannelId       string         `json:"channel_id"`         // 频道ID
 	ChannelType     uint8          `json:"channel_type"`       // 频道类型
@@ -687,8 +733,39 @@ type syncUserConversationResp struct {
 	LastClientMsgNo string         `json:"last_client_msg_no"` // 最后一次消息客户端编号
 	OffsetMsgSeq    int64          `json:"offset_msg_seq"`     // 偏移位的消息seq
 	ReadedToMsgSeq  uint32         `json:"readed_to_msg_seq"`  // 已读至的消息seq
+	Mention         int            `json:"mention"`            // 是否有未读的@消息（包括@所有人） 0.否 1.是
+	MentionMsgSeq   uint32         `json:"mention_msg_seq"`    // 最近一条@当前用户的消息seq，客户端可以用来跳转到@消息
 	Version         int64          `json:"version"`            // 数据版本
 	Recents         []*MessageResp `json:"recents"`            // 最近N条消息
+
+	ConversationVersion uint64 `json:"conversation_version,omitempty"` // 会话版本号（分页同步时返回）
+
+	unreadTracked bool // 未读数是否由服务端维护
+}
+
+// fillDerivedUnread 没有维护未读数的最近会话（升级前保存的会话和接收者过多的频道）使用最后一条消息序号和已读位置推算未读数
+func (s *syncUserConversationResp) fillDerivedUnread() {
+	if !s.unreadTracked && s.LastMsgSeq > s.ReadedToMsgSeq {
+		s.Unread = int(s.LastMsgSeq - s.ReadedToMsgSeq)
+	}
+}
+
+// syncUserConversationPageResp 分页（增量）同步最近会话的返回
+// 客户端先处理deleted再处理conversations，然后将version作为下次请求的conversation_version，more为1时继续请求下一页
+// reset为1时表示客户端的版本号太旧（需要的删除记录已经清理），本页从头返回，客户端需要先清空本地的会话
+type syncUserConversationPageResp struct {
+	Conversations []*syncUserConversationResp        `json:"conversations"` // 新增或更新的会话
+	Deleted       []*syncUserConversationDeletedResp `json:"deleted"`       // 删除的会话
+	Version       uint64                             `json:"version"`       // 本页最大的会话版本号
+	More          int                                `json:"more"`          // 是否还有下一页 0.否 1.是
+	Reset         int                                `json:"reset"`         // 是否需要清空本地会话后全量同步 0.否 1.是
+}
+
+// syncUserConversationDeletedResp 已删除的会话
+type syncUserConversationDeletedResp struct {
+	ChannelId           string `json:"channel_id"`           // 频道ID
+	ChannelType         uint8  `json:"channel_type"`         // 频道类型
+	ConversationVersion uint64 `json:"conversation_version"` // 删除时的会话版本号
 }
 
 func newSyncUserConversationResp(conversation wkdb.Conversation) *syncUserConversationResp {
@@ -706,6 +783,12 @@ func newSyncUserConversationResp(conversation wkdb.Conversation) *syncUserConver
 		ChannelType:    conversation.ChannelType,
 		Unread:         int(conversation.UnreadCount),
 		ReadedToMsgSeq: uint32(conversation.ReadToMsgSeq),
+		Mention:        wkutil.BoolToInt(conversation.MentionMsgSeq > conversation.ReadToMsgSeq),
+		MentionMsgSeq:  uint32(conversation.MentionMsgSeq),
+
+		ConversationVersion: conversation.Version,
+
+		unreadTracked: conversation.UnreadTracked,
 	}
 }
 
@@ -747,7 +830,7 @@ func (r ChannelCreateReq) Check() error {
 	if IsSpecialChar(r.ChannelID) {
 		return errors.New("频道ID不能包含特殊字符！")
 	}
-	return nil
+	return r.checkWebhook()
 }
 
 type subscriberAddReq struct {
@@ -965,6 +1048,19 @@ type ChannelInfoReq struct {
 	Large       int    `json:"large"`        // 是否是超大群
 	Ban         int    `json:"ban"`          // 是否封禁频道（封禁后此频道所有人都将不能发消息，除了系统账号）
 	Disband     int    `json:"disband"`      // 是否解散频道
+	Webhook     string `json:"webhook"`      // 频道的webhook地址，开启频道级别的webhook后此频道的消息通知推送到此地址
+}
+
+// checkWebhook 检查频道的webhook地址
+func (c ChannelInfoReq) checkWebhook() error {
+	webhook := strings.TrimSpace(c.Webhook)
+	if webhook == "" {
+		return nil
+	}
+	if !strings.HasPrefix(webhook, "http://") && !strings.HasPrefix(webhook, "https://") {
+		return errors.New("webhook地址必须以http://或https://开头！")
+	}
+	return nil
 }
 
 func (c ChannelInfoReq) ToChannelInfo() wkdb.ChannelInfo {
@@ -976,6 +1072,7 @@ func (c ChannelInfoReq) ToChannelInfo() wkdb.ChannelInfo {
 		Large:       c.Large == 1,
 		Ban:         c.Ban == 1,
 		Disband:     c.Disband == 1,
+		Webhook:     strings.TrimSpace(c.Webhook),
 		CreatedAt:   &createdAt,
 		UpdatedAt:   &updatedAt,
 	}
@@ -992,6 +1089,7 @@ type MessageSendReq struct {
 	Expire      uint32        `json:"expire"`        // 消息过期时间
 	Subscribers []string      `json:"subscribers"`   // 订阅者 如果此字段有值，表示消息只发给指定的订阅者
 	Payload     []byte        `json:"payload"`       // 消息内容
+	E2EE        int           `json:"e2ee"`          // 消息内容是否是端到端加密的密文（服务端不解析内容）
 }
 
 // Check 检查输入
@@ -1,6 +1,8 @@
 package server
 
 import (
+	stdtls "crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"os"
 	"os/user"
@@ -13,7 +15,9 @@ import (
 
 	"github.com/WuKongIM/WuKongIM/pkg/auth"
 	"github.com/WuKongIM/WuKongIM/pkg/auth/resource"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
 	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/WuKongIM/WuKongIM/pkg/wksink"
 	"github.com/WuKongIM/crypto/tls"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
@@ -65,6 +69,22 @@ type Options struct {
 		CertFile string // 证书文件
 		KeyFile  string // 私钥文件
 	}
+	QUICAddr      string         // quic 监听地址 例如：quic://0.0.0.0:5110 客户端连接后打开一个双向流，流上的数据与tcp连接相同
+	QUICTLSConfig *stdtls.Config // quic的tls配置（根据证书配置生成）
+	QUICConfig    struct {       // quic的证书配置，不配置则使用wss的证书
+		CertFile       string        // 证书文件
+		KeyFile        string        // 私钥文件
+		MaxIdleTimeout time.Duration // 连接最大空闲时间，超过后quic连接断开
+	}
+	// websocket的permessage-deflate压缩（RFC 7692），ws和wss都生效
+	WSCompression struct {
+		On                      bool // 是否开启
+		Level                   int  // 压缩级别 1-9，-1为默认级别
+		Threshold               int  // 小于此大小的消息不压缩（单位字节）
+		ContextTakeover         bool // 是否允许上下文接管，压缩率更高，但每个连接常驻一个压缩器（约1MB）和32KB的解压窗口
+		MaxContextTakeoverConns int  // 使用上下文接管的最大连接数，超过后的连接不使用上下文接管，0表示不限制
+		MaxMessageSize          int  // 解压后单条消息的最大大小（单位字节），超过后断开连接
+	}
 
 	Logger struct {
 		Dir     string // 日志存储目录
@@ -92,6 +112,7 @@ type Options struct {
 		TCPAddr           string // 节点的TCP地址 对外公开，APP端长连接通讯  格式： ip:port
 		WSAddr            string //  节点的wsAdd地址 对外公开 WEB端长连接通讯 格式： ws://ip:port
 		WSSAddr           string // 节点的wssAddr地址 对外公开 WEB端长连接通讯 格式： wss://ip:port
+		QUICAddr          string // 节点的quicAddr地址 对外公开 APP端长连接通讯 格式： quic://ip:port
 		ManagerAddr       string // 对外访问的管理地址
 		APIUrl            string // 对外访问的API基地址 格式: http://ip:port
 		AutoGetExternalIP bool   // 是否自动获取外网IP
@@ -116,10 +137,44 @@ type Options struct {
 		MsgNotifyEventCountPerPush  int           // 每次webhook消息通知事件推送消息数量限制 默认一次请求最多推送100条
 		MsgNotifyEventRetryMaxCount int           // 消息通知事件消息推送失败最大重试次数 默认为5次，超过将丢弃
 		FocusEvents                 []string      // 关注的通知事件,如果为空表示关注所有事件
+		ChannelOn                   bool          // 是否开启频道级别的webhook，开启后频道信息里配置了webhook地址的频道，其msg.notify和msg.offline事件推送到频道的webhook地址
+		ChannelQueueSize            int           // 每个频道webhook地址的待推送事件队列大小，队列满了后msg.notify消息留在通知队列中稍后再推送，其他事件存入死信
+		Secret                      string        // webhook签名密钥，不为空时请求头会带上时间戳和HMAC-SHA256签名，第三方可据此校验请求来源
+		RetryBackoff                time.Duration // 推送失败后的重试等待时间，每次重试翻倍
+		RetryMaxBackoff             time.Duration // 推送失败后的最大重试等待时间
+		RetryQueueSize              int           // 等待重试的事件队列大小，重试在单独的队列里等待，不占用事件协程池，队列满了后事件存入死信（重试队列只在内存中，正常停止时存入死信，进程崩溃时会丢失）
 	}
 	Datasource struct { // 数据源配置，不填写则使用自身数据存储逻辑，如果填写则使用第三方数据源，数据格式请查看文档
-		Addr          string // 数据源地址
-		ChannelInfoOn bool   // 是否开启频道信息获取
+		Addr          string        // 数据源地址，http(s)://开头使用http数据源，grpc://开头使用grpc数据源
+		ChannelInfoOn bool          // 是否开启频道信息获取
+		SubscriberOn  bool          // 是否从数据源获取订阅者和黑白名单，关闭时只使用自身存储（数据源只用于系统账号等）
+		CacheTTL      time.Duration // 订阅者、黑白名单和频道信息的本地缓存时间，0表示不缓存
+		Timeout       time.Duration // 请求数据源的超时时间
+		MaxRetries    int           // 请求数据源失败后的最大重试次数
+		BatchWindow   time.Duration // grpc数据源合并请求的时间窗口，窗口内的单频道请求合并成一次批量请求，0表示不合并
+		BatchMaxSize  int           // grpc数据源每次批量请求的最大频道数量
+	}
+	EventSink struct { // 事件流配置，开启后存储成功的消息会按频道有序地投递到配置的Sink，投递位置保存在本地，投递语义为至少一次
+		On             bool          // 是否开启事件流
+		FileOn         bool          // 是否投递到本地文件（JSONL格式）
+		FileDir        string        // 本地文件目录，默认为 数据目录/eventsink
+		FileMaxSize    int64         // 单个文件最大字节数，超过后轮转
+		FileMaxBackups int           // 保留的轮转文件数量，0表示全部保留
+		HTTPAddr       string        // 投递的http地址，事件以JSONL格式POST到此地址
+		TCPAddr        string        // 投递的tcp地址，格式为 ip:port，事件以JSONL格式写入tcp长连接，接收方每批事件需要回复一行最后一个事件的Offset作为确认
+		Timeout        time.Duration // 投递到http和tcp地址的超时时间
+		BatchSize      int           // 每次投递的最大事件数量
+		WorkerCount    int           // 投递的协程数量，同一频道的事件由同一个协程顺序投递
+		RetryInterval  time.Duration // 投递失败后的重试间隔，某个Sink投递失败时只暂停这个Sink
+		Sinks          []wksink.Sink // 自定义的Sink，通过WithEventSinks设置
+	}
+	Bot struct { // 服务端机器人配置，机器人通过 /bot/register 接口或者 Server.RegisterBot 注册
+		On              bool          // 是否开启服务端机器人
+		RateLimit       float64       // 每个机器人每秒最多处理的消息数量，超过的消息将被丢弃，0表示不限制
+		RateBurst       int           // 频率限制允许的突发数量
+		CommandPrefix   string        // 命令前缀，例如 /weather beijing
+		QueueSize       int           // 每个机器人待处理的消息队列大小，队列满了后新的消息将被丢弃
+		CallbackTimeout time.Duration // 请求机器人回调地址的超时时间
 	}
 	Conversation struct {
 		On                 bool          // 是否开启最近会话
@@ -131,6 +186,9 @@ type Options struct {
 		SavePoolSize       int           // 保存最近会话协程池大小
 		WorkerCount        int           // 处理最近会话工作者数量
 		WorkerScanInterval time.Duration // 处理最近会话扫描间隔
+		SyncPageMaxSize    int           // 分页同步最近会话时每页最大数量
+		SyncMaxMsgCount    int           // 分页同步最近会话时每个会话最多带的最近消息数量
+		UnreadMaxReceivers int           // 频道在本节点的接收者超过此数量时不在服务端维护未读数（避免每条消息都重写所有成员的最近会话），未读数由最后一条消息序号和已读位置推算，0表示不限制（频道人数增长超过此数量后，之前维护过未读数但之后没有再更新的成员会话未读数会停留在旧值）
 
 	}
 	ManagerToken   string // 管理者的token
@@ -166,6 +224,13 @@ type Options struct {
 		WorkerCount  int           // worker数量
 	}
 
+	SessionResume struct {
+		On                bool          // 是否开启会话恢复，开启后connect的ClientKey带“.resume”标记的客户端，connack的ServerKey会带上恢复票据（格式：<服务端DH公钥>.<票据>）
+		TTL               time.Duration // 连接断开后票据的有效期
+		MaxLifetime       time.Duration // 票据从签发开始的最长有效期，超过后即使连接还在线也不能再用于恢复
+		MaxBufferMessages int           // 连接断开期间每个会话最多缓存的待投递消息数量
+	}
+
 	Cluster struct {
 		NodeId              uint64        // 节点ID,节点Id，必须小于或等于1023 （https://github.com/bwmarrin/snowflake 雪花算法的限制）
 		Addr                string        // 节点监听地址 例如：tcp://0.0.0.0:11110
@@ -178,6 +243,8 @@ type Options struct {
 		ChannelReplicaCount int           // 每个频道的副本数量
 		SlotCount           int           // 槽数量
 		InitNodes           []*Node       // 集群初始节点地址
+		Zone                string        // 节点所在可用区，副本会尽量分散在不同可用区
+		Rack                string        // 节点所在机架，同一可用区内副本会尽量分散在不同机架
 
 		TickInterval time.Duration // 分布式tick间隔
 
@@ -188,6 +255,20 @@ type Options struct {
 		SlotReactorSubCount    int // 槽reactor sub的数量
 
 		PongMaxTick int // 节点超过多少tick没有回应心跳就认为是掉线
+
+		CertFile          string // 节点通讯的证书文件，配置后节点之间使用tls通讯
+		KeyFile           string // 节点通讯的私钥文件
+		CAFile            string // 节点通讯的CA证书文件，配置后开启mTLS，证书的CommonName必须为节点id
+		Secret            string // 节点之间的共享密钥，配置后节点握手需要校验密钥签名
+		AllowUnknownNodes bool   // 开启认证后是否允许未知节点连接（新节点通过种子节点加入集群时需要开启）
+
+		ServerTLSConfig *tls.Config    // 节点监听的tls配置（根据证书配置生成）
+		ClientTLSConfig *stdtls.Config // 节点连接其他节点的tls配置（根据证书配置生成）
+
+		FollowerRead         bool          // 是否开启跟随者读，开启后消息同步等读请求可以由本地的频道副本处理
+		ReadIndexWaitTimeout time.Duration // 严格一致性读时，等待本地副本应用到领导读索引的最长时间
+
+		DrainTimeout time.Duration // 节点排空（转移节点上所有领导）的最长时间
 	}
 
 	Trace struct {
@@ -240,6 +321,12 @@ type Options struct {
 		ShardNum     int // 频道db分片数量
 		SlotShardNum int // 槽db分片数量
 		MemTableSize int // MemTable大小
+
+		// 消息内容的静态加密（信封加密），消息使用数据密钥加密，数据密钥使用主密钥加密后保存在db中
+		EncryptionOn             bool             // 是否开启静态加密
+		EncryptionKeyFile        string           // 主密钥文件，内容为base64编码的32字节密钥
+		EncryptionRotateInterval time.Duration    // 数据密钥的自动轮换间隔，0表示不自动轮换
+		EncryptionKeyProvider    wkdb.KeyProvider // 自定义的主密钥提供者（例如对接KMS），设置后忽略EncryptionKeyFile
 	}
 
 	Auth auth.AuthConfig // 认证配置
@@ -249,6 +336,42 @@ type Options struct {
 		Expire time.Duration // jwt expire
 		Issuer string        // jwt 发行者名字
 	}
+
+	// 客户端连接的jwt认证（开启tokenAuthOn后生效），连接token可以是业务服务签发的jwt，无需通过/user/token提前注册
+	ClientJwt struct {
+		On            bool   // 是否开启
+		Algorithm     string // 签名算法 HS256 RS256 ES256
+		Secret        string // HS256的密钥
+		PublicKeyFile string // RS256/ES256的公钥文件（PEM格式）
+		JwksFile      string // RS256/ES256的本地JWKS文件，按jwt头部的kid选择公钥，配置后忽略PublicKeyFile
+		Issuer        string // 签发者，不为空时校验jwt的iss
+		Audience      string // 接收者，不为空时校验jwt的aud
+		UidClaim      string // uid所在的claim，默认为uid
+		FallbackDB    bool   // token不是jwt格式时是否使用/user/token注册的token校验
+	}
+	TokenVerifier TokenVerifier // 自定义客户端连接的token校验，设置后忽略ClientJwt配置
+
+	// 连接层的防护，限制单个IP的连接数和连接频率，认证连续失败的IP临时封禁
+	IPGuard struct {
+		On                bool          // 是否开启
+		MaxConnsPerIp     int           // 单个IP的最大连接数，0表示不限制
+		ConnectRate       float64       // 单个IP每秒允许新建的连接数，0表示不限制
+		ConnectBurst      int           // 单个IP允许突发新建的连接数，默认与ConnectRate相同
+		AuthFailThreshold int           // 窗口时间内认证失败达到此次数后临时封禁，0表示不封禁
+		AuthFailWindow    time.Duration // 认证失败的统计窗口
+		BanDuration       time.Duration // 临时封禁的时间
+		Allowlist         []string      // 白名单（IP或CIDR），不受任何限制
+		Denylist          []string      // 黑名单（IP或CIDR），直接拒绝连接
+		TrustedProxies    []string      // 受信任的代理（IP或CIDR），来自代理的连接按代理协议或websocket请求头中的真实IP限制，开启防护或配置后只信任这些代理传递的真实IP
+	}
+
+	// 审计日志，记录频道、用户、最近会话和分布式管理接口的修改操作
+	Audit struct {
+		On            bool          // 是否开启
+		Retention     time.Duration // 审计日志保留时间
+		CleanInterval time.Duration // 过期审计日志的清理间隔
+	}
+
 	PprofOn          bool        // 是否开启pprof
 	OldV1Api         string      //旧v1版本的api地址，如果不为空则开启数据迁移任务，将v1的数据迁移到v2
 	MigrateStartStep MigrateStep // 从那步开始迁移，默认顺序是 message,user,channel
@@ -335,9 +458,59 @@ func NewOptions(op ...Option) *Options {
 		Datasource: struct {
 			Addr          string
 			ChannelInfoOn bool
+			SubscriberOn  bool
+			CacheTTL      time.Duration
+			Timeout       time.Duration
+			MaxRetries    int
+			BatchWindow   time.Duration
+			BatchMaxSize  int
 		}{
 			Addr:          "",
 			ChannelInfoOn: false,
+			SubscriberOn:  false,
+			CacheTTL:      time.Minute * 5,
+			Timeout:       time.Second * 5,
+			MaxRetries:    2,
+			BatchWindow:   time.Millisecond * 5,
+			BatchMaxSize:  100,
+		},
+		EventSink: struct {
+			On             bool
+			FileOn         bool
+			FileDir        string
+			FileMaxSize    int64
+			FileMaxBackups int
+			HTTPAddr       string
+			TCPAddr        string
+			Timeout        time.Duration
+			BatchSize      int
+			WorkerCount    int
+			RetryInterval  time.Duration
+			Sinks          []wksink.Sink
+		}{
+			On:             false,
+			FileOn:         false,
+			FileMaxSize:    1024 * 1024 * 100,
+			FileMaxBackups: 10,
+			Timeout:        time.Second * 5,
+			BatchSize:      100,
+			WorkerCount:    16,
+			RetryInterval:  time.Second * 2,
+		},
+		Bot: struct {
+			On              bool
+			RateLimit       float64
+			RateBurst       int
+			CommandPrefix   string
+			QueueSize       int
+			CallbackTimeout time.Duration
+		}{
+			On:              true,
+			RateLimit:       10,
+			RateBurst:       20,
+			CommandPrefix:   "/",
+			QueueSize:       1000,
+			CallbackTimeout: time.Second * 5,
 		},
 		TokenAuthOn: false,
 		Conversation: struct {
@@ -350,6 +523,9 @@ func NewOptions(op ...Option) *Options {
 			SavePoolSize       int
 			WorkerCount        int
 			WorkerScanInterval time.Duration
+			SyncPageMaxSize    int
+			SyncMaxMsgCount    int
+			UnreadMaxReceivers int
 		}{
 			On:                 true,
 			CacheExpire:        time.Hour * 2,
@@ -360,6 +536,9 @@ func NewOptions(op ...Option) *Options {
 			SavePoolSize:       100,
 			WorkerCount:        10,
 			WorkerScanInterval: time.Minute * 5,
+			SyncPageMaxSize:    200,
+			SyncMaxMsgCount:    20,
+			UnreadMaxReceivers: 500,
 		},
 		DeliveryMsgPoolSize: 10240,
 		EventPoolSize:       1024,
@@ -374,6 +553,17 @@ func NewOptions(op ...Option) *Options {
 			MaxCount:     5,
 			WorkerCount:  128,
 		},
+		SessionResume: struct {
+			On                bool
+			TTL               time.Duration
+			MaxLifetime       time.Duration
+			MaxBufferMessages int
+		}{
+			On:                false,
+			TTL:               time.Minute * 2,
+			MaxLifetime:       time.Hour * 24,
+			MaxBufferMessages: 200,
+		},
 		Webhook: struct {
 			HTTPAddr                    string
 			GRPCAddr                    string
@@ -381,10 +571,21 @@ func NewOptions(op ...Option) *Options {
 			MsgNotifyEventCountPerPush  int
 			MsgNotifyEventRetryMaxCount int
 			FocusEvents                 []string
+			ChannelOn                   bool
+			ChannelQueueSize            int
+			Secret                      string
+			RetryBackoff                time.Duration
+			RetryMaxBackoff             time.Duration
+			RetryQueueSize              int
 		}{
 			MsgNotifyEventPushInterval:  time.Millisecond * 500,
 			MsgNotifyEventCountPerPush:  100,
 			MsgNotifyEventRetryMaxCount: 5,
+			ChannelOn:                   false,
+			ChannelQueueSize:            1000,
+			RetryBackoff:                time.Second,
+			RetryMaxBackoff:             time.Minute,
+			RetryQueueSize:              10000,
 		},
 		Manager: struct {
 			On   bool
@@ -412,12 +613,24 @@ func NewOptions(op ...Option) *Options {
 			ChannelReplicaCount    int
 			SlotCount              int
 			InitNodes              []*Node
+			Zone                   string
+			Rack                   string
 			TickInterval           time.Duration
 			HeartbeatIntervalTick  int
 			ElectionIntervalTick   int
 			ChannelReactorSubCount int
 			SlotReactorSubCount    int
 			PongMaxTick            int
+			CertFile               string
+			KeyFile                string
+			CAFile                 string
+			Secret                 string
+			AllowUnknownNodes      bool
+			ServerTLSConfig        *tls.Config
+			ClientTLSConfig        *stdtls.Config
+			FollowerRead           bool
+			ReadIndexWaitTimeout   time.Duration
+			DrainTimeout           time.Duration
 		}{
 			NodeId:                 1001,
 			Addr:                   "tcp://0.0.0.0:11110",
@@ -433,6 +646,8 @@ func NewOptions(op ...Option) *Options {
 			ChannelReactorSubCount: 128,
 			SlotReactorSubCount:    64,
 			PongMaxTick:            30,
+			ReadIndexWaitTimeout:   time.Millisecond * 500,
+			DrainTimeout:           time.Minute * 5,
 		},
 		Trace: struct {
 			ServiceName      string
@@ -528,6 +743,11 @@ func NewOptions(op ...Option) *Options {
 			ShardNum     int
 			SlotShardNum int
 			MemTableSize int
+
+			EncryptionOn             bool
+			EncryptionKeyFile        string
+			EncryptionRotateInterval time.Duration
+			EncryptionKeyProvider    wkdb.KeyProvider
 		}{
 			ShardNum:     8,
 			SlotShardNum: 8,
@@ -543,6 +763,71 @@ func NewOptions(op ...Option) *Options {
 			Secret: "secret_wukongim",
 			Issuer: "wukongim",
 		},
+		ClientJwt: struct {
+			On            bool
+			Algorithm     string
+			Secret        string
+			PublicKeyFile string
+			JwksFile      string
+			Issuer        string
+			Audience      string
+			UidClaim      string
+			FallbackDB    bool
+		}{
+			Algorithm:  "HS256",
+			UidClaim:   "uid",
+			FallbackDB: true,
+		},
+		IPGuard: struct {
+			On                bool
+			MaxConnsPerIp     int
+			ConnectRate       float64
+			ConnectBurst      int
+			AuthFailThreshold int
+			AuthFailWindow    time.Duration
+			BanDuration       time.Duration
+			Allowlist         []string
+			Denylist          []string
+			TrustedProxies    []string
+		}{
+			MaxConnsPerIp:     100,
+			ConnectRate:       20,
+			ConnectBurst:      40,
+			AuthFailThreshold: 10,
+			AuthFailWindow:    time.Minute,
+			BanDuration:       time.Minute * 10,
+		},
+		QUICConfig: struct {
+			CertFile       string
+			KeyFile        string
+			MaxIdleTimeout time.Duration
+		}{
+			MaxIdleTimeout: time.Second * 30,
+		},
+		WSCompression: struct {
+			On                      bool
+			Level                   int
+			Threshold               int
+			ContextTakeover         bool
+			MaxContextTakeoverConns int
+			MaxMessageSize          int
+		}{
+			On:                      false,
+			Level:                   -1,
+			Threshold:               256,
+			ContextTakeover:         false,
+			MaxContextTakeoverConns: 1000,
+			MaxMessageSize:          1024 * 1024 * 4,
+		},
+		Audit: struct {
+			On            bool
+			Retention     time.Duration
+			CleanInterval time.Duration
+		}{
+			On:            true,
+			Retention:     time.Hour * 24 * 90,
+			CleanInterval: time.Hour,
+		},
 		MigrateStartStep: MigrateStepMessage,
 	}
 
@@ -595,6 +880,7 @@ func (o *Options) ConfigureWithViper(vp *viper.Viper) {
 	o.External.TCPAddr = o.getString("external.tcpAddr", o.External.TCPAddr)
 	o.External.WSAddr = o.getString("external.wsAddr", o.External.WSAddr)
 	o.External.WSSAddr = o.getString("external.wssAddr", o.External.WSSAddr)
+	o.External.QUICAddr = o.getString("external.quicAddr", o.External.QUICAddr)
 	o.External.ManagerAddr = o.getString("external.managerAddr", o.External.ManagerAddr)
 	o.External.APIUrl = o.getString("external.apiUrl", o.External.APIUrl)
 	o.External.AutoGetExternalIP = o.getBool("external.autoGetExternalIP", o.External.AutoGetExternalIP)
@@ -613,6 +899,11 @@ func (o *Options) ConfigureWithViper(vp *viper.Viper) {
 	o.WSSConfig.CertFile = o.getString("wssConfig.certFile", o.WSSConfig.CertFile)
 	o.WSSConfig.KeyFile = o.getString("wssConfig.keyFile", o.WSSConfig.KeyFile)
 
+	o.QUICAddr = o.getString("quicAddr", o.QUICAddr)
+	o.QUICConfig.CertFile = o.getString("quicConfig.certFile", o.QUICConfig.CertFile)
+	o.QUICConfig.KeyFile = o.getString("quicConfig.keyFile", o.QUICConfig.KeyFile)
+	o.QUICConfig.MaxIdleTimeout = o.getDuration("quicConfig.maxIdleTimeout", o.QUICConfig.MaxIdleTimeout)
+
 	o.Channel.CacheCount = o.getInt("channel.cacheCount", o.Channel.CacheCount)
 	o.Channel.CreateIfNoExist = o.getBool("channel.createIfNoExist", o.Channel.CreateIfNoExist)
 	o.Channel.SubscriberCompressOfCount = o.getInt("channel.subscriberCompressOfCount", o.Channel.SubscriberCompressOfCount)
@@ -638,6 +929,12 @@ func (o *Options) ConfigureWithViper(vp *viper.Viper) {
 	o.Webhook.MsgNotifyEventCountPerPush = o.getInt("webhook.msgNotifyEventCountPerPush", o.Webhook.MsgNotifyEventCountPerPush)
 	o.Webhook.MsgNotifyEventPushInterval = o.getDuration("webhook.msgNotifyEventPushInterval", o.Webhook.MsgNotifyEventPushInterval)
 	o.Webhook.FocusEvents = o.getStringSlice("webhook.focusEvents")
+	o.Webhook.ChannelOn = o.getBool("webhook.channelOn", o.Webhook.ChannelOn)
+	o.Webhook.ChannelQueueSize = o.getInt("webhook.channelQueueSize", o.Webhook.ChannelQueueSize)
+	o.Webhook.Secret = o.getString("webhook.secret", o.Webhook.Secret)
+	o.Webhook.RetryBackoff = o.getDuration("webhook.retryBackoff", o.Webhook.RetryBackoff)
+	o.Webhook.RetryMaxBackoff = o.getDuration("webhook.retryMaxBackoff", o.Webhook.RetryMaxBackoff)
+	o.Webhook.RetryQueueSize = o.getInt("webhook.retryQueueSize", o.Webhook.RetryQueueSize)
 
 	o.EventPoolSize = o.getInt("eventPoolSize", o.EventPoolSize)
 	o.DeliveryMsgPoolSize = o.getInt("deliveryMsgPoolSize", o.DeliveryMsgPoolSize)
@@ -648,6 +945,33 @@ func (o *Options) ConfigureWithViper(vp *viper.Viper) {
 
 	o.Datasource.Addr = o.getString("datasource.addr", o.Datasource.Addr)
 	o.Datasource.ChannelInfoOn = o.getBool("datasource.channelInfoOn", o.Datasource.ChannelInfoOn)
+	o.Datasource.SubscriberOn = o.getBool("datasource.subscriberOn", o.Datasource.SubscriberOn)
+	o.Datasource.CacheTTL = o.getDuration("datasource.cacheTTL", o.Datasource.CacheTTL)
+	o.Datasource.Timeout = o.getDuration("datasource.timeout", o.Datasource.Timeout)
+	o.Datasource.MaxRetries = o.getInt("datasource.maxRetries", o.Datasource.MaxRetries)
+	o.Datasource.BatchWindow = o.getDuration("datasource.batchWindow", o.Datasource.BatchWindow)
+	o.Datasource.BatchMaxSize = o.getInt("datasource.batchMaxSize", o.Datasource.BatchMaxSize)
+
+	// =================== event sink ===================
+	o.EventSink.On = o.getBool("eventSink.on", o.EventSink.On)
+	o.EventSink.FileOn = o.getBool("eventSink.fileOn", o.EventSink.FileOn)
+	o.EventSink.FileDir = o.getString("eventSink.fileDir", o.EventSink.FileDir)
+	o.EventSink.FileMaxSize = o.getInt64("eventSink.fileMaxSize", o.EventSink.FileMaxSize)
+	o.EventSink.FileMaxBackups = o.getInt("eventSink.fileMaxBackups", o.EventSink.FileMaxBackups)
+	o.EventSink.HTTPAddr = o.getString("eventSink.httpAddr", o.EventSink.HTTPAddr)
+	o.EventSink.TCPAddr = o.getString("eventSink.tcpAddr", o.EventSink.TCPAddr)
+	o.EventSink.Timeout = o.getDuration("eventSink.timeout", o.EventSink.Timeout)
+	o.EventSink.BatchSize = o.getInt("eventSink.batchSize", o.EventSink.BatchSize)
+	o.EventSink.WorkerCount = o.getInt("eventSink.workerCount", o.EventSink.WorkerCount)
+	o.EventSink.RetryInterval = o.getDuration("eventSink.retryInterval", o.EventSink.RetryInterval)
+
+	// =================== bot ===================
+	o.Bot.On = o.getBool("bot.on", o.Bot.On)
+	o.Bot.RateLimit = o.getFloat64("bot.rateLimit", o.Bot.RateLimit)
+	o.Bot.RateBurst = o.getInt("bot.rateBurst", o.Bot.RateBurst)
+	o.Bot.CommandPrefix = o.getString("bot.commandPrefix", o.Bot.CommandPrefix)
+	o.Bot.QueueSize = o.getInt("bot.queueSize", o.Bot.QueueSize)
+	o.Bot.CallbackTimeout = o.getDuration("bot.callbackTimeout", o.Bot.CallbackTimeout)
 
 	o.WhitelistOffOfPerson = o.getBool("whitelistOffOfPerson", o.WhitelistOffOfPerson)
 
@@ -656,6 +980,11 @@ func (o *Options) ConfigureWithViper(vp *viper.Viper) {
 	o.MessageRetry.MaxCount = o.getInt("messageRetry.maxCount", o.MessageRetry.MaxCount)
 	o.MessageRetry.WorkerCount = o.getInt("messageRetry.workerCount", o.MessageRetry.WorkerCount)
 
+	o.SessionResume.On = o.getBool("sessionResume.on", o.SessionResume.On)
+	o.SessionResume.TTL = o.getDuration("sessionResume.ttl", o.SessionResume.TTL)
+	o.SessionResume.MaxLifetime = o.getDuration("sessionResume.maxLifetime", o.SessionResume.MaxLifetime)
+	o.SessionResume.MaxBufferMessages = o.getInt("sessionResume.maxBufferMessages", o.SessionResume.MaxBufferMessages)
+
 	o.Conversation.On = o.getBool("conversation.on", o.Conversation.On)
 	o.Conversation.CacheExpire = o.getDuration("conversation.cacheExpire", o.Conversation.CacheExpire)
 	o.Conversation.SyncInterval = o.getDuration("conversation.syncInterval", o.Conversation.SyncInterval)
@@ -665,6 +994,9 @@ func (o *Options) ConfigureWithViper(vp *viper.Viper) {
 	o.Conversation.SavePoolSize = o.getInt("conversation.savePoolSize", o.Conversation.SavePoolSize)
 	o.Conversation.WorkerCount = o.getInt("conversation.workerNum", o.Conversation.WorkerCount)
 	o.Conversation.WorkerScanInterval = o.getDuration("conversation.workerScanInterval", o.Conversation.WorkerScanInterval)
+	o.Conversation.SyncPageMaxSize = o.getInt("conversation.syncPageMaxSize", o.Conversation.SyncPageMaxSize)
+	o.Conversation.SyncMaxMsgCount = o.getInt("conversation.syncMaxMsgCount", o.Conversation.SyncMaxMsgCount)
+	o.Conversation.UnreadMaxReceivers = o.getInt("conversation.unreadMaxReceivers", o.Conversation.UnreadMaxReceivers)
 
 	if o.WSSConfig.CertFile != "" && o.WSSConfig.KeyFile != "" {
 		certificate, err := tls.LoadX509KeyPair(o.WSSConfig.CertFile, o.WSSConfig.KeyFile)
@@ -677,6 +1009,9 @@ func (o *Options) ConfigureWithViper(vp *viper.Viper) {
 			},
 		}
 	}
+	if strings.TrimSpace(o.QUICAddr) != "" {
+		o.configureQUICTLS()
+	}
 
 	o.ConfigureDataDir() // 数据目录
 	o.configureLog(vp)   // 日志配置
@@ -710,6 +1045,11 @@ func (o *Options) ConfigureWithViper(vp *viper.Viper) {
 		portInt64, _ := strconv.ParseInt(addrPairs[len(addrPairs)-1], 10, 64)
 		o.External.WSSAddr = fmt.Sprintf("%s://%s:%d", addrPairs[0], externalIp, portInt64)
 	}
+	if strings.TrimSpace(o.QUICAddr) != "" && strings.TrimSpace(o.External.QUICAddr) == "" {
+		addrPairs := strings.Split(o.QUICAddr, ":")
+		portInt64, _ := strconv.ParseInt(addrPairs[len(addrPairs)-1], 10, 64)
+		o.External.QUICAddr = fmt.Sprintf("%s://%s:%d", addrPairs[0], externalIp, portInt64)
+	}
 
 	if strings.TrimSpace(o.External.ManagerAddr) == "" {
 		addrPairs := strings.Split(o.Manager.Addr, ":")
@@ -748,7 +1088,7 @@ func (o *Options) ConfigureWithViper(vp *viper.Viper) {
 	o.Cluster.ReqTimeout = o.getDuration("cluster.reqTimeout", o.Cluster.ReqTimeout)
 	o.Cluster.Seed = o.getString("cluster.seed", o.Cluster.Seed)
 	o.Cluster.SlotCount = o.getInt("cluster.slotCount", o.Cluster.SlotCount)
-	nodes := o.getStringSlice("cluster.initNodes") // 格式为： nodeID@addr 例如 1@localhost:11110
+	nodes := o.getStringSlice("cluster.initNodes") // 格式为： nodeID@addr[@zone/rack] 例如 1@localhost:11110 或 1@localhost:11110@zone-a/rack-1
 	if len(nodes) > 0 {
 		for _, nodeStr := range nodes {
 			if !strings.Contains(nodeStr, "@") {
@@ -766,18 +1106,36 @@ func (o *Options) ConfigureWithViper(vp *viper.Viper) {
 				addr = fmt.Sprintf("%s:%s", addr, defaultPort)
 			}
 
+			var zone, rack string
+			if len(nodeStrs) > 2 {
+				zone, rack, _ = strings.Cut(nodeStrs[2], "/")
+			}
+
 			o.Cluster.InitNodes = append(o.Cluster.InitNodes, &Node{
 				Id:         nodeID,
 				ServerAddr: addr,
+				Zone:       zone,
+				Rack:       rack,
 			})
 		}
 	}
+	o.Cluster.Zone = o.getString("cluster.zone", o.Cluster.Zone)
+	o.Cluster.Rack = o.getString("cluster.rack", o.Cluster.Rack)
 	o.Cluster.TickInterval = o.getDuration("cluster.tickInterval", o.Cluster.TickInterval)
 	o.Cluster.ElectionIntervalTick = o.getInt("cluster.electionIntervalTick", o.Cluster.ElectionIntervalTick)
 	o.Cluster.HeartbeatIntervalTick = o.getInt("cluster.heartbeatIntervalTick", o.Cluster.HeartbeatIntervalTick)
 	o.Cluster.ChannelReactorSubCount = o.getInt("cluster.channelReactorSubCount", o.Cluster.ChannelReactorSubCount)
 	o.Cluster.SlotReactorSubCount = o.getInt("cluster.slotReactorSubCount", o.Cluster.SlotReactorSubCount)
 	o.Cluster.APIUrl = o.getString("cluster.apiUrl", o.Cluster.APIUrl)
+	o.Cluster.CertFile = o.getString("cluster.certFile", o.Cluster.CertFile)
+	o.Cluster.KeyFile = o.getString("cluster.keyFile", o.Cluster.KeyFile)
+	o.Cluster.CAFile = o.getString("cluster.caFile", o.Cluster.CAFile)
+	o.Cluster.Secret = o.getString("cluster.secret", o.Cluster.Secret)
+	o.Cluster.AllowUnknownNodes = o.getBool("cluster.allowUnknownNodes", o.Cluster.AllowUnknownNodes)
+	o.Cluster.FollowerRead = o.getBool("cluster.followerRead", o.Cluster.FollowerRead)
+	o.Cluster.ReadIndexWaitTimeout = o.getDuration("cluster.readIndexWaitTimeout", o.Cluster.ReadIndexWaitTimeout)
+	o.Cluster.DrainTimeout = o.getDuration("cluster.drainTimeout", o.Cluster.DrainTimeout)
+	o.configureClusterTLS()
 
 	// =================== trace ===================
 	o.Trace.ServiceName = o.getString("trace.serviceName", o.Trace.ServiceName)
@@ -820,6 +1178,9 @@ func (o *Options) ConfigureWithViper(vp *viper.Viper) {
 	o.Db.ShardNum = o.getInt("db.shardNum", o.Db.ShardNum)
 	o.Db.SlotShardNum = o.getInt("db.slotShardNum", o.Db.SlotShardNum)
 	o.Db.MemTableSize = o.getInt("db.memTableSize", o.Db.MemTableSize)
+	o.Db.EncryptionOn = o.getBool("db.encryption.on", o.Db.EncryptionOn)
+	o.Db.EncryptionKeyFile = o.getString("db.encryption.keyFile", o.Db.EncryptionKeyFile)
+	o.Db.EncryptionRotateInterval = o.getDuration("db.encryption.rotateInterval", o.Db.EncryptionRotateInterval)
 
 	// =================== auth ===================
 	o.configureAuth()
@@ -861,6 +1222,48 @@ func (o *Options) configureAuth() {
 		}
 	}
 
+	// =================== client jwt ===================
+	o.ClientJwt.On = o.getBool("clientJwt.on", o.ClientJwt.On)
+	o.ClientJwt.Algorithm = o.getString("clientJwt.algorithm", o.ClientJwt.Algorithm)
+	o.ClientJwt.Secret = o.getString("clientJwt.secret", o.ClientJwt.Secret)
+	o.ClientJwt.PublicKeyFile = o.getString("clientJwt.publicKeyFile", o.ClientJwt.PublicKeyFile)
+	o.ClientJwt.JwksFile = o.getString("clientJwt.jwksFile", o.ClientJwt.JwksFile)
+	o.ClientJwt.Issuer = o.getString("clientJwt.issuer", o.ClientJwt.Issuer)
+	o.ClientJwt.Audience = o.getString("clientJwt.audience", o.ClientJwt.Audience)
+	o.ClientJwt.UidClaim = o.getString("clientJwt.uidClaim", o.ClientJwt.UidClaim)
+	o.ClientJwt.FallbackDB = o.getBool("clientJwt.fallbackDB", o.ClientJwt.FallbackDB)
+
+	// =================== ipGuard ===================
+	o.IPGuard.On = o.getBool("ipGuard.on", o.IPGuard.On)
+	o.IPGuard.MaxConnsPerIp = o.getInt("ipGuard.maxConnsPerIp", o.IPGuard.MaxConnsPerIp)
+	o.IPGuard.ConnectRate = o.getFloat64("ipGuard.connectRate", o.IPGuard.ConnectRate)
+	o.IPGuard.ConnectBurst = o.getInt("ipGuard.connectBurst", o.IPGuard.ConnectBurst)
+	o.IPGuard.AuthFailThreshold = o.getInt("ipGuard.authFailThreshold", o.IPGuard.AuthFailThreshold)
+	o.IPGuard.AuthFailWindow = o.getDuration("ipGuard.authFailWindow", o.IPGuard.AuthFailWindow)
+	o.IPGuard.BanDuration = o.getDuration("ipGuard.banDuration", o.IPGuard.BanDuration)
+	if allowlist := o.getStringSlice("ipGuard.allowlist"); len(allowlist) > 0 {
+		o.IPGuard.Allowlist = allowlist
+	}
+	if denylist := o.getStringSlice("ipGuard.denylist"); len(denylist) > 0 {
+		o.IPGuard.Denylist = denylist
+	}
+	if trustedProxies := o.getStringSlice("ipGuard.trustedProxies"); len(trustedProxies) > 0 {
+		o.IPGuard.TrustedProxies = trustedProxies
+	}
+
+	// =================== wsCompression ===================
+	o.WSCompression.On = o.getBool("wsCompression.on", o.WSCompression.On)
+	o.WSCompression.Level = o.getInt("wsCompression.level", o.WSCompression.Level)
+	o.WSCompression.Threshold = o.getInt("wsCompression.threshold", o.WSCompression.Threshold)
+	o.WSCompression.ContextTakeover = o.getBool("wsCompression.contextTakeover", o.WSCompression.ContextTakeover)
+	o.WSCompression.MaxContextTakeoverConns = o.getInt("wsCompression.maxContextTakeoverConns", o.WSCompression.MaxContextTakeoverConns)
+	o.WSCompression.MaxMessageSize = o.getInt("wsCompression.maxMessageSize", o.WSCompression.MaxMessageSize)
+
+	// =================== audit ===================
+	o.Audit.On = o.getBool("audit.on", o.Audit.On)
+	o.Audit.Retention = o.getDuration("audit.retention", o.Audit.Retention)
+	o.Audit.CleanInterval = o.getDuration("audit.cleanInterval", o.Audit.CleanInterval)
+
 	// =================== auth ===================
 	o.Auth.On = o.getBool("auth.on", o.Auth.On)
 	o.Auth.SuperToken = o.getString("auth.superToken", o.Auth.SuperToken)
@@ -974,6 +1377,91 @@ func (o *Options) configureAuth() {
 	o.Auth.Users = usersCfgs
 }
 
+// quic的tls配置，quic必须使用tls1.3，没有配置quic的证书则使用wss的证书
+func (o *Options) configureQUICTLS() {
+	certFile, keyFile := o.QUICConfig.CertFile, o.QUICConfig.KeyFile
+	if certFile == "" || keyFile == "" {
+		certFile, keyFile = o.WSSConfig.CertFile, o.WSSConfig.KeyFile
+	}
+	if certFile == "" || keyFile == "" {
+		wklog.Panic("quic requires a certificate, please configure quicConfig.certFile and quicConfig.keyFile")
+	}
+	certificate, err := stdtls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		wklog.Panic("load quic cert failed", zap.Error(err))
+	}
+	o.QUICTLSConfig = &stdtls.Config{
+		Certificates: []stdtls.Certificate{certificate},
+		MinVersion:   stdtls.VersionTLS13,
+	}
+}
+
+// 节点通讯的tls配置
+func (o *Options) configureClusterTLS() {
+	if o.Cluster.CertFile == "" || o.Cluster.KeyFile == "" {
+		return
+	}
+	stdCert, err := stdtls.LoadX509KeyPair(o.Cluster.CertFile, o.Cluster.KeyFile)
+	if err != nil {
+		wklog.Panic("load cluster cert failed", zap.Error(err))
+	}
+	certificate, err := tls.LoadX509KeyPair(o.Cluster.CertFile, o.Cluster.KeyFile)
+	if err != nil {
+		wklog.Panic("load cluster cert failed", zap.Error(err))
+	}
+	o.Cluster.ServerTLSConfig = &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+	}
+	o.Cluster.ClientTLSConfig = &stdtls.Config{
+		Certificates: []stdtls.Certificate{stdCert},
+	}
+	if o.Cluster.CAFile == "" {
+		// 没有配置CA，仅加密传输，不校验证书
+		o.Cluster.ClientTLSConfig.InsecureSkipVerify = true
+		return
+	}
+	caData, err := os.ReadFile(o.Cluster.CAFile)
+	if err != nil {
+		wklog.Panic("read cluster ca file failed", zap.Error(err))
+	}
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(caData) {
+		wklog.Panic("append cluster ca failed", zap.String("caFile", o.Cluster.CAFile))
+	}
+	o.Cluster.ServerTLSConfig.ClientCAs = caPool
+	o.Cluster.ServerTLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	o.Cluster.ClientTLSConfig.RootCAs = caPool
+	// 节点通过ip访问，证书的CommonName为节点id，所以这里自定义校验证书链
+	o.Cluster.ClientTLSConfig.InsecureSkipVerify = true
+	o.Cluster.ClientTLSConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		return verifyCertChain(rawCerts, caPool)
+	}
+}
+
+func verifyCertChain(rawCerts [][]byte, roots *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return errors.New("no peer certificate")
+	}
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return err
+		}
+		certs = append(certs, cert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
 func (o *Options) ConfigureDataDir() {
 
 	// 数据目录
@@ -1006,6 +1494,14 @@ func (o *Options) ClusterOn() bool {
 	return o.Cluster.NodeId != 0
 }
 
+// NodeRequestSecret 节点之间http请求的签名密钥，优先使用节点之间的共享密钥，没有配置时使用管理者token
+func (o *Options) NodeRequestSecret() string {
+	if strings.TrimSpace(o.Cluster.Secret) != "" {
+		return o.Cluster.Secret
+	}
+	return o.ManagerToken
+}
+
 // 是否开启了 prometheus
 func (o *Options) PrometheusOn() bool {
 	return strings.TrimSpace(o.Trace.PrometheusApiUrl) != ""
@@ -1155,6 +1651,11 @@ func (o *Options) WebhookOn(event string) bool {
 	return strings.TrimSpace(o.Webhook.HTTPAddr) != "" || o.WebhookGRPCOn()
 }
 
+// WebhookChannelOn 是否开启了频道级别的webhook
+func (o *Options) WebhookChannelOn(event string) bool {
+	return o.Webhook.ChannelOn && o.isEventFocused(event)
+}
+
 // WebhookGRPCOn 是否配置了webhook grpc地址
 func (o *Options) WebhookGRPCOn() bool {
 	return strings.TrimSpace(o.Webhook.GRPCAddr) != ""
@@ -1165,6 +1666,11 @@ func (o *Options) HasDatasource() bool {
 	return strings.TrimSpace(o.Datasource.Addr) != ""
 }
 
+// DatasourceSubscriberOn 订阅者和黑白名单是否以数据源为准
+func (o *Options) DatasourceSubscriberOn() bool {
+	return o.HasDatasource() && o.Datasource.SubscriberOn
+}
+
 // 获取客服频道的访客id
 func (o *Options) GetCustomerServiceVisitorUID(channelID string) (string, bool) {
 	if !strings.Contains(channelID, "|") {
@@ -1246,6 +1752,8 @@ func (o *Options) IsLocalNode(nodeId uint64) bool {
 type Node struct {
 	Id         uint64
 	ServerAddr string
+	Zone       string // 可用区
+	Rack       string // 机架
 }
 
 type Option func(opts *Options)
@@ -1455,6 +1963,151 @@ func WithDatasourceChannelInfoOn(channelInfoOn bool) Option {
 	}
 }
 
+func WithDatasourceSubscriberOn(subscriberOn bool) Option {
+	return func(opts *Options) {
+		opts.Datasource.SubscriberOn = subscriberOn
+	}
+}
+
+func WithDatasourceCacheTTL(ttl time.Duration) Option {
+	return func(opts *Options) {
+		opts.Datasource.CacheTTL = ttl
+	}
+}
+
+func WithDatasourceTimeout(timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.Datasource.Timeout = timeout
+	}
+}
+
+func WithDatasourceMaxRetries(maxRetries int) Option {
+	return func(opts *Options) {
+		opts.Datasource.MaxRetries = maxRetries
+	}
+}
+
+func WithDatasourceBatchWindow(batchWindow time.Duration) Option {
+	return func(opts *Options) {
+		opts.Datasource.BatchWindow = batchWindow
+	}
+}
+
+func WithDatasourceBatchMaxSize(batchMaxSize int) Option {
+	return func(opts *Options) {
+		opts.Datasource.BatchMaxSize = batchMaxSize
+	}
+}
+
+func WithEventSinkOn(on bool) Option {
+	return func(opts *Options) {
+		opts.EventSink.On = on
+	}
+}
+
+func WithEventSinkFileOn(fileOn bool) Option {
+	return func(opts *Options) {
+		opts.EventSink.FileOn = fileOn
+	}
+}
+
+func WithEventSinkFileDir(fileDir string) Option {
+	return func(opts *Options) {
+		opts.EventSink.FileDir = fileDir
+	}
+}
+
+func WithEventSinkFileMaxSize(fileMaxSize int64) Option {
+	return func(opts *Options) {
+		opts.EventSink.FileMaxSize = fileMaxSize
+	}
+}
+
+func WithEventSinkFileMaxBackups(fileMaxBackups int) Option {
+	return func(opts *Options) {
+		opts.EventSink.FileMaxBackups = fileMaxBackups
+	}
+}
+
+func WithEventSinkHTTPAddr(httpAddr string) Option {
+	return func(opts *Options) {
+		opts.EventSink.HTTPAddr = httpAddr
+	}
+}
+
+func WithEventSinkTCPAddr(tcpAddr string) Option {
+	return func(opts *Options) {
+		opts.EventSink.TCPAddr = tcpAddr
+	}
+}
+
+func WithEventSinkTimeout(timeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.EventSink.Timeout = timeout
+	}
+}
+
+func WithEventSinkBatchSize(batchSize int) Option {
+	return func(opts *Options) {
+		opts.EventSink.BatchSize = batchSize
+	}
+}
+
+func WithEventSinkWorkerCount(workerCount int) Option {
+	return func(opts *Options) {
+		opts.EventSink.WorkerCount = workerCount
+	}
+}
+
+func WithEventSinkRetryInterval(retryInterval time.Duration) Option {
+	return func(opts *Options) {
+		opts.EventSink.RetryInterval = retryInterval
+	}
+}
+
+// WithEventSinks 添加自定义的Sink
+func WithEventSinks(sinks ...wksink.Sink) Option {
+	return func(opts *Options) {
+		opts.EventSink.Sinks = append(opts.EventSink.Sinks, sinks...)
+	}
+}
+
+func WithBotOn(on bool) Option {
+	return func(opts *Options) {
+		opts.Bot.On = on
+	}
+}
+
+func WithBotRateLimit(rateLimit float64) Option {
+	return func(opts *Options) {
+		opts.Bot.RateLimit = rateLimit
+	}
+}
+
+func WithBotRateBurst(rateBurst int) Option {
+	return func(opts *Options) {
+		opts.Bot.RateBurst = rateBurst
+	}
+}
+
+func WithBotCommandPrefix(commandPrefix string) Option {
+	return func(opts *Options) {
+		opts.Bot.CommandPrefix = commandPrefix
+	}
+}
+
+func WithBotQueueSize(queueSize int) Option {
+	return func(opts *Options) {
+		opts.Bot.QueueSize = queueSize
+	}
+}
+
+func WithBotCallbackTimeout(callbackTimeout time.Duration) Option {
+	return func(opts *Options) {
+		opts.Bot.CallbackTimeout = callbackTimeout
+	}
+}
+
 func WithWhitelistOffOfPerson(whitelistOffOfPerson bool) Option {
 	return func(opts *Options) {
 		opts.WhitelistOffOfPerson = whitelistOffOfPerson
@@ -1467,6 +2120,13 @@ func WithTokenAuthOn(tokenAuthOn bool) Option {
 	}
 }
 
+// WithTokenVerifier 自定义客户端连接的token校验
+func WithTokenVerifier(verifier TokenVerifier) Option {
+	return func(opts *Options) {
+		opts.TokenVerifier = verifier
+	}
+}
+
 func WithEventPoolSize(eventPoolSize int) Option {
 	return func(opts *Options) {
 		opts.EventPoolSize = eventPoolSize
@@ -1527,6 +2187,24 @@ func WithConversationSavePoolSize(savePoolSize int) Option {
 	}
 }
 
+func WithConversationSyncPageMaxSize(syncPageMaxSize int) Option {
+	return func(opts *Options) {
+		opts.Conversation.SyncPageMaxSize = syncPageMaxSize
+	}
+}
+
+func WithConversationSyncMaxMsgCount(syncMaxMsgCount int) Option {
+	return func(opts *Options) {
+		opts.Conversation.SyncMaxMsgCount = syncMaxMsgCount
+	}
+}
+
+func WithConversationUnreadMaxReceivers(unreadMaxReceivers int) Option {
+	return func(opts *Options) {
+		opts.Conversation.UnreadMaxReceivers = unreadMaxReceivers
+	}
+}
+
 func WithMessageRetryInterval(interval time.Duration) Option {
 	return func(opts *Options) {
 		opts.MessageRetry.Interval = interval
@@ -1551,6 +2229,30 @@ func WithMessageRetryWorkerCount(workerCount int) Option {
 	}
 }
 
+func WithSessionResumeOn(on bool) Option {
+	return func(opts *Options) {
+		opts.SessionResume.On = on
+	}
+}
+
+func WithSessionResumeTTL(ttl time.Duration) Option {
+	return func(opts *Options) {
+		opts.SessionResume.TTL = ttl
+	}
+}
+
+func WithSessionResumeMaxLifetime(maxLifetime time.Duration) Option {
+	return func(opts *Options) {
+		opts.SessionResume.MaxLifetime = maxLifetime
+	}
+}
+
+func WithSessionResumeMaxBufferMessages(maxBufferMessages int) Option {
+	return func(opts *Options) {
+		opts.SessionResume.MaxBufferMessages = maxBufferMessages
+	}
+}
+
 func WithWebhookHTTPAddr(httpAddr string) Option {
 	return func(opts *Options) {
 		opts.Webhook.HTTPAddr = httpAddr
@@ -1581,6 +2283,42 @@ func WithWebhookMsgNotifyEventRetryMaxCount(retryMaxCount int) Option {
 	}
 }
 
+func WithWebhookChannelOn(channelOn bool) Option {
+	return func(opts *Options) {
+		opts.Webhook.ChannelOn = channelOn
+	}
+}
+
+func WithWebhookChannelQueueSize(queueSize int) Option {
+	return func(opts *Options) {
+		opts.Webhook.ChannelQueueSize = queueSize
+	}
+}
+
+func WithWebhookSecret(secret string) Option {
+	return func(opts *Options) {
+		opts.Webhook.Secret = secret
+	}
+}
+
+func WithWebhookRetryBackoff(backoff time.Duration) Option {
+	return func(opts *Options) {
+		opts.Webhook.RetryBackoff = backoff
+	}
+}
+
+func WithWebhookRetryMaxBackoff(maxBackoff time.Duration) Option {
+	return func(opts *Options) {
+		opts.Webhook.RetryMaxBackoff = maxBackoff
+	}
+}
+
+func WithWebhookRetryQueueSize(queueSize int) Option {
+	return func(opts *Options) {
+		opts.Webhook.RetryQueueSize = queueSize
+	}
+}
+
 func WithClusterNodeId(nodeId uint64) Option {
 	return func(opts *Options) {
 		opts.Cluster.NodeId = nodeId
@@ -1750,6 +2488,32 @@ func WithDbSlotShardNum(slotShardNum int) Option {
 	}
 }
 
+func WithDbEncryptionOn(on bool) Option {
+	return func(opts *Options) {
+		opts.Db.EncryptionOn = on
+	}
+}
+
+func WithDbEncryptionKeyFile(keyFile string) Option {
+	return func(opts *Options) {
+		opts.Db.EncryptionKeyFile = keyFile
+	}
+}
+
+func WithDbEncryptionRotateInterval(interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.Db.EncryptionRotateInterval = interval
+	}
+}
+
+// WithDbEncryptionKeyProvider 自定义主密钥提供者（例如对接KMS）
+func WithDbEncryptionKeyProvider(provider wkdb.KeyProvider) Option {
+	return func(opts *Options) {
+		opts.Db.EncryptionOn = true
+		opts.Db.EncryptionKeyProvider = provider
+	}
+}
+
 func WithOpts(opt ...Option) Option {
 	return func(opts *Options) {
 		for _, o := range opt {
@@ -52,6 +52,13 @@ func (s *Server) onData(conn wknet.Conn) error {
 
 	if !isAuth {
 
+		// 受信任代理的连接，此时已经获取到了真实IP
+		if !s.guardRealIp(conn) {
+			s.Debug("conn is rejected by ip guard", zap.String("remoteAddr", conn.RemoteAddr().String()))
+			conn.Close()
+			return nil
+		}
+
 		// 解析连接包
 		packet, _, err := s.opts.Proto.DecodeFrame(data, wkproto.LatestVersion)
 		if err != nil {
@@ -67,6 +67,15 @@ func (r *retryManager) retryMessage(connId int64, messageId int64) *retryMessage
 	return r.retryQueues[index].getInFlightMessage(connId, messageId)
 }
 
+// takeRetryMessagesByConn 取出连接所有等待重试的消息
+func (r *retryManager) takeRetryMessagesByConn(connId int64) []*retryMessage {
+	var msgs []*retryMessage
+	for _, retryQueue := range r.retryQueues {
+		msgs = append(msgs, retryQueue.takeInFlightMessagesByConn(connId)...)
+	}
+	return msgs
+}
+
 func (r *retryManager) retry(msg *retryMessage) {
 	r.Debug("retry msg", zap.Int("retryCount", msg.retry), zap.String("uid", msg.uid), zap.Int64("messageId", msg.messageId), zap.Int64("connId", msg.connId))
 	msg.retry++
@@ -76,11 +85,17 @@ func (r *retryManager) retry(msg *retryMessage) {
 	}
 	userHandler := r.s.userReactor.getUserHandler(msg.uid)
 	if userHandler == nil {
+		if r.s.opts.SessionResume.On && r.s.resumeManager.buffer(msg) { // 缓存起来，等待会话恢复后投递
+			return
+		}
 		r.Debug("user offline, retry end", zap.String("uid", msg.uid), zap.Int64("messageId", msg.messageId), zap.Int64("connId", msg.connId))
 		return
 	}
 	conn := userHandler.getConnById(msg.connId)
 	if conn == nil {
+		if r.s.opts.SessionResume.On && r.s.resumeManager.buffer(msg) {
+			return
+		}
 		r.Debug("conn offline", zap.String("uid", msg.uid), zap.Int64("messageId", msg.messageId), zap.Int64("connId", msg.connId))
 		return
 	}
@@ -131,6 +131,24 @@ func (r *RetryQueue) processInFlightQueue(t int64) {
 	}
 }
 
+// takeInFlightMessagesByConn 取出连接的所有飞行中的消息
+func (r *RetryQueue) takeInFlightMessagesByConn(connId int64) []*retryMessage {
+	r.inFlightMutex.Lock()
+	defer r.inFlightMutex.Unlock()
+	var msgs []*retryMessage
+	for key, msg := range r.inFlightMessages {
+		if msg.connId != connId {
+			continue
+		}
+		delete(r.inFlightMessages, key)
+		if msg.index != -1 {
+			r.inFlightPQ.Remove(msg.index)
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
 // inFlightMessagesCount 返回正在飞行的消息数量
 func (r *RetryQueue) inFlightMessagesCount() int {
 	r.inFlightMutex.Lock()
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"net"
 	"os"
 	"path"
 	"path/filepath"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/RussellLuo/timingwheel"
+	"github.com/WuKongIM/WuKongIM/pkg/auth"
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
 	cluster "github.com/WuKongIM/WuKongIM/pkg/cluster/clusterserver"
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterstore"
@@ -19,6 +21,7 @@ import (
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/replica"
 	"github.com/WuKongIM/WuKongIM/pkg/promtail"
 	"github.com/WuKongIM/WuKongIM/pkg/trace"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
 	"github.com/WuKongIM/WuKongIM/pkg/wklog"
 	"github.com/WuKongIM/WuKongIM/pkg/wknet"
 	"github.com/WuKongIM/WuKongIM/pkg/wkserver/proto"
@@ -64,12 +67,21 @@ type Server struct {
 	tagManager     *tagManager     // tag管理，用来管理频道订阅者的tag，用于快速查找订阅者所在节点
 	deliverManager *deliverManager // 消息投递管理
 	retryManager   *retryManager   // 消息重试管理
+	auditLogger    *auditLogger    // 审计日志
+	eventSink      *eventSink      // 事件流
+	botManager     *botManager     // 服务端机器人
 
 	conversationManager *ConversationManager // 会话管理
 
 	migrateTask *MigrateTask // 迁移任务
 
-	datasource IDatasource // 数据源
+	datasource *datasourceCache // 数据源（带本地缓存）
+
+	tokenVerifier TokenVerifier // 客户端连接的token校验
+
+	ipGuard *ipGuard // 连接层的IP防护
+
+	resumeManager *resumeManager // 会话恢复管理
 
 	promtailServer *promtail.Promtail // 日志收集, 负责收集WuKongIM的日志 上报给Loki
 
@@ -110,12 +122,35 @@ func New(opts *Options) *Server {
 	storeOpts.SlotCount = uint32(s.opts.Cluster.SlotCount)
 	storeOpts.GetSlotId = s.getSlotId
 	storeOpts.IsCmdChannel = opts.IsCmdChannel
+	storeOpts.OnApplied = s.onStoreApplied
 	storeOpts.Db.ShardNum = s.opts.Db.ShardNum
 	storeOpts.Db.MemTableSize = s.opts.Db.MemTableSize
+	if s.opts.Db.EncryptionOn {
+		keyProvider := s.opts.Db.EncryptionKeyProvider
+		if keyProvider == nil {
+			keyProvider, err = wkdb.NewFileKeyProvider(s.opts.Db.EncryptionKeyFile)
+			if err != nil {
+				s.Panic("load db encryption key file failed", zap.Error(err), zap.String("keyFile", s.opts.Db.EncryptionKeyFile))
+			}
+		}
+		storeOpts.Db.EncryptionKeyProvider = keyProvider
+		storeOpts.Db.EncryptionRotateInterval = s.opts.Db.EncryptionRotateInterval
+	}
 	s.store = clusterstore.NewStore(storeOpts)
 
 	// 数据源
-	s.datasource = NewDatasource(s)
+	s.datasource = newDatasourceCache(NewDatasource(s), s.opts.Datasource.CacheTTL)
+
+	// 客户端连接的token校验
+	s.tokenVerifier = newTokenVerifier(s)
+
+	// 连接层的IP防护
+	ipGuard, err := newIPGuard(s.opts)
+	if err != nil {
+		s.Panic("create ip guard failed", zap.Error(err))
+	}
+	s.ipGuard = ipGuard
+
 	// 初始化tag管理
 	s.tagManager = newTagManager(s)
 
@@ -125,12 +160,29 @@ func New(opts *Options) *Server {
 		wknet.WithWSAddr(s.opts.WSAddr),
 		wknet.WithWSSAddr(s.opts.WSSAddr),
 		wknet.WithWSTLSConfig(s.opts.WSTLSConfig),
+		wknet.WithQUICAddr(s.opts.QUICAddr),
+		wknet.WithQUICTLSConfig(s.opts.QUICTLSConfig),
+		wknet.WithQUICMaxIdleTimeout(s.opts.QUICConfig.MaxIdleTimeout),
+		wknet.WithTrustForwardedFrom(func(remoteAddr net.Addr) bool {
+			return s.ipGuard.trustForwarded(remoteAddr)
+		}),
 		wknet.WithOnReadBytes(func(n int) {
 			trace.GlobalTrace.Metrics.System().ExtranetIncomingAdd(int64(n))
 		}),
 		wknet.WithOnWirteBytes(func(n int) {
 			trace.GlobalTrace.Metrics.System().ExtranetOutgoingAdd(int64(n))
 		}),
+		wknet.WithWSCompression(s.opts.WSCompression.On),
+		wknet.WithWSCompressionLevel(s.opts.WSCompression.Level),
+		wknet.WithWSCompressionThreshold(s.opts.WSCompression.Threshold),
+		wknet.WithWSCompressionContextTakeover(s.opts.WSCompression.ContextTakeover, s.opts.WSCompression.MaxContextTakeoverConns),
+		wknet.WithWSCompressionMaxMessageSize(s.opts.WSCompression.MaxMessageSize),
+		wknet.WithOnWSCompress(func(rawSize, compressedSize int) {
+			trace.GlobalTrace.Metrics.System().WSCompressAdd(int64(rawSize), int64(compressedSize))
+		}),
+		wknet.WithOnWSDecompress(func(compressedSize, rawSize int) {
+			trace.GlobalTrace.Metrics.System().WSDecompressAdd(int64(compressedSize), int64(rawSize))
+		}),
 	)
 	s.webhook = newWebhook(s)                         // webhook
 	s.channelReactor = newChannelReactor(s, opts)     // 频道的reactor
@@ -140,17 +192,30 @@ func New(opts *Options) *Server {
 	s.apiServer = NewAPIServer(s)                     // api服务
 	s.managerServer = NewManagerServer(s)             // 管理者的api服务
 	s.retryManager = newRetryManager(s)               // 消息重试管理
+	s.resumeManager = newResumeManager(s)             // 会话恢复管理
+	s.auditLogger = newAuditLogger(s)                 // 审计日志
+	s.eventSink = newEventSink(s)                     // 事件流
+	s.botManager = newBotManager(s)                   // 服务端机器人
 	s.conversationManager = NewConversationManager(s) // 会话管理
 	s.migrateTask = NewMigrateTask(s)                 // 迁移任务
 
 	// 初始化分布式服务
 	initNodes := make(map[uint64]string)
+	initNodeTopologies := make(map[uint64]pb.Topology)
 	if len(s.opts.Cluster.InitNodes) > 0 {
 		for _, node := range s.opts.Cluster.InitNodes {
 			serverAddr := strings.ReplaceAll(node.ServerAddr, "tcp://", "")
 			initNodes[node.Id] = serverAddr
+			initNodeTopologies[node.Id] = pb.Topology{
+				Zone: node.Zone,
+				Rack: node.Rack,
+			}
 		}
 	}
+	// 管理后台的用户和角色保存在集群配置中
+	s.opts.Auth.Store = newManagerAuthStore(s)
+	s.opts.Auth.Cache = auth.NewPermissionCache()
+
 	role := pb.NodeRole_NodeRoleReplica
 	if s.opts.Cluster.Role == RoleProxy {
 		role = pb.NodeRole_NodeRoleProxy
@@ -162,6 +227,9 @@ func New(opts *Options) *Server {
 			cluster.WithDataDir(path.Join(opts.DataDir, "cluster")),
 			cluster.WithSlotCount(uint32(s.opts.Cluster.SlotCount)),
 			cluster.WithInitNodes(initNodes),
+			cluster.WithInitNodeTopologies(initNodeTopologies),
+			cluster.WithZone(s.opts.Cluster.Zone),
+			cluster.WithRack(s.opts.Cluster.Rack),
 			cluster.WithSeed(s.opts.Cluster.Seed),
 			cluster.WithRole(role),
 			cluster.WithServerAddr(s.opts.Cluster.ServerAddr),
@@ -188,6 +256,12 @@ func New(opts *Options) *Server {
 			cluster.WithServiceName(s.opts.Trace.ServiceName),
 			cluster.WithLokiUrl(s.opts.Logger.Loki.Url),
 			cluster.WithLokiJob(s.opts.Logger.Loki.Job),
+			cluster.WithTLSConfig(s.opts.Cluster.ServerTLSConfig),
+			cluster.WithClientTLSConfig(s.opts.Cluster.ClientTLSConfig),
+			cluster.WithNodeSecret(s.opts.Cluster.Secret),
+			cluster.WithAllowUnknownNodes(s.opts.Cluster.AllowUnknownNodes),
+			cluster.WithReadIndexWaitTimeout(s.opts.Cluster.ReadIndexWaitTimeout),
+			cluster.WithDrainTimeout(s.opts.Cluster.DrainTimeout),
 		),
 
 		// cluster.WithOnChannelMetaApply(func(channelID string, channelType uint8, logs []replica.Log) error {
@@ -253,6 +327,9 @@ func (s *Server) Start() error {
 	if s.opts.WSSAddr != "" {
 		s.Info(fmt.Sprintf("Listening  for WSS client on %s", s.opts.WSSAddr))
 	}
+	if s.opts.QUICAddr != "" {
+		s.Info(fmt.Sprintf("Listening  for QUIC client on %s", s.opts.QUICAddr))
+	}
 	s.Info(fmt.Sprintf("Listening  for Manager http api on %s", fmt.Sprintf("http://%s", s.opts.HTTPAddr)))
 
 	if s.opts.Manager.On {
@@ -285,6 +362,8 @@ func (s *Server) Start() error {
 		return err
 	}
 
+	s.auditLogger.start()
+
 	s.apiServer.Start()
 
 	s.managerServer.Start()
@@ -299,6 +378,11 @@ func (s *Server) Start() error {
 		return err
 	}
 
+	s.ipGuard.start()
+
+	s.datasource.start()
+
+	s.engine.OnAccept(s.onAccept)
 	s.engine.OnConnect(s.onConnect)
 	s.engine.OnData(s.onData)
 	s.engine.OnClose(s.onClose)
@@ -322,6 +406,8 @@ func (s *Server) Start() error {
 		return err
 	}
 
+	s.resumeManager.start()
+
 	if s.opts.Conversation.On {
 		err = s.conversationManager.Start()
 		if err != nil {
@@ -331,6 +417,11 @@ func (s *Server) Start() error {
 
 	s.webhook.Start()
 
+	err = s.eventSink.start()
+	if err != nil {
+		return err
+	}
+
 	// 判断是否开启迁移任务
 	if strings.TrimSpace(s.opts.OldV1Api) != "" {
 		s.migrateTask.Run()
@@ -360,6 +451,7 @@ func (s *Server) Stop() error {
 	s.deliverManager.stop()
 
 	s.retryManager.stop()
+	s.resumeManager.stop()
 
 	if s.opts.Conversation.On {
 		s.conversationManager.Stop()
@@ -368,6 +460,12 @@ func (s *Server) Stop() error {
 	s.cluster.Stop()
 	s.apiServer.Stop()
 
+	s.auditLogger.stop()
+
+	s.ipGuard.stop()
+
+	s.datasource.stop()
+
 	_ = s.managerServer.Stop()
 
 	if s.opts.Demo.On {
@@ -376,6 +474,12 @@ func (s *Server) Stop() error {
 	s.channelReactor.stop()
 	s.userReactor.stop()
 
+	s.eventSink.stop()
+	s.botManager.stop()
+
+	// webhook停止时会把未完成的推送写入存储，需要在关闭存储之前停止
+	s.webhook.Stop()
+
 	err := s.engine.Stop()
 	if err != nil {
 		s.Error("engine stop error", zap.Error(err))
@@ -388,8 +492,6 @@ func (s *Server) Stop() error {
 
 	s.tagManager.stop()
 
-	s.webhook.Stop()
-
 	if s.opts.LokiOn() {
 		s.promtailServer.Stop()
 	}
@@ -427,6 +529,16 @@ func (s *Server) onConnect(conn wknet.Conn) error {
 	conn.SetMaxIdle(time.Second * 2) // 在认证之前，连接最多空闲2秒
 	s.trace.Metrics.App().ConnCountAdd(1)
 
+	if !s.guardConn(conn) {
+		s.Debug("conn is rejected by ip guard", zap.String("remoteAddr", conn.RemoteAddr().String()))
+		_ = conn.Close()
+		return nil
+	}
+
+	// 只解析受信任代理的代理协议，其他连接的代理协议头不能改变连接的IP
+	if !s.ipGuard.trustForwarded(conn.RemoteAddr()) {
+		return nil
+	}
 	if conn.InboundBuffer().BoundBufferSize() == 0 {
 		conn.SetValue(ConnKeyParseProxyProto, true) // 设置需要解析代理协议
 		return nil
@@ -466,10 +578,12 @@ func (s *Server) onConnect(conn wknet.Conn) error {
 
 func (s *Server) onClose(conn wknet.Conn) {
 	s.trace.Metrics.App().ConnCountAdd(-1)
+	s.unguardConn(conn)
 	connCtxObj := conn.Context()
 	if connCtxObj != nil {
 		connCtx := connCtxObj.(*connContext)
 		s.userReactor.removeConnById(connCtx.uid, connCtx.connId)
+		s.resumeManager.disconnect(connCtx.connId)
 
 		if connCtx.isAuth.Load() {
 			deviceOnlineCount := s.userReactor.getConnCountByDeviceFlag(connCtx.uid, connCtx.deviceFlag)
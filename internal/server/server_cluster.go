@@ -5,6 +5,8 @@ import (
 	"errors"
 	"time"
 
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
 	"github.com/WuKongIM/WuKongIM/pkg/wkserver"
 	"github.com/WuKongIM/WuKongIM/pkg/wkserver/proto"
 	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
@@ -61,6 +63,11 @@ func (s *Server) setClusterRoutes() {
 	// 频道重新创建ReceiverTag
 	s.cluster.Route("/wk/makeReceiverTag", s.handleMakeReceiverTag)
 
+	// 获取频道配置的webhook地址
+	s.cluster.Route("/wk/getChannelWebhook", s.handleGetChannelWebhook)
+	// 获取事件流频道的投递位置
+	s.cluster.Route("/wk/getEventSinkCheckpoint", s.handleGetEventSinkCheckpoint)
+
 }
 
 func (s *Server) handleChannelForward(c *wkserver.Context) {
@@ -286,6 +293,9 @@ func (s *Server) handleUserAuthResult(c *wkserver.Context) {
 		_ = connCtx.writePacket(connack)
 	} else {
 		connCtx.isAuth.Store(false)
+		if authResult.ReasonCode == wkproto.ReasonAuthFail {
+			s.guardAuthFailed(connCtx.conn)
+		}
 		_ = connCtx.writePacket(&wkproto.ConnackPacket{
 			ReasonCode: authResult.ReasonCode,
 			NodeId:     s.opts.Cluster.NodeId,
@@ -530,6 +540,23 @@ func (s *Server) handleGetSubscribers(c *wkserver.Context) {
 	c.Write(resps.Marshal())
 }
 
+func (s *Server) handleGetChannelWebhook(c *wkserver.Context) {
+	req := &channelReq{}
+	err := req.Unmarshal(c.Body())
+	if err != nil {
+		s.Error("handleGetChannelWebhook Unmarshal err", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	addr, err := s.webhook.localChannelWebhook(req.ChannelId, req.ChannelType)
+	if err != nil {
+		s.Error("handleGetChannelWebhook: get channel failed", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	c.Write([]byte(addr))
+}
+
 func (s *Server) handleMakeReceiverTag(c *wkserver.Context) {
 	req := &channelReq{}
 	err := req.Unmarshal(c.Body())
@@ -552,3 +579,53 @@ func (s *Server) handleMakeReceiverTag(c *wkserver.Context) {
 	}
 	c.WriteOk()
 }
+
+// 获取读取频道数据的节点，开启跟随者读时，本地副本已应用到knownSeq则由本节点处理
+// strict为true时，本地副本需要应用到领导的读索引才能处理
+func (s *Server) nodeOfChannelForRead(channelId string, channelType uint8, knownSeq uint64, strict bool) (*pb.Node, error) {
+	if !s.opts.Cluster.FollowerRead {
+		return s.cluster.LeaderOfChannelForRead(channelId, channelType)
+	}
+	timeoutCtx, cancel := context.WithTimeout(s.ctx, s.opts.Cluster.ReqTimeout)
+	defer cancel()
+	return s.cluster.ReplicaOfChannelForRead(timeoutCtx, channelId, channelType, knownSeq, strict)
+}
+
+// 跟随者读时，去掉本地副本还未应用（未提交）的消息
+func (s *Server) trimUnappliedMessages(channelId string, channelType uint8, messages []wkdb.Message) ([]wkdb.Message, error) {
+	if !s.opts.ClusterOn() || !s.opts.Cluster.FollowerRead || len(messages) == 0 {
+		return messages, nil
+	}
+	appliedIndex, err := s.cluster.ChannelAppliedIndex(channelId, channelType)
+	if err != nil {
+		return nil, err
+	}
+	newMessages := make([]wkdb.Message, 0, len(messages))
+	for _, message := range messages {
+		if uint64(message.MessageSeq) > appliedIndex {
+			continue
+		}
+		newMessages = append(newMessages, message)
+	}
+	return newMessages, nil
+}
+
+// handleGetEventSinkCheckpoint 获取本节点保存的频道投递位置
+func (s *Server) handleGetEventSinkCheckpoint(c *wkserver.Context) {
+	req := &eventSinkCheckpointReq{}
+	if err := req.Unmarshal(c.Body()); err != nil {
+		s.Error("handleGetEventSinkCheckpoint Unmarshal err", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	checkpoint, err := s.store.GetEventSinkCheckpoint(req.Sink, req.ChannelId, req.ChannelType)
+	if err != nil {
+		s.Error("handleGetEventSinkCheckpoint: get checkpoint failed", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+	enc.WriteUint64(checkpoint)
+	c.Write(enc.Bytes())
+}
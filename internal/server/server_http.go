@@ -2,7 +2,6 @@ package server
 
 import (
 	"net/http"
-	"strings"
 
 	cluster "github.com/WuKongIM/WuKongIM/pkg/cluster/clusterserver"
 	"github.com/WuKongIM/WuKongIM/pkg/trace"
@@ -15,47 +14,46 @@ import (
 
 // APIServer ApiServer
 type APIServer struct {
-	r    *wkhttp.WKHttp
-	addr string
-	s    *Server
+	r          *wkhttp.WKHttp
+	addr       string
+	s          *Server
+	apiKeyAuth *apiKeyAuth // 访问密钥认证
 	wklog.Log
 }
 
 // NewAPIServer new一个api server
 func NewAPIServer(s *Server) *APIServer {
 	r := wkhttp.New()
+	r.SetNodeSigner(wkhttp.NewNodeSigner(s.opts.NodeRequestSecret()))
 
 	if s.opts.PprofOn {
 		pprof.Register(r.GetGinRoute()) // 注册pprof
 	}
 
 	hs := &APIServer{
-		r:    r,
-		addr: s.opts.HTTPAddr,
-		s:    s,
-		Log:  wklog.NewWKLog("APIServer"),
+		r:          r,
+		addr:       s.opts.HTTPAddr,
+		s:          s,
+		apiKeyAuth: newApiKeyAuth(s),
+		Log:        wklog.NewWKLog("APIServer"),
 	}
 	return hs
 }
 
+// nodeRequestHeaders 请求其他节点的api时带上节点签名
+func (s *APIServer) nodeRequestHeaders(method string, reqURL string, body []byte) map[string]string {
+	return s.r.NodeSigner().SignHeaders(nil, method, reqURL, body)
+}
+
 // Start 开始
 func (s *APIServer) Start() {
 
-	s.r.Use(func(c *wkhttp.Context) { // 管理者权限判断
-		if strings.TrimSpace(s.s.opts.ManagerToken) == "" {
-			c.Next()
-			return
-		}
-		managerToken := c.GetHeader("token")
-		if managerToken != s.s.opts.ManagerToken {
-			c.AbortWithStatus(http.StatusUnauthorized)
-			return
-		}
-		c.Next()
-	})
-
-	// 跨域
+	// 跨域，需要在认证之前，预检请求（OPTIONS）不带认证信息
 	s.r.Use(wkhttp.CORSMiddleware())
+
+	s.r.Use(s.s.auditLogger.middleware()) // 审计日志
+	s.r.Use(s.apiKeyAuth.middleware())    // 管理者token和访问密钥权限判断
+
 	// 带宽流量计算中间件
 	s.r.Use(bandwidthMiddleware())
 
@@ -115,6 +113,38 @@ func (s *APIServer) setRoutes() {
 	stream := NewStreamAPI(s.s)
 	stream.Route(s.r)
 
+	// 端到端加密的公钥目录api
+	e2ee := NewE2EEAPI(s.s)
+	e2ee.Route(s.r)
+
+	// 数据源缓存api
+	datasource := NewDatasourceAPI(s.s)
+	datasource.Route(s.r)
+
+	// 访问密钥api
+	apiKey := NewApiKeyAPI(s.s)
+	apiKey.Route(s.r)
+
+	// ip防护api
+	ipGuard := NewIPGuardAPI(s.s)
+	ipGuard.Route(s.r)
+
+	// 审计日志api
+	audit := NewAuditAPI(s.s, false)
+	audit.Route(s.r)
+
+	// 静态加密api
+	encryption := NewEncryptionAPI(s.s)
+	encryption.Route(s.r)
+
+	// webhook死信api
+	webhookAPI := NewWebhookAPI(s.s)
+	webhookAPI.Route(s.r)
+
+	// 服务端机器人api
+	bot := NewBotAPI(s.s)
+	bot.Route(s.r)
+
 	// 压测api
 	if s.s.opts.Stress {
 		stress := NewStressAPI(s.s)
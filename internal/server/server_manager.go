@@ -38,6 +38,8 @@ func NewManagerServer(s *Server) *ManagerServer {
 func (m *ManagerServer) Start() {
 
 	m.r.Use(wkhttp.CORSMiddleware())
+	// 审计日志
+	m.r.Use(m.s.auditLogger.middleware())
 	// jwt和token认证中间件
 	m.r.Use(m.jwtAndTokenAuthMiddleware())
 
@@ -90,6 +92,10 @@ func (m *ManagerServer) setRoutes() {
 	manager := NewManagerAPI(m.s)
 	manager.Route(m.r)
 
+	// 审计日志api
+	audit := NewAuditAPI(m.s, true)
+	audit.Route(m.r)
+
 	// 压测api
 	if m.s.opts.Stress {
 		stress := NewStressAPI(m.s)
@@ -0,0 +1,307 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/lni/goutils/syncutil"
+	"go.uber.org/zap"
+)
+
+const (
+	// 客户端使用票据恢复会话时，token填写 resumeTokenPrefix + 票据
+	resumeTokenPrefix = "resume:"
+	// connack的ServerKey中服务端公钥与票据的分隔符（base64不包含此字符）
+	// 格式：<服务端DH公钥>.<票据>，恢复会话成功时服务端DH公钥为空，客户端继续使用原来的aesKey
+	resumeTicketSeparator = "."
+	// 客户端在connect的ClientKey后追加此标记（<客户端DH公钥>.resume）表示支持会话恢复，
+	// 只有带此标记的连接才会在connack的ServerKey中返回票据，旧版本客户端不受影响
+	resumeClientKeyFlag = resumeTicketSeparator + "resume"
+)
+
+// resumeManager 会话恢复管理
+// 认证成功后给声明支持会话恢复的连接签发一个票据，连接断开后在有效期内，客户端可以凭票据重连，
+// 跳过token校验和DH密钥交换，沿用原来的aesKey和aesIV，并收到断开时重试队列里的消息和断开期间投递的消息
+// 票据只保存在处理认证的节点（用户的领导节点）内存中，领导变更或节点重启后票据失效，客户端需要走完整的认证
+type resumeManager struct {
+	s *Server
+
+	mu       sync.Mutex
+	sessions map[string]*resumeSession            // 票据 -> 会话
+	conns    map[int64]*resumeSession             // 连接id -> 会话
+	users    map[string]map[string]*resumeSession // 用户uid -> 票据 -> 会话
+
+	stopper *syncutil.Stopper
+	wklog.Log
+}
+
+type resumeSession struct {
+	ticket       string
+	uid          string
+	deviceId     string
+	deviceFlag   wkproto.DeviceFlag
+	deviceLevel  wkproto.DeviceLevel
+	protoVersion uint8
+	aesKey       []byte
+	aesIV        []byte
+
+	issuedAt       time.Time       // 签发时间，超过MaxLifetime后票据失效
+	connId         int64           // 当前绑定的连接id
+	disconnectedAt time.Time       // 连接断开的时间，为零表示连接还在线
+	buffered       []*retryMessage // 断开期间缓存的待投递消息
+}
+
+func newResumeManager(s *Server) *resumeManager {
+	return &resumeManager{
+		s:        s,
+		sessions: make(map[string]*resumeSession),
+		conns:    make(map[int64]*resumeSession),
+		users:    make(map[string]map[string]*resumeSession),
+		stopper:  syncutil.NewStopper(),
+		Log:      wklog.NewWKLog("resumeManager"),
+	}
+}
+
+func (r *resumeManager) start() {
+	if !r.s.opts.SessionResume.On {
+		return
+	}
+	r.stopper.RunWorker(r.cleanLoop)
+}
+
+func (r *resumeManager) stop() {
+	r.stopper.Stop()
+}
+
+// issue 给认证成功的连接签发票据
+func (r *resumeManager) issue(connCtx *connContext) string {
+	ticket := genResumeTicket()
+	session := &resumeSession{
+		ticket:       ticket,
+		uid:          connCtx.uid,
+		deviceId:     connCtx.deviceId,
+		deviceFlag:   connCtx.deviceFlag,
+		deviceLevel:  connCtx.deviceLevel,
+		protoVersion: connCtx.protoVersion,
+		aesKey:       connCtx.aesKey,
+		aesIV:        connCtx.aesIV,
+		issuedAt:     time.Now(),
+		connId:       connCtx.connId,
+	}
+	r.mu.Lock()
+	// 同一设备已经断开的旧会话不会再被恢复，不再为其缓存消息
+	for _, old := range r.users[session.uid] {
+		if old.deviceId == session.deviceId && !old.disconnectedAt.IsZero() {
+			r.removeSession(old)
+		}
+	}
+	r.sessions[ticket] = session
+	r.conns[connCtx.connId] = session
+	userSessions := r.users[session.uid]
+	if userSessions == nil {
+		userSessions = make(map[string]*resumeSession)
+		r.users[session.uid] = userSessions
+	}
+	userSessions[ticket] = session
+	r.mu.Unlock()
+	return ticket
+}
+
+// take 校验并取出票据对应的会话，票据只能使用一次
+func (r *resumeManager) take(ticket string, connectPacket *wkproto.ConnectPacket, protoVersion uint8) *resumeSession {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session := r.sessions[ticket]
+	if session == nil {
+		return nil
+	}
+	if session.uid != connectPacket.UID || session.deviceId != connectPacket.DeviceID || session.deviceFlag != connectPacket.DeviceFlag || session.protoVersion != protoVersion {
+		return nil
+	}
+	if r.expired(session, time.Now()) {
+		r.removeSession(session)
+		return nil
+	}
+	r.removeSession(session)
+	return session
+}
+
+// expired 票据是否过期：超过签发后的最长有效期，或者连接断开超过TTL
+func (r *resumeManager) expired(session *resumeSession, now time.Time) bool {
+	if r.s.opts.SessionResume.MaxLifetime > 0 && now.Sub(session.issuedAt) > r.s.opts.SessionResume.MaxLifetime {
+		return true
+	}
+	return !session.disconnectedAt.IsZero() && now.Sub(session.disconnectedAt) > r.s.opts.SessionResume.TTL
+}
+
+// revoke 作废用户指定设备的所有票据（强制设备退出、更新token、master设备互踢时调用），缓存的消息一起丢弃
+func (r *resumeManager) revoke(uid string, deviceFlag wkproto.DeviceFlag) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, session := range r.users[uid] {
+		if session.deviceFlag == deviceFlag {
+			r.removeSession(session)
+		}
+	}
+}
+
+// disconnect 连接断开，开始计算票据有效期
+func (r *resumeManager) disconnect(connId int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session := r.conns[connId]
+	if session != nil && session.disconnectedAt.IsZero() {
+		session.disconnectedAt = time.Now()
+	}
+}
+
+// buffer 连接已断开的重试消息缓存到会话中，等待会话恢复后投递
+// 返回false表示连接没有可恢复的会话
+func (r *resumeManager) buffer(msg *retryMessage) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session := r.conns[msg.connId]
+	if session == nil {
+		return false
+	}
+	if session.disconnectedAt.IsZero() {
+		session.disconnectedAt = time.Now()
+	}
+	if len(session.buffered) >= r.s.opts.SessionResume.MaxBufferMessages {
+		r.Debug("resume buffer is full", zap.String("uid", msg.uid), zap.Int64("messageId", msg.messageId), zap.Int64("connId", msg.connId))
+		return false
+	}
+	session.buffered = append(session.buffered, msg)
+	return true
+}
+
+// disconnectedConns 用户已断开但仍可恢复的会话，返回只用于编码接收包的连接（沿用会话的连接id和密钥）
+// 投递消息时通过这些连接把消息缓存到会话中，避免断开期间投递的消息在会话恢复后丢失
+func (r *resumeManager) disconnectedConns(uid string) []*connContext {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var conns []*connContext
+	for _, session := range r.users[uid] {
+		if session.disconnectedAt.IsZero() || r.expired(session, time.Now()) {
+			continue
+		}
+		conns = append(conns, &connContext{connInfo: connInfo{
+			connId:       session.connId,
+			uid:          session.uid,
+			deviceId:     session.deviceId,
+			deviceFlag:   session.deviceFlag,
+			deviceLevel:  session.deviceLevel,
+			aesKey:       session.aesKey,
+			aesIV:        session.aesIV,
+			protoVersion: session.protoVersion,
+		}})
+	}
+	return conns
+}
+
+func (r *resumeManager) sessionCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.sessions)
+}
+
+func (r *resumeManager) removeSession(session *resumeSession) {
+	delete(r.sessions, session.ticket)
+	if r.conns[session.connId] == session {
+		delete(r.conns, session.connId)
+	}
+	if userSessions := r.users[session.uid]; userSessions != nil {
+		delete(userSessions, session.ticket)
+		if len(userSessions) == 0 {
+			delete(r.users, session.uid)
+		}
+	}
+}
+
+// cleanLoop 定时清理过期的会话
+func (r *resumeManager) cleanLoop() {
+	interval := r.s.opts.SessionResume.TTL / 2
+	if interval < time.Second {
+		interval = time.Second
+	}
+	tk := time.NewTicker(interval)
+	defer tk.Stop()
+	for {
+		select {
+		case <-tk.C:
+			r.clean(time.Now())
+		case <-r.stopper.ShouldStop():
+			return
+		}
+	}
+}
+
+func (r *resumeManager) clean(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, session := range r.sessions {
+		if r.expired(session, now) {
+			r.removeSession(session)
+			continue
+		}
+		if session.disconnectedAt.IsZero() {
+			// 连接可能因为踢下线或者代理节点断开等原因被移除，这里兜底开始计算有效期
+			if r.s.userReactor.getConnById(session.uid, session.connId) == nil {
+				session.disconnectedAt = now
+			}
+		}
+	}
+}
+
+// resume 恢复会话，连接沿用会话的密钥，并投递断开期间缓存的消息
+// 需要在回复connack之后调用
+func (r *resumeManager) resume(session *resumeSession, connCtx *connContext) {
+	// 断开前已经进入重试队列但还没到重试时间的消息也一起投递
+	msgs := append(session.buffered, r.s.retryManager.takeRetryMessagesByConn(session.connId)...)
+	session.buffered = nil
+	sort.Slice(msgs, func(i, j int) bool {
+		return msgs[i].messageId < msgs[j].messageId
+	})
+	// 先全部加入重试队列，写入失败时剩下的消息仍然可以通过新票据恢复
+	for _, msg := range msgs {
+		msg.connId = connCtx.connId
+		msg.retry = 0
+		r.s.retryManager.addRetry(msg)
+	}
+	for _, msg := range msgs {
+		if err := connCtx.write(msg.recvPacketData, wkproto.RECV); err != nil {
+			r.Warn("write buffered message failed", zap.String("uid", msg.uid), zap.Int64("messageId", msg.messageId), zap.Int64("connId", connCtx.connId), zap.Error(err))
+			connCtx.close()
+			return
+		}
+	}
+	r.Info("session resumed", zap.String("uid", session.uid), zap.String("deviceId", session.deviceId), zap.Int64("connId", connCtx.connId), zap.Int("messages", len(msgs)))
+}
+
+// parseResumeTicket 从token中解析票据
+func parseResumeTicket(token string) (string, bool) {
+	if !strings.HasPrefix(token, resumeTokenPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(token, resumeTokenPrefix), true
+}
+
+// parseResumeClientKey 解析connect的ClientKey，返回去掉标记后的客户端公钥和客户端是否支持会话恢复
+func parseResumeClientKey(clientKey string) (string, bool) {
+	if !strings.HasSuffix(clientKey, resumeClientKeyFlag) {
+		return clientKey, false
+	}
+	return strings.TrimSuffix(clientKey, resumeClientKeyFlag), true
+}
+
+func genResumeTicket() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestResumeManager() *resumeManager {
+	opts := NewOptions()
+	opts.SessionResume.On = true
+	opts.SessionResume.TTL = time.Minute
+	opts.SessionResume.MaxBufferMessages = 2
+	return newResumeManager(&Server{opts: opts})
+}
+
+func TestResumeManagerTake(t *testing.T) {
+	r := newTestResumeManager()
+	connCtx := &connContext{connInfo: connInfo{
+		uid:          "u1",
+		deviceId:     "d1",
+		deviceFlag:   wkproto.APP,
+		protoVersion: wkproto.LatestVersion,
+		aesKey:       []byte("key"),
+		aesIV:        []byte("iv"),
+		connId:       1,
+	}}
+	ticket := r.issue(connCtx)
+	assert.NotEmpty(t, ticket)
+
+	parsed, ok := parseResumeTicket(resumeTokenPrefix + ticket)
+	assert.True(t, ok)
+	assert.Equal(t, ticket, parsed)
+	_, ok = parseResumeTicket("token")
+	assert.False(t, ok)
+
+	// 设备不匹配
+	assert.Nil(t, r.take(ticket, &wkproto.ConnectPacket{UID: "u1", DeviceID: "d2", DeviceFlag: wkproto.APP}, wkproto.LatestVersion))
+
+	session := r.take(ticket, &wkproto.ConnectPacket{UID: "u1", DeviceID: "d1", DeviceFlag: wkproto.APP}, wkproto.LatestVersion)
+	assert.NotNil(t, session)
+	assert.Equal(t, []byte("key"), session.aesKey)
+
+	// 票据只能使用一次
+	assert.Nil(t, r.take(ticket, &wkproto.ConnectPacket{UID: "u1", DeviceID: "d1", DeviceFlag: wkproto.APP}, wkproto.LatestVersion))
+	assert.Equal(t, 0, r.sessionCount())
+}
+
+func TestResumeManagerBufferAndExpire(t *testing.T) {
+	r := newTestResumeManager()
+	connCtx := &connContext{connInfo: connInfo{uid: "u1", deviceId: "d1", deviceFlag: wkproto.APP, connId: 1}}
+	ticket := r.issue(connCtx)
+
+	assert.False(t, r.buffer(&retryMessage{uid: "u1", connId: 2, messageId: 1}))
+	assert.True(t, r.buffer(&retryMessage{uid: "u1", connId: 1, messageId: 1}))
+	assert.True(t, r.buffer(&retryMessage{uid: "u1", connId: 1, messageId: 2}))
+	assert.False(t, r.buffer(&retryMessage{uid: "u1", connId: 1, messageId: 3})) // 超过缓存上限
+
+	r.clean(time.Now())
+	assert.Equal(t, 1, r.sessionCount())
+
+	r.clean(time.Now().Add(time.Minute * 2))
+	assert.Equal(t, 0, r.sessionCount())
+	assert.Nil(t, r.take(ticket, &wkproto.ConnectPacket{UID: "u1", DeviceID: "d1", DeviceFlag: wkproto.APP}, 0))
+}
+
+func TestResumeManagerDisconnectedConns(t *testing.T) {
+	r := newTestResumeManager()
+	connCtx := &connContext{connInfo: connInfo{uid: "u1", deviceId: "d1", deviceFlag: wkproto.APP, connId: 1, aesKey: []byte("key"), aesIV: []byte("iv")}}
+	r.issue(connCtx)
+
+	// 连接在线时不缓存
+	assert.Empty(t, r.disconnectedConns("u1"))
+
+	// 断开期间投递的消息缓存到会话中
+	r.disconnect(1)
+	conns := r.disconnectedConns("u1")
+	assert.Len(t, conns, 1)
+	assert.Equal(t, int64(1), conns[0].connId)
+	assert.Equal(t, []byte("key"), conns[0].aesKey)
+	assert.True(t, r.buffer(&retryMessage{uid: "u1", connId: conns[0].connId, messageId: 1}))
+
+	// 同一设备重新完整认证后，旧会话不再缓存
+	r.issue(&connContext{connInfo: connInfo{uid: "u1", deviceId: "d1", deviceFlag: wkproto.APP, connId: 2}})
+	assert.Empty(t, r.disconnectedConns("u1"))
+	assert.Equal(t, 1, r.sessionCount())
+}
+
+func TestResumeManagerRevokeAndMaxLifetime(t *testing.T) {
+	r := newTestResumeManager()
+	appTicket := r.issue(&connContext{connInfo: connInfo{uid: "u1", deviceId: "d1", deviceFlag: wkproto.APP, connId: 1}})
+	webTicket := r.issue(&connContext{connInfo: connInfo{uid: "u1", deviceId: "d2", deviceFlag: wkproto.WEB, connId: 2}})
+
+	// 作废APP设备的票据，不影响WEB设备
+	r.revoke("u1", wkproto.APP)
+	assert.Equal(t, 1, r.sessionCount())
+	assert.Nil(t, r.take(appTicket, &wkproto.ConnectPacket{UID: "u1", DeviceID: "d1", DeviceFlag: wkproto.APP}, 0))
+
+	// 连接一直在线，超过签发后的最长有效期也不能恢复
+	r.s.opts.SessionResume.MaxLifetime = time.Hour
+	r.clean(time.Now().Add(time.Hour * 2))
+	assert.Equal(t, 0, r.sessionCount())
+	assert.Nil(t, r.take(webTicket, &wkproto.ConnectPacket{UID: "u1", DeviceID: "d2", DeviceFlag: wkproto.WEB}, 0))
+
+	webTicket = r.issue(&connContext{connInfo: connInfo{uid: "u1", deviceId: "d2", deviceFlag: wkproto.WEB, connId: 3}})
+	r.sessions[webTicket].issuedAt = time.Now().Add(-time.Hour * 2)
+	assert.Nil(t, r.take(webTicket, &wkproto.ConnectPacket{UID: "u1", DeviceID: "d2", DeviceFlag: wkproto.WEB}, 0))
+	assert.Equal(t, 0, r.sessionCount())
+}
+
+func TestParseResumeClientKey(t *testing.T) {
+	clientKey, ok := parseResumeClientKey("cHVia2V5")
+	assert.False(t, ok)
+	assert.Equal(t, "cHVia2V5", clientKey)
+
+	clientKey, ok = parseResumeClientKey("cHVia2V5" + resumeClientKeyFlag)
+	assert.True(t, ok)
+	assert.Equal(t, "cHVia2V5", clientKey)
+}
@@ -132,7 +132,8 @@ func (s *SystemUIDManager) getOrRequestSystemUids() ([]string, error) {
 
 func (s *SystemUIDManager) requestSystemUids(nodeInfo *pb.Node) ([]string, error) {
 
-	resp, err := network.Get(fmt.Sprintf("%s%s", nodeInfo.ApiServerAddr, "/user/systemuids"), nil, nil)
+	reqURL := fmt.Sprintf("%s%s", nodeInfo.ApiServerAddr, "/user/systemuids")
+	resp, err := network.Get(reqURL, nil, s.s.apiServer.nodeRequestHeaders(http.MethodGet, reqURL, nil))
 	if err != nil {
 		return nil, err
 	}
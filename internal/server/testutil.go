@@ -3,6 +3,7 @@ package server
 import (
 	"bytes"
 	"context"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"sync"
@@ -10,6 +11,8 @@ import (
 	"time"
 
 	"github.com/WuKongIM/WuKongIM/pkg/client"
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterstore"
+	"github.com/WuKongIM/WuKongIM/pkg/trace"
 	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -45,6 +48,16 @@ func NewTestServer(t testing.TB, opt ...Option) *Server {
 	return s
 }
 
+// testFreeAddr 获取一个空闲的本地地址，避免同一个包里的测试服务端口冲突
+func testFreeAddr(t testing.TB) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	return lis.Addr().String()
+}
+
 // 创建一个二个节点的分布式服务
 func NewTestClusterServerTwoNode(t *testing.T, opt ...Option) (*Server, *Server) {
 
@@ -207,3 +220,18 @@ func TestCreateClient(t *testing.T, s *Server, uid string) *client.Client {
 	assert.Nil(t, err)
 	return cli
 }
+
+// newTestWebhook 创建只带存储的webhook，停止时没有推送的事件会存入死信
+func newTestWebhook(t testing.TB, opts *Options) *webhook {
+	trace.SetGlobalTrace(trace.New(
+		context.Background(),
+		trace.NewOptions(
+			trace.WithServiceName("test"),
+			trace.WithServiceHostName("host"),
+		)))
+	store := clusterstore.NewStore(clusterstore.NewOptions(1, clusterstore.WithDataDir(t.TempDir())))
+	err := store.Open()
+	assert.NoError(t, err)
+	t.Cleanup(store.Close)
+	return newWebhook(&Server{opts: opts, store: store})
+}
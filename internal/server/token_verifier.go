@@ -0,0 +1,318 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// TokenVerifier 客户端连接的token校验
+type TokenVerifier interface {
+	// Verify 校验用户连接的token，校验通过返回设备等级
+	Verify(uid string, deviceFlag wkproto.DeviceFlag, token string) (wkproto.DeviceLevel, error)
+}
+
+// newTokenVerifier 根据配置创建token校验器，配置了自定义的校验器则直接使用
+func newTokenVerifier(s *Server) TokenVerifier {
+	if s.opts.TokenVerifier != nil {
+		return s.opts.TokenVerifier
+	}
+	dbVerifier := &dbTokenVerifier{s: s}
+	if !s.opts.ClientJwt.On {
+		return dbVerifier
+	}
+	jwtVerifier, err := newJwtTokenVerifier(s.opts)
+	if err != nil {
+		wklog.Panic("create jwt token verifier failed", zap.Error(err))
+	}
+	if !s.opts.ClientJwt.FallbackDB {
+		return jwtVerifier
+	}
+	return &fallbackTokenVerifier{jwt: jwtVerifier, db: dbVerifier}
+}
+
+// dbTokenVerifier 校验通过/user/token接口注册的设备token
+type dbTokenVerifier struct {
+	s *Server
+}
+
+func (d *dbTokenVerifier) Verify(uid string, deviceFlag wkproto.DeviceFlag, token string) (wkproto.DeviceLevel, error) {
+	device, err := d.s.store.GetDevice(uid, deviceFlag)
+	if err != nil {
+		return 0, err
+	}
+	if device.Token != token {
+		return 0, errors.New("token verify fail")
+	}
+	return wkproto.DeviceLevel(device.DeviceLevel), nil
+}
+
+// fallbackTokenVerifier jwt格式的token使用jwt校验，其他token使用设备token校验
+type fallbackTokenVerifier struct {
+	jwt TokenVerifier
+	db  TokenVerifier
+}
+
+func (f *fallbackTokenVerifier) Verify(uid string, deviceFlag wkproto.DeviceFlag, token string) (wkproto.DeviceLevel, error) {
+	if isJwtFormat(token) {
+		return f.jwt.Verify(uid, deviceFlag, token)
+	}
+	return f.db.Verify(uid, deviceFlag, token)
+}
+
+func isJwtFormat(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// 客户端jwt中的claim
+const (
+	jwtClaimDeviceFlag  = "device_flag"  // 设备标识，不为空时必须与连接的设备标识一致
+	jwtClaimDeviceLevel = "device_level" // 设备等级 0.从设备 1.主设备
+)
+
+// jwksReloadInterval 找不到kid对应的公钥时，重新加载JWKS文件的最小间隔
+const jwksReloadInterval = time.Second * 10
+
+// jwtTokenVerifier 校验业务服务签发的jwt，jwt中携带uid、设备标识和设备等级，无需提前注册token
+type jwtTokenVerifier struct {
+	opts   *Options
+	parser *jwt.Parser
+
+	secret    []byte      // HS256的密钥
+	publicKey interface{} // RS256/ES256的公钥
+
+	jwksMu         sync.RWMutex
+	jwksKeys       map[string]interface{} // kid -> 公钥
+	jwksLoadedAt   time.Time
+	jwksReloadLock sync.Mutex
+	wklog.Log
+}
+
+func newJwtTokenVerifier(opts *Options) (*jwtTokenVerifier, error) {
+	cfg := opts.ClientJwt
+	alg := strings.ToUpper(cfg.Algorithm)
+
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{alg}),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(cfg.Audience))
+	}
+	v := &jwtTokenVerifier{
+		opts:   opts,
+		parser: jwt.NewParser(parserOpts...),
+		Log:    wklog.NewWKLog("jwtTokenVerifier"),
+	}
+
+	switch alg {
+	case "HS256":
+		if strings.TrimSpace(cfg.Secret) == "" {
+			return nil, errors.New("clientJwt.secret is empty")
+		}
+		v.secret = []byte(cfg.Secret)
+	case "RS256", "ES256":
+		if cfg.JwksFile != "" {
+			if err := v.loadJwks(); err != nil {
+				return nil, err
+			}
+		} else if cfg.PublicKeyFile != "" {
+			pemData, err := os.ReadFile(cfg.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			if alg == "RS256" {
+				v.publicKey, err = jwt.ParseRSAPublicKeyFromPEM(pemData)
+			} else {
+				v.publicKey, err = jwt.ParseECPublicKeyFromPEM(pemData)
+			}
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			return nil, errors.New("clientJwt.publicKeyFile or clientJwt.jwksFile is required")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported clientJwt.algorithm: %s", cfg.Algorithm)
+	}
+	return v, nil
+}
+
+func (v *jwtTokenVerifier) Verify(uid string, deviceFlag wkproto.DeviceFlag, token string) (wkproto.DeviceLevel, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, v.keyFunc)
+	if err != nil {
+		return 0, err
+	}
+
+	uidClaim := v.opts.ClientJwt.UidClaim
+	if uidClaim == "" {
+		uidClaim = "uid"
+	}
+	tokenUid, _ := claims[uidClaim].(string)
+	if tokenUid == "" {
+		return 0, fmt.Errorf("jwt claim %s is empty", uidClaim)
+	}
+	if tokenUid != uid {
+		return 0, errors.New("jwt uid not match")
+	}
+
+	if flag, ok := claims[jwtClaimDeviceFlag]; ok {
+		tokenDeviceFlag, ok := flag.(float64)
+		if !ok || wkproto.DeviceFlag(tokenDeviceFlag) != deviceFlag {
+			return 0, errors.New("jwt device flag not match")
+		}
+	}
+
+	deviceLevel := wkproto.DeviceLevelSlave
+	if level, ok := claims[jwtClaimDeviceLevel].(float64); ok {
+		deviceLevel = wkproto.DeviceLevel(level)
+	}
+	return deviceLevel, nil
+}
+
+func (v *jwtTokenVerifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	if v.secret != nil {
+		return v.secret, nil
+	}
+	if v.opts.ClientJwt.JwksFile == "" {
+		return v.publicKey, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key := v.jwksKey(kid)
+	if key != nil {
+		return key, nil
+	}
+	// 可能是新增的公钥，重新加载JWKS文件
+	if err := v.reloadJwks(); err != nil {
+		v.Warn("reload jwks failed", zap.Error(err))
+	}
+	key = v.jwksKey(kid)
+	if key == nil {
+		return nil, fmt.Errorf("jwks key not found, kid: %s", kid)
+	}
+	return key, nil
+}
+
+// jwksKey 获取kid对应的公钥，jwt没有kid且JWKS中只有一个公钥时使用这个公钥
+func (v *jwtTokenVerifier) jwksKey(kid string) interface{} {
+	v.jwksMu.RLock()
+	defer v.jwksMu.RUnlock()
+	if kid == "" && len(v.jwksKeys) == 1 {
+		for _, key := range v.jwksKeys {
+			return key
+		}
+	}
+	return v.jwksKeys[kid]
+}
+
+func (v *jwtTokenVerifier) reloadJwks() error {
+	v.jwksReloadLock.Lock()
+	defer v.jwksReloadLock.Unlock()
+
+	v.jwksMu.RLock()
+	loadedAt := v.jwksLoadedAt
+	v.jwksMu.RUnlock()
+	if time.Since(loadedAt) < jwksReloadInterval {
+		return nil
+	}
+	return v.loadJwks()
+}
+
+func (v *jwtTokenVerifier) loadJwks() error {
+	data, err := os.ReadFile(v.opts.ClientJwt.JwksFile)
+	if err != nil {
+		return err
+	}
+	keys, err := parseJwks(data)
+	if err != nil {
+		return err
+	}
+	v.jwksMu.Lock()
+	v.jwksKeys = keys
+	v.jwksLoadedAt = time.Now()
+	v.jwksMu.Unlock()
+	return nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseJwks 解析JWKS中的RSA和EC公钥
+func parseJwks(data []byte) (map[string]interface{}, error) {
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]interface{}, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		switch k.Kty {
+		case "RSA":
+			n, err := base64.RawURLEncoding.DecodeString(k.N)
+			if err != nil {
+				return nil, err
+			}
+			e, err := base64.RawURLEncoding.DecodeString(k.E)
+			if err != nil {
+				return nil, err
+			}
+			keys[k.Kid] = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+		case "EC":
+			var curve elliptic.Curve
+			switch k.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				return nil, fmt.Errorf("unsupported jwk crv: %s", k.Crv)
+			}
+			x, err := base64.RawURLEncoding.DecodeString(k.X)
+			if err != nil {
+				return nil, err
+			}
+			y, err := base64.RawURLEncoding.DecodeString(k.Y)
+			if err != nil {
+				return nil, err
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{
+				Curve: curve,
+				X:     new(big.Int).SetBytes(x),
+				Y:     new(big.Int).SetBytes(y),
+			}
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJwtTokenVerifierHS256(t *testing.T) {
+	opts := NewOptions()
+	opts.ClientJwt.On = true
+	opts.ClientJwt.Secret = "test_secret"
+	opts.ClientJwt.Issuer = "test"
+
+	verifier, err := newJwtTokenVerifier(opts)
+	assert.NoError(t, err)
+
+	sign := func(claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test_secret"))
+		assert.NoError(t, err)
+		return token
+	}
+
+	exp := time.Now().Add(time.Hour).Unix()
+	token := sign(jwt.MapClaims{"uid": "u1", "device_flag": 1, "device_level": 1, "iss": "test", "exp": exp})
+	level, err := verifier.Verify("u1", wkproto.DeviceFlag(1), token)
+	assert.NoError(t, err)
+	assert.Equal(t, wkproto.DeviceLevelMaster, level)
+
+	// uid不匹配
+	_, err = verifier.Verify("u2", wkproto.DeviceFlag(1), token)
+	assert.Error(t, err)
+
+	// 设备标识不匹配
+	_, err = verifier.Verify("u1", wkproto.DeviceFlag(0), token)
+	assert.Error(t, err)
+
+	// 已过期
+	token = sign(jwt.MapClaims{"uid": "u1", "iss": "test", "exp": time.Now().Add(-time.Minute).Unix()})
+	_, err = verifier.Verify("u1", wkproto.DeviceFlag(0), token)
+	assert.Error(t, err)
+
+	// 没有过期时间
+	token = sign(jwt.MapClaims{"uid": "u1", "iss": "test"})
+	_, err = verifier.Verify("u1", wkproto.DeviceFlag(0), token)
+	assert.Error(t, err)
+
+	// 签发者不匹配
+	token = sign(jwt.MapClaims{"uid": "u1", "iss": "other", "exp": exp})
+	_, err = verifier.Verify("u1", wkproto.DeviceFlag(0), token)
+	assert.Error(t, err)
+}
+
+func TestJwtTokenVerifierES256Jwks(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	jwks := `{"keys":[{"kty":"EC","kid":"k1","crv":"P-256","x":"` +
+		base64.RawURLEncoding.EncodeToString(privateKey.X.FillBytes(make([]byte, 32))) + `","y":"` +
+		base64.RawURLEncoding.EncodeToString(privateKey.Y.FillBytes(make([]byte, 32))) + `"}]}`
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	err = os.WriteFile(jwksFile, []byte(jwks), 0644)
+	assert.NoError(t, err)
+
+	opts := NewOptions()
+	opts.ClientJwt.On = true
+	opts.ClientJwt.Algorithm = "ES256"
+	opts.ClientJwt.JwksFile = jwksFile
+
+	verifier, err := newJwtTokenVerifier(opts)
+	assert.NoError(t, err)
+
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{"uid": "u1", "exp": time.Now().Add(time.Hour).Unix()})
+	jwtToken.Header["kid"] = "k1"
+	token, err := jwtToken.SignedString(privateKey)
+	assert.NoError(t, err)
+
+	level, err := verifier.Verify("u1", wkproto.DeviceFlag(0), token)
+	assert.NoError(t, err)
+	assert.Equal(t, wkproto.DeviceLevelSlave, level)
+
+	// HS256签名的token不被接受
+	token, err = jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"uid": "u1", "exp": time.Now().Add(time.Hour).Unix()}).SignedString([]byte("x"))
+	assert.NoError(t, err)
+	_, err = verifier.Verify("u1", wkproto.DeviceFlag(0), token)
+	assert.Error(t, err)
+}
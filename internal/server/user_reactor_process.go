@@ -170,8 +170,28 @@ func (r *userReactor) handleAuth(uid string, msg ReactorUserMessage) (wkproto.Re
 		sub.addConnAndCreateUserHandlerIfNotExist(connCtx)
 		r.Debug("auth: add conn", zap.Any("connCtx", connCtx))
 	}
+	lastVersion := connectPacket.Version
+	hasServerVersion := false
+	if connectPacket.Version > wkproto.LatestVersion {
+		lastVersion = wkproto.LatestVersion
+	}
+
+	// 客户端是否支持会话恢复
+	clientKey, resumeOptIn := parseResumeClientKey(connectPacket.ClientKey)
+
 	// -------------------- token verify --------------------
-	if connectPacket.UID == r.s.opts.ManagerUID {
+	var resumed *resumeSession // 通过票据恢复的会话
+	if ticket, ok := parseResumeTicket(connectPacket.Token); ok {
+		if r.s.opts.SessionResume.On {
+			resumed = r.s.resumeManager.take(ticket, connectPacket, lastVersion)
+		}
+		if resumed == nil {
+			r.Warn("resume ticket is invalid or expired", zap.String("uid", uid), zap.String("deviceId", connectPacket.DeviceID))
+			r.authResponseConnackAuthFail(connCtx)
+			return wkproto.ReasonAuthFail, errors.New("resume ticket is invalid or expired")
+		}
+		devceLevel = resumed.deviceLevel
+	} else if connectPacket.UID == r.s.opts.ManagerUID {
 		if r.s.opts.ManagerTokenOn && connectPacket.Token != r.s.opts.ManagerToken {
 			r.Error("manager token verify fail", zap.String("uid", uid), zap.String("token", connectPacket.Token))
 			r.authResponseConnackAuthFail(connCtx)
@@ -184,19 +204,13 @@ func (r *userReactor) handleAuth(uid string, msg ReactorUserMessage) (wkproto.Re
 			r.authResponseConnackAuthFail(connCtx)
 			return wkproto.ReasonAuthFail, errors.New("token is empty")
 		}
-		device, err := r.s.store.GetDevice(uid, connectPacket.DeviceFlag)
+		var err error
+		devceLevel, err = r.s.tokenVerifier.Verify(uid, connectPacket.DeviceFlag, connectPacket.Token)
 		if err != nil {
-			r.Error("get device token err", zap.Error(err))
+			r.Error("token verify fail", zap.Error(err), zap.String("uid", uid), zap.Any("conn", connCtx))
 			r.authResponseConnackAuthFail(connCtx)
 			return wkproto.ReasonAuthFail, err
-
-		}
-		if device.Token != connectPacket.Token {
-			r.Error("token verify fail", zap.String("expectToken", device.Token), zap.String("actToken", connectPacket.Token), zap.Any("conn", connCtx))
-			r.authResponseConnackAuthFail(connCtx)
-			return wkproto.ReasonAuthFail, errors.New("token verify fail")
 		}
-		devceLevel = wkproto.DeviceLevel(device.DeviceLevel)
 	} else {
 		devceLevel = wkproto.DeviceLevelSlave // 默认都是slave设备
 	}
@@ -219,19 +233,29 @@ func (r *userReactor) handleAuth(uid string, msg ReactorUserMessage) (wkproto.Re
 	}
 
 	// -------------------- get message encrypt key --------------------
-	dhServerPrivKey, dhServerPublicKey := wkutil.GetCurve25519KeypPair() // 生成服务器的DH密钥对
-	aesKey, aesIV, err := r.getClientAesKeyAndIV(connectPacket.ClientKey, dhServerPrivKey)
-	if err != nil {
-		r.Error("get client aes key and iv err", zap.Error(err))
-		r.authResponseConnackAuthFail(connCtx)
-		return wkproto.ReasonAuthFail, err
+	var (
+		aesKey, aesIV        []byte
+		dhServerPublicKeyEnc string
+	)
+	if resumed != nil { // 恢复会话沿用原来的密钥，不再交换密钥
+		aesKey, aesIV = resumed.aesKey, resumed.aesIV
+	} else {
+		dhServerPrivKey, dhServerPublicKey := wkutil.GetCurve25519KeypPair() // 生成服务器的DH密钥对
+		aesKey, aesIV, err = r.getClientAesKeyAndIV(clientKey, dhServerPrivKey)
+		if err != nil {
+			r.Error("get client aes key and iv err", zap.Error(err))
+			r.authResponseConnackAuthFail(connCtx)
+			return wkproto.ReasonAuthFail, err
+		}
+		dhServerPublicKeyEnc = base64.StdEncoding.EncodeToString(dhServerPublicKey[:])
 	}
-	dhServerPublicKeyEnc := base64.StdEncoding.EncodeToString(dhServerPublicKey[:])
 
 	// -------------------- same master kicks each other --------------------
 	oldConns := r.s.userReactor.getConnsByDeviceFlag(uid, connectPacket.DeviceFlag)
 	if len(oldConns) > 0 {
 		if devceLevel == wkproto.DeviceLevelMaster { // 如果设备是master级别，则把旧连接都踢掉
+			// 被踢掉的连接不能再通过票据恢复
+			r.s.resumeManager.revoke(uid, connectPacket.DeviceFlag)
 			for _, oldConn := range oldConns {
 				if oldConn.connId == connCtx.connId { // 不能把自己踢了
 					continue
@@ -278,12 +302,6 @@ func (r *userReactor) handleAuth(uid string, msg ReactorUserMessage) (wkproto.Re
 
 	// connCtx := p.connContextPool.Get().(*connContext)
 
-	lastVersion := connectPacket.Version
-	hasServerVersion := false
-	if connectPacket.Version > wkproto.LatestVersion {
-		lastVersion = wkproto.LatestVersion
-	}
-
 	connCtx.aesIV = aesIV
 	connCtx.aesKey = aesKey
 	connCtx.deviceLevel = devceLevel
@@ -300,7 +318,11 @@ func (r *userReactor) handleAuth(uid string, msg ReactorUserMessage) (wkproto.Re
 		hasServerVersion = true
 	}
 
-	r.Debug("auth: auth Success", zap.Any("conn", connCtx), zap.Uint8("protoVersion", connectPacket.Version), zap.Bool("hasServerVersion", hasServerVersion))
+	if r.s.opts.SessionResume.On && resumeOptIn { // 给支持会话恢复的客户端签发票据
+		dhServerPublicKeyEnc = dhServerPublicKeyEnc + resumeTicketSeparator + r.s.resumeManager.issue(connCtx)
+	}
+
+	r.Debug("auth: auth Success", zap.Any("conn", connCtx), zap.Uint8("protoVersion", connectPacket.Version), zap.Bool("hasServerVersion", hasServerVersion), zap.Bool("resumed", resumed != nil))
 	connack := &wkproto.ConnackPacket{
 		Salt:          string(aesIV),
 		ServerKey:     dhServerPublicKeyEnc,
@@ -311,6 +333,9 @@ func (r *userReactor) handleAuth(uid string, msg ReactorUserMessage) (wkproto.Re
 	}
 	connack.HasServerVersion = hasServerVersion
 	r.authResponse(connCtx, connack)
+	if resumed != nil {
+		r.s.resumeManager.resume(resumed, connCtx)
+	}
 	// -------------------- user online --------------------
 	// 在线webhook
 	deviceOnlineCount := r.s.userReactor.getConnCountByDeviceFlag(uid, connectPacket.DeviceFlag)
@@ -342,6 +367,9 @@ func (r *userReactor) getClientAesKeyAndIV(clientKey string, dhServerPrivKey [32
 
 func (r *userReactor) authResponse(connCtx *connContext, packet *wkproto.ConnackPacket) {
 	if connCtx.isRealConn {
+		if packet.ReasonCode == wkproto.ReasonAuthFail {
+			r.s.guardAuthFailed(connCtx.conn)
+		}
 		_ = connCtx.writeDirectlyPacket(packet)
 	} else {
 		status, err := r.requestUserAuthResult(connCtx.realNodeId, &UserAuthResult{
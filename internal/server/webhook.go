@@ -3,12 +3,14 @@ package server
 import (
 	"bytes"
 	"compress/gzip"
+	"container/heap"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -23,6 +25,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
 )
 
 type webhook struct {
@@ -32,13 +35,29 @@ type webhook struct {
 	httpClient       *http.Client
 	webhookGRPCPool  *grpcpool.Pool // webhook grpc客户端
 	stoped           chan struct{}
+	stopCtx          context.Context // 停止时取消，正在进行的推送请求会被中断
+	stopCancel       context.CancelFunc
+	stopWg           sync.WaitGroup // 会读写存储的协程（通知队列、元数据事件、重试和频道推送队列），停止时等待退出后才能关闭存储
 	onlinestatusLock sync.RWMutex
 	onlinestatusList []string
 	focusEvents      map[string]struct{} // 用户关注的事件类型,如果为空则推送所有类型
+
+	channelWebhookLock sync.RWMutex
+	channelWebhooks    map[string]channelWebhookCacheEntry // 频道配置的webhook地址缓存 key为channelKey
+	destinationLock    sync.Mutex
+	destinations       map[string]*webhookDestination // 频道webhook地址的推送目标 key为webhook地址
+	notifyInflightLock sync.Mutex
+	notifyInflight     map[int64]struct{} // 已放入频道推送队列、等待推送完成的通知队列消息id
+	notifyDeferred     map[int64]time.Time // 暂缓路由的通知队列消息id（获取频道webhook地址失败或者推送队列已满），到期前读取通知队列时跳过
+	appliedEventC      chan *Event        // 元数据日志应用后触发的事件，由单独的协程提交，不阻塞日志应用
+	retryLock          sync.Mutex
+	retryItems         webhookRetryHeap // 等待重试的事件，按重试时间排序（只在内存中，停止时存入死信，进程崩溃时会丢失）
+	retryNotifyC       chan struct{}
 }
 
 func newWebhook(s *Server) *webhook {
-	eventPool, err := ants.NewPool(s.opts.EventPoolSize, ants.WithPanicHandler(func(err interface{}) {
+	// 协程池满了不阻塞提交方，事件放入重试队列
+	eventPool, err := ants.NewPool(s.opts.EventPoolSize, ants.WithNonblocking(true), ants.WithPanicHandler(func(err interface{}) {
 		s.Error("webhook panic", zap.Any("err", err), zap.Stack("stack"))
 	}))
 	if err != nil {
@@ -73,8 +92,11 @@ func newWebhook(s *Server) *webhook {
 		}
 	}
 
+	stopCtx, stopCancel := context.WithCancel(context.Background())
 	return &webhook{
 		s:                s,
+		stopCtx:          stopCtx,
+		stopCancel:       stopCancel,
 		Log:              wklog.NewWKLog("Webhook"),
 		eventPool:        eventPool,
 		webhookGRPCPool:  webhookGRPCPool,
@@ -95,17 +117,85 @@ func newWebhook(s *Server) *webhook {
 				ExpectContinueTimeout: 1 * time.Second,
 			},
 		},
-		focusEvents: focusEvents,
+		focusEvents:     focusEvents,
+		channelWebhooks: make(map[string]channelWebhookCacheEntry),
+		destinations:    make(map[string]*webhookDestination),
+		notifyInflight:  make(map[int64]struct{}),
+		notifyDeferred:  make(map[int64]time.Time),
+		appliedEventC:   make(chan *Event, webhookAppliedEventQueueSize),
+		retryNotifyC:    make(chan struct{}, 1),
 	}
 }
 
 func (w *webhook) Start() {
-	go w.notifyQueueLoop()
+	w.stopWg.Add(1)
+	go func() {
+		defer w.stopWg.Done()
+		w.notifyQueueLoop()
+	}()
+	w.stopWg.Add(2)
+	go func() {
+		defer w.stopWg.Done()
+		w.loopAppliedEvents()
+	}()
+	go func() {
+		defer w.stopWg.Done()
+		w.loopRetry()
+	}()
 	go w.loopOnlineStatus()
+	if w.s.opts.Webhook.ChannelOn {
+		go w.loopCleanChannelWebhooks()
+	}
 }
 
+// Stop 停止推送，需要在关闭存储之前调用
+// 正在推送的请求会被中断，没有推送完成的通知队列消息保留在通知队列中，重启后重新推送
+// 其他只在内存中的事件（重试队列、元数据事件队列和频道推送队列里的事件）存入死信，可通过接口重放
+// 注意：进程崩溃（没有调用Stop）时这些内存中的事件会丢失
 func (w *webhook) Stop() {
 	close(w.stoped)
+	w.stopCancel()
+	// 持有锁保证之后不会再创建频道推送队列的协程
+	w.destinationLock.Lock()
+	w.destinationLock.Unlock()
+	w.stopWg.Wait()
+	// 等待正在推送的事件结束，失败的事件会直接存入死信
+	if err := w.eventPool.ReleaseTimeout(webhookStopTimeout); err != nil {
+		w.Warn("等待webhook事件推送结束超时！", zap.Error(err))
+	}
+	w.flushToDeadLetters()
+}
+
+// flushToDeadLetters 停止时将内存中还没有推送的事件存入死信
+func (w *webhook) flushToDeadLetters() {
+	w.retryLock.Lock()
+	items := make([]*webhookRetryItem, 0, w.retryItems.Len())
+	for w.retryItems.Len() > 0 {
+		items = append(items, heap.Pop(&w.retryItems).(*webhookRetryItem))
+	}
+	w.retryLock.Unlock()
+	for _, item := range items {
+		sendErr := item.lastErr
+		if sendErr == nil {
+			sendErr = errWebhookStopped
+		}
+		w.addDeadLetter(item.addr, item.event, item.data, item.attempt, sendErr)
+	}
+
+	appliedCount := 0
+	for {
+		select {
+		case event := <-w.appliedEventC:
+			data, _ := json.Marshal(event.Data)
+			w.addDeadLetter("", event.Event, data, 0, errWebhookStopped)
+			appliedCount++
+		default:
+			if len(items) > 0 || appliedCount > 0 {
+				w.Warn("webhook停止，未推送的事件存入死信！", zap.Int("retryCount", len(items)), zap.Int("appliedEventCount", appliedCount))
+			}
+			return
+		}
+	}
 }
 
 // Online 用户设备上线通知
@@ -129,34 +219,63 @@ func (w *webhook) Offline(uid string, deviceFlag wkproto.DeviceFlag, connId int6
 }
 
 // TriggerEvent 触发事件
+// 事件在协程池中只推送一次，失败后放入重试队列等待重试，不会在协程池里等待
 func (w *webhook) TriggerEvent(event *Event) {
 	if !w.s.opts.WebhookOn(event.Event) { // 没设置webhook直接忽略
 		return
 	}
-	err := w.eventPool.Submit(func() {
-		jsonData, err := json.Marshal(event.Data)
-		if err != nil {
-			w.Error("webhook的event数据不能json化！", zap.Error(err))
-			return
-		}
+	// http和grpc方式推送的事件数据统一使用json编码
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		w.Error("webhook的event数据不能编码！", zap.Error(err))
+		return
+	}
+	err = w.eventPool.Submit(func() {
+		w.deliverEvent("", event.Event, data, 0)
+	})
+	if err != nil { // 协程池满了，放入重试队列
+		w.Warn("提交事件失败，放入重试队列", zap.Error(err), zap.String("event", event.Event))
+		w.addRetry(&webhookRetryItem{
+			event:   event.Event,
+			data:    data,
+			retryAt: time.Now(),
+		})
+	}
+}
 
-		if w.s.opts.WebhookGRPCOn() {
-			err = w.sendWebhookForGRPC(event.Event, jsonData)
-		} else {
-			err = w.sendWebhookForHttp(event.Event, jsonData)
-		}
-		if err != nil {
-			w.Error("请求webhook失败！", zap.Error(err), zap.String("event", event.Event))
+// triggerAppliedEvent 元数据日志应用后触发事件，只放入队列不阻塞，队列满了存入死信
+func (w *webhook) triggerAppliedEvent(event *Event) {
+	select {
+	case w.appliedEventC <- event:
+	default:
+		w.Warn("元数据事件队列已满，事件存入死信！", zap.String("event", event.Event))
+		data, _ := json.Marshal(event.Data)
+		w.addDeadLetter("", event.Event, data, 0, errWebhookAppliedEventFull)
+	}
+}
+
+func (w *webhook) loopAppliedEvents() {
+	for {
+		select {
+		case event := <-w.appliedEventC:
+			w.TriggerEvent(event)
+		case <-w.stoped:
 			return
 		}
-
-	})
-	if err != nil {
-		w.Error("提交事件失败", zap.Error(err))
 	}
 }
 
 func (w *webhook) notifyOfflineMsg(msg ReactorChannelMessage, subscribers []string) {
+	var channelWebhook string
+	if w.s.opts.WebhookChannelOn(EventMsgOffline) {
+		var err error
+		if channelWebhook, err = w.channelWebhook(msg.SendPacket.ChannelID, msg.SendPacket.ChannelType); err != nil {
+			w.Warn("获取频道webhook地址失败，推送到全局webhook！", zap.Error(err), zap.String("channelId", msg.SendPacket.ChannelID), zap.Uint8("channelType", msg.SendPacket.ChannelType))
+		}
+	}
+	if channelWebhook == "" && !w.s.opts.WebhookOn(EventMsgOffline) {
+		return
+	}
 	compress := ""
 	toUIDs := subscribers
 	var compresssToUIDs []byte
@@ -173,8 +292,13 @@ func (w *webhook) notifyOfflineMsg(msg ReactorChannelMessage, subscribers []stri
 			compresssToUIDs = buff.Bytes()
 		}
 	}
+	// 被@的离线用户需要强制推送
+	var mentionUIDs []string
+	if mention := parseMessageMention(msg.SendPacket); mention != nil {
+		mentionUIDs = mention.filter(subscribers, msg.FromUid)
+	}
 	// 推送离线到上层应用
-	w.TriggerEvent(&Event{
+	event := &Event{
 		Event: EventMsgOffline,
 		Data: MessageOfflineNotify{
 			MessageResp: MessageResp{
@@ -195,24 +319,38 @@ func (w *webhook) notifyOfflineMsg(msg ReactorChannelMessage, subscribers []stri
 				Expire:       msg.SendPacket.Expire,
 				Timestamp:    int32(time.Now().Unix()),
 				Payload:      msg.SendPacket.Payload,
+				E2EE:         wkutil.BoolToInt(msg.SendPacket.Setting.IsSet(wkproto.SettingSignal)),
 			},
 			ToUIDs:          toUIDs,
 			Compress:        compress,
 			CompresssToUIDs: compresssToUIDs,
 			SourceID:        int64(w.s.opts.Cluster.NodeId),
+			MentionUIDs:     mentionUIDs,
 		},
-	})
+	}
+	if channelWebhook != "" { // 频道配置了webhook地址，推送到频道的推送队列
+		jsonData, err := json.Marshal(event.Data)
+		if err != nil {
+			w.Error("webhook的event数据不能json化！", zap.Error(err))
+			return
+		}
+		w.pushToDestination(channelWebhook, &webhookDestinationItem{event: event.Event, data: jsonData})
+		return
+	}
+	w.TriggerEvent(event)
 }
 
 // 通知上层应用 TODO: 此初报错可以做一个邮件报警处理类的东西，
 func (w *webhook) notifyQueueLoop() {
 	errorSleepTime := time.Second * 1 // 发生错误后sleep时间
+	sendErrCount := 0                 // 连续推送失败次数，用于计算退避时间
 	ticker := time.NewTicker(w.s.opts.Webhook.MsgNotifyEventPushInterval)
 	defer ticker.Stop()
 	errMessageIDMap := make(map[int64]int) // 记录错误的消息ID value为错误次数
-	if w.s.opts.WebhookOn(EventMsgNotify) {
+	if w.s.opts.WebhookOn(EventMsgNotify) || w.s.opts.WebhookChannelOn(EventMsgNotify) {
 		for {
-			messages, err := w.s.store.GetMessagesOfNotifyQueue(w.s.opts.Webhook.MsgNotifyEventCountPerPush)
+			// 跳过在频道推送队列里的消息，某个频道的webhook地址响应慢不会阻塞后面的消息
+			messages, err := w.s.store.GetMessagesOfNotifyQueueWithSkip(w.s.opts.Webhook.MsgNotifyEventCountPerPush, w.skipNotifyMessage)
 			if err != nil {
 				w.Error("获取通知队列内的消息失败！", zap.Error(err))
 				// 如果系统出现错误，就移除第一个
@@ -223,6 +361,8 @@ func (w *webhook) notifyQueueLoop() {
 				time.Sleep(errorSleepTime) // 如果报错就休息下
 				continue
 			}
+			// 配置了webhook地址的频道的消息由各自的推送队列推送
+			messages = w.routeNotifyMessages(messages)
 			if len(messages) > 0 {
 				messageResps := make([]*MessageResp, 0, len(messages))
 				for _, msg := range messages {
@@ -244,13 +384,17 @@ func (w *webhook) notifyQueueLoop() {
 				}
 				if err != nil {
 					w.Error("请求所有消息通知webhook失败！", zap.Error(err))
+					sendErr := err
 					errMessageIDs := make([]int64, 0, len(messages))
-					for _, message := range messages {
+					for i, message := range messages {
 						errCount := errMessageIDMap[message.MessageID]
 						errCount++
 						errMessageIDMap[message.MessageID] = errCount
 						if errCount >= w.s.opts.Webhook.MsgNotifyEventRetryMaxCount {
 							errMessageIDs = append(errMessageIDs, message.MessageID)
+							// 超过最大次数的消息存入死信，避免被静默丢弃
+							deadData, _ := json.Marshal([]*MessageResp{messageResps[i]})
+							w.addDeadLetter("", EventMsgNotify, deadData, errCount, sendErr)
 						}
 					}
 					if len(errMessageIDs) > 0 {
@@ -263,9 +407,13 @@ func (w *webhook) notifyQueueLoop() {
 							delete(errMessageIDMap, errMessageID)
 						}
 					}
-					time.Sleep(errorSleepTime) // 如果报错就休息下
+					sendErrCount++
+					if !w.waitRetry(sendErrCount) { // 按退避时间等待后重试
+						return
+					}
 					continue
 				}
+				sendErrCount = 0
 
 				messageIDs := make([]int64, 0, len(messages))
 				for _, message := range messages {
@@ -317,16 +465,13 @@ func (w *webhook) loopOnlineStatus() {
 			continue
 		}
 
-		if w.s.opts.WebhookGRPCOn() {
-			err = w.sendWebhookForGRPC(EventOnlineStatus, jsonData)
-		} else {
-			err = w.sendWebhookForHttp(EventOnlineStatus, jsonData)
-		}
+		err = w.sendWebhookOfAddr("", EventOnlineStatus, jsonData)
 		if err != nil {
 			errCount++
 			w.Error("请求在线状态webhook失败！", zap.Error(err))
 			if errCount >= w.s.opts.Webhook.MsgNotifyEventRetryMaxCount {
 				w.Error("请求在线状态webhook失败通知超过最大次数！", zap.Int("MsgNotifyEventRetryMaxCount", w.s.opts.Webhook.MsgNotifyEventRetryMaxCount))
+				w.addDeadLetter("", EventOnlineStatus, jsonData, errCount, err)
 
 				w.onlinestatusLock.Lock()
 				w.onlinestatusList = w.onlinestatusList[opLen:]
@@ -336,9 +481,12 @@ func (w *webhook) loopOnlineStatus() {
 				errCount = 0
 			}
 
-			time.Sleep(time.Second * 1) // 如果报错就休息下
+			if !w.waitRetry(errCount) { // 按退避时间等待后重试
+				return
+			}
 			continue
 		}
+		errCount = 0
 
 		w.onlinestatusLock.Lock()
 		w.onlinestatusList = w.onlinestatusList[opLen:]
@@ -349,19 +497,31 @@ func (w *webhook) loopOnlineStatus() {
 }
 
 func (w *webhook) sendWebhookForHttp(event string, data []byte) error {
-	eventURL := fmt.Sprintf("%s?event=%s", w.s.opts.Webhook.HTTPAddr, event)
+	return w.sendWebhookForHttpOfAddr(w.s.opts.Webhook.HTTPAddr, event, data)
+}
+
+func (w *webhook) sendWebhookForHttpOfAddr(addr string, event string, data []byte) error {
+	eventURL := fmt.Sprintf("%s?event=%s", addr, event)
 	startTime := time.Now().UnixNano() / 1000 / 1000
 	w.Debug("webhook开始请求", zap.String("eventURL", eventURL))
-	resp, err := w.httpClient.Post(eventURL, "application/json", bytes.NewBuffer(data))
+	req, err := http.NewRequestWithContext(w.stopCtx, http.MethodPost, eventURL, bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range w.signHeaders(event, data) {
+		req.Header.Set(key, value)
+	}
+	resp, err := w.httpClient.Do(req)
 	w.Debug("webhook请求结束 耗时", zap.Int64("mill", time.Now().UnixNano()/1000/1000-startTime))
 	if err != nil {
-		w.Warn("调用第三方消息通知失败！", zap.String("Webhook", w.s.opts.Webhook.HTTPAddr), zap.Error(err))
+		w.Warn("调用第三方消息通知失败！", zap.String("Webhook", addr), zap.Error(err))
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		w.Warn("第三方消息通知接口返回状态错误！", zap.Int("status", resp.StatusCode), zap.String("Webhook", w.s.opts.Webhook.HTTPAddr))
+		w.Warn("第三方消息通知接口返回状态错误！", zap.Int("status", resp.StatusCode), zap.String("Webhook", addr))
 		return errors.New("第三方消息通知接口返回状态错误！")
 	}
 	return nil
@@ -388,6 +548,9 @@ func (w *webhook) sendWebhookForGRPC(event string, data []byte) error {
 
 	sendCtx, sendCancel := context.WithTimeout(context.Background(), time.Second*10)
 	defer sendCancel()
+	for key, value := range w.signHeaders(event, data) {
+		sendCtx = metadata.AppendToOutgoingContext(sendCtx, strings.ToLower(key), value)
+	}
 	resp, err := cli.SendWebhook(sendCtx, &wkhook.EventReq{
 		Event: event,
 		Data:  data,
@@ -411,14 +574,56 @@ const (
 	EventMsgNotify = "msg.notify"
 	// EventOnlineStatus 用户在线状态
 	EventOnlineStatus = "user.onlinestatus"
+	// EventUserDeviceToken 用户设备token更新
+	EventUserDeviceToken = "user.device_token"
+	// EventChannelCreated 频道创建
+	EventChannelCreated = "channel.created"
+	// EventChannelUpdated 频道信息更新
+	EventChannelUpdated = "channel.updated"
+	// EventChannelDeleted 频道删除
+	EventChannelDeleted = "channel.deleted"
+	// EventSubscriberAdded 添加订阅者
+	EventSubscriberAdded = "channel.subscriber_added"
+	// EventSubscriberRemoved 移除订阅者
+	EventSubscriberRemoved = "channel.subscriber_removed"
+	// EventDenylistAdded 添加黑名单
+	EventDenylistAdded = "channel.denylist_added"
+	// EventDenylistRemoved 移除黑名单
+	EventDenylistRemoved = "channel.denylist_removed"
+	// EventAllowlistAdded 添加白名单
+	EventAllowlistAdded = "channel.allowlist_added"
+	// EventAllowlistRemoved 移除白名单
+	EventAllowlistRemoved = "channel.allowlist_removed"
+	// EventConversationRead 会话已读（清空未读）
+	EventConversationRead = "conversation.read"
+	// EventConversationCleared 会话被删除
+	EventConversationCleared = "conversation.cleared"
+	// EventStreamStart 流消息开始
+	EventStreamStart = "stream.start"
+	// EventStreamEnd 流消息结束
+	EventStreamEnd = "stream.end"
 )
 
 var (
 	// eventWebHook 用于快速校验用用户配置的关注事件
 	eventWebHook = map[string]map[string]struct{}{
-		EventMsgOffline:   {},
-		EventMsgNotify:    {},
-		EventOnlineStatus: {},
+		EventMsgOffline:          {},
+		EventMsgNotify:           {},
+		EventOnlineStatus:        {},
+		EventUserDeviceToken:     {},
+		EventChannelCreated:      {},
+		EventChannelUpdated:      {},
+		EventChannelDeleted:      {},
+		EventSubscriberAdded:     {},
+		EventSubscriberRemoved:   {},
+		EventDenylistAdded:       {},
+		EventDenylistRemoved:     {},
+		EventAllowlistAdded:      {},
+		EventAllowlistRemoved:    {},
+		EventConversationRead:    {},
+		EventConversationCleared: {},
+		EventStreamStart:         {},
+		EventStreamEnd:           {},
 	}
 )
 
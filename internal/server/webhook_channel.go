@@ -0,0 +1,384 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkserver/proto"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"go.uber.org/zap"
+)
+
+const (
+	channelWebhookCacheTTL        = time.Minute     // 频道webhook地址的本地缓存时间
+	channelWebhookErrCacheTTL     = time.Second * 5 // 获取频道webhook地址失败的缓存时间，期间同一频道的消息不再重复请求
+	webhookNotifyDeferDuration    = time.Second * 5 // 通知队列的消息暂缓路由的时间
+	webhookDestinationIdleTimeout = time.Minute * 5 // 推送目标空闲多久后回收
+)
+
+type channelWebhookCacheEntry struct {
+	addr     string
+	err      error // 获取失败的原因
+	expireAt time.Time
+}
+
+var errWebhookDestinationFull = errors.New("channel webhook queue is full")
+
+// webhookDestinationItem 待推送到频道webhook地址的事件
+type webhookDestinationItem struct {
+	event          string
+	data           []byte       // msg.offline等事件的数据
+	message        *MessageResp // msg.notify事件的消息，多条会合并成一次推送
+	queueMessageId int64        // msg.notify事件在通知队列里的消息id，推送完成（或存入死信）后才从通知队列移除
+}
+
+// deadLetterData 存入死信的事件数据
+func (item *webhookDestinationItem) deadLetterData() []byte {
+	if item.message == nil {
+		return item.data
+	}
+	data, _ := json.Marshal([]*MessageResp{item.message})
+	return data
+}
+
+// webhookDestination 频道webhook地址的推送目标
+// 每个地址有独立的队列和推送协程，某个地址响应慢不会影响其他地址和全局webhook的推送
+type webhookDestination struct {
+	addr   string
+	queueC chan *webhookDestinationItem
+}
+
+// channelWebhook 获取频道配置的webhook地址，没有配置返回空
+// 频道信息从频道所在slot的领导节点读取（本节点不一定是该slot的副本）
+func (w *webhook) channelWebhook(channelId string, channelType uint8) (string, error) {
+	if channelType == wkproto.ChannelTypePerson {
+		return "", nil
+	}
+	channelKey := wkutil.ChannelToKey(channelId, channelType)
+	w.channelWebhookLock.RLock()
+	entry, ok := w.channelWebhooks[channelKey]
+	w.channelWebhookLock.RUnlock()
+	if ok && time.Now().Before(entry.expireAt) {
+		return entry.addr, entry.err
+	}
+
+	addr, err := w.requestChannelWebhook(channelId, channelType)
+	entry = channelWebhookCacheEntry{addr: addr, err: err, expireAt: time.Now().Add(channelWebhookCacheTTL)}
+	if err != nil { // 失败也缓存一小段时间，避免领导节点不可用时每条消息都同步请求一次
+		entry.addr = ""
+		entry.expireAt = time.Now().Add(channelWebhookErrCacheTTL)
+	}
+	w.channelWebhookLock.Lock()
+	w.channelWebhooks[channelKey] = entry
+	w.channelWebhookLock.Unlock()
+	return entry.addr, err
+}
+
+// requestChannelWebhook 向频道所在slot的领导节点获取频道配置的webhook地址
+func (w *webhook) requestChannelWebhook(channelId string, channelType uint8) (string, error) {
+	leaderNode, err := w.s.cluster.SlotLeaderOfChannel(channelId, channelType)
+	if err != nil {
+		return "", err
+	}
+	if leaderNode == nil {
+		return "", errors.New("requestChannelWebhook: slot leader is nil")
+	}
+	if leaderNode.Id == w.s.opts.Cluster.NodeId {
+		return w.localChannelWebhook(channelId, channelType)
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(w.s.ctx, time.Second*5)
+	defer cancel()
+	req := &channelReq{
+		ChannelId:   channelId,
+		ChannelType: channelType,
+	}
+	resp, err := w.s.cluster.RequestWithContext(timeoutCtx, leaderNode.Id, "/wk/getChannelWebhook", req.Marshal())
+	if err != nil {
+		return "", err
+	}
+	if resp.Status != proto.StatusOK {
+		return "", fmt.Errorf("requestChannelWebhook: response status code is %d", resp.Status)
+	}
+	return string(resp.Body), nil
+}
+
+// localChannelWebhook 从本节点的存储读取频道配置的webhook地址
+func (w *webhook) localChannelWebhook(channelId string, channelType uint8) (string, error) {
+	channelInfo, err := w.s.store.GetChannel(channelId, channelType)
+	if err != nil && err != wkdb.ErrNotFound {
+		return "", err
+	}
+	return strings.TrimSpace(channelInfo.Webhook), nil
+}
+
+// invalidateChannelWebhook 频道信息更新后让缓存的webhook地址失效
+func (w *webhook) invalidateChannelWebhook(channelId string, channelType uint8) {
+	w.channelWebhookLock.Lock()
+	delete(w.channelWebhooks, wkutil.ChannelToKey(channelId, channelType))
+	w.channelWebhookLock.Unlock()
+}
+
+// cleanChannelWebhooks 清理过期的频道webhook地址缓存
+func (w *webhook) cleanChannelWebhooks() {
+	now := time.Now()
+	w.channelWebhookLock.Lock()
+	defer w.channelWebhookLock.Unlock()
+	for channelKey, entry := range w.channelWebhooks {
+		if now.After(entry.expireAt) {
+			delete(w.channelWebhooks, channelKey)
+		}
+	}
+}
+
+func (w *webhook) loopCleanChannelWebhooks() {
+	tk := time.NewTicker(channelWebhookCacheTTL)
+	defer tk.Stop()
+	for {
+		select {
+		case <-tk.C:
+			w.cleanChannelWebhooks()
+		case <-w.stoped:
+			return
+		}
+	}
+}
+
+// routeNotifyMessages 将配置了webhook地址的频道的消息放入对应地址的推送队列，返回需要推送到全局webhook的消息
+// 放入推送队列的消息仍然保留在通知队列中，推送完成（或存入死信）后才移除，节点重启后会重新推送
+func (w *webhook) routeNotifyMessages(messages []wkdb.Message) []wkdb.Message {
+	if !w.s.opts.WebhookChannelOn(EventMsgNotify) {
+		return messages
+	}
+	globalOn := w.s.opts.WebhookOn(EventMsgNotify)
+	globalMessages := make([]wkdb.Message, 0, len(messages))
+	noAddrMessageIds := make([]int64, 0)
+	for _, msg := range messages {
+		if w.isNotifyInflight(msg.MessageID) { // 已经在频道的推送队列中
+			continue
+		}
+		addr, err := w.channelWebhook(msg.ChannelID, msg.ChannelType)
+		if err != nil { // 留在通知队列中，暂缓一段时间后再路由
+			w.deferNotify(msg.MessageID)
+			w.Warn("获取频道webhook地址失败！", zap.Error(err), zap.String("channelId", msg.ChannelID), zap.Uint8("channelType", msg.ChannelType))
+			continue
+		}
+		if addr == "" {
+			if globalOn {
+				globalMessages = append(globalMessages, msg)
+			} else {
+				noAddrMessageIds = append(noAddrMessageIds, msg.MessageID)
+			}
+			continue
+		}
+		resp := &MessageResp{}
+		resp.from(msg, w.s)
+		w.setNotifyInflight(msg.MessageID, true)
+		if !w.pushToDestination(addr, &webhookDestinationItem{event: EventMsgNotify, message: resp, queueMessageId: msg.MessageID}) {
+			w.setNotifyInflight(msg.MessageID, false) // 推送队列满了，留在通知队列中，暂缓一段时间后再推送
+			w.deferNotify(msg.MessageID)
+		}
+	}
+	if len(noAddrMessageIds) > 0 {
+		// 没有可推送的地址的消息从通知队列里移除
+		if err := w.s.store.RemoveMessagesOfNotifyQueue(noAddrMessageIds); err != nil {
+			w.Warn("从通知队列里移除消息失败！", zap.Error(err), zap.Int64s("messageIDs", noAddrMessageIds))
+		}
+	}
+	return globalMessages
+}
+
+// skipNotifyMessage 读取通知队列时是否跳过此消息（在频道推送队列中或者暂缓路由中）
+func (w *webhook) skipNotifyMessage(messageId int64) bool {
+	w.notifyInflightLock.Lock()
+	defer w.notifyInflightLock.Unlock()
+	if _, ok := w.notifyInflight[messageId]; ok {
+		return true
+	}
+	deferUntil, ok := w.notifyDeferred[messageId]
+	if !ok {
+		return false
+	}
+	if time.Now().Before(deferUntil) {
+		return true
+	}
+	delete(w.notifyDeferred, messageId)
+	return false
+}
+
+// deferNotify 暂缓路由通知队列的消息
+func (w *webhook) deferNotify(messageId int64) {
+	w.notifyInflightLock.Lock()
+	defer w.notifyInflightLock.Unlock()
+	w.notifyDeferred[messageId] = time.Now().Add(webhookNotifyDeferDuration)
+}
+
+func (w *webhook) isNotifyInflight(messageId int64) bool {
+	w.notifyInflightLock.Lock()
+	defer w.notifyInflightLock.Unlock()
+	_, ok := w.notifyInflight[messageId]
+	return ok
+}
+
+func (w *webhook) setNotifyInflight(messageId int64, inflight bool) {
+	w.notifyInflightLock.Lock()
+	defer w.notifyInflightLock.Unlock()
+	if inflight {
+		w.notifyInflight[messageId] = struct{}{}
+	} else {
+		delete(w.notifyInflight, messageId)
+	}
+}
+
+// finishNotifyMessages 频道推送队列里的消息推送完成（或存入死信）后从通知队列移除
+func (w *webhook) finishNotifyMessages(messageIds []int64) {
+	if len(messageIds) == 0 {
+		return
+	}
+	if err := w.s.store.RemoveMessagesOfNotifyQueue(messageIds); err != nil {
+		// 没移除的消息保持推送中的状态，避免重复推送，节点重启后会重新推送
+		w.Warn("从通知队列里移除消息失败！", zap.Error(err), zap.Int64s("messageIDs", messageIds))
+		return
+	}
+	for _, messageId := range messageIds {
+		w.setNotifyInflight(messageId, false)
+	}
+}
+
+// pushToDestination 放入频道webhook地址的推送队列，返回是否放入成功
+// 队列满时，通知队列里的消息留在通知队列中等待下次推送，其他事件存入死信
+func (w *webhook) pushToDestination(addr string, item *webhookDestinationItem) bool {
+	w.destinationLock.Lock()
+	defer w.destinationLock.Unlock()
+	dest := w.destinations[addr]
+	if dest == nil {
+		select {
+		case <-w.stoped: // 已经停止，不再创建推送协程，按队列满处理
+			return w.destinationFull(addr, item)
+		default:
+		}
+		dest = &webhookDestination{
+			addr:   addr,
+			queueC: make(chan *webhookDestinationItem, w.s.opts.Webhook.ChannelQueueSize),
+		}
+		w.destinations[addr] = dest
+		w.stopWg.Add(1)
+		go func() {
+			defer w.stopWg.Done()
+			w.loopDestination(dest)
+		}()
+	}
+	select {
+	case dest.queueC <- item:
+		return true
+	default:
+	}
+	return w.destinationFull(addr, item)
+}
+
+// destinationFull 推送队列满了，通知队列里的消息留在通知队列中等待下次推送，其他事件存入死信
+func (w *webhook) destinationFull(addr string, item *webhookDestinationItem) bool {
+	if item.queueMessageId != 0 {
+		w.Debug("频道webhook推送队列已满，消息留在通知队列中！", zap.String("webhook", addr), zap.Int64("messageId", item.queueMessageId))
+		return false
+	}
+	w.Warn("频道webhook推送队列已满，事件存入死信！", zap.String("webhook", addr), zap.String("event", item.event))
+	w.addDeadLetter(addr, item.event, item.deadLetterData(), 0, errWebhookDestinationFull)
+	return false
+}
+
+// removeDestinationIfIdle 推送目标没有待推送的事件则回收
+func (w *webhook) removeDestinationIfIdle(dest *webhookDestination) bool {
+	w.destinationLock.Lock()
+	defer w.destinationLock.Unlock()
+	if len(dest.queueC) > 0 {
+		return false
+	}
+	delete(w.destinations, dest.addr)
+	return true
+}
+
+func (w *webhook) loopDestination(dest *webhookDestination) {
+	idleTimer := time.NewTimer(webhookDestinationIdleTimeout)
+	defer idleTimer.Stop()
+	for {
+		select {
+		case item := <-dest.queueC:
+			items := []*webhookDestinationItem{item}
+			// 合并队列里已有的事件
+		merge:
+			for len(items) < w.s.opts.Webhook.MsgNotifyEventCountPerPush {
+				select {
+				case item = <-dest.queueC:
+					items = append(items, item)
+				default:
+					break merge
+				}
+			}
+			w.sendToDestination(dest, items)
+			if !idleTimer.Stop() {
+				select {
+				case <-idleTimer.C:
+				default:
+				}
+			}
+			idleTimer.Reset(webhookDestinationIdleTimeout)
+		case <-idleTimer.C:
+			if w.removeDestinationIfIdle(dest) {
+				return
+			}
+			idleTimer.Reset(webhookDestinationIdleTimeout)
+		case <-w.stoped:
+			w.flushDestination(dest)
+			return
+		}
+	}
+}
+
+// flushDestination 停止时推送队列里还没有推送的事件存入死信，通知队列里的消息留在通知队列中重启后重新推送
+func (w *webhook) flushDestination(dest *webhookDestination) {
+	for {
+		select {
+		case item := <-dest.queueC:
+			if item.queueMessageId != 0 {
+				continue
+			}
+			w.addDeadLetter(dest.addr, item.event, item.deadLetterData(), 0, errWebhookStopped)
+		default:
+			return
+		}
+	}
+}
+
+func (w *webhook) sendToDestination(dest *webhookDestination, items []*webhookDestinationItem) {
+	messages := make([]*MessageResp, 0, len(items))
+	queueMessageIds := make([]int64, 0, len(items))
+	for _, item := range items {
+		if item.event == EventMsgNotify {
+			messages = append(messages, item.message)
+			if item.queueMessageId != 0 {
+				queueMessageIds = append(queueMessageIds, item.queueMessageId)
+			}
+			continue
+		}
+		if !w.sendWebhookWithRetry(dest.addr, item.event, item.data) { // 停止时还没有推送成功，存入死信
+			w.addDeadLetter(dest.addr, item.event, item.data, 0, errWebhookStopped)
+		}
+	}
+	if len(messages) > 0 {
+		messageData, err := json.Marshal(messages)
+		if err != nil {
+			w.Error("第三方消息通知的event数据不能json化！", zap.Error(err))
+			return
+		}
+		if w.sendWebhookWithRetry(dest.addr, EventMsgNotify, messageData) {
+			w.finishNotifyMessages(queueMessageIds)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookDestination(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events = make(map[string][][]byte)
+	)
+	fast := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		event := r.URL.Query().Get("event")
+		events[event] = append(events[event], data)
+		mu.Unlock()
+	}))
+	defer fast.Close()
+
+	slowC := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		<-slowC
+	}))
+	defer slow.Close()
+	defer close(slowC)
+
+	opts := NewOptions()
+	w := newTestWebhook(t, opts)
+	defer w.Stop()
+
+	// 慢的地址不影响其他地址的推送
+	w.pushToDestination(slow.URL, &webhookDestinationItem{event: EventMsgOffline, data: []byte("{}")})
+
+	w.pushToDestination(fast.URL, &webhookDestinationItem{event: EventMsgOffline, data: []byte(`{"message_id":1}`)})
+	w.pushToDestination(fast.URL, &webhookDestinationItem{event: EventMsgNotify, message: &MessageResp{MessageId: 2}})
+	w.pushToDestination(fast.URL, &webhookDestinationItem{event: EventMsgNotify, message: &MessageResp{MessageId: 3}})
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		notifyCount := 0
+		for _, data := range events[EventMsgNotify] {
+			var messages []*MessageResp
+			assert.NoError(t, json.Unmarshal(data, &messages))
+			notifyCount += len(messages)
+		}
+		return len(events[EventMsgOffline]) == 1 && notifyCount == 2
+	}, time.Second*5, time.Millisecond*10)
+}
+
+func TestWebhookDestinationFull(t *testing.T) {
+	slowC := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		<-slowC
+	}))
+	defer slow.Close()
+	defer close(slowC)
+
+	opts := NewOptions()
+	opts.Webhook.ChannelQueueSize = 1
+	w := newTestWebhook(t, opts)
+	defer w.Stop()
+
+	assert.True(t, w.pushToDestination(slow.URL, &webhookDestinationItem{event: EventMsgNotify, message: &MessageResp{MessageId: 1}}))
+	assert.Eventually(t, func() bool {
+		w.destinationLock.Lock()
+		defer w.destinationLock.Unlock()
+		return len(w.destinations[slow.URL].queueC) == 0
+	}, time.Second*5, time.Millisecond*10)
+	assert.True(t, w.pushToDestination(slow.URL, &webhookDestinationItem{event: EventMsgNotify, message: &MessageResp{MessageId: 2}}))
+
+	// 队列满了，通知队列里的消息不丢弃，留在通知队列中等待下次推送
+	assert.False(t, w.pushToDestination(slow.URL, &webhookDestinationItem{event: EventMsgNotify, message: &MessageResp{MessageId: 3}, queueMessageId: 3}))
+}
+
+func TestWebhookNotifyQueueNotBlockedByDestination(t *testing.T) {
+	var (
+		mu             sync.Mutex
+		globalMessages []*MessageResp
+	)
+	global := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		if r.URL.Query().Get("event") != EventMsgNotify {
+			return
+		}
+		var messages []*MessageResp
+		_ = json.Unmarshal(data, &messages)
+		mu.Lock()
+		globalMessages = append(globalMessages, messages...)
+		mu.Unlock()
+	}))
+	defer global.Close()
+
+	slowC := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		<-slowC
+	}))
+	defer slow.Close()
+	defer close(slowC)
+
+	s := NewTestServer(t, WithHTTPAddr(testFreeAddr(t)), WithManagerAddr(testFreeAddr(t)), WithDemoOn(false),
+		WithWebhookHTTPAddr(global.URL),
+		WithWebhookChannelOn(true),
+		WithWebhookMsgNotifyEventCountPerPush(2),
+		WithWebhookMsgNotifyEventPushInterval(time.Millisecond*10),
+	)
+	// 频道g1配置了响应很慢的webhook地址，g2没有配置，推送到全局webhook
+	s.webhook.channelWebhooks[wkutil.ChannelToKey("g1", wkproto.ChannelTypeGroup)] = channelWebhookCacheEntry{addr: slow.URL, expireAt: time.Now().Add(time.Hour)}
+	s.webhook.channelWebhooks[wkutil.ChannelToKey("g2", wkproto.ChannelTypeGroup)] = channelWebhookCacheEntry{expireAt: time.Now().Add(time.Hour)}
+
+	messages := make([]wkdb.Message, 0)
+	for i := 1; i <= 3; i++ {
+		messages = append(messages, wkdb.Message{RecvPacket: wkproto.RecvPacket{MessageID: int64(i), ChannelID: "g1", ChannelType: wkproto.ChannelTypeGroup, Payload: []byte("hello")}})
+	}
+	messages = append(messages, wkdb.Message{RecvPacket: wkproto.RecvPacket{MessageID: 4, ChannelID: "g2", ChannelType: wkproto.ChannelTypeGroup, Payload: []byte("hello")}})
+	err := s.Start()
+	assert.NoError(t, err)
+	defer func() {
+		_ = s.Stop()
+	}()
+	err = s.store.AppendMessageOfNotifyQueue(messages)
+	assert.NoError(t, err)
+
+	// 通知队列头部的消息在慢的地址推送中，不影响后面的消息推送到全局webhook
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(globalMessages) == 1 && globalMessages[0].MessageId == 4
+	}, time.Second*5, time.Millisecond*10)
+}
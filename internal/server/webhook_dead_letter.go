@@ -0,0 +1,149 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"go.uber.org/zap"
+)
+
+const (
+	webhookHeaderTimestamp = "X-WuKong-Timestamp" // 请求时间戳（秒）
+	webhookHeaderSignature = "X-WuKong-Signature" // 请求签名 格式为 sha256=<hex>
+
+	webhookDeadLetterErrorMaxLen = 512 // 死信记录的错误信息最大长度
+)
+
+// webhookSignature 计算webhook请求的签名 hex(HMAC-SHA256(secret, timestamp.event.body))
+// 第三方用同样的方式计算签名并比对，同时校验时间戳防止重放
+func webhookSignature(secret string, timestamp int64, event string, data []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write([]byte(event))
+	mac.Write([]byte("."))
+	mac.Write(data)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// signHeaders 返回签名相关的请求头，没有配置密钥返回nil
+func (w *webhook) signHeaders(event string, data []byte) map[string]string {
+	if w.s.opts.Webhook.Secret == "" {
+		return nil
+	}
+	timestamp := time.Now().Unix()
+	return map[string]string{
+		webhookHeaderTimestamp: strconv.FormatInt(timestamp, 10),
+		webhookHeaderSignature: webhookSignature(w.s.opts.Webhook.Secret, timestamp, event, data),
+	}
+}
+
+// retryBackoff 第attempt次失败后的重试等待时间，从RetryBackoff开始每次翻倍，最大不超过RetryMaxBackoff
+func (w *webhook) retryBackoff(attempt int) time.Duration {
+	backoff := w.s.opts.Webhook.RetryBackoff
+	maxBackoff := w.s.opts.Webhook.RetryMaxBackoff
+	if backoff <= 0 {
+		return 0
+	}
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if maxBackoff > 0 && backoff >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	if maxBackoff > 0 && backoff > maxBackoff {
+		return maxBackoff
+	}
+	return backoff
+}
+
+// waitRetry 等待重试，webhook停止了返回false
+func (w *webhook) waitRetry(attempt int) bool {
+	select {
+	case <-time.After(w.retryBackoff(attempt)):
+		return true
+	case <-w.stoped:
+		return false
+	}
+}
+
+// sendWebhookOfAddr 推送事件，addr为空表示推送到全局webhook
+func (w *webhook) sendWebhookOfAddr(addr string, event string, data []byte) error {
+	if addr != "" {
+		return w.sendWebhookForHttpOfAddr(addr, event, data)
+	}
+	if w.s.opts.WebhookGRPCOn() {
+		return w.sendWebhookForGRPC(event, data)
+	}
+	return w.sendWebhookForHttp(event, data)
+}
+
+// sendWebhookWithRetry 推送失败后按退避时间重试，超过最大重试次数后存入死信
+// 返回false表示webhook已停止，事件既没有推送成功也没有存入死信
+func (w *webhook) sendWebhookWithRetry(addr string, event string, data []byte) bool {
+	var err error
+	for attempt := 1; attempt <= w.s.opts.Webhook.MsgNotifyEventRetryMaxCount; attempt++ {
+		err = w.sendWebhookOfAddr(addr, event, data)
+		if err == nil {
+			return true
+		}
+		w.Error("请求webhook失败！", zap.Error(err), zap.String("webhook", addr), zap.String("event", event), zap.Int("attempt", attempt))
+		if attempt < w.s.opts.Webhook.MsgNotifyEventRetryMaxCount && !w.waitRetry(attempt) {
+			return false
+		}
+	}
+	w.Error("webhook推送失败超过最大次数！", zap.String("webhook", addr), zap.String("event", event))
+	w.addDeadLetter(addr, event, data, w.s.opts.Webhook.MsgNotifyEventRetryMaxCount, err)
+	return true
+}
+
+// addDeadLetter 推送失败超过最大次数的事件存入死信，可通过接口查看和重放
+func (w *webhook) addDeadLetter(addr string, event string, data []byte, attempts int, sendErr error) {
+	var errStr string
+	if sendErr != nil {
+		errStr = sendErr.Error()
+		if len(errStr) > webhookDeadLetterErrorMaxLen {
+			errStr = errStr[:webhookDeadLetterErrorMaxLen]
+		}
+	}
+	err := w.s.store.DB().AddWebhookDeadLetter(wkdb.WebhookDeadLetter{
+		Event:     event,
+		Addr:      addr,
+		Data:      data,
+		Attempts:  attempts,
+		Error:     errStr,
+		CreatedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		w.Error("保存webhook死信失败！", zap.Error(err), zap.String("webhook", addr), zap.String("event", event))
+	}
+}
+
+// replayDeadLetters 重新推送死信，推送成功的死信会被删除，返回推送成功的死信id
+func (w *webhook) replayDeadLetters(ids []uint64) ([]uint64, error) {
+	successIds := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		deadLetter, err := w.s.store.DB().GetWebhookDeadLetter(id)
+		if err != nil {
+			if err == wkdb.ErrNotFound {
+				continue
+			}
+			return successIds, err
+		}
+		if err = w.sendWebhookOfAddr(deadLetter.Addr, deadLetter.Event, deadLetter.Data); err != nil {
+			w.Warn("重放webhook死信失败！", zap.Error(err), zap.Uint64("id", id), zap.String("event", deadLetter.Event))
+			continue
+		}
+		successIds = append(successIds, id)
+	}
+	if len(successIds) > 0 {
+		if err := w.s.store.DB().RemoveWebhookDeadLetters(successIds); err != nil {
+			return successIds, err
+		}
+	}
+	return successIds, nil
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	secret := "test-secret"
+	var (
+		timestamp string
+		signature string
+		body      []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		timestamp = r.Header.Get(webhookHeaderTimestamp)
+		signature = r.Header.Get(webhookHeaderSignature)
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	opts := NewOptions()
+	opts.Webhook.Secret = secret
+	w := newTestWebhook(t, opts)
+	defer w.Stop()
+
+	err := w.sendWebhookForHttpOfAddr(srv.URL, EventMsgOffline, []byte(`{"message_id":1}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"message_id":1}`, string(body))
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	assert.NoError(t, err)
+	assert.Equal(t, webhookSignature(secret, ts, EventMsgOffline, body), signature)
+	assert.NotEqual(t, webhookSignature("other", ts, EventMsgOffline, body), signature)
+
+	// 没有配置密钥不签名
+	opts.Webhook.Secret = ""
+	err = w.sendWebhookForHttpOfAddr(srv.URL, EventMsgOffline, []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Empty(t, signature)
+}
+
+func TestWebhookRetryBackoff(t *testing.T) {
+	opts := NewOptions()
+	opts.Webhook.RetryBackoff = time.Second
+	opts.Webhook.RetryMaxBackoff = time.Second * 5
+	w := &webhook{s: &Server{opts: opts}}
+
+	assert.Equal(t, time.Second, w.retryBackoff(1))
+	assert.Equal(t, time.Second*2, w.retryBackoff(2))
+	assert.Equal(t, time.Second*4, w.retryBackoff(3))
+	assert.Equal(t, time.Second*5, w.retryBackoff(4))
+	assert.Equal(t, time.Second*5, w.retryBackoff(100))
+}
+
+func TestWebhookStopFlushToDeadLetters(t *testing.T) {
+	slowC := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		<-slowC
+	}))
+	defer slow.Close()
+	defer close(slowC)
+
+	opts := NewOptions()
+	w := newTestWebhook(t, opts)
+
+	// 等待重试的事件
+	w.addRetry(&webhookRetryItem{event: EventMsgOffline, data: []byte(`{"message_id":1}`), attempt: 1, retryAt: time.Now().Add(time.Hour)})
+	// 还没有提交的元数据事件
+	w.triggerAppliedEvent(&Event{Event: EventConversationRead, Data: map[string]interface{}{"uid": "u1"}})
+	// 频道推送队列里正在推送和等待推送的事件，通知队列里的消息不存入死信
+	w.pushToDestination(slow.URL, &webhookDestinationItem{event: EventMsgOffline, data: []byte(`{"message_id":2}`)})
+	assert.Eventually(t, func() bool {
+		w.destinationLock.Lock()
+		defer w.destinationLock.Unlock()
+		return len(w.destinations[slow.URL].queueC) == 0
+	}, time.Second*5, time.Millisecond*10)
+	w.pushToDestination(slow.URL, &webhookDestinationItem{event: EventMsgOffline, data: []byte(`{"message_id":3}`)})
+	w.pushToDestination(slow.URL, &webhookDestinationItem{event: EventMsgNotify, message: &MessageResp{MessageId: 4}, queueMessageId: 4})
+
+	w.Stop()
+
+	deadLetters, err := w.s.store.DB().GetWebhookDeadLetters(0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, deadLetters, 4)
+	datas := make([]string, 0, len(deadLetters))
+	for _, deadLetter := range deadLetters {
+		datas = append(datas, string(deadLetter.Data))
+	}
+	assert.ElementsMatch(t, []string{`{"message_id":1}`, `{"uid":"u1"}`, `{"message_id":2}`, `{"message_id":3}`}, datas)
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"errors"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterstore"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkhook"
+	"go.uber.org/zap"
+)
+
+// 元数据事件队列的大小
+const webhookAppliedEventQueueSize = 1024
+
+var errWebhookAppliedEventFull = errors.New("applied event queue is full")
+
+// storeAppliedEvents 元数据日志对应的webhook事件
+var storeAppliedEvents = map[clusterstore.CMDType]string{
+	clusterstore.CMDAddChannelInfo:      EventChannelCreated,
+	clusterstore.CMDUpdateChannelInfo:   EventChannelUpdated,
+	clusterstore.CMDDeleteChannel:       EventChannelDeleted,
+	clusterstore.CMDAddSubscribers:      EventSubscriberAdded,
+	clusterstore.CMDRemoveSubscribers:   EventSubscriberRemoved,
+	clusterstore.CMDRemoveAllSubscriber: EventSubscriberRemoved,
+	clusterstore.CMDAddDenylist:         EventDenylistAdded,
+	clusterstore.CMDRemoveDenylist:      EventDenylistRemoved,
+	clusterstore.CMDRemoveAllDenylist:   EventDenylistRemoved,
+	clusterstore.CMDAddAllowlist:        EventAllowlistAdded,
+	clusterstore.CMDRemoveAllowlist:     EventAllowlistRemoved,
+	clusterstore.CMDRemoveAllAllowlist:  EventAllowlistRemoved,
+}
+
+// onStoreApplied 频道相关的元数据应用后触发webhook事件
+// 每个副本都会应用日志，只由频道所在slot的领导节点触发，避免重复推送
+// 在raft应用日志的流程中调用，不能阻塞，事件交给webhook的队列异步推送
+func (s *Server) onStoreApplied(cmd *clusterstore.CMD) {
+	event, ok := storeAppliedEvents[cmd.CmdType]
+	if !ok || !s.opts.WebhookOn(event) || s.webhook == nil {
+		return
+	}
+	data, channelId, channelType, err := storeAppliedEventData(cmd)
+	if err != nil {
+		s.Warn("解码元数据日志失败！", zap.Error(err), zap.String("cmd", cmd.CmdType.String()))
+		return
+	}
+	isLeader, err := s.cluster.IsSlotLeaderOfChannel(channelId, channelType)
+	if err != nil || !isLeader {
+		return
+	}
+	s.webhook.triggerAppliedEvent(&Event{
+		Event: event,
+		Data:  data,
+	})
+}
+
+func storeAppliedEventData(cmd *clusterstore.CMD) (data interface{}, channelId string, channelType uint8, err error) {
+	timestamp := time.Now().Unix()
+	switch cmd.CmdType {
+	case clusterstore.CMDAddChannelInfo, clusterstore.CMDUpdateChannelInfo:
+		var channelInfo wkdb.ChannelInfo
+		if channelInfo, err = cmd.DecodeChannelInfo(); err != nil {
+			return
+		}
+		channelId, channelType = channelInfo.ChannelId, channelInfo.ChannelType
+		data = &wkhook.ChannelEvent{
+			ChannelId:   channelId,
+			ChannelType: uint32(channelType),
+			Ban:         channelInfo.Ban,
+			Large:       channelInfo.Large,
+			Disband:     channelInfo.Disband,
+			Timestamp:   timestamp,
+		}
+	case clusterstore.CMDDeleteChannel:
+		if channelId, channelType, err = cmd.DecodeChannel(); err != nil {
+			return
+		}
+		data = &wkhook.ChannelEvent{
+			ChannelId:   channelId,
+			ChannelType: uint32(channelType),
+			Timestamp:   timestamp,
+		}
+	case clusterstore.CMDAddSubscribers, clusterstore.CMDAddDenylist, clusterstore.CMDAddAllowlist:
+		var members []wkdb.Member
+		if channelId, channelType, members, err = cmd.DecodeMembers(); err != nil {
+			return
+		}
+		uids := make([]string, 0, len(members))
+		for _, member := range members {
+			uids = append(uids, member.Uid)
+		}
+		data = &wkhook.ChannelMembersEvent{
+			ChannelId:   channelId,
+			ChannelType: uint32(channelType),
+			Uids:        uids,
+			Timestamp:   timestamp,
+		}
+	case clusterstore.CMDRemoveSubscribers, clusterstore.CMDRemoveDenylist, clusterstore.CMDRemoveAllowlist:
+		var uids []string
+		if channelId, channelType, uids, err = cmd.DecodeChannelUids(); err != nil {
+			return
+		}
+		data = &wkhook.ChannelMembersEvent{
+			ChannelId:   channelId,
+			ChannelType: uint32(channelType),
+			Uids:        uids,
+			Timestamp:   timestamp,
+		}
+	case clusterstore.CMDRemoveAllSubscriber, clusterstore.CMDRemoveAllDenylist, clusterstore.CMDRemoveAllAllowlist:
+		if channelId, channelType, err = cmd.DecodeChannel(); err != nil {
+			return
+		}
+		data = &wkhook.ChannelMembersEvent{
+			ChannelId:   channelId,
+			ChannelType: uint32(channelType),
+			All:         true,
+			Timestamp:   timestamp,
+		}
+	}
+	return
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterstore"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/WuKongIM/pkg/wkhook"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreAppliedEventData(t *testing.T) {
+	cmd := clusterstore.NewCMD(clusterstore.CMDAddSubscribers, clusterstore.EncodeMembers("g1", 2, []wkdb.Member{{Uid: "u1"}, {Uid: "u2"}}))
+	data, channelId, channelType, err := storeAppliedEventData(cmd)
+	assert.NoError(t, err)
+	assert.Equal(t, "g1", channelId)
+	assert.Equal(t, uint8(2), channelType)
+	membersEvent := data.(*wkhook.ChannelMembersEvent)
+	assert.Equal(t, []string{"u1", "u2"}, membersEvent.Uids)
+
+	cmd = clusterstore.NewCMD(clusterstore.CMDRemoveAllDenylist, clusterstore.EncodeChannel("g1", 2))
+	data, _, _, err = storeAppliedEventData(cmd)
+	assert.NoError(t, err)
+	assert.True(t, data.(*wkhook.ChannelMembersEvent).All)
+
+	// json格式的字段名同proto字段名
+	jsonData, err := json.Marshal(data)
+	assert.NoError(t, err)
+	var m map[string]interface{}
+	assert.NoError(t, json.Unmarshal(jsonData, &m))
+	assert.Equal(t, "g1", m["channel_id"])
+	assert.Equal(t, true, m["all"])
+}
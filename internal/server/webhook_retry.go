@@ -0,0 +1,160 @@
+package server
+
+import (
+	"container/heap"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	errWebhookRetryQueueFull = errors.New("webhook retry queue is full")
+	errWebhookStopped        = errors.New("webhook stopped")
+)
+
+const webhookStopTimeout = time.Second * 5 // 停止时等待正在推送的事件结束的最长时间
+
+// webhookRetryItem 等待重试的事件
+type webhookRetryItem struct {
+	addr    string // 推送地址，为空表示全局webhook
+	event   string
+	data    []byte
+	attempt int       // 已经失败的次数
+	retryAt time.Time // 下次重试的时间
+	lastErr error     // 最后一次失败的原因
+	index   int       // 在堆中的索引
+}
+
+// webhookRetryHeap 按重试时间排序的最小堆
+type webhookRetryHeap []*webhookRetryItem
+
+func (h webhookRetryHeap) Len() int { return len(h) }
+
+func (h webhookRetryHeap) Less(i, j int) bool { return h[i].retryAt.Before(h[j].retryAt) }
+
+func (h webhookRetryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *webhookRetryHeap) Push(x any) {
+	item := x.(*webhookRetryItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *webhookRetryHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*h = old[:n-1]
+	return item
+}
+
+// deliverEvent 推送一次事件，失败后放入重试队列，超过最大重试次数存入死信
+// 在事件协程池中执行，不会在协程池里等待重试
+func (w *webhook) deliverEvent(addr string, event string, data []byte, attempt int) {
+	err := w.sendWebhookOfAddr(addr, event, data)
+	if err == nil {
+		return
+	}
+	attempt++
+	w.Error("请求webhook失败！", zap.Error(err), zap.String("webhook", addr), zap.String("event", event), zap.Int("attempt", attempt))
+	if attempt >= w.s.opts.Webhook.MsgNotifyEventRetryMaxCount {
+		w.Error("webhook推送失败超过最大次数！", zap.String("webhook", addr), zap.String("event", event))
+		w.addDeadLetter(addr, event, data, attempt, err)
+		return
+	}
+	w.addRetry(&webhookRetryItem{
+		addr:    addr,
+		event:   event,
+		data:    data,
+		attempt: attempt,
+		retryAt: time.Now().Add(w.retryBackoff(attempt)),
+		lastErr: err,
+	})
+}
+
+// addRetry 放入重试队列，队列满了或者webhook已经停止存入死信
+func (w *webhook) addRetry(item *webhookRetryItem) {
+	w.retryLock.Lock()
+	select {
+	case <-w.stoped: // 已经停止（在锁内判断，停止后取出重试队列时不会漏掉）
+		w.retryLock.Unlock()
+		sendErr := item.lastErr
+		if sendErr == nil {
+			sendErr = errWebhookStopped
+		}
+		w.addDeadLetter(item.addr, item.event, item.data, item.attempt, sendErr)
+		return
+	default:
+	}
+	if w.retryItems.Len() >= w.s.opts.Webhook.RetryQueueSize {
+		w.retryLock.Unlock()
+		w.Warn("webhook重试队列已满，事件存入死信！", zap.String("webhook", item.addr), zap.String("event", item.event))
+		sendErr := item.lastErr
+		if sendErr == nil {
+			sendErr = errWebhookRetryQueueFull
+		}
+		w.addDeadLetter(item.addr, item.event, item.data, item.attempt, sendErr)
+		return
+	}
+	heap.Push(&w.retryItems, item)
+	w.retryLock.Unlock()
+
+	select {
+	case w.retryNotifyC <- struct{}{}:
+	default:
+	}
+}
+
+// popDueRetries 取出已经到重试时间的事件，返回下一个事件的等待时间
+func (w *webhook) popDueRetries(now time.Time) ([]*webhookRetryItem, time.Duration) {
+	w.retryLock.Lock()
+	defer w.retryLock.Unlock()
+	var items []*webhookRetryItem
+	for w.retryItems.Len() > 0 {
+		item := w.retryItems[0]
+		if item.retryAt.After(now) {
+			return items, item.retryAt.Sub(now)
+		}
+		items = append(items, heap.Pop(&w.retryItems).(*webhookRetryItem))
+	}
+	return items, time.Hour
+}
+
+// loopRetry 到重试时间的事件交给事件协程池推送
+func (w *webhook) loopRetry() {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		items, wait := w.popDueRetries(time.Now())
+		for _, item := range items {
+			item := item
+			err := w.eventPool.Submit(func() {
+				w.deliverEvent(item.addr, item.event, item.data, item.attempt)
+			})
+			if err != nil { // 协程池满了，稍后再试
+				item.retryAt = time.Now().Add(w.retryBackoff(1))
+				w.addRetry(item)
+			}
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+		select {
+		case <-timer.C:
+		case <-w.retryNotifyC:
+		case <-w.stoped:
+			return
+		}
+	}
+}
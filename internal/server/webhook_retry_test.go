@@ -0,0 +1,63 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookRetryQueue(t *testing.T) {
+	var count atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		// 前两次失败，之后成功
+		if count.Add(1) <= 2 {
+			rw.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	opts := NewOptions()
+	opts.Webhook.HTTPAddr = srv.URL
+	opts.Webhook.RetryBackoff = time.Millisecond * 10
+	opts.Webhook.RetryMaxBackoff = time.Millisecond * 20
+	opts.Webhook.MsgNotifyEventRetryMaxCount = 5
+	w := newTestWebhook(t, opts)
+	go w.loopRetry()
+	defer w.Stop()
+
+	w.TriggerEvent(&Event{Event: EventMsgOffline, Data: map[string]interface{}{"message_id": 1}})
+
+	assert.Eventually(t, func() bool {
+		return count.Load() == 3
+	}, time.Second*5, time.Millisecond*10)
+	assert.Eventually(t, func() bool {
+		w.retryLock.Lock()
+		defer w.retryLock.Unlock()
+		return w.retryItems.Len() == 0
+	}, time.Second*5, time.Millisecond*10)
+}
+
+func TestWebhookPopDueRetries(t *testing.T) {
+	opts := NewOptions()
+	w := newTestWebhook(t, opts)
+	defer w.Stop()
+
+	now := time.Now()
+	w.addRetry(&webhookRetryItem{event: "b", retryAt: now.Add(time.Second)})
+	w.addRetry(&webhookRetryItem{event: "a", retryAt: now.Add(-time.Second)})
+	w.addRetry(&webhookRetryItem{event: "c", retryAt: now.Add(time.Second * 2)})
+
+	items, wait := w.popDueRetries(now)
+	assert.Len(t, items, 1)
+	assert.Equal(t, "a", items[0].event)
+	assert.Equal(t, time.Second, wait)
+
+	items, _ = w.popDueRetries(now.Add(time.Second * 3))
+	assert.Len(t, items, 2)
+	assert.Equal(t, "b", items[0].event)
+	assert.Equal(t, "c", items[1].event)
+}
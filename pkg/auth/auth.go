@@ -2,9 +2,11 @@ package auth
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/WuKongIM/WuKongIM/pkg/auth/resource"
 	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type Kind string
@@ -24,24 +26,62 @@ const (
 )
 
 type AuthConfig struct {
-	On         bool   // 是否开启鉴权
-	SuperToken string // 超级token
-	Kind       Kind   // 鉴权类型
-	Users      []UserConfig
+	On         bool             // 是否开启鉴权
+	SuperToken string           // 超级token
+	Kind       Kind             // 鉴权类型
+	Users      []UserConfig     // 配置文件中的用户，作为初始管理员，不能通过接口修改
+	Store      Store            // 存储中的用户和角色，可以通过接口增删改
+	Cache      *PermissionCache // 用户权限索引的缓存，为nil则每次鉴权时构建索引
 }
 
-func (a AuthConfig) Auth(username string, password string) error {
-	if len(a.Users) == 0 {
-		return ErrAuthFailed
+// Store 管理用户和角色的存储
+type Store interface {
+	// User 获取用户，不存在返回nil
+	User(username string) *StoredUser
+	// Role 获取角色，不存在返回nil
+	Role(name string) *Role
+	// Version 存储的版本，用户或角色变化后版本会变化
+	Version() uint64
+}
+
+// StoredUser 存储中的用户，权限由拥有的角色决定
+type StoredUser struct {
+	Username     string
+	PasswordHash string // 密码的bcrypt hash
+	Roles        []string
+}
+
+// Role 角色，一组资源权限
+type Role struct {
+	Name        string
+	Permissions PermissionConfigs
+}
+
+// HashPassword 计算密码的bcrypt hash
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
 	}
+	return string(hash), nil
+}
 
-	for _, user := range a.Users {
-		if user.Username == username && user.Password == password {
+func (a AuthConfig) Auth(username string, password string) error {
+	if user := a.configUser(username); user != nil {
+		if user.Password == password {
 			return nil
 		}
+		return ErrAuthFailed
+	}
 
+	user := a.storedUser(username)
+	if user == nil {
+		return ErrAuthFailed
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return ErrAuthFailed
 	}
-	return ErrAuthFailed
+	return nil
 }
 
 // HasPermission 是否有权限
@@ -53,41 +93,71 @@ func (a AuthConfig) HasPermission(username string, rs resource.Id, action Action
 	if username == "" {
 		return false
 	}
-	if len(a.Users) == 0 {
-		return false
+	return a.permissionIndex(username).Allow(rs, action)
+}
+
+// permissionIndex 用户的权限索引，开启缓存时同一存储版本下只构建一次
+func (a AuthConfig) permissionIndex(username string) PermissionIndex {
+	if a.Cache == nil {
+		return a.Persmissions(username).Index()
 	}
-	for _, user := range a.Users {
-		if user.Username == username {
-			for _, permission := range user.Permissions {
-				if permission.Resource == rs || permission.Resource == resource.All {
-					for _, a := range permission.Actions {
-						if a == ActionAll || a == action {
-							return true
-						}
-					}
-				}
-			}
-		}
+	var version uint64
+	if a.Store != nil {
+		version = a.Store.Version()
 	}
-	return false
+	if index, ok := a.Cache.get(username, version); ok {
+		return index
+	}
+	index := a.Persmissions(username).Index()
+	a.Cache.set(username, version, index)
+	return index
 }
 
 func (a AuthConfig) HasPermissionWithContext(ctx *wkhttp.Context, rs resource.Id, action Action) bool {
 	return a.HasPermission(ctx.Username(), rs, action)
 }
 
+// Persmissions 用户的权限，存储中的用户为所有角色权限的合集
 func (a AuthConfig) Persmissions(username string) PermissionConfigs {
-	if len(a.Users) == 0 {
+	if user := a.configUser(username); user != nil {
+		return user.Permissions
+	}
+	user := a.storedUser(username)
+	if user == nil {
 		return nil
 	}
-	for _, user := range a.Users {
+	var permissions PermissionConfigs
+	for _, roleName := range user.Roles {
+		role := a.Store.Role(roleName)
+		if role == nil {
+			continue
+		}
+		permissions = append(permissions, role.Permissions...)
+	}
+	return permissions
+}
+
+// IsConfigUser 是否是配置文件中的用户
+func (a AuthConfig) IsConfigUser(username string) bool {
+	return a.configUser(username) != nil
+}
+
+func (a AuthConfig) configUser(username string) *UserConfig {
+	for i, user := range a.Users {
 		if user.Username == username {
-			return user.Permissions
+			return &a.Users[i]
 		}
 	}
 	return nil
 }
 
+func (a AuthConfig) storedUser(username string) *StoredUser {
+	if a.Store == nil {
+		return nil
+	}
+	return a.Store.User(username)
+}
+
 type UserConfig struct {
 	Username    string
 	Password    string
@@ -101,6 +171,80 @@ type PermissionConfig struct {
 
 type PermissionConfigs []PermissionConfig
 
+// Allow 是否允许对资源进行操作
+func (p PermissionConfigs) Allow(rs resource.Id, action Action) bool {
+	return p.Index().Allow(rs, action)
+}
+
+// Index 按资源构建权限索引
+func (p PermissionConfigs) Index() PermissionIndex {
+	index := make(PermissionIndex, len(p))
+	for _, permission := range p {
+		actions := index[permission.Resource]
+		if actions == nil {
+			actions = make(map[Action]struct{}, len(permission.Actions))
+			index[permission.Resource] = actions
+		}
+		for _, a := range permission.Actions {
+			actions[a] = struct{}{}
+		}
+	}
+	return index
+}
+
+// PermissionIndex 资源到操作集合的索引
+type PermissionIndex map[resource.Id]map[Action]struct{}
+
+// Allow 是否允许对资源进行操作，只查找资源本身和所有资源（*）两项
+func (pi PermissionIndex) Allow(rs resource.Id, action Action) bool {
+	return pi.allowResource(rs, action) || (rs != resource.All && pi.allowResource(resource.All, action))
+}
+
+func (pi PermissionIndex) allowResource(rs resource.Id, action Action) bool {
+	actions := pi[rs]
+	if actions == nil {
+		return false
+	}
+	if _, ok := actions[ActionAll]; ok {
+		return true
+	}
+	_, ok := actions[action]
+	return ok
+}
+
+// PermissionCache 用户权限索引的缓存，存储版本变化后整体失效
+type PermissionCache struct {
+	mu      sync.RWMutex
+	version uint64
+	indexes map[string]PermissionIndex
+}
+
+func NewPermissionCache() *PermissionCache {
+	return &PermissionCache{
+		indexes: make(map[string]PermissionIndex),
+	}
+}
+
+func (c *PermissionCache) get(username string, version uint64) (PermissionIndex, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.version != version {
+		return nil, false
+	}
+	index, ok := c.indexes[username]
+	return index, ok
+}
+
+func (c *PermissionCache) set(username string, version uint64, index PermissionIndex) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.version != version {
+		c.version = version
+		c.indexes = make(map[string]PermissionIndex)
+	}
+	c.indexes[username] = index
+}
+
 func (p PermissionConfigs) Format() string {
 	var str string
 	for i, permission := range p {
@@ -114,6 +258,15 @@ func (p PermissionConfigs) Format() string {
 
 type Actions []Action
 
+// ParseActions 解析资源操作，例如 rw
+func ParseActions(s string) Actions {
+	actions := make(Actions, 0, len(s))
+	for _, r := range s {
+		actions = append(actions, Action(string(r)))
+	}
+	return actions
+}
+
 func (as Actions) Format() string {
 
 	str := ""
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/WuKongIM/WuKongIM/pkg/auth/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+type testStore struct {
+	users map[string]*StoredUser
+	roles map[string]*Role
+
+	version uint64
+}
+
+func (t *testStore) User(username string) *StoredUser {
+	return t.users[username]
+}
+
+func (t *testStore) Role(name string) *Role {
+	return t.roles[name]
+}
+
+func (t *testStore) Version() uint64 {
+	return t.version
+}
+
+func TestAuthWithStore(t *testing.T) {
+	passwordHash, err := HashPassword("pwd2")
+	assert.NoError(t, err)
+
+	cfg := AuthConfig{
+		On: true,
+		Users: []UserConfig{
+			{Username: "admin", Password: "pwd", Permissions: PermissionConfigs{{Resource: resource.All, Actions: Actions{ActionAll}}}},
+		},
+		Store: &testStore{
+			users: map[string]*StoredUser{
+				"ops": {Username: "ops", PasswordHash: passwordHash, Roles: []string{"slotAdmin", "notExist"}},
+			},
+			roles: map[string]*Role{
+				"slotAdmin": {Name: "slotAdmin", Permissions: PermissionConfigs{{Resource: resource.Slot.Migrate, Actions: ParseActions("rw")}}},
+			},
+		},
+	}
+
+	// 配置文件中的用户
+	assert.NoError(t, cfg.Auth("admin", "pwd"))
+	assert.True(t, cfg.HasPermission("admin", resource.ClusterChannel.Migrate, ActionWrite))
+
+	// 存储中的用户
+	assert.NoError(t, cfg.Auth("ops", "pwd2"))
+	assert.ErrorIs(t, cfg.Auth("ops", "pwd"), ErrAuthFailed)
+	assert.ErrorIs(t, cfg.Auth("nobody", "pwd"), ErrAuthFailed)
+	assert.True(t, cfg.HasPermission("ops", resource.Slot.Migrate, ActionWrite))
+	assert.False(t, cfg.HasPermission("ops", resource.ClusterChannel.Migrate, ActionRead))
+	assert.Equal(t, "slotMigrate:rw", cfg.Persmissions("ops").Format())
+}
+
+func TestAuthPermissionCache(t *testing.T) {
+	store := &testStore{
+		users: map[string]*StoredUser{
+			"ops": {Username: "ops", Roles: []string{"slotAdmin"}},
+		},
+		roles: map[string]*Role{
+			"slotAdmin": {Name: "slotAdmin", Permissions: PermissionConfigs{{Resource: resource.Slot.Migrate, Actions: ParseActions("r")}}},
+		},
+		version: 1,
+	}
+	cfg := AuthConfig{
+		On:    true,
+		Store: store,
+		Cache: NewPermissionCache(),
+	}
+	assert.True(t, cfg.HasPermission("ops", resource.Slot.Migrate, ActionRead))
+	assert.False(t, cfg.HasPermission("ops", resource.Slot.Migrate, ActionWrite))
+
+	// 存储版本没变，使用缓存的权限
+	store.roles["slotAdmin"] = &Role{Name: "slotAdmin", Permissions: PermissionConfigs{{Resource: resource.All, Actions: Actions{ActionAll}}}}
+	assert.False(t, cfg.HasPermission("ops", resource.Slot.Migrate, ActionWrite))
+
+	// 存储版本变化后缓存失效
+	store.version = 2
+	assert.True(t, cfg.HasPermission("ops", resource.Slot.Migrate, ActionWrite))
+	assert.True(t, cfg.HasPermission("ops", resource.ClusterNode.Drain, ActionRead))
+}
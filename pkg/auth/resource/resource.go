@@ -14,6 +14,23 @@ var ClusterChannel = channel{
 	Stop:    "clusterchannelStop",    // 停止频道
 }
 
+// 节点资源
+var ClusterNode = node{
+	Drain: "clusternodeDrain", // 排空节点
+}
+
+// 集群资源
+var Cluster = cluster{
+	Info: "clusterInfo", // 集群信息（集群配置）
+}
+
+// 管理后台资源
+var Manager = manager{
+	User:  "managerUser",  // 管理用户
+	Role:  "managerRole",  // 管理角色
+	Audit: "managerAudit", // 审计日志
+}
+
 type slot struct {
 	Migrate Id
 }
@@ -24,4 +41,18 @@ type channel struct {
 	Stop    Id
 }
 
+type node struct {
+	Drain Id
+}
+
+type cluster struct {
+	Info Id
+}
+
+type manager struct {
+	User  Id
+	Role  Id
+	Audit Id
+}
+
 var All Id = "*"
@@ -21,6 +21,18 @@ const (
 	CMDTypeSlotMigrate                       // 槽迁移
 	CMDTypeSlotUpdate                        // 槽更新
 	CMDTypeNodeStatusChange                  // 节点状态改变
+	CMDTypeNodeTopologyChange                // 节点拓扑（可用区/机架）变更
+	CMDTypeNodeDrainingChange                // 节点排空状态变更
+	CMDTypeNodeProtoVersionChange            // 节点协议版本变更
+	CMDTypeFeatureEnable                     // 开启集群特性
+	CMDTypeApiKeySave                        // 保存业务api访问密钥（新增、修改或轮换）
+	CMDTypeApiKeyRemove                      // 吊销业务api访问密钥
+	CMDTypeManagerUserSave                   // 保存管理后台用户
+	CMDTypeManagerUserRemove                 // 删除管理后台用户
+	CMDTypeManagerRoleSave                   // 保存管理后台角色
+	CMDTypeManagerRoleRemove                 // 删除管理后台角色
+	CMDTypeBotSave                           // 保存服务端机器人
+	CMDTypeBotRemove                         // 删除服务端机器人
 
 )
 
@@ -48,6 +60,30 @@ func (c CMDType) String() string {
 		return "CMDTypeSlotUpdate"
 	case CMDTypeNodeStatusChange:
 		return "CMDTypeNodeStatusChange"
+	case CMDTypeNodeTopologyChange:
+		return "CMDTypeNodeTopologyChange"
+	case CMDTypeNodeDrainingChange:
+		return "CMDTypeNodeDrainingChange"
+	case CMDTypeNodeProtoVersionChange:
+		return "CMDTypeNodeProtoVersionChange"
+	case CMDTypeFeatureEnable:
+		return "CMDTypeFeatureEnable"
+	case CMDTypeApiKeySave:
+		return "CMDTypeApiKeySave"
+	case CMDTypeApiKeyRemove:
+		return "CMDTypeApiKeyRemove"
+	case CMDTypeManagerUserSave:
+		return "CMDTypeManagerUserSave"
+	case CMDTypeManagerUserRemove:
+		return "CMDTypeManagerUserRemove"
+	case CMDTypeManagerRoleSave:
+		return "CMDTypeManagerRoleSave"
+	case CMDTypeManagerRoleRemove:
+		return "CMDTypeManagerRoleRemove"
+	case CMDTypeBotSave:
+		return "CMDTypeBotSave"
+	case CMDTypeBotRemove:
+		return "CMDTypeBotRemove"
 	}
 	return "CMDTypeUnknown"
 }
@@ -169,6 +205,92 @@ func (c *CMD) CMDContent() (string, error) {
 			"nodeId": nodeId,
 			"status": status,
 		}), nil
+	case CMDTypeNodeTopologyChange:
+		nodeId, topology, err := DecodeNodeTopologyChange(c.Data)
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(map[string]interface{}{
+			"nodeId": nodeId,
+			"zone":   topology.Zone,
+			"rack":   topology.Rack,
+		}), nil
+	case CMDTypeNodeDrainingChange:
+		nodeId, draining, err := DecodeNodeDrainingChange(c.Data)
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(map[string]interface{}{
+			"nodeId":   nodeId,
+			"draining": draining,
+		}), nil
+	case CMDTypeNodeProtoVersionChange:
+		nodeId, protoVersion, err := DecodeNodeProtoVersionChange(c.Data)
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(map[string]interface{}{
+			"nodeId":       nodeId,
+			"protoVersion": protoVersion,
+		}), nil
+	case CMDTypeFeatureEnable:
+		return wkutil.ToJSON(map[string]interface{}{
+			"feature": string(c.Data),
+		}), nil
+	case CMDTypeApiKeySave:
+		apiKey := &pb.ApiKey{}
+		err := apiKey.Unmarshal(c.Data)
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(map[string]interface{}{
+			"id":              apiKey.Id,
+			"name":            apiKey.Name,
+			"scopes":          apiKey.Scopes,
+			"channelPrefixes": apiKey.ChannelPrefixes,
+			"expireAt":        apiKey.ExpireAt,
+			"rotatedAt":       apiKey.RotatedAt,
+		}), nil
+	case CMDTypeApiKeyRemove:
+		return wkutil.ToJSON(map[string]interface{}{
+			"id": string(c.Data),
+		}), nil
+	case CMDTypeManagerUserSave:
+		user := &pb.ManagerUser{}
+		err := user.Unmarshal(c.Data)
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(map[string]interface{}{
+			"username": user.Username,
+			"roles":    user.Roles,
+		}), nil
+	case CMDTypeManagerUserRemove:
+		return wkutil.ToJSON(map[string]interface{}{
+			"username": string(c.Data),
+		}), nil
+	case CMDTypeManagerRoleSave:
+		role := &pb.ManagerRole{}
+		err := role.Unmarshal(c.Data)
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(role), nil
+	case CMDTypeManagerRoleRemove:
+		return wkutil.ToJSON(map[string]interface{}{
+			"name": string(c.Data),
+		}), nil
+	case CMDTypeBotSave:
+		bot := &pb.Bot{}
+		err := bot.Unmarshal(c.Data)
+		if err != nil {
+			return "", err
+		}
+		return wkutil.ToJSON(bot), nil
+	case CMDTypeBotRemove:
+		return wkutil.ToJSON(map[string]interface{}{
+			"uid": string(c.Data),
+		}), nil
 	}
 
 	return "", nil
@@ -193,6 +315,70 @@ func DecodeApiServerAddrChange(data []byte) (uint64, string, error) {
 	return nodeId, apiServerAddr, err
 }
 
+func EncodeNodeTopologyChange(nodeId uint64, topology pb.Topology) ([]byte, error) {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+	enc.WriteUint64(nodeId)
+	enc.WriteString(topology.Zone)
+	enc.WriteString(topology.Rack)
+	return enc.Bytes(), nil
+}
+
+func DecodeNodeTopologyChange(data []byte) (uint64, pb.Topology, error) {
+	dec := wkproto.NewDecoder(data)
+	var err error
+	var nodeId uint64
+	var topology pb.Topology
+	if nodeId, err = dec.Uint64(); err != nil {
+		return 0, topology, err
+	}
+	if topology.Zone, err = dec.String(); err != nil {
+		return 0, topology, err
+	}
+	if topology.Rack, err = dec.String(); err != nil {
+		return 0, topology, err
+	}
+	return nodeId, topology, nil
+}
+
+func EncodeNodeDrainingChange(nodeId uint64, draining bool) ([]byte, error) {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+	enc.WriteUint64(nodeId)
+	enc.WriteUint8(wkutil.BoolToUint8(draining))
+	return enc.Bytes(), nil
+}
+
+func DecodeNodeDrainingChange(data []byte) (uint64, bool, error) {
+	dec := wkproto.NewDecoder(data)
+	var err error
+	var nodeId uint64
+	if nodeId, err = dec.Uint64(); err != nil {
+		return 0, false, err
+	}
+	draining, err := dec.Uint8()
+	return nodeId, wkutil.Uint8ToBool(draining), err
+}
+
+func EncodeNodeProtoVersionChange(nodeId uint64, protoVersion uint32) ([]byte, error) {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+	enc.WriteUint64(nodeId)
+	enc.WriteUint32(protoVersion)
+	return enc.Bytes(), nil
+}
+
+func DecodeNodeProtoVersionChange(data []byte) (uint64, uint32, error) {
+	dec := wkproto.NewDecoder(data)
+	var err error
+	var nodeId uint64
+	if nodeId, err = dec.Uint64(); err != nil {
+		return 0, 0, err
+	}
+	protoVersion, err := dec.Uint32()
+	return nodeId, protoVersion, err
+}
+
 func EncodeNodeOnlineStatusChange(nodeId uint64, online bool) ([]byte, error) {
 	enc := wkproto.NewEncoder()
 	defer enc.End()
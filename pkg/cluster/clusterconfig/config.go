@@ -182,6 +182,145 @@ func (c *Config) updateApiServerAddr(nodeId uint64, addr string) {
 	}
 }
 
+func (c *Config) updateNodeTopology(nodeId uint64, topology pb.Topology) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, node := range c.cfg.Nodes {
+		if node.Id == nodeId {
+			node.Zone = topology.Zone
+			node.Rack = topology.Rack
+			return
+		}
+	}
+}
+
+func (c *Config) updateNodeDraining(nodeId uint64, draining bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, node := range c.cfg.Nodes {
+		if node.Id == nodeId {
+			node.Draining = draining
+			return
+		}
+	}
+}
+
+func (c *Config) updateNodeProtoVersion(nodeId uint64, protoVersion uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, node := range c.cfg.Nodes {
+		if node.Id == nodeId {
+			node.ProtoVersion = protoVersion
+			return
+		}
+	}
+}
+
+func (c *Config) enableFeature(feature string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if wkutil.ArrayContains(c.cfg.Features, feature) {
+		return
+	}
+	c.cfg.Features = append(c.cfg.Features, feature)
+}
+
+// saveApiKey 新增或替换业务api访问密钥
+func (c *Config) saveApiKey(apiKey *pb.ApiKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, k := range c.cfg.ApiKeys {
+		if k.Id == apiKey.Id {
+			c.cfg.ApiKeys[i] = apiKey
+			return
+		}
+	}
+	c.cfg.ApiKeys = append(c.cfg.ApiKeys, apiKey)
+}
+
+func (c *Config) removeApiKey(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, k := range c.cfg.ApiKeys {
+		if k.Id == id {
+			c.cfg.ApiKeys = append(c.cfg.ApiKeys[:i], c.cfg.ApiKeys[i+1:]...)
+			return
+		}
+	}
+}
+
+// saveManagerUser 新增或替换管理后台用户
+func (c *Config) saveManagerUser(user *pb.ManagerUser) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, u := range c.cfg.ManagerUsers {
+		if u.Username == user.Username {
+			c.cfg.ManagerUsers[i] = user
+			return
+		}
+	}
+	c.cfg.ManagerUsers = append(c.cfg.ManagerUsers, user)
+}
+
+func (c *Config) removeManagerUser(username string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, u := range c.cfg.ManagerUsers {
+		if u.Username == username {
+			c.cfg.ManagerUsers = append(c.cfg.ManagerUsers[:i], c.cfg.ManagerUsers[i+1:]...)
+			return
+		}
+	}
+}
+
+// saveManagerRole 新增或替换管理后台角色
+func (c *Config) saveManagerRole(role *pb.ManagerRole) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, r := range c.cfg.ManagerRoles {
+		if r.Name == role.Name {
+			c.cfg.ManagerRoles[i] = role
+			return
+		}
+	}
+	c.cfg.ManagerRoles = append(c.cfg.ManagerRoles, role)
+}
+
+func (c *Config) removeManagerRole(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, r := range c.cfg.ManagerRoles {
+		if r.Name == name {
+			c.cfg.ManagerRoles = append(c.cfg.ManagerRoles[:i], c.cfg.ManagerRoles[i+1:]...)
+			return
+		}
+	}
+}
+
+// saveBot 新增或替换服务端机器人
+func (c *Config) saveBot(bot *pb.Bot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, b := range c.cfg.Bots {
+		if b.Uid == bot.Uid {
+			c.cfg.Bots[i] = bot
+			return
+		}
+	}
+	c.cfg.Bots = append(c.cfg.Bots, bot)
+}
+
+func (c *Config) removeBot(uid string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, b := range c.cfg.Bots {
+		if b.Uid == uid {
+			c.cfg.Bots = append(c.cfg.Bots[:i], c.cfg.Bots[i+1:]...)
+			return
+		}
+	}
+}
+
 func (c *Config) updateNodeOnlineStatus(nodeId uint64, online bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -0,0 +1,101 @@
+package pb
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// ApiKeyScopeAll 允许访问全部路由分组
+const ApiKeyScopeAll = "*"
+
+// HashApiKey 计算密钥的sha256，集群配置中只保存密钥的hash
+func HashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ApiKeyByHash 通过密钥hash获取有效的密钥（未过期，或者是仍在宽限期内的轮换前密钥）
+func (c *Config) ApiKeyByHash(keyHash string, now int64) *ApiKey {
+	for _, apiKey := range c.ApiKeys {
+		if apiKey.ExpireAt > 0 && apiKey.ExpireAt <= now {
+			continue
+		}
+		if apiKey.KeyHash == keyHash {
+			return apiKey
+		}
+		if apiKey.PrevKeyHash == keyHash && apiKey.PrevExpireAt > now {
+			return apiKey
+		}
+	}
+	return nil
+}
+
+// ApiKeyById 通过id获取密钥
+func (c *Config) ApiKeyById(id string) *ApiKey {
+	for _, apiKey := range c.ApiKeys {
+		if apiKey.Id == id {
+			return apiKey
+		}
+	}
+	return nil
+}
+
+// HasScope 密钥是否允许访问指定的路由分组
+func (k *ApiKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == ApiKeyScopeAll || s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowChannel 密钥是否允许操作指定的频道
+func (k *ApiKey) AllowChannel(channelId string) bool {
+	if len(k.ChannelPrefixes) == 0 {
+		return true
+	}
+	for _, prefix := range k.ChannelPrefixes {
+		if strings.HasPrefix(channelId, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers 密钥的权限（路由分组和频道范围）是否覆盖other的权限，用于限制密钥只能创建或管理不超出自身权限的密钥
+func (k *ApiKey) Covers(other *ApiKey) bool {
+	for _, scope := range other.Scopes {
+		if scope == ApiKeyScopeAll {
+			if !k.hasAllScope() {
+				return false
+			}
+			continue
+		}
+		if !k.HasScope(scope) {
+			return false
+		}
+	}
+	if len(k.ChannelPrefixes) == 0 {
+		return true
+	}
+	if len(other.ChannelPrefixes) == 0 { // other不限制频道
+		return false
+	}
+	for _, prefix := range other.ChannelPrefixes {
+		if !k.AllowChannel(prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+func (k *ApiKey) hasAllScope() bool {
+	for _, s := range k.Scopes {
+		if s == ApiKeyScopeAll {
+			return true
+		}
+	}
+	return false
+}
@@ -40,6 +40,84 @@ func (s *Slot) Unmarshal(data []byte) error {
 	return proto.Unmarshal(data, s)
 }
 
+func (k *ApiKey) Marshal() ([]byte, error) {
+	return proto.Marshal(k)
+}
+
+func (k *ApiKey) Unmarshal(data []byte) error {
+	return proto.Unmarshal(data, k)
+}
+
+func (k *ApiKey) Clone() *ApiKey {
+	return proto.Clone(k).(*ApiKey)
+}
+
+func (u *ManagerUser) Marshal() ([]byte, error) {
+	return proto.Marshal(u)
+}
+
+func (u *ManagerUser) Unmarshal(data []byte) error {
+	return proto.Unmarshal(data, u)
+}
+
+func (u *ManagerUser) Clone() *ManagerUser {
+	return proto.Clone(u).(*ManagerUser)
+}
+
+func (r *ManagerRole) Marshal() ([]byte, error) {
+	return proto.Marshal(r)
+}
+
+func (r *ManagerRole) Unmarshal(data []byte) error {
+	return proto.Unmarshal(data, r)
+}
+
+func (r *ManagerRole) Clone() *ManagerRole {
+	return proto.Clone(r).(*ManagerRole)
+}
+
+// ManagerUser 通过用户名获取管理用户
+func (c *Config) ManagerUser(username string) *ManagerUser {
+	for _, user := range c.ManagerUsers {
+		if user.Username == username {
+			return user
+		}
+	}
+	return nil
+}
+
+// ManagerRole 通过名称获取管理角色
+func (c *Config) ManagerRole(name string) *ManagerRole {
+	for _, role := range c.ManagerRoles {
+		if role.Name == name {
+			return role
+		}
+	}
+	return nil
+}
+
+func (b *Bot) Marshal() ([]byte, error) {
+	return proto.Marshal(b)
+}
+
+func (b *Bot) Unmarshal(data []byte) error {
+	return proto.Unmarshal(data, b)
+}
+
+func (b *Bot) Clone() *Bot {
+	return proto.Clone(b).(*Bot)
+}
+
+// Bot 通过uid获取机器人
+func (c *Config) Bot(uid string) *Bot {
+	for _, bot := range c.Bots {
+		if bot.Uid == uid {
+			return bot
+		}
+	}
+	return nil
+}
+
 // func (s *SlotMigrate) Equal(v *SlotMigrate) bool {
 // 	if s.From != v.From {
 // 		return false
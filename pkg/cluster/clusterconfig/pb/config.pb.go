@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.27.1
+// 	protoc-gen-go v1.34.2
 // 	protoc        v3.18.1
 // source: pkg/cluster/clusterconfig/pb/config.proto
 
@@ -270,16 +270,21 @@ type Config struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Version             uint64   `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`                         // 配置版本
-	SlotCount           uint32   `protobuf:"varint,2,opt,name=slotCount,proto3" json:"slotCount,omitempty"`                     // 槽位数量
-	SlotReplicaCount    uint32   `protobuf:"varint,3,opt,name=slotReplicaCount,proto3" json:"slotReplicaCount,omitempty"`       // 槽最大副本数量  (这个数量包含领导，比如副本为3，则是1个领导2个副本节点)
-	ChannelReplicaCount uint32   `protobuf:"varint,4,opt,name=channelReplicaCount,proto3" json:"channelReplicaCount,omitempty"` // 频道最大副本数量
-	Term                uint32   `protobuf:"varint,5,opt,name=term,proto3" json:"term,omitempty"`                               // 领导任期
-	MigrateFrom         uint64   `protobuf:"varint,6,opt,name=migrateFrom,proto3" json:"migrateFrom,omitempty"`                 // 迁移的源节点
-	MigrateTo           uint64   `protobuf:"varint,7,opt,name=migrateTo,proto3" json:"migrateTo,omitempty"`                     // 迁移的目标节点
-	Learners            []uint64 `protobuf:"varint,8,rep,packed,name=learners,proto3" json:"learners,omitempty"`                // 学习者列表
-	Nodes               []*Node  `protobuf:"bytes,9,rep,name=nodes,proto3" json:"nodes,omitempty"`                              // 分布式中的节点
-	Slots               []*Slot  `protobuf:"bytes,10,rep,name=slots,proto3" json:"slots,omitempty"`                             // 分布式中的槽位
+	Version             uint64         `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`                         // 配置版本
+	SlotCount           uint32         `protobuf:"varint,2,opt,name=slotCount,proto3" json:"slotCount,omitempty"`                     // 槽位数量
+	SlotReplicaCount    uint32         `protobuf:"varint,3,opt,name=slotReplicaCount,proto3" json:"slotReplicaCount,omitempty"`       // 槽最大副本数量  (这个数量包含领导，比如副本为3，则是1个领导2个副本节点)
+	ChannelReplicaCount uint32         `protobuf:"varint,4,opt,name=channelReplicaCount,proto3" json:"channelReplicaCount,omitempty"` // 频道最大副本数量
+	Term                uint32         `protobuf:"varint,5,opt,name=term,proto3" json:"term,omitempty"`                               // 领导任期
+	MigrateFrom         uint64         `protobuf:"varint,6,opt,name=migrateFrom,proto3" json:"migrateFrom,omitempty"`                 // 迁移的源节点
+	MigrateTo           uint64         `protobuf:"varint,7,opt,name=migrateTo,proto3" json:"migrateTo,omitempty"`                     // 迁移的目标节点
+	Learners            []uint64       `protobuf:"varint,8,rep,packed,name=learners,proto3" json:"learners,omitempty"`                // 学习者列表
+	Nodes               []*Node        `protobuf:"bytes,9,rep,name=nodes,proto3" json:"nodes,omitempty"`                              // 分布式中的节点
+	Slots               []*Slot        `protobuf:"bytes,10,rep,name=slots,proto3" json:"slots,omitempty"`                             // 分布式中的槽位
+	Features            []string       `protobuf:"bytes,11,rep,name=features,proto3" json:"features,omitempty"`                       // 已开启的集群特性（所有节点的协议版本都支持后才会开启）
+	ApiKeys             []*ApiKey      `protobuf:"bytes,12,rep,name=apiKeys,proto3" json:"apiKeys,omitempty"`                         // 业务api的访问密钥
+	ManagerUsers        []*ManagerUser `protobuf:"bytes,13,rep,name=managerUsers,proto3" json:"managerUsers,omitempty"`               // 管理后台的用户
+	ManagerRoles        []*ManagerRole `protobuf:"bytes,14,rep,name=managerRoles,proto3" json:"managerRoles,omitempty"`               // 管理后台的角色
+	Bots                []*Bot         `protobuf:"bytes,15,rep,name=bots,proto3" json:"bots,omitempty"`                               // 服务端机器人
 }
 
 func (x *Config) Reset() {
@@ -384,6 +389,41 @@ func (x *Config) GetSlots() []*Slot {
 	return nil
 }
 
+func (x *Config) GetFeatures() []string {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+func (x *Config) GetApiKeys() []*ApiKey {
+	if x != nil {
+		return x.ApiKeys
+	}
+	return nil
+}
+
+func (x *Config) GetManagerUsers() []*ManagerUser {
+	if x != nil {
+		return x.ManagerUsers
+	}
+	return nil
+}
+
+func (x *Config) GetManagerRoles() []*ManagerRole {
+	if x != nil {
+		return x.ManagerRoles
+	}
+	return nil
+}
+
+func (x *Config) GetBots() []*Bot {
+	if x != nil {
+		return x.Bots
+	}
+	return nil
+}
+
 type Node struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -402,6 +442,10 @@ type Node struct {
 	Role         NodeRole   `protobuf:"varint,9,opt,name=role,proto3,enum=pb.NodeRole" json:"role,omitempty"`        // 节点角色
 	Status       NodeStatus `protobuf:"varint,10,opt,name=status,proto3,enum=pb.NodeStatus" json:"status,omitempty"` // 节点状态
 	CreatedAt    int64      `protobuf:"varint,11,opt,name=createdAt,proto3" json:"createdAt,omitempty"`              // 创建时间
+	Zone         string     `protobuf:"bytes,12,opt,name=zone,proto3" json:"zone,omitempty"`                         // 节点所在可用区
+	Rack         string     `protobuf:"bytes,13,opt,name=rack,proto3" json:"rack,omitempty"`                         // 节点所在机架
+	Draining     bool       `protobuf:"varint,14,opt,name=draining,proto3" json:"draining,omitempty"`                // 是否正在排空（领导权迁出中或已迁出，不再接收新的领导权）
+	ProtoVersion uint32     `protobuf:"varint,15,opt,name=protoVersion,proto3" json:"protoVersion,omitempty"`        // 节点的集群协议版本（由配置领导在节点握手后记录，0表示旧版本节点）
 }
 
 func (x *Node) Reset() {
@@ -513,6 +557,34 @@ func (x *Node) GetCreatedAt() int64 {
 	return 0
 }
 
+func (x *Node) GetZone() string {
+	if x != nil {
+		return x.Zone
+	}
+	return ""
+}
+
+func (x *Node) GetRack() string {
+	if x != nil {
+		return x.Rack
+	}
+	return ""
+}
+
+func (x *Node) GetDraining() bool {
+	if x != nil {
+		return x.Draining
+	}
+	return false
+}
+
+func (x *Node) GetProtoVersion() uint32 {
+	if x != nil {
+		return x.ProtoVersion
+	}
+	return 0
+}
+
 type Slot struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -734,13 +806,440 @@ func (x *Learner) GetStatus() LearnerStatus {
 	return LearnerStatus_LearnerStatusLearning
 }
 
+type ApiKey struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id              string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                           // 密钥id
+	Name            string   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`                       // 密钥名称
+	KeyHash         string   `protobuf:"bytes,3,opt,name=keyHash,proto3" json:"keyHash,omitempty"`                 // 密钥的sha256（不保存明文）
+	Scopes          []string `protobuf:"bytes,4,rep,name=scopes,proto3" json:"scopes,omitempty"`                   // 允许访问的路由分组，*表示全部
+	ChannelPrefixes []string `protobuf:"bytes,5,rep,name=channelPrefixes,proto3" json:"channelPrefixes,omitempty"` // 允许操作的频道id前缀，为空表示不限制
+	CreatedAt       int64    `protobuf:"varint,6,opt,name=createdAt,proto3" json:"createdAt,omitempty"`            // 创建时间
+	ExpireAt        int64    `protobuf:"varint,7,opt,name=expireAt,proto3" json:"expireAt,omitempty"`              // 过期时间，0表示不过期
+	PrevKeyHash     string   `protobuf:"bytes,8,opt,name=prevKeyHash,proto3" json:"prevKeyHash,omitempty"`         // 轮换前的密钥sha256，在prevExpireAt之前仍然有效
+	PrevExpireAt    int64    `protobuf:"varint,9,opt,name=prevExpireAt,proto3" json:"prevExpireAt,omitempty"`      // 轮换前的密钥的失效时间
+	RotatedAt       int64    `protobuf:"varint,10,opt,name=rotatedAt,proto3" json:"rotatedAt,omitempty"`           // 最近一次轮换时间
+}
+
+func (x *ApiKey) Reset() {
+	*x = ApiKey{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ApiKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApiKey) ProtoMessage() {}
+
+func (x *ApiKey) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApiKey.ProtoReflect.Descriptor instead.
+func (*ApiKey) Descriptor() ([]byte, []int) {
+	return file_pkg_cluster_clusterconfig_pb_config_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ApiKey) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ApiKey) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ApiKey) GetKeyHash() string {
+	if x != nil {
+		return x.KeyHash
+	}
+	return ""
+}
+
+func (x *ApiKey) GetScopes() []string {
+	if x != nil {
+		return x.Scopes
+	}
+	return nil
+}
+
+func (x *ApiKey) GetChannelPrefixes() []string {
+	if x != nil {
+		return x.ChannelPrefixes
+	}
+	return nil
+}
+
+func (x *ApiKey) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *ApiKey) GetExpireAt() int64 {
+	if x != nil {
+		return x.ExpireAt
+	}
+	return 0
+}
+
+func (x *ApiKey) GetPrevKeyHash() string {
+	if x != nil {
+		return x.PrevKeyHash
+	}
+	return ""
+}
+
+func (x *ApiKey) GetPrevExpireAt() int64 {
+	if x != nil {
+		return x.PrevExpireAt
+	}
+	return 0
+}
+
+func (x *ApiKey) GetRotatedAt() int64 {
+	if x != nil {
+		return x.RotatedAt
+	}
+	return 0
+}
+
+type ManagerUser struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Username     string   `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`         // 用户名
+	PasswordHash string   `protobuf:"bytes,2,opt,name=passwordHash,proto3" json:"passwordHash,omitempty"` // 密码的bcrypt hash
+	Roles        []string `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty"`               // 用户拥有的角色
+	CreatedAt    int64    `protobuf:"varint,4,opt,name=createdAt,proto3" json:"createdAt,omitempty"`      // 创建时间
+	UpdatedAt    int64    `protobuf:"varint,5,opt,name=updatedAt,proto3" json:"updatedAt,omitempty"`      // 更新时间
+}
+
+func (x *ManagerUser) Reset() {
+	*x = ManagerUser{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ManagerUser) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ManagerUser) ProtoMessage() {}
+
+func (x *ManagerUser) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ManagerUser.ProtoReflect.Descriptor instead.
+func (*ManagerUser) Descriptor() ([]byte, []int) {
+	return file_pkg_cluster_clusterconfig_pb_config_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ManagerUser) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *ManagerUser) GetPasswordHash() string {
+	if x != nil {
+		return x.PasswordHash
+	}
+	return ""
+}
+
+func (x *ManagerUser) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *ManagerUser) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *ManagerUser) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+type ManagerRole struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name        string               `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`               // 角色名称
+	Description string               `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"` // 角色描述
+	Permissions []*ManagerPermission `protobuf:"bytes,3,rep,name=permissions,proto3" json:"permissions,omitempty"` // 角色拥有的资源权限
+	CreatedAt   int64                `protobuf:"varint,4,opt,name=createdAt,proto3" json:"createdAt,omitempty"`    // 创建时间
+	UpdatedAt   int64                `protobuf:"varint,5,opt,name=updatedAt,proto3" json:"updatedAt,omitempty"`    // 更新时间
+}
+
+func (x *ManagerRole) Reset() {
+	*x = ManagerRole{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ManagerRole) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ManagerRole) ProtoMessage() {}
+
+func (x *ManagerRole) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ManagerRole.ProtoReflect.Descriptor instead.
+func (*ManagerRole) Descriptor() ([]byte, []int) {
+	return file_pkg_cluster_clusterconfig_pb_config_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ManagerRole) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ManagerRole) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ManagerRole) GetPermissions() []*ManagerPermission {
+	if x != nil {
+		return x.Permissions
+	}
+	return nil
+}
+
+func (x *ManagerRole) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *ManagerRole) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+type Bot struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uid           string  `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`                     // 机器人的用户uid
+	CallbackUrl   string  `protobuf:"bytes,2,opt,name=callbackUrl,proto3" json:"callbackUrl,omitempty"`     // 收到消息后回调的http地址，为空表示使用进程内注册的处理器
+	RateLimit     float64 `protobuf:"fixed64,3,opt,name=rateLimit,proto3" json:"rateLimit,omitempty"`       // 每秒最多处理的消息数量，0表示使用默认配置
+	CommandPrefix string  `protobuf:"bytes,4,opt,name=commandPrefix,proto3" json:"commandPrefix,omitempty"` // 命令前缀，为空表示使用默认配置
+	Description   string  `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`     // 描述
+	CreatedAt     int64   `protobuf:"varint,6,opt,name=createdAt,proto3" json:"createdAt,omitempty"`        // 创建时间
+	UpdatedAt     int64   `protobuf:"varint,7,opt,name=updatedAt,proto3" json:"updatedAt,omitempty"`        // 更新时间
+}
+
+func (x *Bot) Reset() {
+	*x = Bot{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Bot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Bot) ProtoMessage() {}
+
+func (x *Bot) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Bot.ProtoReflect.Descriptor instead.
+func (*Bot) Descriptor() ([]byte, []int) {
+	return file_pkg_cluster_clusterconfig_pb_config_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Bot) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+func (x *Bot) GetCallbackUrl() string {
+	if x != nil {
+		return x.CallbackUrl
+	}
+	return ""
+}
+
+func (x *Bot) GetRateLimit() float64 {
+	if x != nil {
+		return x.RateLimit
+	}
+	return 0
+}
+
+func (x *Bot) GetCommandPrefix() string {
+	if x != nil {
+		return x.CommandPrefix
+	}
+	return ""
+}
+
+func (x *Bot) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Bot) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Bot) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+type ManagerPermission struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Resource string `protobuf:"bytes,1,opt,name=resource,proto3" json:"resource,omitempty"` // 资源id，*表示全部资源
+	Actions  string `protobuf:"bytes,2,opt,name=actions,proto3" json:"actions,omitempty"`   // 资源操作 r:读 w:写 *:全部，例如 rw
+}
+
+func (x *ManagerPermission) Reset() {
+	*x = ManagerPermission{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ManagerPermission) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ManagerPermission) ProtoMessage() {}
+
+func (x *ManagerPermission) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ManagerPermission.ProtoReflect.Descriptor instead.
+func (*ManagerPermission) Descriptor() ([]byte, []int) {
+	return file_pkg_cluster_clusterconfig_pb_config_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ManagerPermission) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *ManagerPermission) GetActions() string {
+	if x != nil {
+		return x.Actions
+	}
+	return ""
+}
+
 var File_pkg_cluster_clusterconfig_pb_config_proto protoreflect.FileDescriptor
 
 var file_pkg_cluster_clusterconfig_pb_config_proto_rawDesc = []byte{
 	0x0a, 0x29, 0x70, 0x6b, 0x67, 0x2f, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x2f, 0x63, 0x6c,
 	0x75, 0x73, 0x74, 0x65, 0x72, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2f, 0x70, 0x62, 0x2f, 0x63,
 	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x22,
-	0xce, 0x02, 0x0a, 0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65,
+	0x97, 0x04, 0x0a, 0x06, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65,
 	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x76, 0x65, 0x72,
 	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x6c, 0x6f, 0x74, 0x43, 0x6f, 0x75, 0x6e,
 	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x73, 0x6c, 0x6f, 0x74, 0x43, 0x6f, 0x75,
@@ -761,53 +1260,131 @@ var file_pkg_cluster_clusterconfig_pb_config_proto_rawDesc = []byte{
 	0x08, 0x2e, 0x70, 0x62, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73,
 	0x12, 0x1e, 0x0a, 0x05, 0x73, 0x6c, 0x6f, 0x74, 0x73, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32,
 	0x08, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x6c, 0x6f, 0x74, 0x52, 0x05, 0x73, 0x6c, 0x6f, 0x74, 0x73,
-	0x22, 0xd6, 0x02, 0x0a, 0x04, 0x4e, 0x6f, 0x64, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x69, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6c, 0x75,
-	0x73, 0x74, 0x65, 0x72, 0x41, 0x64, 0x64, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
-	0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x41, 0x64, 0x64, 0x72, 0x12, 0x24, 0x0a, 0x0d, 0x61,
-	0x70, 0x69, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x41, 0x64, 0x64, 0x72, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x0d, 0x61, 0x70, 0x69, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x41, 0x64, 0x64,
-	0x72, 0x12, 0x12, 0x0a, 0x04, 0x6a, 0x6f, 0x69, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52,
-	0x04, 0x6a, 0x6f, 0x69, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x6e, 0x6c, 0x69, 0x6e, 0x65, 0x18,
-	0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6f, 0x6e, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x22, 0x0a,
-	0x0c, 0x6f, 0x66, 0x66, 0x6c, 0x69, 0x6e, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x06, 0x20,
-	0x01, 0x28, 0x0d, 0x52, 0x0c, 0x6f, 0x66, 0x66, 0x6c, 0x69, 0x6e, 0x65, 0x43, 0x6f, 0x75, 0x6e,
-	0x74, 0x12, 0x20, 0x0a, 0x0b, 0x6c, 0x61, 0x73, 0x74, 0x4f, 0x66, 0x66, 0x6c, 0x69, 0x6e, 0x65,
-	0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x6c, 0x61, 0x73, 0x74, 0x4f, 0x66, 0x66, 0x6c,
-	0x69, 0x6e, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x56, 0x6f, 0x74, 0x65,
-	0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x56, 0x6f, 0x74,
-	0x65, 0x12, 0x20, 0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0e, 0x32,
-	0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x04, 0x72,
-	0x6f, 0x6c, 0x65, 0x12, 0x26, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x0a, 0x20,
-	0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x70, 0x62, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x53, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x63,
-	0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09,
-	0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x22, 0x86, 0x02, 0x0a, 0x04, 0x53, 0x6c,
-	0x6f, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02,
-	0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x04, 0x52, 0x06, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x65,
-	0x72, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x74, 0x65, 0x72, 0x6d, 0x12, 0x1a,
-	0x0a, 0x08, 0x72, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x04,
-	0x52, 0x08, 0x72, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x65,
-	0x61, 0x72, 0x6e, 0x65, 0x72, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x04, 0x52, 0x08, 0x6c, 0x65,
-	0x61, 0x72, 0x6e, 0x65, 0x72, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x6d, 0x69, 0x67, 0x72, 0x61, 0x74,
-	0x65, 0x46, 0x72, 0x6f, 0x6d, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x6d, 0x69, 0x67,
-	0x72, 0x61, 0x74, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x69, 0x67, 0x72,
-	0x61, 0x74, 0x65, 0x54, 0x6f, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x6d, 0x69, 0x67,
-	0x72, 0x61, 0x74, 0x65, 0x54, 0x6f, 0x12, 0x22, 0x0a, 0x0c, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74,
-	0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x09, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x65, 0x78,
-	0x70, 0x65, 0x63, 0x74, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x26, 0x0a, 0x06, 0x73, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x70, 0x62, 0x2e,
-	0x53, 0x6c, 0x6f, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x22, 0x31, 0x0a, 0x0b, 0x53, 0x6c, 0x6f, 0x74, 0x4d, 0x69, 0x67, 0x72, 0x61, 0x74,
-	0x65, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
-	0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x04, 0x52, 0x02, 0x74, 0x6f, 0x22, 0x52, 0x0a, 0x07, 0x4c, 0x65, 0x61, 0x72, 0x6e, 0x65, 0x72,
-	0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x65, 0x61, 0x72, 0x6e, 0x65, 0x72, 0x49, 0x64, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x04, 0x52, 0x09, 0x6c, 0x65, 0x61, 0x72, 0x6e, 0x65, 0x72, 0x49, 0x64, 0x12, 0x29,
-	0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11,
-	0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x65, 0x61, 0x72, 0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x2a, 0x32, 0x0a, 0x08, 0x4e, 0x6f, 0x64,
+	0x12, 0x1a, 0x0a, 0x08, 0x66, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x18, 0x0b, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x08, 0x66, 0x65, 0x61, 0x74, 0x75, 0x72, 0x65, 0x73, 0x12, 0x24, 0x0a, 0x07,
+	0x61, 0x70, 0x69, 0x4b, 0x65, 0x79, 0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0a, 0x2e,
+	0x70, 0x62, 0x2e, 0x41, 0x70, 0x69, 0x4b, 0x65, 0x79, 0x52, 0x07, 0x61, 0x70, 0x69, 0x4b, 0x65,
+	0x79, 0x73, 0x12, 0x33, 0x0a, 0x0c, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x55, 0x73, 0x65,
+	0x72, 0x73, 0x18, 0x0d, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x4d, 0x61,
+	0x6e, 0x61, 0x67, 0x65, 0x72, 0x55, 0x73, 0x65, 0x72, 0x52, 0x0c, 0x6d, 0x61, 0x6e, 0x61, 0x67,
+	0x65, 0x72, 0x55, 0x73, 0x65, 0x72, 0x73, 0x12, 0x33, 0x0a, 0x0c, 0x6d, 0x61, 0x6e, 0x61, 0x67,
+	0x65, 0x72, 0x52, 0x6f, 0x6c, 0x65, 0x73, 0x18, 0x0e, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e,
+	0x70, 0x62, 0x2e, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x0c,
+	0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x52, 0x6f, 0x6c, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x04,
+	0x62, 0x6f, 0x74, 0x73, 0x18, 0x0f, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x07, 0x2e, 0x70, 0x62, 0x2e,
+	0x42, 0x6f, 0x74, 0x52, 0x04, 0x62, 0x6f, 0x74, 0x73, 0x22, 0xbe, 0x03, 0x0a, 0x04, 0x4e, 0x6f,
+	0x64, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02,
+	0x69, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72, 0x41, 0x64, 0x64,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6c, 0x75, 0x73, 0x74, 0x65, 0x72,
+	0x41, 0x64, 0x64, 0x72, 0x12, 0x24, 0x0a, 0x0d, 0x61, 0x70, 0x69, 0x53, 0x65, 0x72, 0x76, 0x65,
+	0x72, 0x41, 0x64, 0x64, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x61, 0x70, 0x69,
+	0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x41, 0x64, 0x64, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6a, 0x6f,
+	0x69, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x6a, 0x6f, 0x69, 0x6e, 0x12, 0x16,
+	0x0a, 0x06, 0x6f, 0x6e, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06,
+	0x6f, 0x6e, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x6f, 0x66, 0x66, 0x6c, 0x69, 0x6e,
+	0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x6f, 0x66,
+	0x66, 0x6c, 0x69, 0x6e, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x20, 0x0a, 0x0b, 0x6c, 0x61,
+	0x73, 0x74, 0x4f, 0x66, 0x66, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0b, 0x6c, 0x61, 0x73, 0x74, 0x4f, 0x66, 0x66, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x1c, 0x0a, 0x09,
+	0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x56, 0x6f, 0x74, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x09, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x56, 0x6f, 0x74, 0x65, 0x12, 0x20, 0x0a, 0x04, 0x72, 0x6f,
+	0x6c, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x4e, 0x6f,
+	0x64, 0x65, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x12, 0x26, 0x0a, 0x06,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x70,
+	0x62, 0x2e, 0x4e, 0x6f, 0x64, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41,
+	0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64,
+	0x41, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x7a, 0x6f, 0x6e, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x61, 0x63, 0x6b, 0x18, 0x0d,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x72, 0x61, 0x63, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x72,
+	0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x64, 0x72,
+	0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x22, 0x0a, 0x0c, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x56,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x22, 0x86, 0x02, 0x0a, 0x04, 0x53,
+	0x6c, 0x6f, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x02, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x06, 0x6c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x74,
+	0x65, 0x72, 0x6d, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x74, 0x65, 0x72, 0x6d, 0x12,
+	0x1a, 0x0a, 0x08, 0x72, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28,
+	0x04, 0x52, 0x08, 0x72, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x6c,
+	0x65, 0x61, 0x72, 0x6e, 0x65, 0x72, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x04, 0x52, 0x08, 0x6c,
+	0x65, 0x61, 0x72, 0x6e, 0x65, 0x72, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x6d, 0x69, 0x67, 0x72, 0x61,
+	0x74, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x6d, 0x69,
+	0x67, 0x72, 0x61, 0x74, 0x65, 0x46, 0x72, 0x6f, 0x6d, 0x12, 0x1c, 0x0a, 0x09, 0x6d, 0x69, 0x67,
+	0x72, 0x61, 0x74, 0x65, 0x54, 0x6f, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x6d, 0x69,
+	0x67, 0x72, 0x61, 0x74, 0x65, 0x54, 0x6f, 0x12, 0x22, 0x0a, 0x0c, 0x65, 0x78, 0x70, 0x65, 0x63,
+	0x74, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x09, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x65,
+	0x78, 0x70, 0x65, 0x63, 0x74, 0x4c, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x26, 0x0a, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0e, 0x2e, 0x70, 0x62,
+	0x2e, 0x53, 0x6c, 0x6f, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x22, 0x31, 0x0a, 0x0b, 0x53, 0x6c, 0x6f, 0x74, 0x4d, 0x69, 0x67, 0x72, 0x61,
+	0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x02, 0x74, 0x6f, 0x22, 0x52, 0x0a, 0x07, 0x4c, 0x65, 0x61, 0x72, 0x6e, 0x65,
+	0x72, 0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x65, 0x61, 0x72, 0x6e, 0x65, 0x72, 0x49, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x6c, 0x65, 0x61, 0x72, 0x6e, 0x65, 0x72, 0x49, 0x64, 0x12,
+	0x29, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x11, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x65, 0x61, 0x72, 0x6e, 0x65, 0x72, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x22, 0xa6, 0x02, 0x0a, 0x06, 0x41,
+	0x70, 0x69, 0x4b, 0x65, 0x79, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6b, 0x65, 0x79,
+	0x48, 0x61, 0x73, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x48,
+	0x61, 0x73, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x73, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x06, 0x73, 0x63, 0x6f, 0x70, 0x65, 0x73, 0x12, 0x28, 0x0a, 0x0f, 0x63,
+	0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x65, 0x73, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0f, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x50, 0x72, 0x65,
+	0x66, 0x69, 0x78, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64,
+	0x41, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x64, 0x41, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x41, 0x74, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x41, 0x74, 0x12,
+	0x20, 0x0a, 0x0b, 0x70, 0x72, 0x65, 0x76, 0x4b, 0x65, 0x79, 0x48, 0x61, 0x73, 0x68, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x70, 0x72, 0x65, 0x76, 0x4b, 0x65, 0x79, 0x48, 0x61, 0x73,
+	0x68, 0x12, 0x22, 0x0a, 0x0c, 0x70, 0x72, 0x65, 0x76, 0x45, 0x78, 0x70, 0x69, 0x72, 0x65, 0x41,
+	0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x70, 0x72, 0x65, 0x76, 0x45, 0x78, 0x70,
+	0x69, 0x72, 0x65, 0x41, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x64,
+	0x41, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x72, 0x6f, 0x74, 0x61, 0x74, 0x65,
+	0x64, 0x41, 0x74, 0x22, 0x9f, 0x01, 0x0a, 0x0b, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x55,
+	0x73, 0x65, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x75, 0x73, 0x65, 0x72, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x22, 0x0a, 0x0c, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x48, 0x61, 0x73, 0x68, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x48,
+	0x61, 0x73, 0x68, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x6f, 0x6c, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x05, 0x72, 0x6f, 0x6c, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x63, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x64, 0x41, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x75, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x64, 0x41, 0x74, 0x22, 0xb8, 0x01, 0x0a, 0x0b, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65,
+	0x72, 0x52, 0x6f, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73,
+	0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x37, 0x0a, 0x0b, 0x70,
+	0x65, 0x72, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x50, 0x65, 0x72,
+	0x6d, 0x69, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x70, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41,
+	0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64,
+	0x41, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74,
+	0x22, 0xdb, 0x01, 0x0a, 0x03, 0x42, 0x6f, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x69, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x61,
+	0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x55, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x63, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x55, 0x72, 0x6c, 0x12, 0x1c, 0x0a, 0x09,
+	0x72, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x09, 0x72, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x24, 0x0a, 0x0d, 0x63, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0d, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78,
+	0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74,
+	0x12, 0x1c, 0x0a, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x09, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x22, 0x49,
+	0x0a, 0x11, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x50, 0x65, 0x72, 0x6d, 0x69, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2a, 0x32, 0x0a, 0x08, 0x4e, 0x6f, 0x64,
 	0x65, 0x52, 0x6f, 0x6c, 0x65, 0x12, 0x13, 0x0a, 0x0f, 0x4e, 0x6f, 0x64, 0x65, 0x52, 0x6f, 0x6c,
 	0x65, 0x52, 0x65, 0x70, 0x6c, 0x69, 0x63, 0x61, 0x10, 0x00, 0x12, 0x11, 0x0a, 0x0d, 0x4e, 0x6f,
 	0x64, 0x65, 0x52, 0x6f, 0x6c, 0x65, 0x50, 0x72, 0x6f, 0x78, 0x79, 0x10, 0x01, 0x2a, 0x67, 0x0a,
@@ -851,31 +1428,41 @@ func file_pkg_cluster_clusterconfig_pb_config_proto_rawDescGZIP() []byte {
 }
 
 var file_pkg_cluster_clusterconfig_pb_config_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
-var file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
-var file_pkg_cluster_clusterconfig_pb_config_proto_goTypes = []interface{}{
-	(NodeRole)(0),       // 0: pb.NodeRole
-	(NodeStatus)(0),     // 1: pb.NodeStatus
-	(MigrateStatus)(0),  // 2: pb.MigrateStatus
-	(SlotStatus)(0),     // 3: pb.SlotStatus
-	(LearnerStatus)(0),  // 4: pb.LearnerStatus
-	(*Config)(nil),      // 5: pb.Config
-	(*Node)(nil),        // 6: pb.Node
-	(*Slot)(nil),        // 7: pb.Slot
-	(*SlotMigrate)(nil), // 8: pb.SlotMigrate
-	(*Learner)(nil),     // 9: pb.Learner
+var file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_pkg_cluster_clusterconfig_pb_config_proto_goTypes = []any{
+	(NodeRole)(0),             // 0: pb.NodeRole
+	(NodeStatus)(0),           // 1: pb.NodeStatus
+	(MigrateStatus)(0),        // 2: pb.MigrateStatus
+	(SlotStatus)(0),           // 3: pb.SlotStatus
+	(LearnerStatus)(0),        // 4: pb.LearnerStatus
+	(*Config)(nil),            // 5: pb.Config
+	(*Node)(nil),              // 6: pb.Node
+	(*Slot)(nil),              // 7: pb.Slot
+	(*SlotMigrate)(nil),       // 8: pb.SlotMigrate
+	(*Learner)(nil),           // 9: pb.Learner
+	(*ApiKey)(nil),            // 10: pb.ApiKey
+	(*ManagerUser)(nil),       // 11: pb.ManagerUser
+	(*ManagerRole)(nil),       // 12: pb.ManagerRole
+	(*Bot)(nil),               // 13: pb.Bot
+	(*ManagerPermission)(nil), // 14: pb.ManagerPermission
 }
 var file_pkg_cluster_clusterconfig_pb_config_proto_depIdxs = []int32{
-	6, // 0: pb.Config.nodes:type_name -> pb.Node
-	7, // 1: pb.Config.slots:type_name -> pb.Slot
-	0, // 2: pb.Node.role:type_name -> pb.NodeRole
-	1, // 3: pb.Node.status:type_name -> pb.NodeStatus
-	3, // 4: pb.Slot.status:type_name -> pb.SlotStatus
-	4, // 5: pb.Learner.status:type_name -> pb.LearnerStatus
-	6, // [6:6] is the sub-list for method output_type
-	6, // [6:6] is the sub-list for method input_type
-	6, // [6:6] is the sub-list for extension type_name
-	6, // [6:6] is the sub-list for extension extendee
-	0, // [0:6] is the sub-list for field type_name
+	6,  // 0: pb.Config.nodes:type_name -> pb.Node
+	7,  // 1: pb.Config.slots:type_name -> pb.Slot
+	10, // 2: pb.Config.apiKeys:type_name -> pb.ApiKey
+	11, // 3: pb.Config.managerUsers:type_name -> pb.ManagerUser
+	12, // 4: pb.Config.managerRoles:type_name -> pb.ManagerRole
+	13, // 5: pb.Config.bots:type_name -> pb.Bot
+	0,  // 6: pb.Node.role:type_name -> pb.NodeRole
+	1,  // 7: pb.Node.status:type_name -> pb.NodeStatus
+	3,  // 8: pb.Slot.status:type_name -> pb.SlotStatus
+	4,  // 9: pb.Learner.status:type_name -> pb.LearnerStatus
+	14, // 10: pb.ManagerRole.permissions:type_name -> pb.ManagerPermission
+	11, // [11:11] is the sub-list for method output_type
+	11, // [11:11] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
 }
 
 func init() { file_pkg_cluster_clusterconfig_pb_config_proto_init() }
@@ -884,7 +1471,7 @@ func file_pkg_cluster_clusterconfig_pb_config_proto_init() {
 		return
 	}
 	if !protoimpl.UnsafeEnabled {
-		file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[0].Exporter = func(v any, i int) any {
 			switch v := v.(*Config); i {
 			case 0:
 				return &v.state
@@ -896,7 +1483,7 @@ func file_pkg_cluster_clusterconfig_pb_config_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[1].Exporter = func(v any, i int) any {
 			switch v := v.(*Node); i {
 			case 0:
 				return &v.state
@@ -908,7 +1495,7 @@ func file_pkg_cluster_clusterconfig_pb_config_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[2].Exporter = func(v any, i int) any {
 			switch v := v.(*Slot); i {
 			case 0:
 				return &v.state
@@ -920,7 +1507,7 @@ func file_pkg_cluster_clusterconfig_pb_config_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[3].Exporter = func(v any, i int) any {
 			switch v := v.(*SlotMigrate); i {
 			case 0:
 				return &v.state
@@ -932,7 +1519,7 @@ func file_pkg_cluster_clusterconfig_pb_config_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[4].Exporter = func(v any, i int) any {
 			switch v := v.(*Learner); i {
 			case 0:
 				return &v.state
@@ -944,6 +1531,66 @@ func file_pkg_cluster_clusterconfig_pb_config_proto_init() {
 				return nil
 			}
 		}
+		file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*ApiKey); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*ManagerUser); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*ManagerRole); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*Bot); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_cluster_clusterconfig_pb_config_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*ManagerPermission); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -951,7 +1598,7 @@ func file_pkg_cluster_clusterconfig_pb_config_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_cluster_clusterconfig_pb_config_proto_rawDesc,
 			NumEnums:      5,
-			NumMessages:   5,
+			NumMessages:   10,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
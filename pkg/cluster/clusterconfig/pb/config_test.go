@@ -36,3 +36,93 @@ func TestSlotSetMarshal(t *testing.T) {
 	assert.Equal(t, len(slotSet), len(slotSet2))
 
 }
+
+func TestSpreadReplicas(t *testing.T) {
+	nodes := []*Node{
+		{Id: 1, Zone: "a", Rack: "r1"},
+		{Id: 2, Zone: "a", Rack: "r2"},
+		{Id: 3, Zone: "b", Rack: "r1"},
+		{Id: 4, Zone: "b", Rack: "r2"},
+		{Id: 5, Zone: "c", Rack: "r1"},
+	}
+
+	replicas := SpreadReplicas(nodes, []uint64{1}, 3)
+	assert.Equal(t, []uint64{1, 3, 5}, replicas)
+	assert.Equal(t, 3, ZoneCount(nodes, replicas))
+
+	// 可用区不足时按机架分散
+	replicas = SpreadReplicas(nodes[:4], nil, 4)
+	assert.Equal(t, []uint64{1, 3, 2, 4}, replicas)
+
+	// 未配置可用区时按候选顺序选择
+	plainNodes := []*Node{{Id: 1}, {Id: 2}, {Id: 3}}
+	assert.Equal(t, []uint64{1, 2}, SpreadReplicas(plainNodes, nil, 2))
+
+	assert.True(t, ReplaceKeepsSpread(nodes, []uint64{1, 3, 5}, 1, 2))
+	assert.False(t, ReplaceKeepsSpread(nodes, []uint64{1, 3, 5}, 1, 4))
+}
+
+func TestFeatureGates(t *testing.T) {
+	cfg := &Config{
+		Nodes: []*Node{
+			{Id: 1, ProtoVersion: 1},
+			{Id: 2, ProtoVersion: 0}, // 旧版本节点
+		},
+	}
+	feature := Feature{Name: FeatureNodeDraining, MinVersion: 1}
+	assert.Equal(t, []uint64{2}, cfg.FeatureBlockingNodes(feature))
+	assert.False(t, cfg.FeatureEnabled(feature.Name))
+	assert.Equal(t, uint32(0), cfg.MinProtoVersion())
+
+	cfg.Nodes[1].ProtoVersion = 1
+	assert.Empty(t, cfg.FeatureBlockingNodes(feature))
+
+	cfg.Features = append(cfg.Features, feature.Name)
+	assert.True(t, cfg.FeatureEnabled(feature.Name))
+	assert.Equal(t, uint32(1), cfg.MinProtoVersion())
+}
+
+func TestApiKey(t *testing.T) {
+	now := int64(1000)
+	cfg := &Config{
+		ApiKeys: []*ApiKey{
+			{
+				Id:              "1",
+				KeyHash:         HashApiKey("new"),
+				PrevKeyHash:     HashApiKey("old"),
+				PrevExpireAt:    now + 10,
+				Scopes:          []string{"message"},
+				ChannelPrefixes: []string{"shop_"},
+			},
+			{
+				Id:       "2",
+				KeyHash:  HashApiKey("expired"),
+				ExpireAt: now,
+				Scopes:   []string{ApiKeyScopeAll},
+			},
+		},
+	}
+
+	apiKey := cfg.ApiKeyByHash(HashApiKey("new"), now)
+	assert.Equal(t, "1", apiKey.Id)
+	assert.True(t, apiKey.HasScope("message"))
+	assert.False(t, apiKey.HasScope("channel"))
+	assert.True(t, apiKey.AllowChannel("shop_1"))
+	assert.False(t, apiKey.AllowChannel("group_1"))
+	assert.False(t, apiKey.AllowChannel(""))
+
+	// 轮换前的密钥在宽限期内有效
+	assert.NotNil(t, cfg.ApiKeyByHash(HashApiKey("old"), now))
+	assert.Nil(t, cfg.ApiKeyByHash(HashApiKey("old"), now+10))
+
+	// 过期的密钥
+	assert.Nil(t, cfg.ApiKeyByHash(HashApiKey("expired"), now))
+
+	// 权限覆盖
+	assert.True(t, apiKey.Covers(&ApiKey{Scopes: []string{"message"}, ChannelPrefixes: []string{"shop_a"}}))
+	assert.False(t, apiKey.Covers(&ApiKey{Scopes: []string{ApiKeyScopeAll}, ChannelPrefixes: []string{"shop_"}}))
+	assert.False(t, apiKey.Covers(&ApiKey{Scopes: []string{"channel"}, ChannelPrefixes: []string{"shop_"}}))
+	assert.False(t, apiKey.Covers(&ApiKey{Scopes: []string{"message"}}))
+	allKey := &ApiKey{Scopes: []string{ApiKeyScopeAll}}
+	assert.True(t, allKey.Covers(&ApiKey{Scopes: []string{ApiKeyScopeAll}}))
+}
@@ -0,0 +1,64 @@
+package pb
+
+// ProtoVersion 当前节点的集群协议版本，节点之间的rpc格式或分布式配置命令出现不兼容的变化时需要递增
+// 旧版本节点在握手时不携带版本，视为0
+const ProtoVersion uint32 = 4
+
+const (
+	FeatureNodeTopology = "nodeTopology" // 节点拓扑（可用区/机架）变更命令
+	FeatureNodeDraining = "nodeDraining" // 节点排空命令
+	FeatureApiKey       = "apiKey"       // 业务api访问密钥命令
+	FeatureManagerRbac  = "managerRbac"  // 管理后台用户和角色命令
+	FeatureBot          = "bot"          // 服务端机器人命令
+)
+
+// Feature 集群特性，所有节点的协议版本都不低于MinVersion后才会开启，开启后不会再关闭
+type Feature struct {
+	Name       string
+	MinVersion uint32 // 开启此特性需要的最低协议版本
+}
+
+// Features 所有的集群特性
+var Features = []Feature{
+	{Name: FeatureNodeTopology, MinVersion: 1},
+	{Name: FeatureNodeDraining, MinVersion: 1},
+	{Name: FeatureApiKey, MinVersion: 2},
+	{Name: FeatureManagerRbac, MinVersion: 3},
+	{Name: FeatureBot, MinVersion: 4},
+}
+
+// FeatureEnabled 特性是否已开启
+func (c *Config) FeatureEnabled(name string) bool {
+	return containsString(c.Features, name)
+}
+
+// FeatureBlockingNodes 协议版本低于特性要求，导致特性无法开启的节点
+func (c *Config) FeatureBlockingNodes(feature Feature) []uint64 {
+	var nodeIds []uint64
+	for _, node := range c.Nodes {
+		if node.ProtoVersion < feature.MinVersion {
+			nodeIds = append(nodeIds, node.Id)
+		}
+	}
+	return nodeIds
+}
+
+// MinProtoVersion 已开启的特性要求的最低协议版本，低于此版本的节点不允许加入集群
+func (c *Config) MinProtoVersion() uint32 {
+	var minVersion uint32
+	for _, feature := range Features {
+		if feature.MinVersion > minVersion && c.FeatureEnabled(feature.Name) {
+			minVersion = feature.MinVersion
+		}
+	}
+	return minVersion
+}
+
+func containsString(items []string, v string) bool {
+	for _, item := range items {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package pb
+
+// Topology 节点的拓扑标签
+type Topology struct {
+	Zone string // 可用区
+	Rack string // 机架
+}
+
+// rackKey 机架在全局范围内的唯一标识（不同可用区可能存在同名机架）
+func (n *Node) rackKey() string {
+	return n.Zone + "/" + n.Rack
+}
+
+// SpreadReplicas 从候选节点中按可用区和机架分散选择副本，直到副本数量达到count
+// selected为已经确定的副本（比如频道领导），候选节点的顺序决定了同等条件下的优先级
+// 未配置可用区的节点都视为同一个可用区，此时结果等同于按候选节点顺序选择
+func SpreadReplicas(candidates []*Node, selected []uint64, count int) []uint64 {
+	replicas := make([]uint64, 0, count)
+	replicas = append(replicas, selected...)
+
+	zoneCountMap := make(map[string]int) // 每个可用区已选的副本数量
+	rackCountMap := make(map[string]int) // 每个机架已选的副本数量
+	for _, id := range selected {
+		node := nodeById(candidates, id)
+		if node == nil {
+			continue
+		}
+		zoneCountMap[node.Zone]++
+		rackCountMap[node.rackKey()]++
+	}
+
+	for len(replicas) < count {
+		var best *Node
+		for _, node := range candidates {
+			if containsUint64(replicas, node.Id) {
+				continue
+			}
+			if best == nil {
+				best = node
+				continue
+			}
+			zoneCount, bestZoneCount := zoneCountMap[node.Zone], zoneCountMap[best.Zone]
+			if zoneCount < bestZoneCount || (zoneCount == bestZoneCount && rackCountMap[node.rackKey()] < rackCountMap[best.rackKey()]) {
+				best = node
+			}
+		}
+		if best == nil {
+			break
+		}
+		replicas = append(replicas, best.Id)
+		zoneCountMap[best.Zone]++
+		rackCountMap[best.rackKey()]++
+	}
+	return replicas
+}
+
+// ZoneCount 副本分布的可用区数量
+func ZoneCount(nodes []*Node, replicas []uint64) int {
+	zones := make(map[string]struct{})
+	for _, id := range replicas {
+		node := nodeById(nodes, id)
+		if node == nil {
+			continue
+		}
+		zones[node.Zone] = struct{}{}
+	}
+	return len(zones)
+}
+
+// ExpectZoneCount 副本数量为replicaCount时，理想情况下副本应该分布的可用区数量
+func ExpectZoneCount(nodes []*Node, replicaCount int) int {
+	zones := make(map[string]struct{})
+	for _, node := range nodes {
+		if !node.AllowVote || node.Status != NodeStatus_NodeStatusJoined {
+			continue
+		}
+		zones[node.Zone] = struct{}{}
+	}
+	if len(zones) < replicaCount {
+		return len(zones)
+	}
+	return replicaCount
+}
+
+// ReplaceKeepsSpread 判断将副本from替换为to后，副本分布的可用区数量是否不会减少
+func ReplaceKeepsSpread(nodes []*Node, replicas []uint64, from, to uint64) bool {
+	newReplicas := make([]uint64, 0, len(replicas))
+	for _, id := range replicas {
+		if id == from {
+			continue
+		}
+		newReplicas = append(newReplicas, id)
+	}
+	newReplicas = append(newReplicas, to)
+	return ZoneCount(nodes, newReplicas) >= ZoneCount(nodes, replicas)
+}
+
+func nodeById(nodes []*Node, id uint64) *Node {
+	for _, node := range nodes {
+		if node.Id == id {
+			return node
+		}
+	}
+	return nil
+}
+
+func containsUint64(items []uint64, v uint64) bool {
+	for _, item := range items {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -69,6 +69,35 @@ func (s *Server) handleCmd(cmd *CMD) error {
 		return s.handleSlotUpdate(cmd)
 	case CMDTypeNodeStatusChange: // 节点状态改变
 		return s.handleNodeStatusChange(cmd)
+	case CMDTypeNodeTopologyChange: // 节点拓扑变更
+		return s.handleNodeTopologyChange(cmd)
+	case CMDTypeNodeDrainingChange: // 节点排空状态变更
+		return s.handleNodeDrainingChange(cmd)
+	case CMDTypeNodeProtoVersionChange: // 节点协议版本变更
+		return s.handleNodeProtoVersionChange(cmd)
+	case CMDTypeFeatureEnable: // 开启集群特性
+		s.cfg.enableFeature(string(cmd.Data))
+		return nil
+	case CMDTypeApiKeySave: // 保存业务api访问密钥
+		return s.handleApiKeySave(cmd)
+	case CMDTypeApiKeyRemove: // 吊销业务api访问密钥
+		s.cfg.removeApiKey(string(cmd.Data))
+		return nil
+	case CMDTypeManagerUserSave: // 保存管理后台用户
+		return s.handleManagerUserSave(cmd)
+	case CMDTypeManagerUserRemove: // 删除管理后台用户
+		s.cfg.removeManagerUser(string(cmd.Data))
+		return nil
+	case CMDTypeManagerRoleSave: // 保存管理后台角色
+		return s.handleManagerRoleSave(cmd)
+	case CMDTypeManagerRoleRemove: // 删除管理后台角色
+		s.cfg.removeManagerRole(string(cmd.Data))
+		return nil
+	case CMDTypeBotSave: // 保存服务端机器人
+		return s.handleBotSave(cmd)
+	case CMDTypeBotRemove: // 删除服务端机器人
+		s.cfg.removeBot(string(cmd.Data))
+		return nil
 	}
 	return nil
 }
@@ -97,6 +126,87 @@ func (s *Server) handleApiServerAddrChange(cmd *CMD) error {
 	return nil
 }
 
+func (s *Server) handleNodeTopologyChange(cmd *CMD) error {
+	nodeId, topology, err := DecodeNodeTopologyChange(cmd.Data)
+	if err != nil {
+		s.Error("decode node topology change err", zap.Error(err))
+		return err
+	}
+
+	s.cfg.updateNodeTopology(nodeId, topology)
+	return nil
+}
+
+func (s *Server) handleNodeDrainingChange(cmd *CMD) error {
+	nodeId, draining, err := DecodeNodeDrainingChange(cmd.Data)
+	if err != nil {
+		s.Error("decode node draining change err", zap.Error(err))
+		return err
+	}
+
+	s.cfg.updateNodeDraining(nodeId, draining)
+	return nil
+}
+
+func (s *Server) handleNodeProtoVersionChange(cmd *CMD) error {
+	nodeId, protoVersion, err := DecodeNodeProtoVersionChange(cmd.Data)
+	if err != nil {
+		s.Error("decode node proto version change err", zap.Error(err))
+		return err
+	}
+
+	s.cfg.updateNodeProtoVersion(nodeId, protoVersion)
+	return nil
+}
+
+func (s *Server) handleApiKeySave(cmd *CMD) error {
+	apiKey := &pb.ApiKey{}
+	err := apiKey.Unmarshal(cmd.Data)
+	if err != nil {
+		s.Error("unmarshal api key err", zap.Error(err))
+		return err
+	}
+
+	s.cfg.saveApiKey(apiKey)
+	return nil
+}
+
+func (s *Server) handleManagerUserSave(cmd *CMD) error {
+	user := &pb.ManagerUser{}
+	err := user.Unmarshal(cmd.Data)
+	if err != nil {
+		s.Error("unmarshal manager user err", zap.Error(err))
+		return err
+	}
+
+	s.cfg.saveManagerUser(user)
+	return nil
+}
+
+func (s *Server) handleManagerRoleSave(cmd *CMD) error {
+	role := &pb.ManagerRole{}
+	err := role.Unmarshal(cmd.Data)
+	if err != nil {
+		s.Error("unmarshal manager role err", zap.Error(err))
+		return err
+	}
+
+	s.cfg.saveManagerRole(role)
+	return nil
+}
+
+func (s *Server) handleBotSave(cmd *CMD) error {
+	bot := &pb.Bot{}
+	err := bot.Unmarshal(cmd.Data)
+	if err != nil {
+		s.Error("unmarshal bot err", zap.Error(err))
+		return err
+	}
+
+	s.cfg.saveBot(bot)
+	return nil
+}
+
 func (s *Server) handleNodeJoin(cmd *CMD) error {
 
 	newNode := &pb.Node{}
@@ -64,6 +64,266 @@ func (s *Server) ProposeApiServerAddr(nodeId uint64, apiServerAddr string) error
 	return nil
 }
 
+// ProposeNodeTopology 提案节点拓扑（可用区/机架）变更
+func (s *Server) ProposeNodeTopology(nodeId uint64, topology pb.Topology) error {
+
+	data, err := EncodeNodeTopologyChange(nodeId, topology)
+	if err != nil {
+		return err
+	}
+
+	cmd := NewCMD(CMDTypeNodeTopologyChange, data)
+	cmdBytes, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = s.proposeAndWait([]replica.Log{
+		{
+			Id:   uint64(s.cfgGenId.Generate().Int64()),
+			Data: cmdBytes,
+		},
+	})
+	if err != nil {
+		s.Error("ProposeNodeTopology failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// ProposeNodeDraining 提案节点排空状态变更
+func (s *Server) ProposeNodeDraining(nodeId uint64, draining bool) error {
+
+	data, err := EncodeNodeDrainingChange(nodeId, draining)
+	if err != nil {
+		return err
+	}
+
+	cmd := NewCMD(CMDTypeNodeDrainingChange, data)
+	cmdBytes, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = s.proposeAndWait([]replica.Log{
+		{
+			Id:   uint64(s.cfgGenId.Generate().Int64()),
+			Data: cmdBytes,
+		},
+	})
+	if err != nil {
+		s.Error("ProposeNodeDraining failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// ProposeNodeProtoVersion 提案节点协议版本变更
+func (s *Server) ProposeNodeProtoVersion(nodeId uint64, protoVersion uint32) error {
+
+	data, err := EncodeNodeProtoVersionChange(nodeId, protoVersion)
+	if err != nil {
+		return err
+	}
+
+	cmd := NewCMD(CMDTypeNodeProtoVersionChange, data)
+	cmdBytes, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = s.proposeAndWait([]replica.Log{
+		{
+			Id:   uint64(s.cfgGenId.Generate().Int64()),
+			Data: cmdBytes,
+		},
+	})
+	if err != nil {
+		s.Error("ProposeNodeProtoVersion failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// ProposeFeatureEnable 提案开启集群特性
+func (s *Server) ProposeFeatureEnable(feature string) error {
+
+	cmd := NewCMD(CMDTypeFeatureEnable, []byte(feature))
+	cmdBytes, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = s.proposeAndWait([]replica.Log{
+		{
+			Id:   uint64(s.cfgGenId.Generate().Int64()),
+			Data: cmdBytes,
+		},
+	})
+	if err != nil {
+		s.Error("ProposeFeatureEnable failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// ProposeApiKeySave 提案保存业务api访问密钥
+func (s *Server) ProposeApiKeySave(apiKey *pb.ApiKey) error {
+
+	data, err := apiKey.Marshal()
+	if err != nil {
+		return err
+	}
+
+	cmd := NewCMD(CMDTypeApiKeySave, data)
+	cmdBytes, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = s.proposeAndWait([]replica.Log{
+		{
+			Id:   uint64(s.cfgGenId.Generate().Int64()),
+			Data: cmdBytes,
+		},
+	})
+	if err != nil {
+		s.Error("ProposeApiKeySave failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// ProposeApiKeyRemove 提案吊销业务api访问密钥
+func (s *Server) ProposeApiKeyRemove(id string) error {
+
+	cmd := NewCMD(CMDTypeApiKeyRemove, []byte(id))
+	cmdBytes, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = s.proposeAndWait([]replica.Log{
+		{
+			Id:   uint64(s.cfgGenId.Generate().Int64()),
+			Data: cmdBytes,
+		},
+	})
+	if err != nil {
+		s.Error("ProposeApiKeyRemove failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// ProposeManagerUserSave 提案保存管理后台用户
+func (s *Server) ProposeManagerUserSave(user *pb.ManagerUser) error {
+
+	data, err := user.Marshal()
+	if err != nil {
+		return err
+	}
+
+	cmd := NewCMD(CMDTypeManagerUserSave, data)
+	cmdBytes, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = s.proposeAndWait([]replica.Log{
+		{
+			Id:   uint64(s.cfgGenId.Generate().Int64()),
+			Data: cmdBytes,
+		},
+	})
+	if err != nil {
+		s.Error("ProposeManagerUserSave failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// ProposeManagerUserRemove 提案删除管理后台用户
+func (s *Server) ProposeManagerUserRemove(username string) error {
+
+	cmd := NewCMD(CMDTypeManagerUserRemove, []byte(username))
+	cmdBytes, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = s.proposeAndWait([]replica.Log{
+		{
+			Id:   uint64(s.cfgGenId.Generate().Int64()),
+			Data: cmdBytes,
+		},
+	})
+	if err != nil {
+		s.Error("ProposeManagerUserRemove failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// ProposeManagerRoleSave 提案保存管理后台角色
+func (s *Server) ProposeManagerRoleSave(role *pb.ManagerRole) error {
+
+	data, err := role.Marshal()
+	if err != nil {
+		return err
+	}
+
+	cmd := NewCMD(CMDTypeManagerRoleSave, data)
+	cmdBytes, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = s.proposeAndWait([]replica.Log{
+		{
+			Id:   uint64(s.cfgGenId.Generate().Int64()),
+			Data: cmdBytes,
+		},
+	})
+	if err != nil {
+		s.Error("ProposeManagerRoleSave failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// ProposeManagerRoleRemove 提案删除管理后台角色
+func (s *Server) ProposeManagerRoleRemove(name string) error {
+
+	cmd := NewCMD(CMDTypeManagerRoleRemove, []byte(name))
+	cmdBytes, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = s.proposeAndWait([]replica.Log{
+		{
+			Id:   uint64(s.cfgGenId.Generate().Int64()),
+			Data: cmdBytes,
+		},
+	})
+	if err != nil {
+		s.Error("ProposeManagerRoleRemove failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
 // ProposeJoin 提案节点加入
 func (s *Server) ProposeJoin(node *pb.Node) error {
 
@@ -244,3 +504,54 @@ func (s *Server) ProposeNodeStatus(nodeId uint64, status pb.NodeStatus) error {
 	}
 	return nil
 }
+
+// ProposeBotSave 提案保存服务端机器人
+func (s *Server) ProposeBotSave(bot *pb.Bot) error {
+
+	data, err := bot.Marshal()
+	if err != nil {
+		return err
+	}
+
+	cmd := NewCMD(CMDTypeBotSave, data)
+	cmdBytes, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = s.proposeAndWait([]replica.Log{
+		{
+			Id:   uint64(s.cfgGenId.Generate().Int64()),
+			Data: cmdBytes,
+		},
+	})
+	if err != nil {
+		s.Error("ProposeBotSave failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// ProposeBotRemove 提案删除服务端机器人
+func (s *Server) ProposeBotRemove(uid string) error {
+
+	cmd := NewCMD(CMDTypeBotRemove, []byte(uid))
+	cmdBytes, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+
+	err = s.proposeAndWait([]replica.Log{
+		{
+			Id:   uint64(s.cfgGenId.Generate().Int64()),
+			Data: cmdBytes,
+		},
+	})
+	if err != nil {
+		s.Error("ProposeBotRemove failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package clusterevent
 
 import (
+	"sort"
 	"strings"
 	"time"
 
@@ -53,6 +54,20 @@ func (s *Server) checkClusterConfig() error {
 			return err
 		}
 
+		// 记录节点的协议版本
+		err = s.handleNodeProtoVersionChange()
+		if err != nil {
+			s.Error("handleNodeProtoVersionChange failed", zap.Error(err))
+			return err
+		}
+
+		// 所有节点都支持后开启集群特性
+		err = s.handleFeatureGates()
+		if err != nil {
+			s.Error("handleFeatureGates failed", zap.Error(err))
+			return err
+		}
+
 		// 检查和均衡槽领导
 		err = s.handleSlotLeaderAutoBalance()
 		if err != nil {
@@ -120,8 +135,10 @@ func (s *Server) handleClusterConfigInit() error {
 	var replicas []uint64
 	for nodeId, addr := range s.opts.InitNodes {
 		apiAddr := ""
+		topology := s.opts.InitNodeTopologies[nodeId]
 		if nodeId == s.opts.NodeId {
 			apiAddr = s.opts.ApiServerAddr
+			topology = s.localTopology()
 		}
 		nodes = append(nodes, &pb.Node{
 			Id:            nodeId,
@@ -132,6 +149,8 @@ func (s *Server) handleClusterConfigInit() error {
 			Role:          pb.NodeRole_NodeRoleReplica,
 			Status:        pb.NodeStatus_NodeStatusJoined,
 			CreatedAt:     time.Now().Unix(),
+			Zone:          topology.Zone,
+			Rack:          topology.Rack,
 		})
 		replicas = append(replicas, nodeId)
 	}
@@ -147,11 +166,12 @@ func (s *Server) handleClusterConfigInit() error {
 			if len(replicas) <= int(replicaCount) {
 				slot.Replicas = replicas
 			} else {
-				slot.Replicas = make([]uint64, 0, replicaCount)
-				for i := uint32(0); i < replicaCount; i++ {
-					idx := (offset + int(i)) % len(replicas)
-					slot.Replicas = append(slot.Replicas, replicas[idx])
+				// 按偏移轮转候选节点保证槽副本均匀分布，再按可用区/机架分散选择副本
+				candidates := make([]*pb.Node, 0, len(nodes))
+				for j := 0; j < len(nodes); j++ {
+					candidates = append(candidates, nodes[(offset+j)%len(nodes)])
 				}
+				slot.Replicas = pb.SpreadReplicas(candidates, nil, int(replicaCount))
 			}
 			offset++
 			// 随机选举一个领导者
@@ -202,6 +222,19 @@ func (s *Server) handleCompareLocalClusterConfig() error {
 		}
 	}
 
+	// 如果配置里自己节点的拓扑信息（可用区/机架）与本地配置不同，则提案配置（需要所有节点都支持拓扑命令）
+	localNode := s.cfgServer.Node(s.opts.NodeId)
+	if localNode != nil && s.FeatureEnabled(pb.FeatureNodeTopology) {
+		topology := s.localTopology()
+		if localNode.Zone != topology.Zone || localNode.Rack != topology.Rack {
+			err := s.cfgServer.ProposeNodeTopology(s.opts.NodeId, topology)
+			if err != nil {
+				s.Error("ProposeNodeTopology failed", zap.Error(err))
+				return err
+			}
+		}
+	}
+
 	if s.IsLeader() {
 		// 节点在线状态改变
 		err := s.handleNodeOnlineStatusChange()
@@ -292,6 +325,26 @@ func (s *Server) autoBalanceSlotLeaders(cfg *pb.Config) []*pb.Slot {
 		return false
 	}
 
+	var nodeZone = func(nodeId uint64) string {
+		for _, node := range cfg.Nodes {
+			if node.Id == nodeId {
+				return node.Zone
+			}
+		}
+		return ""
+	}
+
+	// 每个可用区的槽领导数量
+	zoneLeaderCountMap := make(map[string]uint32)
+	for nodeId, leaderCount := range nodeLeaderCountMap {
+		zoneLeaderCountMap[nodeZone(nodeId)] += leaderCount
+	}
+
+	importNodeIds := make([]uint64, 0, len(importNodeLeaderCountMap))
+	for importNodeId := range importNodeLeaderCountMap {
+		importNodeIds = append(importNodeIds, importNodeId)
+	}
+
 	var newSlots []*pb.Slot
 	for exportNodeId, exportLeaderCount := range exportNodeLeaderCountMap {
 		if exportLeaderCount == 0 {
@@ -301,7 +354,18 @@ func (s *Server) autoBalanceSlotLeaders(cfg *pb.Config) []*pb.Slot {
 		if !nodeOnline(exportNodeId) { // 节点不在线 不参与
 			continue
 		}
-		for importNodeId, importLeaderCount := range importNodeLeaderCountMap {
+
+		// 优先迁入到槽领导数量少的可用区，让槽领导尽量分散在不同可用区
+		sort.Slice(importNodeIds, func(i, j int) bool {
+			iCount, jCount := zoneLeaderCountMap[nodeZone(importNodeIds[i])], zoneLeaderCountMap[nodeZone(importNodeIds[j])]
+			if iCount != jCount {
+				return iCount < jCount
+			}
+			return importNodeIds[i] < importNodeIds[j]
+		})
+
+		for _, importNodeId := range importNodeIds {
+			importLeaderCount := importNodeLeaderCountMap[importNodeId]
 			if importLeaderCount == 0 {
 				continue
 			}
@@ -321,11 +385,17 @@ func (s *Server) autoBalanceSlotLeaders(cfg *pb.Config) []*pb.Slot {
 					newSlots = append(newSlots, newSlot)
 					exportLeaderCount--
 					importLeaderCount--
+					zoneLeaderCountMap[nodeZone(exportNodeId)]--
+					zoneLeaderCountMap[nodeZone(importNodeId)]++
 					if exportLeaderCount == 0 || importLeaderCount == 0 {
 						break
 					}
 				}
 			}
+			importNodeLeaderCountMap[importNodeId] = importLeaderCount
+			if exportLeaderCount == 0 {
+				break
+			}
 		}
 	}
 	return newSlots
@@ -354,6 +424,7 @@ func (s *Server) handleNodeJoining() error {
 	var migrateSlots []*pb.Slot // 迁移的槽列表
 
 	voteNodes := s.cfgServer.AllowVoteNodes()
+	nodes := s.cfgServer.Nodes()
 
 	if uint32(len(firstSlot.Replicas)) < s.cfgServer.SlotReplicaCount() { // 如果当前槽的副本数量小于配置的副本数量，则可以将新节点直接加入到学习节点中
 		for _, slot := range slots {
@@ -429,7 +500,10 @@ func (s *Server) handleNodeJoining() error {
 
 				// ------------------- 分配槽领导 -------------------
 				allocSlotLeader := false // 是否已经分配完槽领导
-				if fromSlotCount > 0 && fromSlotLeaderCount > 0 && slot.Leader == node.Id {
+				// 迁移后副本分布的可用区数量不能减少
+				keepsSpread := pb.ReplaceKeepsSpread(nodes, slot.Replicas, node.Id, joiningNode.Id)
+
+				if fromSlotCount > 0 && fromSlotLeaderCount > 0 && slot.Leader == node.Id && keepsSpread {
 
 					allocSlotLeader = true
 					newSlot := slot.Clone()
@@ -446,7 +520,7 @@ func (s *Server) handleNodeJoining() error {
 				}
 
 				// ------------------- 分配槽副本 -------------------
-				if fromSlotCount > 0 && !allocSlotLeader {
+				if fromSlotCount > 0 && !allocSlotLeader && keepsSpread {
 					if wkutil.ArrayContainsUint64(slot.Replicas, node.Id) {
 						newSlot := slot.Clone()
 						newSlot.MigrateFrom = node.Id
@@ -464,15 +538,66 @@ func (s *Server) handleNodeJoining() error {
 
 	}
 
-	if len(migrateSlots) > 0 {
-		err := s.ProposeJoined(joiningNode.Id, migrateSlots)
+	if len(migrateSlots) == 0 {
+		// 没有可迁移的槽（例如迁移会减少副本分布的可用区），节点也需要标记为已加入，否则节点会一直处于加入中
+		s.Info("no slots to migrate to the joining node", zap.Uint64("nodeId", joiningNode.Id))
+	}
+
+	err := s.ProposeJoined(joiningNode.Id, migrateSlots)
+	if err != nil {
+		return err
+	}
+
+	return nil
+
+}
+
+// handleNodeProtoVersionChange 将节点握手时告知的协议版本记录到分布式配置中
+func (s *Server) handleNodeProtoVersionChange() error {
+	if s.opts.NodeProtoVersion == nil {
+		return nil
+	}
+	for _, node := range s.cfgServer.Nodes() {
+		var (
+			version uint32
+			ok      bool
+		)
+		if node.Id == s.opts.NodeId {
+			version, ok = pb.ProtoVersion, true
+		} else if node.Online {
+			version, ok = s.opts.NodeProtoVersion(node.Id)
+		}
+		if !ok || version == node.ProtoVersion {
+			continue
+		}
+		s.Info("node proto version change", zap.Uint64("nodeId", node.Id), zap.Uint32("oldVersion", node.ProtoVersion), zap.Uint32("newVersion", version))
+		err := s.cfgServer.ProposeNodeProtoVersion(node.Id, version)
 		if err != nil {
+			s.Error("ProposeNodeProtoVersion failed", zap.Error(err))
 			return err
 		}
 	}
-
 	return nil
+}
 
+// handleFeatureGates 所有节点的协议版本都满足要求后开启集群特性
+func (s *Server) handleFeatureGates() error {
+	cfg := s.cfgServer.Config()
+	for _, feature := range pb.Features {
+		if cfg.FeatureEnabled(feature.Name) {
+			continue
+		}
+		if len(cfg.FeatureBlockingNodes(feature)) > 0 {
+			continue
+		}
+		s.Info("enable cluster feature", zap.String("feature", feature.Name))
+		err := s.cfgServer.ProposeFeatureEnable(feature.Name)
+		if err != nil {
+			s.Error("ProposeFeatureEnable failed", zap.Error(err))
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *Server) handleNodeOnlineStatusChange() error {
@@ -497,6 +622,9 @@ func (s *Server) handleNodeOnlineChange(nodeId uint64, online bool) error {
 	if online { // 节点上线
 
 		s.Info("节点上线", zap.Uint64("nodeId", nodeId))
+		if s.NodeDraining(nodeId) { // 排空中的节点不迁入槽领导
+			return nil
+		}
 		slots := s.cfgServer.Slots()
 
 		onlineNodeCount := s.cfgServer.AllowVoteAndJoinedOnlineNodeCount()
@@ -578,6 +706,10 @@ func (s *Server) handleNodeOnlineChange(nodeId uint64, online bool) error {
 					continue
 				}
 
+				if s.NodeDraining(nId) { // 排空中的节点不参与
+					continue
+				}
+
 				if currentNodeSlotLeaderCount <= 0 {
 					break
 				}
@@ -634,11 +766,14 @@ func (s *Server) handleSlotLeaderAutoBalance() error {
 
 	cfg := s.cfgServer.Config()
 
-	// 有未加入的节点或者有槽正在迁移，则不进行自动均衡
+	// 有未加入或正在排空的节点或者有槽正在迁移，则不进行自动均衡
 	for _, node := range cfg.Nodes {
 		if node.Status != pb.NodeStatus_NodeStatusJoined {
 			return nil
 		}
+		if node.Draining {
+			return nil
+		}
 	}
 	for _, slot := range cfg.Slots {
 		if slot.MigrateFrom != 0 || slot.MigrateTo != 0 {
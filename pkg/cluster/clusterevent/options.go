@@ -17,9 +17,14 @@ type Options struct {
 	ChannelMaxReplicaCount uint32 // 每个频道最大副本数量
 	ConfigDir              string
 	ApiServerAddr          string                       // api服务地址
+	Zone                   string                       // 当前节点所在可用区
+	Rack                   string                       // 当前节点所在机架
+	InitNodeTopologies     map[uint64]pb.Topology       // 初始节点的拓扑信息，用于初始化时按可用区分配槽副本
 	OnClusterConfigChange  func(cfg *pb.Config)         // 分布式配置改变
 	OnSlotElection         func(slots []*pb.Slot) error // 槽位选举
-	Send                   func(m reactor.Message)      // 发送消息
+	// NodeProtoVersion 获取节点在握手时告知的协议版本，ok为false表示还未与此节点建立连接
+	NodeProtoVersion func(nodeId uint64) (version uint32, ok bool)
+	Send             func(m reactor.Message) // 发送消息
 	// PongMaxTick 节点超过多少tick没有回应心跳就认为是掉线
 	PongMaxTick int
 	// 学习者检查间隔（每隔这个间隔时间检查下学习者的日志）
@@ -105,6 +110,24 @@ func WithApiServerAddr(apiServerAddr string) Option {
 	}
 }
 
+func WithZone(zone string) Option {
+	return func(o *Options) {
+		o.Zone = zone
+	}
+}
+
+func WithRack(rack string) Option {
+	return func(o *Options) {
+		o.Rack = rack
+	}
+}
+
+func WithInitNodeTopologies(topologies map[uint64]pb.Topology) Option {
+	return func(o *Options) {
+		o.InitNodeTopologies = topologies
+	}
+}
+
 func WithCluster(cluster icluster.Cluster) Option {
 	return func(o *Options) {
 		o.Cluster = cluster
@@ -147,6 +170,12 @@ func WithSeed(seed string) Option {
 	}
 }
 
+func WithNodeProtoVersion(f func(nodeId uint64) (uint32, bool)) Option {
+	return func(o *Options) {
+		o.NodeProtoVersion = f
+	}
+}
+
 func WithOnSlotElection(f func(slots []*pb.Slot) error) Option {
 	return func(o *Options) {
 		o.OnSlotElection = f
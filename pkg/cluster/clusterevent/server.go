@@ -206,6 +206,66 @@ func (s *Server) ProposeMigrateSlot(slotId uint32, fromNodeId, toNodeId uint64)
 	return s.cfgServer.ProposeMigrateSlot(slotId, fromNodeId, toNodeId)
 }
 
+// ProposeNodeDraining 提案节点排空状态，排空中的节点不再参与槽领导的均衡和选举
+func (s *Server) ProposeNodeDraining(nodeId uint64, draining bool) error {
+
+	return s.cfgServer.ProposeNodeDraining(nodeId, draining)
+}
+
+// ProposeApiKeySave 提案保存业务api访问密钥
+func (s *Server) ProposeApiKeySave(apiKey *pb.ApiKey) error {
+	return s.cfgServer.ProposeApiKeySave(apiKey)
+}
+
+// ProposeApiKeyRemove 提案吊销业务api访问密钥
+func (s *Server) ProposeApiKeyRemove(id string) error {
+	return s.cfgServer.ProposeApiKeyRemove(id)
+}
+
+// ProposeManagerUserSave 提案保存管理后台用户
+func (s *Server) ProposeManagerUserSave(user *pb.ManagerUser) error {
+	return s.cfgServer.ProposeManagerUserSave(user)
+}
+
+// ProposeManagerUserRemove 提案删除管理后台用户
+func (s *Server) ProposeManagerUserRemove(username string) error {
+	return s.cfgServer.ProposeManagerUserRemove(username)
+}
+
+// ProposeManagerRoleSave 提案保存管理后台角色
+func (s *Server) ProposeManagerRoleSave(role *pb.ManagerRole) error {
+	return s.cfgServer.ProposeManagerRoleSave(role)
+}
+
+// ProposeManagerRoleRemove 提案删除管理后台角色
+func (s *Server) ProposeManagerRoleRemove(name string) error {
+	return s.cfgServer.ProposeManagerRoleRemove(name)
+}
+
+// ProposeBotSave 提案保存服务端机器人
+func (s *Server) ProposeBotSave(bot *pb.Bot) error {
+	return s.cfgServer.ProposeBotSave(bot)
+}
+
+// ProposeBotRemove 提案删除服务端机器人
+func (s *Server) ProposeBotRemove(uid string) error {
+	return s.cfgServer.ProposeBotRemove(uid)
+}
+
+// FeatureEnabled 集群特性是否已开启
+func (s *Server) FeatureEnabled(feature string) bool {
+	return s.cfgServer.Config().FeatureEnabled(feature)
+}
+
+// NodeDraining 节点是否正在排空
+func (s *Server) NodeDraining(nodeId uint64) bool {
+	node := s.cfgServer.Node(nodeId)
+	if node == nil {
+		return false
+	}
+	return node.Draining
+}
+
 func (s *Server) ProposeSlots(slots []*pb.Slot) error {
 
 	return s.cfgServer.ProposeSlots(slots)
@@ -223,6 +283,17 @@ func (s *Server) GetLogsInReverseOrder(startLogIndex uint64, endLogIndex uint64,
 	return s.cfgServer.GetLogsInReverseOrder(startLogIndex, endLogIndex, limit)
 }
 
+// 当前节点的拓扑信息，未配置可用区时使用初始节点里的配置
+func (s *Server) localTopology() pb.Topology {
+	if s.opts.Zone != "" || s.opts.Rack != "" {
+		return pb.Topology{
+			Zone: s.opts.Zone,
+			Rack: s.opts.Rack,
+		}
+	}
+	return s.opts.InitNodeTopologies[s.opts.NodeId]
+}
+
 func (s *Server) saveLocalConfig(cfg *pb.Config) error {
 
 	err := s.localCfgFile.Truncate(0)
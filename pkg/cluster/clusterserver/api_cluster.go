@@ -9,6 +9,7 @@ import (
 
 	"github.com/WuKongIM/WuKongIM/pkg/auth"
 	"github.com/WuKongIM/WuKongIM/pkg/auth/resource"
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/replica"
 	"github.com/WuKongIM/WuKongIM/pkg/network"
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
@@ -63,53 +64,87 @@ func (s *Server) channelMigrate(c *wkhttp.Context) {
 		return
 	}
 
-	newClusterConfig := clusterConfig.Clone()
-	if newClusterConfig.MigrateFrom != 0 || newClusterConfig.MigrateTo != 0 {
-		c.ResponseError(errors.New("migrate is in progress"))
+	err = s.proposeChannelMigrate(clusterConfig, req.MigrateFrom, req.MigrateTo)
+	if err != nil {
+		s.Error("channelMigrate: proposeChannelMigrate error", zap.Error(err))
+		c.ResponseError(err)
 		return
 	}
+	c.ResponseOK()
 
-	// 保存配置
-	newClusterConfig.MigrateFrom = req.MigrateFrom
-	newClusterConfig.MigrateTo = req.MigrateTo
-	newClusterConfig.ConfVersion = uint64(time.Now().UnixNano())
+}
+
+// channelTransferLeader 转移频道领导到指定的副本（未指定则自动选择），新领导追上日志后才会切换
+func (s *Server) channelTransferLeader(c *wkhttp.Context) {
+	var req struct {
+		TransferTo uint64 `json:"transfer_to"` // 转移的目标节点，为0则自动选择
+	}
 
-	if !wkutil.ArrayContainsUint64(clusterConfig.Replicas, req.MigrateTo) {
-		// 将要目标节点加入学习者中
-		newClusterConfig.Learners = append(newClusterConfig.Learners, req.MigrateTo)
+	if !s.opts.Auth.HasPermissionWithContext(c, resource.ClusterChannel.Migrate, auth.ActionWrite) {
+		c.ResponseStatus(http.StatusUnauthorized)
+		return
 	}
 
-	// 提案保存配置
-	err = s.opts.ChannelClusterStorage.Propose(newClusterConfig)
+	bodyBytes, err := BindJSON(&req, c)
 	if err != nil {
-		s.Error("channelMigrate: Save error", zap.Error(err))
+		s.Error("BindJSON error", zap.Error(err))
 		c.ResponseError(err)
 		return
 	}
 
-	// 如果频道领导不是当前节点，则发送最新配置给频道领导 （这里就算发送失败也没问题，因为频道领导会间隔比对自己与槽领导的配置）
-	if newClusterConfig.LeaderId != s.opts.NodeId {
-		err = s.SendChannelClusterConfigUpdate(channelId, channelType, newClusterConfig.LeaderId)
-		if err != nil {
-			s.Error("channelMigrate: sendChannelClusterConfigUpdate error", zap.Error(err))
-			c.ResponseError(err)
-			return
-		}
-	} else {
-		s.UpdateChannelClusterConfig(newClusterConfig)
+	channelId := c.Param("channel_id")
+	channelType := wkutil.ParseUint8(c.Param("channel_type"))
+
+	// 获取频道所属槽领导的id
+	nodeId, err := s.SlotLeaderIdOfChannel(channelId, channelType)
+	if err != nil {
+		s.Error("channelTransferLeader: LeaderIdOfChannel error", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if nodeId != s.opts.NodeId {
+		c.ForwardWithBody(fmt.Sprintf("%s%s", s.clusterEventServer.Node(nodeId).ApiServerAddr, c.Request.URL.Path), bodyBytes)
+		return
 	}
 
-	// 如果目标节点不是当前节点，则发送最新配置给目标节点
-	if req.MigrateTo != s.opts.NodeId {
-		err = s.SendChannelClusterConfigUpdate(channelId, channelType, req.MigrateTo)
-		if err != nil {
-			s.Error("channelMigrate: sendChannelClusterConfigUpdate error", zap.Error(err))
-			c.ResponseError(err)
+	clusterConfig, err := s.getChannelClusterConfig(channelId, channelType)
+	if err != nil {
+		s.Error("channelTransferLeader: getChannelClusterConfig error", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	if clusterConfig.LeaderId == 0 {
+		c.ResponseError(ErrNoLeader)
+		return
+	}
+
+	transferTo := req.TransferTo
+	if transferTo == 0 {
+		transferTo = s.leaderTransferTarget(clusterConfig.Replicas, clusterConfig.LeaderId)
+		if transferTo == 0 {
+			c.ResponseError(ErrNoLeaderTransferTarget)
 			return
 		}
 	}
-	c.ResponseOK()
+	if transferTo == clusterConfig.LeaderId {
+		c.ResponseError(errors.New("transferTo is already the leader"))
+		return
+	}
+	if !wkutil.ArrayContainsUint64(clusterConfig.Replicas, transferTo) {
+		c.ResponseError(errors.New("transferTo not in replicas"))
+		return
+	}
 
+	err = s.proposeChannelMigrate(clusterConfig, clusterConfig.LeaderId, transferTo)
+	if err != nil {
+		s.Error("channelTransferLeader: proposeChannelMigrate error", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"transfer_from": clusterConfig.LeaderId,
+		"transfer_to":   transferTo,
+	})
 }
 
 func (s *Server) channelClusterConfig(c *wkhttp.Context) {
@@ -607,6 +642,11 @@ func (s *Server) requestChannelStatus(nodeId uint64, channels []*channelBase, he
 
 func (s *Server) clusterInfoGet(c *wkhttp.Context) {
 
+	if !s.opts.Auth.HasPermissionWithContext(c, resource.Cluster.Info, auth.ActionRead) {
+		c.ResponseStatus(http.StatusUnauthorized)
+		return
+	}
+
 	leaderId := s.clusterEventServer.LeaderId()
 	if leaderId == 0 {
 		c.ResponseError(errors.New("leader not found"))
@@ -617,6 +657,140 @@ func (s *Server) clusterInfoGet(c *wkhttp.Context) {
 		c.Forward(fmt.Sprintf("%s%s", leaderNode.ApiServerAddr, c.Request.URL.Path))
 		return
 	}
-	cfg := s.clusterEventServer.Config()
+	cfg := s.clusterEventServer.Config().Clone()
+	// 脱敏，不返回密码和访问密钥的hash
+	for _, user := range cfg.ManagerUsers {
+		user.PasswordHash = ""
+	}
+	for _, apiKey := range cfg.ApiKeys {
+		apiKey.KeyHash = ""
+		apiKey.PrevKeyHash = ""
+	}
 	c.JSON(http.StatusOK, cfg)
 }
+
+// clusterVersionsGet 获取集群各节点的协议版本和特性开启状态
+func (s *Server) clusterVersionsGet(c *wkhttp.Context) {
+	cfg := s.clusterEventServer.Config()
+
+	resp := &ClusterVersionResp{
+		ProtoVersion:    pb.ProtoVersion,
+		AppVersion:      s.opts.AppVersion,
+		MinProtoVersion: cfg.MinProtoVersion(),
+		Nodes:           make([]*NodeVersion, 0, len(cfg.Nodes)),
+		Features:        make([]*FeatureGate, 0, len(pb.Features)),
+	}
+
+	for _, node := range cfg.Nodes {
+		nodeVersion := &NodeVersion{
+			NodeId:       node.Id,
+			Online:       wkutil.BoolToInt(node.Online),
+			ProtoVersion: node.ProtoVersion,
+		}
+		if node.Id == s.opts.NodeId {
+			nodeVersion.ConnProtoVersion = pb.ProtoVersion
+			nodeVersion.Connected = 1
+		} else if connVersion, ok := s.nodeProtoVersion(node.Id); ok {
+			nodeVersion.ConnProtoVersion = connVersion
+			nodeVersion.Connected = 1
+		}
+		if node.ProtoVersion != pb.ProtoVersion {
+			resp.Mixed = 1
+		}
+		resp.Nodes = append(resp.Nodes, nodeVersion)
+	}
+
+	for _, feature := range pb.Features {
+		resp.Features = append(resp.Features, &FeatureGate{
+			Name:          feature.Name,
+			MinVersion:    feature.MinVersion,
+			Enabled:       wkutil.BoolToInt(cfg.FeatureEnabled(feature.Name)),
+			BlockingNodes: cfg.FeatureBlockingNodes(feature),
+		})
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// 获取副本放置违规（副本没有按可用区分散）的槽和频道
+func (s *Server) placementViolationsGet(c *wkhttp.Context) {
+	nodeId := wkutil.ParseUint64(c.Query("node_id"))
+	if nodeId > 0 && nodeId != s.opts.NodeId {
+		node := s.clusterEventServer.Node(nodeId)
+		if node == nil {
+			c.ResponseError(errors.New("node not found"))
+			return
+		}
+		c.Forward(fmt.Sprintf("%s%s", node.ApiServerAddr, c.Request.URL.Path))
+		return
+	}
+
+	offsetId := wkutil.ParseUint64(c.Query("offset_id")) // 频道分布式配置的偏移id
+	limit := wkutil.ParseInt(c.Query("limit"))
+	if limit <= 0 {
+		limit = 1000
+	}
+
+	cfg := s.clusterEventServer.Config()
+	nodes := cfg.Nodes
+
+	resp := &PlacementViolationResp{
+		Slots:    make([]*PlacementViolation, 0),
+		Channels: make([]*PlacementViolation, 0),
+	}
+
+	// 槽的副本放置检查
+	for _, st := range cfg.Slots {
+		expectZoneCount := pb.ExpectZoneCount(nodes, len(st.Replicas))
+		if pb.ZoneCount(nodes, st.Replicas) >= expectZoneCount {
+			continue
+		}
+		violation := newPlacementViolation(nodes, st.Replicas, expectZoneCount)
+		violation.SlotId = st.Id
+		resp.Slots = append(resp.Slots, violation)
+	}
+
+	// 本节点存储的频道副本放置检查
+	channelClusterConfigs, err := s.opts.ChannelClusterStorage.GetAll(offsetId, limit)
+	if err != nil {
+		s.Error("placementViolationsGet: GetAll error", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	for _, channelClusterConfig := range channelClusterConfigs {
+		resp.NextOffsetId = channelClusterConfig.Id
+		expectZoneCount := pb.ExpectZoneCount(nodes, len(channelClusterConfig.Replicas))
+		if pb.ZoneCount(nodes, channelClusterConfig.Replicas) >= expectZoneCount {
+			continue
+		}
+		violation := newPlacementViolation(nodes, channelClusterConfig.Replicas, expectZoneCount)
+		violation.ChannelId = channelClusterConfig.ChannelId
+		violation.ChannelType = channelClusterConfig.ChannelType
+		resp.Channels = append(resp.Channels, violation)
+	}
+	if len(channelClusterConfigs) >= limit {
+		resp.More = 1
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func newPlacementViolation(nodes []*pb.Node, replicas []uint64, expectZoneCount int) *PlacementViolation {
+	zones := make([]string, 0, len(replicas))
+	for _, replicaId := range replicas {
+		zone := ""
+		for _, node := range nodes {
+			if node.Id == replicaId {
+				zone = node.Zone
+				break
+			}
+		}
+		zones = append(zones, zone)
+	}
+	return &PlacementViolation{
+		Replicas:        replicas,
+		Zones:           zones,
+		ZoneCount:       pb.ZoneCount(nodes, replicas),
+		ExpectZoneCount: expectZoneCount,
+	}
+}
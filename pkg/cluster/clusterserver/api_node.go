@@ -11,6 +11,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/WuKongIM/WuKongIM/pkg/auth"
+	"github.com/WuKongIM/WuKongIM/pkg/auth/resource"
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
 	"github.com/WuKongIM/WuKongIM/pkg/wkhttp"
 	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
@@ -110,6 +113,77 @@ func (s *Server) simpleNodesGet(c *wkhttp.Context) {
 	})
 }
 
+// nodeDrain 排空节点，将节点上的槽领导和活跃频道领导转移到其他副本，通过GET查询排空进度
+func (s *Server) nodeDrain(c *wkhttp.Context) {
+	if !s.opts.Auth.HasPermissionWithContext(c, resource.ClusterNode.Drain, auth.ActionWrite) {
+		c.ResponseStatus(http.StatusUnauthorized)
+		return
+	}
+	node, ok := s.drainNodeOrForward(c)
+	if !ok {
+		return
+	}
+	err := s.drainer.start()
+	if err != nil {
+		s.Error("nodeDrain: start drain error", zap.Error(err), zap.Uint64("nodeId", node.Id))
+		c.ResponseError(err)
+		return
+	}
+	c.JSON(http.StatusOK, s.drainer.resp())
+}
+
+// nodeDrainGet 获取节点排空状态
+func (s *Server) nodeDrainGet(c *wkhttp.Context) {
+	if _, ok := s.drainNodeOrForward(c); !ok {
+		return
+	}
+	c.JSON(http.StatusOK, s.drainer.resp())
+}
+
+// nodeUndrain 取消节点排空，节点重新参与领导均衡
+func (s *Server) nodeUndrain(c *wkhttp.Context) {
+	if !s.opts.Auth.HasPermissionWithContext(c, resource.ClusterNode.Drain, auth.ActionWrite) {
+		c.ResponseStatus(http.StatusUnauthorized)
+		return
+	}
+	node, ok := s.drainNodeOrForward(c)
+	if !ok {
+		return
+	}
+	err := s.drainer.stop()
+	if err != nil {
+		s.Error("nodeUndrain: stop drain error", zap.Error(err), zap.Uint64("nodeId", node.Id))
+		c.ResponseError(err)
+		return
+	}
+	c.JSON(http.StatusOK, s.drainer.resp())
+}
+
+// drainNodeOrForward 排空只能由节点自己执行，不是当前节点则转发给目标节点
+func (s *Server) drainNodeOrForward(c *wkhttp.Context) (*pb.Node, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		s.Error("id parse error", zap.Error(err))
+		c.ResponseError(err)
+		return nil, false
+	}
+	node := s.clusterEventServer.Node(id)
+	if node == nil {
+		s.Error("node not found", zap.Uint64("nodeId", id))
+		c.ResponseError(ErrNodeNotFound)
+		return nil, false
+	}
+	if node.Id != s.opts.NodeId {
+		if !node.Online {
+			c.ResponseError(errors.New("node offline"))
+			return nil, false
+		}
+		c.Forward(fmt.Sprintf("%s%s", node.ApiServerAddr, c.Request.URL.Path))
+		return nil, false
+	}
+	return node, true
+}
+
 func (s *Server) nodeChannelsGet(c *wkhttp.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
@@ -207,6 +207,80 @@ func (s *Server) slotMigrate(c *wkhttp.Context) {
 
 }
 
+// slotTransferLeader 转移槽领导到指定的副本（未指定则自动选择），新领导追上日志后才会切换
+func (s *Server) slotTransferLeader(c *wkhttp.Context) {
+	var req struct {
+		TransferTo uint64 `json:"transfer_to"` // 转移的目标节点，为0则自动选择
+	}
+
+	if !s.opts.Auth.HasPermissionWithContext(c, resource.Slot.Migrate, auth.ActionWrite) {
+		c.ResponseStatus(http.StatusUnauthorized)
+		return
+	}
+
+	bodyBytes, err := BindJSON(&req, c)
+	if err != nil {
+		s.Error("bind json error", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+	idStr := c.Param("id")
+	id := wkutil.ParseUint32(idStr)
+
+	slot := s.clusterEventServer.Slot(id)
+	if slot == nil {
+		s.Error("slot not found", zap.Uint32("slotId", id))
+		c.ResponseError(errors.New("slot not found"))
+		return
+	}
+
+	node := s.clusterEventServer.Node(slot.Leader)
+	if node == nil {
+		s.Error("leader not found", zap.Uint64("leaderId", slot.Leader))
+		c.ResponseError(errors.New("leader not found"))
+		return
+	}
+
+	if slot.Leader != s.opts.NodeId {
+		c.ForwardWithBody(fmt.Sprintf("%s%s", node.ApiServerAddr, c.Request.URL.Path), bodyBytes)
+		return
+	}
+
+	if slot.MigrateFrom != 0 || slot.MigrateTo != 0 {
+		c.ResponseError(ErrMigrateInProgress)
+		return
+	}
+
+	transferTo := req.TransferTo
+	if transferTo == 0 {
+		transferTo = s.leaderTransferTarget(slot.Replicas, slot.Leader)
+		if transferTo == 0 {
+			c.ResponseError(ErrNoLeaderTransferTarget)
+			return
+		}
+	}
+	if transferTo == slot.Leader {
+		c.ResponseError(errors.New("transferTo is already the leader"))
+		return
+	}
+	if !wkutil.ArrayContainsUint64(slot.Replicas, transferTo) {
+		c.ResponseError(errors.New("transferTo not in replicas"))
+		return
+	}
+
+	err = s.clusterEventServer.ProposeMigrateSlot(id, slot.Leader, transferTo)
+	if err != nil {
+		s.Error("slotTransferLeader: ProposeMigrateSlot error", zap.Error(err))
+		c.ResponseError(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"transfer_from": slot.Leader,
+		"transfer_to":   transferTo,
+	})
+}
+
 func (s *Server) allSlotsGet(c *wkhttp.Context) {
 	leaderId := s.clusterEventServer.LeaderId()
 	if leaderId == 0 {
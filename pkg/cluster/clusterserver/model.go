@@ -46,6 +46,10 @@ var (
 	ErrSlotLeaderNotFound           = errors.New("slot leader not found")
 	ErrEmptyRequest                 = errors.New("empty request")
 	ErrChannelClusterConfigNotFound = errors.New("channel cluster config not found")
+	ErrMigrateInProgress            = errors.New("migrate is in progress")
+	ErrNoLeaderTransferTarget       = errors.New("no leader transfer target")
+	ErrProtoVersionTooLow           = errors.New("proto version too low")
+	ErrFeatureNotEnabled            = errors.New("cluster feature not enabled")
 )
 
 const (
@@ -633,6 +637,8 @@ type ClusterJoinReq struct {
 	NodeId     uint64
 	ServerAddr string
 	Role       pb.NodeRole
+	Zone       string // 节点所在可用区
+	Rack       string // 节点所在机架
 }
 
 func (c *ClusterJoinReq) Marshal() ([]byte, error) {
@@ -641,6 +647,8 @@ func (c *ClusterJoinReq) Marshal() ([]byte, error) {
 	enc.WriteUint64(c.NodeId)
 	enc.WriteString(c.ServerAddr)
 	enc.WriteUint32(uint32(c.Role))
+	enc.WriteString(c.Zone)
+	enc.WriteString(c.Rack)
 	return enc.Bytes(), nil
 
 }
@@ -659,6 +667,16 @@ func (c *ClusterJoinReq) Unmarshal(data []byte) error {
 		return err
 	}
 	c.Role = pb.NodeRole(role)
+
+	// 兼容旧版本节点，旧版本没有拓扑信息
+	if dec.Len() > 0 {
+		if c.Zone, err = dec.String(); err != nil {
+			return err
+		}
+		if c.Rack, err = dec.String(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -1077,6 +1095,7 @@ type NodeConfig struct {
 	ConfigVersion   uint64         `json:"config_version,omitempty"`    // 配置版本
 	Status          pb.NodeStatus  `json:"status,omitempty"`            // 状态
 	StatusFormat    string         `json:"status_format,omitempty"`     // 状态格式化
+	Draining        int            `json:"draining,omitempty"`          // 是否正在排空
 }
 
 func NewNodeConfigFromNode(n *pb.Node) *NodeConfig {
@@ -1105,6 +1124,7 @@ func NewNodeConfigFromNode(n *pb.Node) *NodeConfig {
 		AllowVote:     wkutil.BoolToInt(n.AllowVote),
 		Status:        n.Status,
 		StatusFormat:  status,
+		Draining:      wkutil.BoolToInt(n.Draining),
 	}
 }
 
@@ -1340,6 +1360,7 @@ type conversationResp struct {
 	UnreadCount       uint32                `json:"unread_count"`        // 未读消息数量（这个可以用户自己设置）
 	LastMsgSeq        uint64                `json:"last_msg_seq"`        // 最新消息序号
 	ReadedToMsgSeq    uint64                `json:"readed_to_msg_seq"`   // 已经读至的消息序号
+	MentionMsgSeq     uint64                `json:"mention_msg_seq"`     // 最近一条@此用户的消息序号
 	CreatedAt         int64                 `json:"created_at"`          // 创建时间
 	UpdatedAt         int64                 `json:"updated_at"`          // 更新时间
 	CreatedAtFormat   string                `json:"created_at_format"`   // 创建时间格式化
@@ -1373,6 +1394,7 @@ func newConversationResp(c wkdb.Conversation) *conversationResp {
 		ChannelTypeFormat: formatChannelType(c.ChannelType),
 		UnreadCount:       c.UnreadCount,
 		ReadedToMsgSeq:    c.ReadToMsgSeq,
+		MentionMsgSeq:     c.MentionMsgSeq,
 		CreatedAt:         createdAt,
 		UpdatedAt:         updatedAt,
 		CreatedAtFormat:   createdAtFormat,
@@ -1771,3 +1793,59 @@ type channelReplicaDetailResp struct {
 	RoleFormat        string `json:"role_format"`          // 角色格式化
 	LastMsgTimeFormat string `json:"last_msg_time_format"` // 最新消息时间格式化
 }
+
+// PlacementViolation 副本放置违规（副本分布的可用区数量少于期望值）
+type PlacementViolation struct {
+	SlotId          uint32   `json:"slot_id,omitempty"`      // 槽id
+	ChannelId       string   `json:"channel_id,omitempty"`   // 频道id
+	ChannelType     uint8    `json:"channel_type,omitempty"` // 频道类型
+	Replicas        []uint64 `json:"replicas"`               // 副本节点
+	Zones           []string `json:"zones"`                  // 副本节点对应的可用区
+	ZoneCount       int      `json:"zone_count"`             // 副本实际分布的可用区数量
+	ExpectZoneCount int      `json:"expect_zone_count"`      // 副本期望分布的可用区数量
+}
+
+type PlacementViolationResp struct {
+	Slots        []*PlacementViolation `json:"slots"`          // 违规的槽
+	Channels     []*PlacementViolation `json:"channels"`       // 违规的频道（本节点存储的频道分布式配置）
+	NextOffsetId uint64                `json:"next_offset_id"` // 下一页频道的偏移id
+	More         int                   `json:"more"`           // 是否还有更多频道
+}
+
+// NodeDrainResp 节点排空状态
+type NodeDrainResp struct {
+	NodeId             uint64      `json:"node_id"`               // 节点id
+	Draining           bool        `json:"draining"`              // 节点在集群配置中是否标记为排空
+	Status             DrainStatus `json:"status"`                // 排空任务状态
+	SlotLeaderCount    int         `json:"slot_leader_count"`     // 剩余的槽领导数量
+	ChannelLeaderCount int         `json:"channel_leader_count"`  // 剩余的活跃频道领导数量
+	StartedAt          int64       `json:"started_at,omitempty"`  // 开始时间
+	FinishedAt         int64       `json:"finished_at,omitempty"` // 结束时间
+}
+
+// NodeVersion 节点的版本信息
+type NodeVersion struct {
+	NodeId           uint64 `json:"node_id"`                      // 节点id
+	Online           int    `json:"online"`                       // 是否在线
+	ProtoVersion     uint32 `json:"proto_version"`                // 分布式配置中记录的协议版本
+	ConnProtoVersion uint32 `json:"conn_proto_version,omitempty"` // 当前节点与此节点握手时对方告知的协议版本
+	Connected        int    `json:"connected"`                    // 当前节点与此节点是否已连接
+}
+
+// FeatureGate 集群特性的开启状态
+type FeatureGate struct {
+	Name          string   `json:"name"`                     // 特性名
+	MinVersion    uint32   `json:"min_version"`              // 需要的最低协议版本
+	Enabled       int      `json:"enabled"`                  // 是否已开启
+	BlockingNodes []uint64 `json:"blocking_nodes,omitempty"` // 协议版本过低，阻止特性开启的节点
+}
+
+// ClusterVersionResp 集群的版本状态，用于滚动升级时查看混合版本状态
+type ClusterVersionResp struct {
+	ProtoVersion    uint32         `json:"proto_version"`     // 当前节点的协议版本
+	AppVersion      string         `json:"app_version"`       // 当前节点的应用版本
+	Mixed           int            `json:"mixed"`             // 集群中是否存在不同协议版本的节点
+	MinProtoVersion uint32         `json:"min_proto_version"` // 已开启的特性要求的最低协议版本（新加入节点不能低于此版本）
+	Nodes           []*NodeVersion `json:"nodes"`             // 节点版本
+	Features        []*FeatureGate `json:"features"`          // 集群特性
+}
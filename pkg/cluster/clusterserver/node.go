@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
 	"github.com/WuKongIM/WuKongIM/pkg/trace"
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
 	"github.com/WuKongIM/WuKongIM/pkg/wklog"
@@ -48,7 +49,15 @@ func newNode(id uint64, uid string, addr string, opts *Options) *node {
 			rl: NewRateLimiter(opts.MaxSendQueueSize),
 		},
 	}
-	n.client = client.New(addr, client.WithUID(uid), client.WithOnConnectStatus(n.connectStatusChange), client.WithRequestTimeout(opts.ReqTimeout))
+	n.client = client.New(
+		addr,
+		client.WithUID(uid),
+		client.WithOnConnectStatus(n.connectStatusChange),
+		client.WithRequestTimeout(opts.ReqTimeout),
+		client.WithTLSConfig(opts.ClientTLSConfig),
+		client.WithSecret(opts.NodeSecret),
+		client.WithProtoVersion(uint16(pb.ProtoVersion)),
+	)
 	return n
 }
 
@@ -56,6 +65,14 @@ func (n *node) connectStatusChange(status client.ConnectStatus) {
 	// n.Debug("节点连接状态改变", zap.String("status", status.String()))
 }
 
+// protoVersion 节点在握手时返回的协议版本，ok为false表示还未连接成功
+func (n *node) protoVersion() (uint32, bool) {
+	if n.client.ConnectStatus() != client.CONNECTED {
+		return 0, false
+	}
+	return uint32(n.client.ServerVersion()), true
+}
+
 func (n *node) start() {
 	n.stopper.RunWorker(n.processMessages)
 
@@ -1,6 +1,7 @@
 package cluster
 
 import (
+	stdtls "crypto/tls"
 	"strings"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/reactor"
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/replica"
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/WuKongIM/crypto/tls"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -23,6 +25,12 @@ type Options struct {
 	AppVersion    string      // 当前应用版本
 	// InitNodes 集群初始节点，key为节点id，value为节点内网通信地址
 	InitNodes map[uint64]string
+	// InitNodeTopologies 初始节点的拓扑信息（可用区/机架），key为节点id
+	InitNodeTopologies map[uint64]pb.Topology
+	// Zone 当前节点所在可用区，副本会尽量分散在不同的可用区
+	Zone string
+	// Rack 当前节点所在机架，同一可用区内副本会尽量分散在不同的机架
+	Rack string
 	// SlotCount 槽位数量
 	SlotCount uint32
 	// SlotMaxReplicaCount 每个槽位最大副本数量
@@ -99,6 +107,22 @@ type Options struct {
 
 	LokiUrl string // loki url example: http://localhost:3100
 	LokiJob string
+
+	// TLSConfig 节点监听的tls配置，如果设置了ClientCAs并且ClientAuth为RequireAndVerifyClientCert则为mTLS
+	// mTLS模式下，对端证书的CommonName必须为对端的节点id
+	TLSConfig *tls.Config
+	// ClientTLSConfig 连接其他节点时使用的tls配置
+	ClientTLSConfig *stdtls.Config
+	// NodeSecret 节点之间的共享密钥，不为空时节点握手需要使用此密钥签名
+	NodeSecret string
+	// NodeTokenMaxSkew 节点握手token允许的最大时间偏差
+	NodeTokenMaxSkew time.Duration
+	// AllowUnknownNodes 开启认证后，是否允许未知节点（不在集群配置和初始节点内）连接，新节点通过种子节点加入集群时需要开启
+	AllowUnknownNodes bool
+	// ReadIndexWaitTimeout 严格一致性的跟随者读时，等待本地副本应用到领导读索引的最长时间，超时则从领导读取
+	ReadIndexWaitTimeout time.Duration
+	// DrainTimeout 节点排空（转移所有领导）的最长时间
+	DrainTimeout time.Duration
 }
 
 func NewOptions(opt ...Option) *Options {
@@ -120,6 +144,8 @@ func NewOptions(opt ...Option) *Options {
 		ChannelMaxReplicaCount:     3,
 		ChannelLoadPoolSize:        1000,
 		LeaderTransferMinLogGap:    20,
+		ReadIndexWaitTimeout:       time.Millisecond * 500,
+		DrainTimeout:               time.Minute * 5,
 		LearnerMinLogGap:           100,
 		PageSize:                   20,
 
@@ -133,6 +159,8 @@ func NewOptions(opt ...Option) *Options {
 		SlotDbShardNum:         8,
 
 		LokiJob: "wk",
+
+		NodeTokenMaxSkew: 5 * time.Minute,
 	}
 	for _, o := range opt {
 		o(opts)
@@ -154,6 +182,24 @@ func WithInitNodes(initNodes map[uint64]string) Option {
 	}
 
 }
+func WithInitNodeTopologies(topologies map[uint64]pb.Topology) Option {
+	return func(o *Options) {
+		o.InitNodeTopologies = topologies
+	}
+}
+
+func WithZone(zone string) Option {
+	return func(o *Options) {
+		o.Zone = zone
+	}
+}
+
+func WithRack(rack string) Option {
+	return func(o *Options) {
+		o.Rack = rack
+	}
+}
+
 func WithSlotCount(slotCount uint32) Option {
 	return func(o *Options) {
 		o.SlotCount = slotCount
@@ -379,6 +425,18 @@ func WithChannelLoadPoolSize(size int) Option {
 	}
 }
 
+func WithReadIndexWaitTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.ReadIndexWaitTimeout = timeout
+	}
+}
+
+func WithDrainTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.DrainTimeout = timeout
+	}
+}
+
 func WithLeaderTransferMinLogGap(gap uint64) Option {
 	return func(o *Options) {
 		o.LeaderTransferMinLogGap = gap
@@ -426,3 +484,43 @@ func WithLokiJob(job string) Option {
 		o.LokiJob = job
 	}
 }
+
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(o *Options) {
+		o.TLSConfig = tlsConfig
+	}
+}
+
+func WithClientTLSConfig(tlsConfig *stdtls.Config) Option {
+	return func(o *Options) {
+		o.ClientTLSConfig = tlsConfig
+	}
+}
+
+func WithNodeSecret(secret string) Option {
+	return func(o *Options) {
+		o.NodeSecret = secret
+	}
+}
+
+func WithNodeTokenMaxSkew(skew time.Duration) Option {
+	return func(o *Options) {
+		o.NodeTokenMaxSkew = skew
+	}
+}
+
+func WithAllowUnknownNodes(allow bool) Option {
+	return func(o *Options) {
+		o.AllowUnknownNodes = allow
+	}
+}
+
+// 是否开启了节点认证
+func (o *Options) nodeAuthOn() bool {
+	return strings.TrimSpace(o.NodeSecret) != "" || o.mTLSOn()
+}
+
+// 是否开启了mTLS
+func (o *Options) mTLSOn() bool {
+	return o.TLSConfig != nil && o.TLSConfig.ClientAuth == tls.RequireAndVerifyClientCert
+}
@@ -38,6 +38,7 @@ type Server struct {
 	nodeManager        *nodeManager         // 节点管理者
 	slotManager        *slotManager         // 槽管理者
 	channelManager     *channelManager      // 频道管理者
+	drainer            *nodeDrainer         // 节点排空
 
 	channelKeyLock         *keylock.KeyLock        // 频道锁
 	netServer              *wkserver.Server        // 节点之间通讯的网络服务
@@ -76,6 +77,7 @@ func New(opts *Options) *Server {
 
 	s.slotManager = newSlotManager(s)
 	s.channelManager = newChannelManager(s)
+	s.drainer = newNodeDrainer(s)
 
 	if opts.SlotLogStorage == nil {
 		s.slotStorage = NewPebbleShardLogStorage(path.Join(opts.DataDir, "logdb"), uint32(opts.SlotDbShardNum))
@@ -120,11 +122,15 @@ func New(opts *Options) *Server {
 		clusterevent.WithSend(s.onSend),
 		clusterevent.WithConfigDir(cfgDir),
 		clusterevent.WithApiServerAddr(opts.ApiServerAddr),
+		clusterevent.WithZone(opts.Zone),
+		clusterevent.WithRack(opts.Rack),
+		clusterevent.WithInitNodeTopologies(opts.InitNodeTopologies),
 		clusterevent.WithCluster(s),
 		clusterevent.WithElectionIntervalTick(opts.ElectionIntervalTick),
 		clusterevent.WithHeartbeatIntervalTick(opts.HeartbeatIntervalTick),
 		clusterevent.WithTickInterval(opts.TickInterval),
 		clusterevent.WithPongMaxTick(opts.PongMaxTick),
+		clusterevent.WithNodeProtoVersion(s.nodeProtoVersion),
 	))
 
 	channelElectionPool, err := ants.NewPool(s.opts.ChannelElectionPoolSize, ants.WithNonblocking(false), ants.WithDisablePurge(true), ants.WithPanicHandler(func(err interface{}) {
@@ -142,15 +148,23 @@ func New(opts *Options) *Server {
 		s.Panic("new channelLoadPool failed", zap.Error(err))
 	}
 
-	s.netServer = wkserver.New(
-		opts.Addr, wkserver.WithMessagePoolOn(false),
+	netServerOpts := []wkserver.Option{
+		wkserver.WithMessagePoolOn(false),
+		wkserver.WithProtoVersion(uint16(pb.ProtoVersion)),
 		wkserver.WithOnRequest(func(conn wknet.Conn, req *proto.Request) {
 			trace.GlobalTrace.Metrics.System().IntranetIncomingAdd(int64(len(req.Body)))
 		}),
 		wkserver.WithOnResponse(func(conn wknet.Conn, resp *proto.Response) {
 			trace.GlobalTrace.Metrics.System().IntranetOutgoingAdd(int64(len(resp.Body)))
 		}),
-	)
+	}
+	if opts.TLSConfig != nil {
+		netServerOpts = append(netServerOpts, wkserver.WithTLSConfig(opts.TLSConfig))
+	}
+	if opts.nodeAuthOn() {
+		netServerOpts = append(netServerOpts, wkserver.WithConnectAuth(s.authNode))
+	}
+	s.netServer = wkserver.New(opts.Addr, netServerOpts...)
 	s.channelElectionManager = newChannelElectionManager(s)
 	s.cancelCtx, s.cancelFnc = context.WithCancel(context.Background())
 	return s
@@ -282,6 +296,72 @@ func (s *Server) MigrateSlot(slotId uint32, fromNodeId, toNodeId uint64) error {
 	return s.clusterEventServer.ProposeMigrateSlot(slotId, fromNodeId, toNodeId)
 }
 
+// FeatureEnabled 集群特性是否已开启（所有节点都支持后才会开启），节点之间rpc格式升级前应判断对应特性
+func (s *Server) FeatureEnabled(feature string) bool {
+	return s.clusterEventServer.FeatureEnabled(feature)
+}
+
+// ProposeApiKeySave 保存业务api访问密钥（新增、修改或轮换）
+func (s *Server) ProposeApiKeySave(apiKey *pb.ApiKey) error {
+	if !s.clusterEventServer.FeatureEnabled(pb.FeatureApiKey) {
+		return ErrFeatureNotEnabled
+	}
+	return s.clusterEventServer.ProposeApiKeySave(apiKey)
+}
+
+// ProposeApiKeyRemove 吊销业务api访问密钥
+func (s *Server) ProposeApiKeyRemove(id string) error {
+	return s.clusterEventServer.ProposeApiKeyRemove(id)
+}
+
+// ProposeManagerUserSave 保存管理后台用户
+func (s *Server) ProposeManagerUserSave(user *pb.ManagerUser) error {
+	if !s.clusterEventServer.FeatureEnabled(pb.FeatureManagerRbac) {
+		return ErrFeatureNotEnabled
+	}
+	return s.clusterEventServer.ProposeManagerUserSave(user)
+}
+
+// ProposeManagerUserRemove 删除管理后台用户
+func (s *Server) ProposeManagerUserRemove(username string) error {
+	return s.clusterEventServer.ProposeManagerUserRemove(username)
+}
+
+// ProposeManagerRoleSave 保存管理后台角色
+func (s *Server) ProposeManagerRoleSave(role *pb.ManagerRole) error {
+	if !s.clusterEventServer.FeatureEnabled(pb.FeatureManagerRbac) {
+		return ErrFeatureNotEnabled
+	}
+	return s.clusterEventServer.ProposeManagerRoleSave(role)
+}
+
+// ProposeManagerRoleRemove 删除管理后台角色
+func (s *Server) ProposeManagerRoleRemove(name string) error {
+	return s.clusterEventServer.ProposeManagerRoleRemove(name)
+}
+
+// ProposeBotSave 保存服务端机器人（新增或修改）
+func (s *Server) ProposeBotSave(bot *pb.Bot) error {
+	if !s.clusterEventServer.FeatureEnabled(pb.FeatureBot) {
+		return ErrFeatureNotEnabled
+	}
+	return s.clusterEventServer.ProposeBotSave(bot)
+}
+
+// ProposeBotRemove 删除服务端机器人
+func (s *Server) ProposeBotRemove(uid string) error {
+	return s.clusterEventServer.ProposeBotRemove(uid)
+}
+
+// nodeProtoVersion 节点在握手时返回的协议版本
+func (s *Server) nodeProtoVersion(nodeId uint64) (uint32, bool) {
+	n := s.nodeManager.node(nodeId)
+	if n == nil {
+		return 0, false
+	}
+	return n.protoVersion()
+}
+
 func (s *Server) AddSlotMessage(m reactor.Message) {
 
 	// 统计引入的消息
@@ -524,6 +604,8 @@ func (s *Server) joinLoop() {
 		NodeId:     s.opts.NodeId,
 		ServerAddr: s.opts.ServerAddr,
 		Role:       s.opts.Role,
+		Zone:       s.opts.Zone,
+		Rack:       s.opts.Rack,
 	}
 	for {
 		select {
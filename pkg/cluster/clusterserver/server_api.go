@@ -15,14 +15,18 @@ func (s *Server) ServerAPI(route *wkhttp.WKHttp, prefix string) {
 	route.GET(s.formatPath("/node"), s.nodeGet)                       // 获取当前节点信息
 	route.GET(s.formatPath("/simpleNodes"), s.simpleNodesGet)         // 获取简单节点信息
 	route.GET(s.formatPath("/nodes/:id/channels"), s.nodeChannelsGet) // 获取节点的所有频道信息
+	route.POST(s.formatPath("/nodes/:id/drain"), s.nodeDrain)         // 排空节点（转移节点上的所有领导）
+	route.GET(s.formatPath("/nodes/:id/drain"), s.nodeDrainGet)       // 获取节点排空状态
+	route.DELETE(s.formatPath("/nodes/:id/drain"), s.nodeUndrain)     // 取消节点排空
 
 	// ================== slot ==================
 	// route.GET(s.formatPath("/channels/:channel_id/:channel_type/config"), s.channelClusterConfigGet) // 获取频道分布式配置
-	route.GET(s.formatPath("/slots"), s.slotsGet)                        // 获取指定的槽信息
-	route.GET(s.formatPath("/allslot"), s.allSlotsGet)                   // 获取所有槽信息
-	route.GET(s.formatPath("/slots/:id/config"), s.slotClusterConfigGet) // 槽分布式配置
-	route.GET(s.formatPath("/slots/:id/channels"), s.slotChannelsGet)    // 获取某个槽的所有频道信息
-	route.POST(s.formatPath("/slots/:id/migrate"), s.slotMigrate)        // 迁移槽
+	route.GET(s.formatPath("/slots"), s.slotsGet)                                // 获取指定的槽信息
+	route.GET(s.formatPath("/allslot"), s.allSlotsGet)                           // 获取所有槽信息
+	route.GET(s.formatPath("/slots/:id/config"), s.slotClusterConfigGet)         // 槽分布式配置
+	route.GET(s.formatPath("/slots/:id/channels"), s.slotChannelsGet)            // 获取某个槽的所有频道信息
+	route.POST(s.formatPath("/slots/:id/migrate"), s.slotMigrate)                // 迁移槽
+	route.POST(s.formatPath("/slots/:id/transfer_leader"), s.slotTransferLeader) // 转移槽领导
 
 	// ================== message ==================
 	route.GET(s.formatPath("/messages"), s.messageSearch) // 搜索消息
@@ -42,17 +46,20 @@ func (s *Server) ServerAPI(route *wkhttp.WKHttp, prefix string) {
 
 	// ================== cluster ==================
 
-	route.GET(s.formatPath("/info"), s.clusterInfoGet) // 获取集群信息
-	route.GET(s.formatPath("/logs"), s.clusterLogs)    // 获取节点日志
+	route.GET(s.formatPath("/info"), s.clusterInfoGet)                         // 获取集群信息
+	route.GET(s.formatPath("/logs"), s.clusterLogs)                            // 获取节点日志
+	route.GET(s.formatPath("/placement/violations"), s.placementViolationsGet) // 获取副本放置违规信息
+	route.GET(s.formatPath("/versions"), s.clusterVersionsGet)                 // 获取集群各节点的协议版本和特性开启状态
 
 	// ================== cluster channel ==================
-	route.POST(s.formatPath("/channels/:channel_id/:channel_type/migrate"), s.channelMigrate)          // 迁移频道
-	route.GET(s.formatPath("/channels/:channel_id/:channel_type/config"), s.channelClusterConfig)      // 获取频道的分布式配置
-	route.POST(s.formatPath("/channels/:channel_id/:channel_type/start"), s.channelStart)              // 开始频道
-	route.POST(s.formatPath("/channels/:channel_id/:channel_type/stop"), s.channelStop)                // 停止频道
-	route.POST(s.formatPath("/channel/status"), s.channelStatus)                                       // 获取频道状态
-	route.GET(s.formatPath("/channels/:channel_id/:channel_type/replicas"), s.channelReplicas)         // 获取频道副本信息
-	route.GET(s.formatPath("/channels/:channel_id/:channel_type/localReplica"), s.channelLocalReplica) // 获取频道在本节点的副本信息
+	route.POST(s.formatPath("/channels/:channel_id/:channel_type/migrate"), s.channelMigrate)                // 迁移频道
+	route.POST(s.formatPath("/channels/:channel_id/:channel_type/transfer_leader"), s.channelTransferLeader) // 转移频道领导
+	route.GET(s.formatPath("/channels/:channel_id/:channel_type/config"), s.channelClusterConfig)            // 获取频道的分布式配置
+	route.POST(s.formatPath("/channels/:channel_id/:channel_type/start"), s.channelStart)                    // 开始频道
+	route.POST(s.formatPath("/channels/:channel_id/:channel_type/stop"), s.channelStop)                      // 停止频道
+	route.POST(s.formatPath("/channel/status"), s.channelStatus)                                             // 获取频道状态
+	route.GET(s.formatPath("/channels/:channel_id/:channel_type/replicas"), s.channelReplicas)               // 获取频道副本信息
+	route.GET(s.formatPath("/channels/:channel_id/:channel_type/localReplica"), s.channelLocalReplica)       // 获取频道在本节点的副本信息
 
 	// ================== logs ==================
 	route.GET(s.formatPath("/message/trace"), s.messageTrace)                // 获取消息轨迹
@@ -0,0 +1,62 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wknet"
+	"github.com/WuKongIM/WuKongIM/pkg/wkserver/proto"
+	"github.com/WuKongIM/crypto/tls"
+)
+
+var (
+	errNodeUnknown      = errors.New("unknown node")
+	errNodeCertMismatch = errors.New("node certificate does not match node id")
+	errNodeCertMissing  = errors.New("node certificate missing")
+)
+
+// authNode 校验连接到本节点的其他节点身份
+func (s *Server) authNode(conn wknet.Conn, req *proto.Connect) error {
+	nodeId := s.uidToServerId(req.Uid)
+	if nodeId == 0 {
+		return fmt.Errorf("invalid node uid: %s", req.Uid)
+	}
+
+	// 共享密钥校验
+	if strings.TrimSpace(s.opts.NodeSecret) != "" {
+		err := proto.VerifyConnectToken(s.opts.NodeSecret, req.Uid, req.Token, s.opts.NodeTokenMaxSkew)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 证书校验，证书的CommonName必须为节点id
+	if s.opts.mTLSOn() {
+		tlsConn, ok := conn.(interface{ ConnectionState() tls.ConnectionState })
+		if !ok {
+			return errNodeCertMissing
+		}
+		state := tlsConn.ConnectionState()
+		if len(state.PeerCertificates) == 0 {
+			return errNodeCertMissing
+		}
+		if state.PeerCertificates[0].Subject.CommonName != req.Uid {
+			return errNodeCertMismatch
+		}
+	}
+
+	if !s.isKnownNode(nodeId) && !s.opts.AllowUnknownNodes {
+		return errNodeUnknown
+	}
+	return nil
+}
+
+// 是否是已知节点（集群配置内的节点或初始节点）
+func (s *Server) isKnownNode(nodeId uint64) bool {
+	if s.clusterEventServer.Node(nodeId) != nil {
+		return true
+	}
+	_, ok := s.opts.InitNodes[nodeId]
+	return ok
+}
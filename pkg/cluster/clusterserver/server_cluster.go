@@ -2,6 +2,7 @@ package cluster
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
 	"math/rand"
 	"time"
@@ -67,6 +68,80 @@ func (s *Server) LeaderOfChannelForRead(channelId string, channelType uint8) (*p
 	return node, nil
 }
 
+// ReplicaOfChannelForRead 获取可读取频道数据的节点（跟随者读）
+// 如果当前节点是频道的副本并且已应用的日志下标不小于minIndex，则返回当前节点，否则返回频道领导节点
+// strict为true时会先向频道领导获取读索引，当前节点需要应用到读索引才能读取
+func (s *Server) ReplicaOfChannelForRead(ctx context.Context, channelId string, channelType uint8, minIndex uint64, strict bool) (*pb.Node, error) {
+	cfg, err := s.loadOnlyChannelClusterConfig(channelId, channelType)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.LeaderId == 0 {
+		return nil, ErrNotLeader
+	}
+	leaderNode := s.clusterEventServer.Node(cfg.LeaderId)
+	if leaderNode == nil {
+		return nil, ErrNodeNotExist
+	}
+
+	// 当前节点是领导或者不是频道副本，直接从领导读取
+	if cfg.LeaderId == s.opts.NodeId || !wkutil.ArrayContainsUint64(cfg.Replicas, s.opts.NodeId) {
+		return leaderNode, nil
+	}
+	localNode := s.clusterEventServer.Node(s.opts.NodeId)
+	if localNode == nil {
+		return leaderNode, nil
+	}
+
+	if strict {
+		readIndex, err := s.requestChannelReadIndex(ctx, cfg.LeaderId, channelId, channelType)
+		if err != nil {
+			s.Warn("ReplicaOfChannelForRead: requestChannelReadIndex failed, read from leader", zap.Error(err), zap.String("channelId", channelId), zap.Uint8("channelType", channelType))
+			return leaderNode, nil
+		}
+		if readIndex > minIndex {
+			minIndex = readIndex
+		}
+	}
+
+	shardNo := wkutil.ChannelToKey(channelId, channelType)
+	appliedIndex, err := s.opts.MessageLogStorage.AppliedIndex(shardNo)
+	if err != nil {
+		return nil, err
+	}
+	if appliedIndex >= minIndex {
+		return localNode, nil
+	}
+	if !strict {
+		return leaderNode, nil
+	}
+
+	// 严格一致性读，等待本地副本应用到读索引
+	timeoutCtx, cancel := context.WithTimeout(ctx, s.opts.ReadIndexWaitTimeout)
+	defer cancel()
+	tk := time.NewTicker(time.Millisecond * 10)
+	defer tk.Stop()
+	for {
+		select {
+		case <-tk.C:
+			appliedIndex, err = s.opts.MessageLogStorage.AppliedIndex(shardNo)
+			if err != nil {
+				return nil, err
+			}
+			if appliedIndex >= minIndex {
+				return localNode, nil
+			}
+		case <-timeoutCtx.Done():
+			return leaderNode, nil
+		}
+	}
+}
+
+// ChannelAppliedIndex 获取当前节点频道副本已应用的日志下标
+func (s *Server) ChannelAppliedIndex(channelId string, channelType uint8) (uint64, error) {
+	return s.opts.MessageLogStorage.AppliedIndex(wkutil.ChannelToKey(channelId, channelType))
+}
+
 func (s *Server) SlotLeaderIdOfChannel(channelId string, channelType uint8) (nodeID uint64, err error) {
 	slotId := s.getSlotId(channelId)
 	slot := s.clusterEventServer.Slot(slotId)
@@ -527,17 +602,8 @@ func (s *Server) createChannelClusterConfig(channelId string, channelType uint8)
 		newAllowVoteNodes[i], newAllowVoteNodes[j] = newAllowVoteNodes[j], newAllowVoteNodes[i]
 	})
 
-	for _, allowVoteNode := range newAllowVoteNodes {
-		if allowVoteNode.Id == s.opts.NodeId {
-			continue
-		}
-		if len(replicaIds) >= int(s.opts.ChannelMaxReplicaCount) {
-			break
-		}
-		replicaIds = append(replicaIds, allowVoteNode.Id)
-
-	}
-	clusterConfig.Replicas = replicaIds
+	// 按可用区/机架分散选择副本
+	clusterConfig.Replicas = pb.SpreadReplicas(newAllowVoteNodes, replicaIds, int(s.opts.ChannelMaxReplicaCount))
 	return clusterConfig, nil
 }
 
@@ -583,6 +649,77 @@ func (s *Server) createChannelClusterConfig(channelId string, channelType uint8)
 // 	return clusterCfg, updated, nil
 // }
 
+// proposeChannelMigrate 提案频道迁移，from为领导且to为副本时即为领导转移（等待to追上日志后切换领导）
+func (s *Server) proposeChannelMigrate(clusterConfig wkdb.ChannelClusterConfig, from, to uint64) error {
+	if clusterConfig.MigrateFrom != 0 || clusterConfig.MigrateTo != 0 {
+		return ErrMigrateInProgress
+	}
+	channelId, channelType := clusterConfig.ChannelId, clusterConfig.ChannelType
+
+	// 保存配置
+	newClusterConfig := clusterConfig.Clone()
+	newClusterConfig.MigrateFrom = from
+	newClusterConfig.MigrateTo = to
+	newClusterConfig.ConfVersion = uint64(time.Now().UnixNano())
+
+	if !wkutil.ArrayContainsUint64(clusterConfig.Replicas, to) {
+		// 将要目标节点加入学习者中
+		newClusterConfig.Learners = append(newClusterConfig.Learners, to)
+	}
+
+	// 提案保存配置
+	err := s.opts.ChannelClusterStorage.Propose(newClusterConfig)
+	if err != nil {
+		s.Error("proposeChannelMigrate: Save error", zap.Error(err))
+		return err
+	}
+
+	// 如果频道领导不是当前节点，则发送最新配置给频道领导 （这里就算发送失败也没问题，因为频道领导会间隔比对自己与槽领导的配置）
+	if newClusterConfig.LeaderId != s.opts.NodeId {
+		err = s.SendChannelClusterConfigUpdate(channelId, channelType, newClusterConfig.LeaderId)
+		if err != nil {
+			s.Error("proposeChannelMigrate: sendChannelClusterConfigUpdate error", zap.Error(err))
+			return err
+		}
+	} else {
+		s.UpdateChannelClusterConfig(newClusterConfig)
+	}
+
+	// 如果目标节点不是当前节点，则发送最新配置给目标节点
+	if to != s.opts.NodeId {
+		err = s.SendChannelClusterConfigUpdate(channelId, channelType, to)
+		if err != nil {
+			s.Error("proposeChannelMigrate: sendChannelClusterConfigUpdate error", zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// leaderTransferTarget 从副本中选择领导转移的目标节点，优先选择槽领导数量少的节点，排除离线和排空中的节点
+func (s *Server) leaderTransferTarget(replicas []uint64, leaderId uint64) uint64 {
+	cfg := s.clusterEventServer.Config()
+	var (
+		target      uint64
+		targetCount int
+	)
+	for _, replicaId := range replicas {
+		if replicaId == leaderId {
+			continue
+		}
+		node := s.clusterEventServer.Node(replicaId)
+		if node == nil || !node.Online || node.Draining || node.Status != pb.NodeStatus_NodeStatusJoined {
+			continue
+		}
+		leaderCount := s.getNodeSlotLeaderCount(replicaId, cfg)
+		if target == 0 || leaderCount < targetCount {
+			target = replicaId
+			targetCount = leaderCount
+		}
+	}
+	return target
+}
+
 func (s *Server) getChannelClusterConfig(channelId string, channelType uint8) (wkdb.ChannelClusterConfig, error) {
 	return s.opts.ChannelClusterStorage.Get(channelId, channelType)
 }
@@ -720,6 +857,31 @@ func (s *Server) loadOnlyChannelClusterConfig(channelId string, channelType uint
 	return clusterConfig, nil
 }
 
+// 向频道领导请求频道的读索引
+func (s *Server) requestChannelReadIndex(ctx context.Context, leaderId uint64, channelId string, channelType uint8) (uint64, error) {
+	req := &ChannelClusterConfigReq{
+		ChannelId:   channelId,
+		ChannelType: channelType,
+	}
+	data, err := req.Marshal()
+	if err != nil {
+		return 0, err
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, s.opts.ReqTimeout)
+	defer cancel()
+	resp, err := s.RequestWithContext(timeoutCtx, leaderId, "/channel/readIndex", data)
+	if err != nil {
+		return 0, err
+	}
+	if resp.Status != proto.StatusOK {
+		return 0, fmt.Errorf("requestChannelReadIndex failed, status:%d", resp.Status)
+	}
+	if len(resp.Body) < 8 {
+		return 0, fmt.Errorf("requestChannelReadIndex failed, invalid body length:%d", len(resp.Body))
+	}
+	return binary.BigEndian.Uint64(resp.Body), nil
+}
+
 func (s *Server) requestChannelProposeMessage(to uint64, channelId string, channelType uint8, logs []replica.Log) (*ChannelProposeResp, error) {
 	node := s.nodeManager.node(to)
 	if node == nil {
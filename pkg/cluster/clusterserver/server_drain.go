@@ -0,0 +1,218 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/clusterconfig/pb"
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/reactor"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"go.uber.org/zap"
+)
+
+type DrainStatus string
+
+const (
+	DrainStatusNone     DrainStatus = "none"     // 未排空
+	DrainStatusDraining DrainStatus = "draining" // 排空中
+	DrainStatusDone     DrainStatus = "done"     // 排空完成
+	DrainStatusTimeout  DrainStatus = "timeout"  // 排空超时
+	DrainStatusCanceled DrainStatus = "canceled" // 排空已取消
+)
+
+// nodeDrainer 将本节点的槽领导和活跃频道领导全部转移到其他副本，用于滚动重启前避免选举超时
+type nodeDrainer struct {
+	s *Server
+	wklog.Log
+
+	mu                 sync.Mutex
+	status             DrainStatus
+	startedAt          time.Time
+	finishedAt         time.Time
+	slotLeaderCount    int // 剩余的槽领导数量
+	channelLeaderCount int // 剩余的活跃频道领导数量
+	cancel             context.CancelFunc
+}
+
+func newNodeDrainer(s *Server) *nodeDrainer {
+	return &nodeDrainer{
+		s:      s,
+		status: DrainStatusNone,
+		Log:    wklog.NewWKLog("nodeDrainer"),
+	}
+}
+
+// start 开始排空，已经在排空中则忽略
+func (d *nodeDrainer) start() error {
+	d.mu.Lock()
+	if d.status == DrainStatusDraining {
+		d.mu.Unlock()
+		return nil
+	}
+	d.mu.Unlock()
+
+	// 集群中还有不支持排空命令的旧版本节点
+	if !d.s.clusterEventServer.FeatureEnabled(pb.FeatureNodeDraining) {
+		return ErrFeatureNotEnabled
+	}
+
+	// 标记节点为排空中，防止槽领导被自动均衡迁回
+	err := d.s.clusterEventServer.ProposeNodeDraining(d.s.opts.NodeId, true)
+	if err != nil {
+		d.Error("propose node draining failed", zap.Error(err))
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(d.s.cancelCtx, d.s.opts.DrainTimeout)
+	d.mu.Lock()
+	d.status = DrainStatusDraining
+	d.startedAt = time.Now()
+	d.finishedAt = time.Time{}
+	d.cancel = cancel
+	d.mu.Unlock()
+
+	go d.run(ctx)
+	return nil
+}
+
+// stop 取消排空，节点重新参与领导均衡
+func (d *nodeDrainer) stop() error {
+	d.mu.Lock()
+	if d.cancel != nil {
+		d.cancel()
+		d.cancel = nil
+	}
+	d.status = DrainStatusNone
+	d.mu.Unlock()
+
+	err := d.s.clusterEventServer.ProposeNodeDraining(d.s.opts.NodeId, false)
+	if err != nil {
+		d.Error("propose node undraining failed", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (d *nodeDrainer) run(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		slotLeaderCount := d.transferSlotLeaders()
+		channelLeaderCount := d.transferChannelLeaders()
+
+		d.mu.Lock()
+		d.slotLeaderCount = slotLeaderCount
+		d.channelLeaderCount = channelLeaderCount
+		if slotLeaderCount == 0 && channelLeaderCount == 0 {
+			d.finish(DrainStatusDone)
+			d.mu.Unlock()
+			d.Info("node drain done", zap.Duration("cost", time.Since(d.startedAt)))
+			return
+		}
+		d.mu.Unlock()
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			d.mu.Lock()
+			if ctx.Err() == context.DeadlineExceeded {
+				d.finish(DrainStatusTimeout)
+				d.Warn("node drain timeout", zap.Int("slotLeaderCount", slotLeaderCount), zap.Int("channelLeaderCount", channelLeaderCount))
+			} else if d.status == DrainStatusDraining { // 服务停止
+				d.finish(DrainStatusCanceled)
+			}
+			d.mu.Unlock()
+			return
+		}
+	}
+}
+
+// finish 需要在加锁的情况下调用
+func (d *nodeDrainer) finish(status DrainStatus) {
+	d.status = status
+	d.finishedAt = time.Now()
+	if d.cancel != nil {
+		d.cancel()
+		d.cancel = nil
+	}
+}
+
+// transferSlotLeaders 转移本节点的槽领导，返回本节点剩余的槽领导数量
+func (d *nodeDrainer) transferSlotLeaders() int {
+	nodeId := d.s.opts.NodeId
+	remaining := 0
+	for _, slot := range d.s.clusterEventServer.Slots() {
+		if slot.Leader != nodeId {
+			continue
+		}
+		remaining++
+		if slot.MigrateFrom != 0 || slot.MigrateTo != 0 { // 迁移中，等待新领导追上日志
+			continue
+		}
+		to := d.s.leaderTransferTarget(slot.Replicas, nodeId)
+		if to == 0 {
+			d.Warn("no leader transfer target for slot", zap.Uint32("slotId", slot.Id))
+			continue
+		}
+		err := d.s.clusterEventServer.ProposeMigrateSlot(slot.Id, nodeId, to)
+		if err != nil {
+			d.Error("transfer slot leader failed", zap.Error(err), zap.Uint32("slotId", slot.Id), zap.Uint64("to", to))
+		}
+	}
+	return remaining
+}
+
+// transferChannelLeaders 转移本节点的活跃频道领导，返回本节点剩余的活跃频道领导数量
+func (d *nodeDrainer) transferChannelLeaders() int {
+	channels := make([]*channel, 0)
+	d.s.channelManager.channelReactor.IteratorHandler(func(h reactor.IHandler) bool {
+		ch := h.(*channel)
+		if ch.isLeader() {
+			channels = append(channels, ch)
+		}
+		return true
+	})
+
+	nodeId := d.s.opts.NodeId
+	for _, ch := range channels {
+		cfg, err := d.s.loadOnlyChannelClusterConfig(ch.channelId, ch.channelType)
+		if err != nil {
+			d.Error("load channel cluster config failed", zap.Error(err), zap.String("channelId", ch.channelId), zap.Uint8("channelType", ch.channelType))
+			continue
+		}
+		if cfg.MigrateFrom != 0 || cfg.MigrateTo != 0 { // 迁移中，等待新领导追上日志
+			continue
+		}
+		to := d.s.leaderTransferTarget(cfg.Replicas, nodeId)
+		if to == 0 {
+			d.Warn("no leader transfer target for channel", zap.String("channelId", ch.channelId), zap.Uint8("channelType", ch.channelType))
+			continue
+		}
+		err = d.s.proposeChannelMigrate(cfg, nodeId, to)
+		if err != nil {
+			d.Error("transfer channel leader failed", zap.Error(err), zap.String("channelId", ch.channelId), zap.Uint8("channelType", ch.channelType), zap.Uint64("to", to))
+		}
+	}
+	return len(channels)
+}
+
+func (d *nodeDrainer) resp() *NodeDrainResp {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	resp := &NodeDrainResp{
+		NodeId:             d.s.opts.NodeId,
+		Draining:           d.s.clusterEventServer.NodeDraining(d.s.opts.NodeId),
+		Status:             d.status,
+		SlotLeaderCount:    d.slotLeaderCount,
+		ChannelLeaderCount: d.channelLeaderCount,
+	}
+	if !d.startedAt.IsZero() {
+		resp.StartedAt = d.startedAt.Unix()
+	}
+	if !d.finishedAt.IsZero() {
+		resp.FinishedAt = d.finishedAt.Unix()
+	}
+	return resp
+}
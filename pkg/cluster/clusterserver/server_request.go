@@ -34,6 +34,9 @@ func (s *Server) setRoutes() {
 
 	// 获取槽日志信息
 	s.netServer.Route("/slot/logInfo", s.handleSlotLogInfo)
+
+	// 获取频道领导的读索引（跟随者严格一致性读）
+	s.netServer.Route("/channel/readIndex", s.handleChannelReadIndex)
 }
 
 func (s *Server) handleChannelLastLogInfo(c *wkserver.Context) {
@@ -242,6 +245,14 @@ func (s *Server) handleClusterJoin(c *wkserver.Context) {
 		return
 	}
 
+	// 已开启的集群特性要求的协议版本高于加入节点的版本，则不允许加入（请求被转发时这里校验的是转发节点的版本）
+	minProtoVersion := s.clusterEventServer.Config().MinProtoVersion()
+	if uint32(c.ConnVersion()) < minProtoVersion {
+		s.Warn("join node proto version too low", zap.Uint64("nodeId", req.NodeId), zap.Uint16("version", c.ConnVersion()), zap.Uint32("minVersion", minProtoVersion))
+		c.WriteErr(ErrProtoVersionTooLow)
+		return
+	}
+
 	if !s.clusterEventServer.IsLeader() {
 		resp, err := s.nodeManager.requestClusterJoin(s.clusterEventServer.LeaderId(), req)
 		if err != nil {
@@ -284,6 +295,8 @@ func (s *Server) handleClusterJoin(c *wkserver.Context) {
 		AllowVote:   allowVote,
 		CreatedAt:   time.Now().Unix(),
 		Status:      pb.NodeStatus_NodeStatusWillJoin,
+		Zone:        req.Zone,
+		Rack:        req.Rack,
 	})
 	if err != nil {
 		s.Error("proposeJoin failed", zap.Error(err))
@@ -415,3 +428,35 @@ func (s *Server) handleSlotLogInfo(c *wkserver.Context) {
 	}
 	c.Write(data)
 }
+
+func (s *Server) handleChannelReadIndex(c *wkserver.Context) {
+	req := &ChannelClusterConfigReq{}
+	err := req.Unmarshal(c.Body())
+	if err != nil {
+		s.Error("unmarshal ChannelClusterConfigReq failed", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+
+	cfg, err := s.loadOnlyChannelClusterConfig(req.ChannelId, req.ChannelType)
+	if err != nil {
+		s.Error("handleChannelReadIndex: loadOnlyChannelClusterConfig failed", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	if cfg.LeaderId != s.opts.NodeId {
+		s.Error("not leader,handleChannelReadIndex failed", zap.Uint64("leader", cfg.LeaderId), zap.String("channelId", req.ChannelId), zap.Uint8("channelType", req.ChannelType))
+		c.WriteErr(ErrNotIsLeader)
+		return
+	}
+
+	appliedIndex, err := s.opts.MessageLogStorage.AppliedIndex(wkutil.ChannelToKey(req.ChannelId, req.ChannelType))
+	if err != nil {
+		s.Error("handleChannelReadIndex: get applied index failed", zap.Error(err))
+		c.WriteErr(err)
+		return
+	}
+	resultBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(resultBytes, appliedIndex)
+	c.Write(resultBytes)
+}
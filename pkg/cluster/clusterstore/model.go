@@ -89,6 +89,14 @@ const (
 	CMDAddOrUpdateTester
 	// 移除测试机
 	CMDRemoveTester
+	// 保存端到端加密的公钥包
+	CMDSaveE2EEKeyBundle
+	// 移除一次性预共享公钥
+	CMDRemoveE2EEOneTimePrekey
+	// 删除端到端加密的公钥包
+	CMDDeleteE2EEKeyBundle
+	// 保存事件流的投递位置
+	CMDSetEventSinkCheckpoint
 )
 
 func (c CMDType) Uint16() uint16 {
@@ -171,6 +179,14 @@ func (c CMDType) String() string {
 		return "CMDAddOrUpdateTester"
 	case CMDRemoveTester:
 		return "CMDRemoveTester"
+	case CMDSaveE2EEKeyBundle:
+		return "CMDSaveE2EEKeyBundle"
+	case CMDRemoveE2EEOneTimePrekey:
+		return "CMDRemoveE2EEOneTimePrekey"
+	case CMDDeleteE2EEKeyBundle:
+		return "CMDDeleteE2EEKeyBundle"
+	case CMDSetEventSinkCheckpoint:
+		return "CMDSetEventSinkCheckpoint"
 	default:
 		return fmt.Sprintf("CMDUnknown[%d]", c)
 	}
@@ -1289,6 +1305,65 @@ func (c *CMD) DecodeCMDRemoveTester() (no string, err error) {
 	return
 }
 
+func EncodeCMDSaveE2EEKeyBundle(bundle wkdb.E2EEKeyBundle) ([]byte, error) {
+	return bundle.Marshal()
+}
+
+func (c *CMD) DecodeCMDSaveE2EEKeyBundle() (bundle wkdb.E2EEKeyBundle, err error) {
+	err = bundle.Unmarshal(c.Data)
+	return
+}
+
+func EncodeCMDE2EEPrekey(uid string, deviceId string, prekeyId uint32) []byte {
+	encoder := wkproto.NewEncoder()
+	defer encoder.End()
+	encoder.WriteString(uid)
+	encoder.WriteString(deviceId)
+	encoder.WriteUint32(prekeyId)
+	return encoder.Bytes()
+}
+
+func (c *CMD) DecodeCMDE2EEPrekey() (uid string, deviceId string, prekeyId uint32, err error) {
+	decoder := wkproto.NewDecoder(c.Data)
+	if uid, err = decoder.String(); err != nil {
+		return
+	}
+	if deviceId, err = decoder.String(); err != nil {
+		return
+	}
+	if prekeyId, err = decoder.Uint32(); err != nil {
+		return
+	}
+	return
+}
+
+func EncodeCMDEventSinkCheckpoint(sink string, channelId string, channelType uint8, messageSeq uint64) []byte {
+	encoder := wkproto.NewEncoder()
+	defer encoder.End()
+	encoder.WriteString(sink)
+	encoder.WriteString(channelId)
+	encoder.WriteUint8(channelType)
+	encoder.WriteUint64(messageSeq)
+	return encoder.Bytes()
+}
+
+func (c *CMD) DecodeCMDEventSinkCheckpoint() (sink string, channelId string, channelType uint8, messageSeq uint64, err error) {
+	decoder := wkproto.NewDecoder(c.Data)
+	if sink, err = decoder.String(); err != nil {
+		return
+	}
+	if channelId, err = decoder.String(); err != nil {
+		return
+	}
+	if channelType, err = decoder.Uint8(); err != nil {
+		return
+	}
+	if messageSeq, err = decoder.Uint64(); err != nil {
+		return
+	}
+	return
+}
+
 var ErrStoreStopped = fmt.Errorf("store stopped")
 
 type applyReq struct {
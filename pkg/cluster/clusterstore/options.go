@@ -1,7 +1,10 @@
 package clusterstore
 
 import (
+	"time"
+
 	"github.com/WuKongIM/WuKongIM/pkg/cluster/icluster"
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
 )
 
 type Options struct {
@@ -14,9 +17,13 @@ type Options struct {
 
 	IsCmdChannel func(string) bool // 是否是cmd频道
 
+	OnApplied func(cmd *CMD) // 元数据日志应用成功后的回调（每个副本都会回调），不能阻塞
+
 	Db struct {
-		ShardNum     int // 分片数量
-		MemTableSize int // MemTable大小
+		ShardNum                 int              // 分片数量
+		MemTableSize             int              // MemTable大小
+		EncryptionKeyProvider    wkdb.KeyProvider // 消息内容静态加密的主密钥提供者，为nil表示不加密
+		EncryptionRotateInterval time.Duration    // 数据密钥的自动轮换间隔，0表示不自动轮换
 	}
 }
 
@@ -33,8 +40,10 @@ func newOptions() *Options {
 	return &Options{
 		SlotCount: 64,
 		Db: struct {
-			ShardNum     int
-			MemTableSize int
+			ShardNum                 int
+			MemTableSize             int
+			EncryptionKeyProvider    wkdb.KeyProvider
+			EncryptionRotateInterval time.Duration
 		}{
 			ShardNum:     8,
 			MemTableSize: 16 * 1024 * 1024,
@@ -68,6 +77,12 @@ func WithIsCmdChannel(f func(string) bool) Option {
 	}
 }
 
+func WithOnApplied(f func(cmd *CMD)) Option {
+	return func(o *Options) {
+		o.OnApplied = f
+	}
+}
+
 func WithGetSlotId(f func(uid string) uint32) Option {
 	return func(o *Options) {
 		o.GetSlotId = f
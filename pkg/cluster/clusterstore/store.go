@@ -51,6 +51,8 @@ func NewStore(opts *Options) *Store {
 			wkdb.WithNodeId(opts.NodeID),
 			wkdb.WithMemTableSize(opts.Db.MemTableSize),
 			wkdb.WithSlotCount(int(opts.SlotCount)),
+			wkdb.WithEncryptionKeyProvider(opts.Db.EncryptionKeyProvider),
+			wkdb.WithEncryptionRotateInterval(opts.Db.EncryptionRotateInterval),
 		),
 	)
 
@@ -111,6 +111,9 @@ func (s *Store) execCMDs(cmdType CMDType, cmds []*CMD) error {
 			if err != nil {
 				return err
 			}
+			if s.opts.OnApplied != nil {
+				s.opts.OnApplied(cmd)
+			}
 		}
 	}
 	return nil
@@ -174,6 +177,14 @@ func (s *Store) execCMD(cmd *CMD) error {
 		return s.handleAddOrUpdateTester(cmd)
 	case CMDRemoveTester: // 移除测试机
 		return s.handleRemoveTester(cmd)
+	case CMDSaveE2EEKeyBundle: // 保存端到端加密的公钥包
+		return s.handleSaveE2EEKeyBundle(cmd)
+	case CMDRemoveE2EEOneTimePrekey: // 移除一次性预共享公钥
+		return s.handleRemoveE2EEOneTimePrekey(cmd)
+	case CMDDeleteE2EEKeyBundle: // 删除端到端加密的公钥包
+		return s.handleDeleteE2EEKeyBundle(cmd)
+	case CMDSetEventSinkCheckpoint: // 保存事件流的投递位置
+		return s.handleSetEventSinkCheckpoint(cmd)
 
 	}
 	return nil
@@ -450,3 +461,35 @@ func (s *Store) handleRemoveTester(cmd *CMD) error {
 	}
 	return s.wdb.RemoveTester(no)
 }
+
+func (s *Store) handleSaveE2EEKeyBundle(cmd *CMD) error {
+	bundle, err := cmd.DecodeCMDSaveE2EEKeyBundle()
+	if err != nil {
+		return err
+	}
+	return s.wdb.SaveE2EEKeyBundle(bundle)
+}
+
+func (s *Store) handleRemoveE2EEOneTimePrekey(cmd *CMD) error {
+	uid, deviceId, prekeyId, err := cmd.DecodeCMDE2EEPrekey()
+	if err != nil {
+		return err
+	}
+	return s.wdb.RemoveE2EEOneTimePrekey(uid, deviceId, prekeyId)
+}
+
+func (s *Store) handleDeleteE2EEKeyBundle(cmd *CMD) error {
+	uid, deviceId, _, err := cmd.DecodeCMDE2EEPrekey()
+	if err != nil {
+		return err
+	}
+	return s.wdb.DeleteE2EEKeyBundle(uid, deviceId)
+}
+
+func (s *Store) handleSetEventSinkCheckpoint(cmd *CMD) error {
+	sink, channelId, channelType, messageSeq, err := cmd.DecodeCMDEventSinkCheckpoint()
+	if err != nil {
+		return err
+	}
+	return s.wdb.SetEventSinkCheckpoint(sink, channelId, channelType, messageSeq)
+}
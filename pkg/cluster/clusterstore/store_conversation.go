@@ -154,6 +154,10 @@ func (s *Store) GetLastConversations(uid string, tp wkdb.ConversationType, updat
 	return s.wdb.GetLastConversations(uid, tp, updatedAt, limit)
 }
 
+func (s *Store) GetConversationDelta(uid string, tp wkdb.ConversationType, version uint64, limit int) (wkdb.ConversationDelta, error) {
+	return s.wdb.GetConversationDelta(uid, tp, version, limit)
+}
+
 func (s *Store) GetChannelLastMessageSeq(channelId string, channelType uint8) (uint64, error) {
 	seq, _, err := s.wdb.GetChannelLastMessageSeq(channelId, channelType)
 	return seq, err
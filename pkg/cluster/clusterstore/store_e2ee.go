@@ -0,0 +1,47 @@
+package clusterstore
+
+import (
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"go.uber.org/zap"
+)
+
+// SaveE2EEKeyBundle 保存设备的端到端加密公钥包
+func (s *Store) SaveE2EEKeyBundle(bundle wkdb.E2EEKeyBundle) error {
+	data, err := EncodeCMDSaveE2EEKeyBundle(bundle)
+	if err != nil {
+		return err
+	}
+	return s.proposeE2EE(bundle.Uid, NewCMD(CMDSaveE2EEKeyBundle, data))
+}
+
+func (s *Store) GetE2EEKeyBundle(uid string, deviceId string) (wkdb.E2EEKeyBundle, error) {
+	return s.wdb.GetE2EEKeyBundle(uid, deviceId)
+}
+
+func (s *Store) GetE2EEKeyBundles(uid string) ([]wkdb.E2EEKeyBundle, error) {
+	return s.wdb.GetE2EEKeyBundles(uid)
+}
+
+// RemoveE2EEOneTimePrekey 移除设备的一次性预共享公钥
+func (s *Store) RemoveE2EEOneTimePrekey(uid string, deviceId string, prekeyId uint32) error {
+	data := EncodeCMDE2EEPrekey(uid, deviceId, prekeyId)
+	return s.proposeE2EE(uid, NewCMD(CMDRemoveE2EEOneTimePrekey, data))
+}
+
+// DeleteE2EEKeyBundle 删除设备的端到端加密公钥包
+func (s *Store) DeleteE2EEKeyBundle(uid string, deviceId string) error {
+	data := EncodeCMDE2EEPrekey(uid, deviceId, 0)
+	return s.proposeE2EE(uid, NewCMD(CMDDeleteE2EEKeyBundle, data))
+}
+
+// 公钥包和用户数据在同一个槽位
+func (s *Store) proposeE2EE(uid string, cmd *CMD) error {
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		s.Error("marshal cmd failed", zap.Error(err), zap.String("cmd", cmd.CmdType.String()))
+		return err
+	}
+	slotId := s.opts.GetSlotId(uid)
+	_, err = s.opts.Cluster.ProposeDataToSlot(slotId, cmdData)
+	return err
+}
@@ -0,0 +1,19 @@
+package clusterstore
+
+// SetEventSinkCheckpoint 保存事件流频道已投递到的消息序号
+// 投递位置和频道信息在同一个槽位，通过槽位同步到各个副本，频道领导切换后新的领导节点可以继续投递
+func (s *Store) SetEventSinkCheckpoint(sink string, channelId string, channelType uint8, messageSeq uint64) error {
+	cmd := NewCMD(CMDSetEventSinkCheckpoint, EncodeCMDEventSinkCheckpoint(sink, channelId, channelType, messageSeq))
+	cmdData, err := cmd.Marshal()
+	if err != nil {
+		return err
+	}
+	slotId := s.opts.GetSlotId(channelId)
+	_, err = s.opts.Cluster.ProposeDataToSlot(slotId, cmdData)
+	return err
+}
+
+// GetEventSinkCheckpoint 获取本节点保存的事件流频道已投递到的消息序号，本节点需要是频道所在槽位的副本
+func (s *Store) GetEventSinkCheckpoint(sink string, channelId string, channelType uint8) (uint64, error) {
+	return s.wdb.GetEventSinkCheckpoint(sink, channelId, channelType)
+}
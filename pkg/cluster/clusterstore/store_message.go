@@ -69,6 +69,10 @@ func (s *Store) GetMessagesOfNotifyQueue(count int) ([]wkdb.Message, error) {
 	return s.wdb.GetMessagesOfNotifyQueue(count)
 }
 
+func (s *Store) GetMessagesOfNotifyQueueWithSkip(count int, skip func(messageId int64) bool) ([]wkdb.Message, error) {
+	return s.wdb.GetMessagesOfNotifyQueueWithSkip(count, skip)
+}
+
 func (s *Store) AppendMessageOfNotifyQueue(messages []wkdb.Message) error {
 	return s.wdb.AppendMessageOfNotifyQueue(messages)
 }
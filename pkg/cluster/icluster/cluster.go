@@ -24,6 +24,11 @@ type Cluster interface {
 	LoadOrCreateChannel(ctx context.Context, channelId string, channelType uint8) (wkdb.ChannelClusterConfig, error)
 	// SlotLeaderIdOfChannel 获取channel的leader节点信息(不激活频道)
 	LeaderOfChannelForRead(channelId string, channelType uint8) (nodeInfo *pb.Node, err error)
+	// ReplicaOfChannelForRead 获取可读取频道数据的节点（跟随者读），当前节点副本已应用到minIndex则返回当前节点，否则返回领导节点
+	// strict为true时会先向领导获取读索引，保证读到领导已应用的数据
+	ReplicaOfChannelForRead(ctx context.Context, channelId string, channelType uint8, minIndex uint64, strict bool) (nodeInfo *pb.Node, err error)
+	// ChannelAppliedIndex 当前节点频道副本已应用的日志下标
+	ChannelAppliedIndex(channelId string, channelType uint8) (uint64, error)
 	// SlotLeaderIdOfChannel 获取频道所属槽的领导
 	SlotLeaderIdOfChannel(channelId string, channelType uint8) (nodeId uint64, err error)
 	// SlotLeaderOfChannel 获取频道所属槽的领导
@@ -56,6 +61,9 @@ type Cluster interface {
 	// 领导者Id
 	LeaderId() uint64
 
+	// FeatureEnabled 集群特性是否已开启，只有集群所有节点都支持后才会开启，用于滚动升级时协商节点之间的rpc格式
+	FeatureEnabled(feature string) bool
+
 	// 等待集群准备好
 	MustWaitClusterReady(timeout time.Duration)
 
@@ -40,6 +40,11 @@ type ISystemMetrics interface {
 	// ExtranetOutgoingAdd 外网出口流量
 	ExtranetOutgoingAdd(v int64)
 
+	// WSCompressAdd websocket消息压缩前后的字节数（压缩率 = compressed/raw）
+	WSCompressAdd(raw, compressed int64)
+	// WSDecompressAdd websocket消息解压前后的字节数
+	WSDecompressAdd(compressed, raw int64)
+
 	// CPUUsageAdd CPU使用率
 	CPUUsageAdd(v float64)
 	// MemoryUsageAdd 内存使用率
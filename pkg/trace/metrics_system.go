@@ -18,6 +18,11 @@ type systemMetrics struct {
 	intranetOutgoingBytes atomic.Int64
 	extranetIncomingBytes atomic.Int64
 	extranetOutgoingBytes atomic.Int64
+
+	wsCompressRawBytes   atomic.Int64 // websocket压缩前的字节数
+	wsCompressedBytes    atomic.Int64 // websocket压缩后的字节数
+	wsDecompressRawBytes atomic.Int64 // websocket解压后的字节数
+	wsDecompressedBytes  atomic.Int64 // websocket解压前的字节数
 }
 
 func newSystemMetrics(opts *Options) *systemMetrics {
@@ -32,6 +37,10 @@ func newSystemMetrics(opts *Options) *systemMetrics {
 	extranetIncomingBytes := NewInt64ObservableCounter("system_extranet_incoming_bytes")
 	extranetOutgoingBytes := NewInt64ObservableCounter("system_extranet_outgoing_bytes")
 	cpuUsage := NewFloat64ObservableCounter("system_cpu_percent")
+	wsCompressRawBytes := NewInt64ObservableCounter("system_ws_compress_raw_bytes")
+	wsCompressedBytes := NewInt64ObservableCounter("system_ws_compress_compressed_bytes")
+	wsDecompressRawBytes := NewInt64ObservableCounter("system_ws_decompress_raw_bytes")
+	wsDecompressedBytes := NewInt64ObservableCounter("system_ws_decompress_compressed_bytes")
 
 	RegisterCallback(func(ctx context.Context, obs metric.Observer) error {
 		obs.ObserveInt64(intranetIncomingBytes, s.intranetIncomingBytes.Load())
@@ -40,9 +49,14 @@ func newSystemMetrics(opts *Options) *systemMetrics {
 		obs.ObserveInt64(extranetOutgoingBytes, s.extranetOutgoingBytes.Load())
 		cpuPercent := float64(runtime.NumCPU())
 		obs.ObserveFloat64(cpuUsage, cpuPercent)
+		obs.ObserveInt64(wsCompressRawBytes, s.wsCompressRawBytes.Load())
+		obs.ObserveInt64(wsCompressedBytes, s.wsCompressedBytes.Load())
+		obs.ObserveInt64(wsDecompressRawBytes, s.wsDecompressRawBytes.Load())
+		obs.ObserveInt64(wsDecompressedBytes, s.wsDecompressedBytes.Load())
 
 		return nil
-	}, intranetIncomingBytes, intranetOutgoingBytes, extranetIncomingBytes, extranetOutgoingBytes, cpuUsage)
+	}, intranetIncomingBytes, intranetOutgoingBytes, extranetIncomingBytes, extranetOutgoingBytes, cpuUsage,
+		wsCompressRawBytes, wsCompressedBytes, wsDecompressRawBytes, wsDecompressedBytes)
 
 	return s
 }
@@ -67,6 +81,18 @@ func (s *systemMetrics) ExtranetOutgoingAdd(v int64) {
 	s.extranetOutgoingBytes.Add(v)
 }
 
+// WSCompressAdd websocket消息压缩前后的字节数
+func (s *systemMetrics) WSCompressAdd(raw, compressed int64) {
+	s.wsCompressRawBytes.Add(raw)
+	s.wsCompressedBytes.Add(compressed)
+}
+
+// WSDecompressAdd websocket消息解压前后的字节数
+func (s *systemMetrics) WSDecompressAdd(compressed, raw int64) {
+	s.wsDecompressedBytes.Add(compressed)
+	s.wsDecompressRawBytes.Add(raw)
+}
+
 // CPUUsageAdd CPU使用率
 func (s *systemMetrics) CPUUsageAdd(v float64) {
 
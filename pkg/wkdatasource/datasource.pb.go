@@ -0,0 +1,666 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        v3.18.1
+// source: pkg/wkdatasource/datasource.proto
+
+package wkdatasource
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Channel struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ChannelId   string `protobuf:"bytes,1,opt,name=channelId,proto3" json:"channelId,omitempty"`
+	ChannelType uint32 `protobuf:"varint,2,opt,name=channelType,proto3" json:"channelType,omitempty"`
+}
+
+func (x *Channel) Reset() {
+	*x = Channel{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkdatasource_datasource_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Channel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Channel) ProtoMessage() {}
+
+func (x *Channel) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkdatasource_datasource_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Channel.ProtoReflect.Descriptor instead.
+func (*Channel) Descriptor() ([]byte, []int) {
+	return file_pkg_wkdatasource_datasource_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Channel) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *Channel) GetChannelType() uint32 {
+	if x != nil {
+		return x.ChannelType
+	}
+	return 0
+}
+
+type ChannelsReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channels []*Channel `protobuf:"bytes,1,rep,name=channels,proto3" json:"channels,omitempty"`
+}
+
+func (x *ChannelsReq) Reset() {
+	*x = ChannelsReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkdatasource_datasource_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChannelsReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelsReq) ProtoMessage() {}
+
+func (x *ChannelsReq) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkdatasource_datasource_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelsReq.ProtoReflect.Descriptor instead.
+func (*ChannelsReq) Descriptor() ([]byte, []int) {
+	return file_pkg_wkdatasource_datasource_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ChannelsReq) GetChannels() []*Channel {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+type ChannelUids struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channel *Channel `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	Uids    []string `protobuf:"bytes,2,rep,name=uids,proto3" json:"uids,omitempty"`
+}
+
+func (x *ChannelUids) Reset() {
+	*x = ChannelUids{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkdatasource_datasource_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChannelUids) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelUids) ProtoMessage() {}
+
+func (x *ChannelUids) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkdatasource_datasource_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelUids.ProtoReflect.Descriptor instead.
+func (*ChannelUids) Descriptor() ([]byte, []int) {
+	return file_pkg_wkdatasource_datasource_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ChannelUids) GetChannel() *Channel {
+	if x != nil {
+		return x.Channel
+	}
+	return nil
+}
+
+func (x *ChannelUids) GetUids() []string {
+	if x != nil {
+		return x.Uids
+	}
+	return nil
+}
+
+type ChannelUidsResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channels []*ChannelUids `protobuf:"bytes,1,rep,name=channels,proto3" json:"channels,omitempty"`
+}
+
+func (x *ChannelUidsResp) Reset() {
+	*x = ChannelUidsResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkdatasource_datasource_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChannelUidsResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelUidsResp) ProtoMessage() {}
+
+func (x *ChannelUidsResp) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkdatasource_datasource_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelUidsResp.ProtoReflect.Descriptor instead.
+func (*ChannelUidsResp) Descriptor() ([]byte, []int) {
+	return file_pkg_wkdatasource_datasource_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ChannelUidsResp) GetChannels() []*ChannelUids {
+	if x != nil {
+		return x.Channels
+	}
+	return nil
+}
+
+type ChannelInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Channel *Channel `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	Large   bool     `protobuf:"varint,2,opt,name=large,proto3" json:"large,omitempty"`     // 是否是超大群
+	Ban     bool     `protobuf:"varint,3,opt,name=ban,proto3" json:"ban,omitempty"`         // 是否封禁频道（封禁后此频道所有人都将不能发消息，除了系统账号）
+	Disband bool     `protobuf:"varint,4,opt,name=disband,proto3" json:"disband,omitempty"` // 是否解散频道
+}
+
+func (x *ChannelInfo) Reset() {
+	*x = ChannelInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkdatasource_datasource_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChannelInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelInfo) ProtoMessage() {}
+
+func (x *ChannelInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkdatasource_datasource_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelInfo.ProtoReflect.Descriptor instead.
+func (*ChannelInfo) Descriptor() ([]byte, []int) {
+	return file_pkg_wkdatasource_datasource_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ChannelInfo) GetChannel() *Channel {
+	if x != nil {
+		return x.Channel
+	}
+	return nil
+}
+
+func (x *ChannelInfo) GetLarge() bool {
+	if x != nil {
+		return x.Large
+	}
+	return false
+}
+
+func (x *ChannelInfo) GetBan() bool {
+	if x != nil {
+		return x.Ban
+	}
+	return false
+}
+
+func (x *ChannelInfo) GetDisband() bool {
+	if x != nil {
+		return x.Disband
+	}
+	return false
+}
+
+type ChannelInfosResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Infos []*ChannelInfo `protobuf:"bytes,1,rep,name=infos,proto3" json:"infos,omitempty"`
+}
+
+func (x *ChannelInfosResp) Reset() {
+	*x = ChannelInfosResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkdatasource_datasource_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChannelInfosResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelInfosResp) ProtoMessage() {}
+
+func (x *ChannelInfosResp) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkdatasource_datasource_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelInfosResp.ProtoReflect.Descriptor instead.
+func (*ChannelInfosResp) Descriptor() ([]byte, []int) {
+	return file_pkg_wkdatasource_datasource_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ChannelInfosResp) GetInfos() []*ChannelInfo {
+	if x != nil {
+		return x.Infos
+	}
+	return nil
+}
+
+type SystemUIDsReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *SystemUIDsReq) Reset() {
+	*x = SystemUIDsReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkdatasource_datasource_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SystemUIDsReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SystemUIDsReq) ProtoMessage() {}
+
+func (x *SystemUIDsReq) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkdatasource_datasource_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SystemUIDsReq.ProtoReflect.Descriptor instead.
+func (*SystemUIDsReq) Descriptor() ([]byte, []int) {
+	return file_pkg_wkdatasource_datasource_proto_rawDescGZIP(), []int{6}
+}
+
+type UidsResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uids []string `protobuf:"bytes,1,rep,name=uids,proto3" json:"uids,omitempty"`
+}
+
+func (x *UidsResp) Reset() {
+	*x = UidsResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkdatasource_datasource_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UidsResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UidsResp) ProtoMessage() {}
+
+func (x *UidsResp) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkdatasource_datasource_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UidsResp.ProtoReflect.Descriptor instead.
+func (*UidsResp) Descriptor() ([]byte, []int) {
+	return file_pkg_wkdatasource_datasource_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UidsResp) GetUids() []string {
+	if x != nil {
+		return x.Uids
+	}
+	return nil
+}
+
+var File_pkg_wkdatasource_datasource_proto protoreflect.FileDescriptor
+
+var file_pkg_wkdatasource_datasource_proto_rawDesc = []byte{
+	0x0a, 0x21, 0x70, 0x6b, 0x67, 0x2f, 0x77, 0x6b, 0x64, 0x61, 0x74, 0x61, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x2f, 0x64, 0x61, 0x74, 0x61, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x0c, 0x77, 0x6b, 0x64, 0x61, 0x74, 0x61, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x22, 0x49, 0x0a, 0x07, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x12, 0x1c, 0x0a, 0x09,
+	0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x63, 0x68,
+	0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x54, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x0b, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x54, 0x79, 0x70, 0x65, 0x22, 0x40, 0x0a, 0x0b,
+	0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x12, 0x31, 0x0a, 0x08, 0x63,
+	0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e,
+	0x77, 0x6b, 0x64, 0x61, 0x74, 0x61, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x43, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x22, 0x52,
+	0x0a, 0x0b, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x55, 0x69, 0x64, 0x73, 0x12, 0x2f, 0x0a,
+	0x07, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15,
+	0x2e, 0x77, 0x6b, 0x64, 0x61, 0x74, 0x61, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x43, 0x68,
+	0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x12, 0x12,
+	0x0a, 0x04, 0x75, 0x69, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x75, 0x69,
+	0x64, 0x73, 0x22, 0x48, 0x0a, 0x0f, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x55, 0x69, 0x64,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x35, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x77, 0x6b, 0x64, 0x61, 0x74, 0x61,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x55, 0x69,
+	0x64, 0x73, 0x52, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x22, 0x80, 0x01, 0x0a,
+	0x0b, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x2f, 0x0a, 0x07,
+	0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e,
+	0x77, 0x6b, 0x64, 0x61, 0x74, 0x61, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x43, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x52, 0x07, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x12, 0x14, 0x0a,
+	0x05, 0x6c, 0x61, 0x72, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x6c, 0x61,
+	0x72, 0x67, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x62, 0x61, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x03, 0x62, 0x61, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x69, 0x73, 0x62, 0x61, 0x6e, 0x64,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x64, 0x69, 0x73, 0x62, 0x61, 0x6e, 0x64, 0x22,
+	0x43, 0x0a, 0x10, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x6e, 0x66, 0x6f, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x12, 0x2f, 0x0a, 0x05, 0x69, 0x6e, 0x66, 0x6f, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x77, 0x6b, 0x64, 0x61, 0x74, 0x61, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x05, 0x69,
+	0x6e, 0x66, 0x6f, 0x73, 0x22, 0x0f, 0x0a, 0x0d, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x55, 0x49,
+	0x44, 0x73, 0x52, 0x65, 0x71, 0x22, 0x1e, 0x0a, 0x08, 0x55, 0x69, 0x64, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x69, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x04, 0x75, 0x69, 0x64, 0x73, 0x32, 0x87, 0x03, 0x0a, 0x11, 0x44, 0x61, 0x74, 0x61, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x4a, 0x0a, 0x0e, 0x47,
+	0x65, 0x74, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x73, 0x12, 0x19, 0x2e,
+	0x77, 0x6b, 0x64, 0x61, 0x74, 0x61, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x43, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x1d, 0x2e, 0x77, 0x6b, 0x64, 0x61, 0x74,
+	0x61, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x55,
+	0x69, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x48, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x42, 0x6c,
+	0x61, 0x63, 0x6b, 0x6c, 0x69, 0x73, 0x74, 0x12, 0x19, 0x2e, 0x77, 0x6b, 0x64, 0x61, 0x74, 0x61,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x52,
+	0x65, 0x71, 0x1a, 0x1d, 0x2e, 0x77, 0x6b, 0x64, 0x61, 0x74, 0x61, 0x73, 0x6f, 0x75, 0x72, 0x63,
+	0x65, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x55, 0x69, 0x64, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x12, 0x48, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x57, 0x68, 0x69, 0x74, 0x65, 0x6c, 0x69, 0x73,
+	0x74, 0x12, 0x19, 0x2e, 0x77, 0x6b, 0x64, 0x61, 0x74, 0x61, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x1d, 0x2e, 0x77,
+	0x6b, 0x64, 0x61, 0x74, 0x61, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x43, 0x68, 0x61, 0x6e,
+	0x6e, 0x65, 0x6c, 0x55, 0x69, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x4c, 0x0a, 0x0f, 0x47,
+	0x65, 0x74, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x6e, 0x66, 0x6f, 0x73, 0x12, 0x19,
+	0x2e, 0x77, 0x6b, 0x64, 0x61, 0x74, 0x61, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x43, 0x68,
+	0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x1e, 0x2e, 0x77, 0x6b, 0x64, 0x61,
+	0x74, 0x61, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
+	0x49, 0x6e, 0x66, 0x6f, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x44, 0x0a, 0x0d, 0x47, 0x65, 0x74,
+	0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x55, 0x49, 0x44, 0x73, 0x12, 0x1b, 0x2e, 0x77, 0x6b, 0x64,
+	0x61, 0x74, 0x61, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d,
+	0x55, 0x49, 0x44, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x16, 0x2e, 0x77, 0x6b, 0x64, 0x61, 0x74, 0x61,
+	0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x2e, 0x55, 0x69, 0x64, 0x73, 0x52, 0x65, 0x73, 0x70, 0x42,
+	0x11, 0x5a, 0x0f, 0x2e, 0x2f, 0x3b, 0x77, 0x6b, 0x64, 0x61, 0x74, 0x61, 0x73, 0x6f, 0x75, 0x72,
+	0x63, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_pkg_wkdatasource_datasource_proto_rawDescOnce sync.Once
+	file_pkg_wkdatasource_datasource_proto_rawDescData = file_pkg_wkdatasource_datasource_proto_rawDesc
+)
+
+func file_pkg_wkdatasource_datasource_proto_rawDescGZIP() []byte {
+	file_pkg_wkdatasource_datasource_proto_rawDescOnce.Do(func() {
+		file_pkg_wkdatasource_datasource_proto_rawDescData = protoimpl.X.CompressGZIP(file_pkg_wkdatasource_datasource_proto_rawDescData)
+	})
+	return file_pkg_wkdatasource_datasource_proto_rawDescData
+}
+
+var file_pkg_wkdatasource_datasource_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_pkg_wkdatasource_datasource_proto_goTypes = []any{
+	(*Channel)(nil),          // 0: wkdatasource.Channel
+	(*ChannelsReq)(nil),      // 1: wkdatasource.ChannelsReq
+	(*ChannelUids)(nil),      // 2: wkdatasource.ChannelUids
+	(*ChannelUidsResp)(nil),  // 3: wkdatasource.ChannelUidsResp
+	(*ChannelInfo)(nil),      // 4: wkdatasource.ChannelInfo
+	(*ChannelInfosResp)(nil), // 5: wkdatasource.ChannelInfosResp
+	(*SystemUIDsReq)(nil),    // 6: wkdatasource.SystemUIDsReq
+	(*UidsResp)(nil),         // 7: wkdatasource.UidsResp
+}
+var file_pkg_wkdatasource_datasource_proto_depIdxs = []int32{
+	0,  // 0: wkdatasource.ChannelsReq.channels:type_name -> wkdatasource.Channel
+	0,  // 1: wkdatasource.ChannelUids.channel:type_name -> wkdatasource.Channel
+	2,  // 2: wkdatasource.ChannelUidsResp.channels:type_name -> wkdatasource.ChannelUids
+	0,  // 3: wkdatasource.ChannelInfo.channel:type_name -> wkdatasource.Channel
+	4,  // 4: wkdatasource.ChannelInfosResp.infos:type_name -> wkdatasource.ChannelInfo
+	1,  // 5: wkdatasource.DatasourceService.GetSubscribers:input_type -> wkdatasource.ChannelsReq
+	1,  // 6: wkdatasource.DatasourceService.GetBlacklist:input_type -> wkdatasource.ChannelsReq
+	1,  // 7: wkdatasource.DatasourceService.GetWhitelist:input_type -> wkdatasource.ChannelsReq
+	1,  // 8: wkdatasource.DatasourceService.GetChannelInfos:input_type -> wkdatasource.ChannelsReq
+	6,  // 9: wkdatasource.DatasourceService.GetSystemUIDs:input_type -> wkdatasource.SystemUIDsReq
+	3,  // 10: wkdatasource.DatasourceService.GetSubscribers:output_type -> wkdatasource.ChannelUidsResp
+	3,  // 11: wkdatasource.DatasourceService.GetBlacklist:output_type -> wkdatasource.ChannelUidsResp
+	3,  // 12: wkdatasource.DatasourceService.GetWhitelist:output_type -> wkdatasource.ChannelUidsResp
+	5,  // 13: wkdatasource.DatasourceService.GetChannelInfos:output_type -> wkdatasource.ChannelInfosResp
+	7,  // 14: wkdatasource.DatasourceService.GetSystemUIDs:output_type -> wkdatasource.UidsResp
+	10, // [10:15] is the sub-list for method output_type
+	5,  // [5:10] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_pkg_wkdatasource_datasource_proto_init() }
+func file_pkg_wkdatasource_datasource_proto_init() {
+	if File_pkg_wkdatasource_datasource_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pkg_wkdatasource_datasource_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*Channel); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_wkdatasource_datasource_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*ChannelsReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_wkdatasource_datasource_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*ChannelUids); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_wkdatasource_datasource_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*ChannelUidsResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_wkdatasource_datasource_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*ChannelInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_wkdatasource_datasource_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*ChannelInfosResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_wkdatasource_datasource_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*SystemUIDsReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_wkdatasource_datasource_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*UidsResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_pkg_wkdatasource_datasource_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pkg_wkdatasource_datasource_proto_goTypes,
+		DependencyIndexes: file_pkg_wkdatasource_datasource_proto_depIdxs,
+		MessageInfos:      file_pkg_wkdatasource_datasource_proto_msgTypes,
+	}.Build()
+	File_pkg_wkdatasource_datasource_proto = out.File
+	file_pkg_wkdatasource_datasource_proto_rawDesc = nil
+	file_pkg_wkdatasource_datasource_proto_goTypes = nil
+	file_pkg_wkdatasource_datasource_proto_depIdxs = nil
+}
@@ -0,0 +1,109 @@
+package wkdb
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb/key"
+	"github.com/cockroachdb/pebble"
+)
+
+// AppendAuditLog 追加审计日志
+func (wk *wukongDB) AppendAuditLog(log AuditLog) error {
+	if log.Id == 0 {
+		log.Id = wk.NextPrimaryKey()
+	}
+	if log.CreatedAt == 0 {
+		log.CreatedAt = time.Now().Unix()
+	}
+	data, err := log.Marshal()
+	if err != nil {
+		return err
+	}
+	batch := wk.defaultShardBatchDB().NewBatch()
+	batch.Set(key.NewAuditLogKey(log.Id), data)
+	return batch.CommitWait()
+}
+
+// SearchAuditLogs 查询审计日志，从新到旧返回
+func (wk *wukongDB) SearchAuditLogs(req AuditLogSearchReq) ([]AuditLog, error) {
+	upperId := uint64(math.MaxUint64)
+	if req.OffsetId > 0 {
+		upperId = req.OffsetId
+	}
+	iter := wk.defaultShardDB().NewIter(&pebble.IterOptions{
+		LowerBound: key.NewAuditLogKey(0),
+		UpperBound: key.NewAuditLogKey(upperId),
+	})
+	defer iter.Close()
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	logs := make([]AuditLog, 0, limit)
+	for iter.Last(); iter.Valid(); iter.Prev() {
+		var log AuditLog
+		if err := log.Unmarshal(iter.Value()); err != nil {
+			return nil, err
+		}
+		if req.StartTime > 0 && log.CreatedAt < req.StartTime {
+			break // 日志按时间递增，后面的更旧
+		}
+		if !req.match(log) {
+			continue
+		}
+		logs = append(logs, log)
+		if len(logs) >= limit {
+			break
+		}
+	}
+	return logs, nil
+}
+
+// RemoveAuditLogsBefore 移除指定时间之前的审计日志
+func (wk *wukongDB) RemoveAuditLogsBefore(t time.Time) error {
+	iter := wk.defaultShardDB().NewIter(&pebble.IterOptions{
+		LowerBound: key.NewAuditLogKey(0),
+		UpperBound: key.NewAuditLogKey(math.MaxUint64),
+	})
+	defer iter.Close()
+
+	batch := wk.defaultShardDB().NewBatch()
+	defer batch.Close()
+
+	before := t.Unix()
+	for iter.First(); iter.Valid(); iter.Next() {
+		var log AuditLog
+		if err := log.Unmarshal(iter.Value()); err != nil {
+			return err
+		}
+		if log.CreatedAt >= before {
+			break
+		}
+		if err := batch.Delete(iter.Key(), wk.noSync); err != nil {
+			return err
+		}
+	}
+	return batch.Commit(wk.sync)
+}
+
+func (r AuditLogSearchReq) match(log AuditLog) bool {
+	if r.EndTime > 0 && log.CreatedAt >= r.EndTime {
+		return false
+	}
+	if r.Actor != "" && log.Actor != r.Actor {
+		return false
+	}
+	if r.ApiKeyId != "" && log.ApiKeyId != r.ApiKeyId {
+		return false
+	}
+	if r.Path != "" && !strings.HasPrefix(log.Path, r.Path) {
+		return false
+	}
+	if r.Target != "" && log.Target != r.Target {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package wkdb_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLog(t *testing.T) {
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	now := time.Now().Unix()
+	logs := []wkdb.AuditLog{
+		{ActorType: wkdb.AuditActorApiKey, Actor: "ops", ApiKeyId: "k1", Method: http.MethodPost, Path: "/channel/blacklist_add", Target: "channel:g1:2", StatusCode: http.StatusOK, CreatedAt: now - 3600},
+		{ActorType: wkdb.AuditActorManagerUser, Actor: "admin", Method: http.MethodPost, Path: "/user/device_quit", Target: "uid:u1", StatusCode: http.StatusOK, CreatedAt: now},
+		{ActorType: wkdb.AuditActorManagerUser, Actor: "admin", Method: http.MethodPost, Path: "/channel/delete", Target: "channel:g2:2", StatusCode: http.StatusBadRequest, Error: "频道不存在", CreatedAt: now},
+	}
+	for _, log := range logs {
+		err = d.AppendAuditLog(log)
+		assert.NoError(t, err)
+	}
+
+	t.Run("SearchAuditLogs", func(t *testing.T) {
+		result, err := d.SearchAuditLogs(wkdb.AuditLogSearchReq{Limit: 10})
+		assert.NoError(t, err)
+		assert.Len(t, result, 3)
+		assert.Equal(t, "/channel/delete", result[0].Path)
+		assert.Equal(t, "频道不存在", result[0].Error)
+		assert.False(t, result[0].Success())
+
+		result, err = d.SearchAuditLogs(wkdb.AuditLogSearchReq{Actor: "admin", Limit: 1})
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+
+		result, err = d.SearchAuditLogs(wkdb.AuditLogSearchReq{Actor: "admin", OffsetId: result[0].Id, Limit: 10})
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+		assert.Equal(t, "uid:u1", result[0].Target)
+
+		result, err = d.SearchAuditLogs(wkdb.AuditLogSearchReq{Path: "/channel", StartTime: now - 60})
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+	})
+
+	t.Run("RemoveAuditLogsBefore", func(t *testing.T) {
+		err := d.RemoveAuditLogsBefore(time.Unix(now-60, 0))
+		assert.NoError(t, err)
+
+		result, err := d.SearchAuditLogs(wkdb.AuditLogSearchReq{})
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+	})
+}
@@ -549,6 +549,17 @@ func (wk *wukongDB) writeChannelInfo(primaryKey uint64, channelInfo ChannelInfo,
 		return err
 	}
 
+	// webhook
+	if channelInfo.Webhook != "" {
+		if err = w.Set(key.NewChannelInfoColumnKey(primaryKey, key.TableChannelInfo.Column.Webhook), []byte(channelInfo.Webhook), wk.noSync); err != nil {
+			return err
+		}
+	} else {
+		if err = w.Delete(key.NewChannelInfoColumnKey(primaryKey, key.TableChannelInfo.Column.Webhook), wk.noSync); err != nil {
+			return err
+		}
+	}
+
 	// createdAt
 	if channelInfo.CreatedAt != nil {
 		ct := uint64(channelInfo.CreatedAt.UnixNano())
@@ -707,6 +718,8 @@ func (wk *wukongDB) iterChannelInfo(iter *pebble.Iterator, iterFnc func(channelI
 			preChannelInfo.AllowlistCount = int(wk.endian.Uint32(iter.Value()))
 		case key.TableChannelInfo.Column.DenylistCount:
 			preChannelInfo.DenylistCount = int(wk.endian.Uint32(iter.Value()))
+		case key.TableChannelInfo.Column.Webhook:
+			preChannelInfo.Webhook = string(iter.Value())
 		case key.TableChannelInfo.Column.CreatedAt:
 			tm := int64(wk.endian.Uint64(iter.Value()))
 			if tm > 0 {
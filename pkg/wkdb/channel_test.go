@@ -24,6 +24,7 @@ func TestAddChannel(t *testing.T) {
 		Ban:         true,
 		Large:       true,
 		Disband:     true,
+		Webhook:     "http://127.0.0.1:8080/webhook",
 		CreatedAt:   &nw,
 		UpdatedAt:   &nw,
 	}
@@ -38,6 +39,7 @@ func TestAddChannel(t *testing.T) {
 	assert.Equal(t, channelInfo.Ban, channelInfo2.Ban)
 	assert.Equal(t, channelInfo.Large, channelInfo2.Large)
 	assert.Equal(t, channelInfo.Disband, channelInfo2.Disband)
+	assert.Equal(t, channelInfo.Webhook, channelInfo2.Webhook)
 	assert.Equal(t, channelInfo.CreatedAt.Unix(), channelInfo2.CreatedAt.Unix())
 	assert.Equal(t, channelInfo.UpdatedAt.Unix(), channelInfo2.UpdatedAt.Unix())
 }
@@ -59,6 +61,7 @@ func TestUpdateChannel(t *testing.T) {
 		Ban:         true,
 		Large:       true,
 		Disband:     true,
+		Webhook:     "http://127.0.0.1:8080/webhook",
 		CreatedAt:   &createdAt,
 		UpdatedAt:   &updatedAt,
 	}
@@ -70,6 +73,7 @@ func TestUpdateChannel(t *testing.T) {
 	channelInfo.Ban = false
 	channelInfo.Large = false
 	channelInfo.Disband = false
+	channelInfo.Webhook = ""
 	channelInfo.UpdatedAt = &nw
 
 	err = d.UpdateChannel(channelInfo)
@@ -83,6 +87,7 @@ func TestUpdateChannel(t *testing.T) {
 	assert.Equal(t, channelInfo.Ban, channelInfo2.Ban)
 	assert.Equal(t, channelInfo.Large, channelInfo2.Large)
 	assert.Equal(t, channelInfo.Disband, channelInfo2.Disband)
+	assert.Equal(t, "", channelInfo2.Webhook)
 	assert.Equal(t, channelInfo.CreatedAt.Unix(), channelInfo2.CreatedAt.Unix())
 	assert.Equal(t, channelInfo.UpdatedAt.Unix(), channelInfo2.UpdatedAt.Unix())
 }
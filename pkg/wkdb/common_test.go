@@ -9,8 +9,10 @@ import (
 )
 
 func newTestDB(t testing.TB) wkdb.DB {
-	dr := t.TempDir()
+	return newTestDBWithOptions(t.TempDir())
+}
 
+func newTestDBWithOptions(dr string, opt ...wkdb.Option) wkdb.DB {
 	traceObj := trace.New(
 		context.Background(),
 		trace.NewOptions(
@@ -19,5 +21,5 @@ func newTestDB(t testing.TB) wkdb.DB {
 		))
 	trace.SetGlobalTrace(traceObj)
 
-	return wkdb.NewWukongDB(wkdb.NewOptions(wkdb.WithDir(dr), wkdb.WithShardNum(1)))
+	return wkdb.NewWukongDB(wkdb.NewOptions(append([]wkdb.Option{wkdb.WithDir(dr), wkdb.WithShardNum(1)}, opt...)...))
 }
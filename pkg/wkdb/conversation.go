@@ -3,9 +3,11 @@ package wkdb
 import (
 	"fmt"
 	"math"
+	"sort"
 	"time"
 
 	"github.com/WuKongIM/WuKongIM/pkg/wkdb/key"
+	"github.com/WuKongIM/WuKongIM/pkg/wkutil"
 	"github.com/cockroachdb/pebble"
 	"go.uber.org/zap"
 )
@@ -17,7 +19,16 @@ func (wk *wukongDB) AddOrUpdateConversations(conversations []Conversation) error
 		return nil
 	}
 
+	// 会话版本号是读-改-写，同一用户的写入需要串行（不同slot的日志可能并发应用）
+	uids := make([]string, 0, len(conversations))
+	for _, conversation := range conversations {
+		uids = append(uids, conversation.Uid)
+	}
+	unlock := wk.lockConversationUsers(uids)
+	defer unlock()
+
 	userBatchMap := make(map[uint32]*Batch)
+	versions := make(map[string]*conversationVersionState) // 本批次用户分配到的会话版本号
 
 	for _, conversation := range conversations {
 		shardId := wk.shardId(conversation.Uid)
@@ -47,11 +58,20 @@ func (wk *wukongDB) AddOrUpdateConversations(conversations []Conversation) error
 			conversation.CreatedAt = nil // 更新时不更新创建时间
 		}
 
+		conversation.Version, err = wk.nextConversationVersion(conversation.Uid, versions)
+		if err != nil {
+			return err
+		}
+
 		if err := wk.writeConversation(conversation, batch); err != nil {
 			return err
 		}
 	}
 
+	for uid, state := range versions {
+		wk.writeConversationVersion(uid, state, userBatchMap[wk.shardId(uid)])
+	}
+
 	err := wk.setConversationLocalUserRelation(conversations, false)
 	if err != nil {
 		return err
@@ -80,8 +100,8 @@ func (wk *wukongDB) AddOrUpdateConversations(conversations []Conversation) error
 
 func (wk *wukongDB) AddOrUpdateConversationsWithUser(uid string, conversations []Conversation) error {
 	wk.metrics.AddOrUpdateConversationsAdd(1)
-	// wk.dblock.conversationLock.lock(uid)
-	// defer wk.dblock.conversationLock.unlock(uid)
+	wk.dblock.conversationLock.lock(uid)
+	defer wk.dblock.conversationLock.unlock(uid)
 	if wk.opts.EnableCost {
 		start := time.Now()
 		defer func() {
@@ -93,6 +113,7 @@ func (wk *wukongDB) AddOrUpdateConversationsWithUser(uid string, conversations [
 	}
 
 	batch := wk.sharedBatchDB(uid).NewBatch()
+	versions := make(map[string]*conversationVersionState)
 
 	for _, cn := range conversations {
 		oldConversation, err := wk.GetConversation(uid, cn.ChannelId, cn.ChannelType)
@@ -116,11 +137,20 @@ func (wk *wukongDB) AddOrUpdateConversationsWithUser(uid string, conversations [
 			cn.CreatedAt = nil // 更新时不更新创建时间
 		}
 
+		cn.Version, err = wk.nextConversationVersion(uid, versions)
+		if err != nil {
+			return err
+		}
+
 		if err := wk.writeConversation(cn, batch); err != nil {
 			return err
 		}
 	}
 
+	if state, ok := versions[uid]; ok {
+		wk.writeConversationVersion(uid, state, batch)
+	}
+
 	err := wk.setConversationLocalUserRelation(conversations, false)
 	if err != nil {
 		return err
@@ -134,6 +164,8 @@ func (wk *wukongDB) AddOrUpdateConversationsWithUser(uid string, conversations [
 	return batch.CommitWait()
 }
 
+// UpdateConversationIfSeqGreaterAsync 只更新本地的已读位置，不经过分布式日志，所以不修改会话版本号
+// 会话版本号只在日志应用时分配，保证各个副本的版本号一致
 func (wk *wukongDB) UpdateConversationIfSeqGreaterAsync(uid, channelId string, channelType uint8, readToMsgSeq uint64) error {
 
 	existConversation, err := wk.GetConversation(uid, channelId, channelType)
@@ -153,6 +185,7 @@ func (wk *wukongDB) UpdateConversationIfSeqGreaterAsync(uid, channelId string, c
 	var msgSeqBytes = make([]byte, 8)
 	wk.endian.PutUint64(msgSeqBytes, readToMsgSeq)
 	w.Set(key.NewConversationColumnKey(uid, existConversation.Id, key.TableConversation.Column.ReadedToMsgSeq), msgSeqBytes)
+
 	return w.Commit()
 }
 
@@ -299,17 +332,260 @@ func (wk *wukongDB) getLastConversationIds(uid string, updatedAt uint64, limit i
 	return ids, nil
 }
 
+// GetConversationVersion 获取用户当前最大的会话版本号
+func (wk *wukongDB) GetConversationVersion(uid string) (uint64, error) {
+	state, err := wk.getConversationVersionState(uid)
+	if err != nil {
+		return 0, err
+	}
+	return state.version, nil
+}
+
+// conversationVersionState 用户的会话版本号状态
+type conversationVersionState struct {
+	version   uint64 // 当前最大的会话版本号
+	compacted uint64 // 已清理的删除记录的最大版本号，客户端的版本号小于此值时需要全量同步
+}
+
+func (wk *wukongDB) getConversationVersionState(uid string) (*conversationVersionState, error) {
+	state := &conversationVersionState{}
+	data, closer, err := wk.shardDB(uid).Get(key.NewConversationVersionKey(uid))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return state, nil
+		}
+		return nil, err
+	}
+	defer closer.Close()
+	if len(data) >= 8 {
+		state.version = wk.endian.Uint64(data)
+	}
+	if len(data) >= 16 {
+		state.compacted = wk.endian.Uint64(data[8:])
+	}
+	return state, nil
+}
+
+// GetConversationDelta 获取用户版本号大于version的会话变更（新增、更新和删除），按版本号升序最多返回limit条
+func (wk *wukongDB) GetConversationDelta(uid string, tp ConversationType, version uint64, limit int) (ConversationDelta, error) {
+
+	delta := ConversationDelta{
+		Version: version,
+	}
+
+	state, err := wk.getConversationVersionState(uid)
+	if err != nil {
+		return delta, err
+	}
+	if version > 0 && version < state.compacted { // 需要的删除记录已经清理，从头全量同步
+		delta.Reset = true
+		version = 0
+		delta.Version = 0
+	}
+	if version == 0 {
+		// 升级前保存的会话没有版本号，全量同步时在第一页返回
+		unversioned, err := wk.getUnversionedConversations(uid, tp)
+		if err != nil {
+			return delta, err
+		}
+		delta.Conversations = append(delta.Conversations, unversioned...)
+	}
+
+	fetchLimit := 0
+	if limit > 0 {
+		fetchLimit = limit + 1 // 多取一条用来判断是否还有更多数据
+	}
+
+	versionIds, err := wk.getConversationVersionIds(uid, version, fetchLimit)
+	if err != nil {
+		return delta, err
+	}
+	tombstones, err := wk.getConversationTombstones(uid, version, fetchLimit)
+	if err != nil {
+		return delta, err
+	}
+
+	// 按版本号合并会话和删除记录
+	var (
+		i, j  int
+		count int
+	)
+	for i < len(versionIds) || j < len(tombstones) {
+		if limit > 0 && count >= limit {
+			delta.More = true
+			break
+		}
+		if j >= len(tombstones) || (i < len(versionIds) && versionIds[i].version < tombstones[j].Version) {
+			versionId := versionIds[i]
+			i++
+			count++
+			delta.Version = versionId.version
+
+			conversation, err := wk.getConversation(uid, versionId.id)
+			if err != nil && err != ErrNotFound {
+				return delta, err
+			}
+			// 会话已删除或者索引已过期（会话有更新的版本）则跳过
+			if err == ErrNotFound || conversation.Version != versionId.version || conversation.Type != tp {
+				continue
+			}
+			delta.Conversations = append(delta.Conversations, conversation)
+		} else {
+			tombstone := tombstones[j]
+			j++
+			count++
+			delta.Version = tombstone.Version
+			if tombstone.Type != tp {
+				continue
+			}
+			delta.Tombstones = append(delta.Tombstones, tombstone)
+		}
+	}
+	return delta, nil
+}
+
+// getUnversionedConversations 获取用户没有版本号的会话（升级前保存的会话，之后再更新时会分配版本号）
+func (wk *wukongDB) getUnversionedConversations(uid string, tp ConversationType) ([]Conversation, error) {
+	conversations, err := wk.GetConversationsByType(uid, tp)
+	if err != nil {
+		return nil, err
+	}
+	unversioned := make([]Conversation, 0)
+	for _, conversation := range conversations {
+		if conversation.Version == 0 {
+			unversioned = append(unversioned, conversation)
+		}
+	}
+	return unversioned, nil
+}
+
+type conversationVersionId struct {
+	version uint64
+	id      uint64
+}
+
+func (wk *wukongDB) getConversationVersionIds(uid string, version uint64, limit int) ([]conversationVersionId, error) {
+	iter := wk.shardDB(uid).NewIter(&pebble.IterOptions{
+		LowerBound: key.NewConversationSecondIndexKey(uid, key.TableConversation.SecondIndex.Version, version+1, 0),
+		UpperBound: key.NewConversationSecondIndexKey(uid, key.TableConversation.SecondIndex.Version, math.MaxUint64, math.MaxUint64),
+	})
+	defer iter.Close()
+
+	versionIds := make([]conversationVersionId, 0)
+	for iter.First(); iter.Valid(); iter.Next() {
+		id, _, columnValue, err := key.ParseConversationSecondIndexKey(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		versionIds = append(versionIds, conversationVersionId{version: columnValue, id: id})
+		if limit > 0 && len(versionIds) >= limit {
+			break
+		}
+	}
+	return versionIds, nil
+}
+
+func (wk *wukongDB) getConversationTombstones(uid string, version uint64, limit int) ([]ConversationTombstone, error) {
+	iter := wk.shardDB(uid).NewIter(&pebble.IterOptions{
+		LowerBound: key.NewConversationTombstoneKey(uid, version+1),
+		UpperBound: key.NewConversationTombstoneKey(uid, math.MaxUint64),
+	})
+	defer iter.Close()
+
+	tombstones := make([]ConversationTombstone, 0)
+	for iter.First(); iter.Valid(); iter.Next() {
+		var tombstone ConversationTombstone
+		if err := tombstone.Unmarshal(iter.Value()); err != nil {
+			return nil, err
+		}
+		if tombstone.Uid != uid { // uid hash冲突
+			continue
+		}
+		tombstones = append(tombstones, tombstone)
+		if limit > 0 && len(tombstones) >= limit {
+			break
+		}
+	}
+	return tombstones, nil
+}
+
+// nextConversationVersion 分配用户的下一个会话版本号，versions记录本批次已分配的版本号
+// 只在分布式日志应用时调用，调用方需要持有用户的会话锁直到写入完成
+func (wk *wukongDB) nextConversationVersion(uid string, versions map[string]*conversationVersionState) (uint64, error) {
+	state, ok := versions[uid]
+	if !ok {
+		var err error
+		state, err = wk.getConversationVersionState(uid)
+		if err != nil {
+			return 0, err
+		}
+		versions[uid] = state
+	}
+	state.version++
+	return state.version, nil
+}
+
+func (wk *wukongDB) writeConversationVersion(uid string, state *conversationVersionState, w *Batch) {
+	versionBytes := make([]byte, 16)
+	wk.endian.PutUint64(versionBytes, state.version)
+	wk.endian.PutUint64(versionBytes[8:], state.compacted)
+	w.Set(key.NewConversationVersionKey(uid), versionBytes)
+}
+
+// compactConversationTombstones 清理ConversationTombstoneRetain个版本之前的删除记录
+func (wk *wukongDB) compactConversationTombstones(uid string, state *conversationVersionState, w *Batch) {
+	retain := wk.opts.ConversationTombstoneRetain
+	if retain == 0 || state.version <= retain {
+		return
+	}
+	compacted := state.version - retain
+	if compacted <= state.compacted {
+		return
+	}
+	w.DeleteRange(key.NewConversationTombstoneKey(uid, state.compacted), key.NewConversationTombstoneKey(uid, compacted+1))
+	state.compacted = compacted
+}
+
+// lockConversationUsers 按顺序锁住多个用户的会话，返回解锁函数
+func (wk *wukongDB) lockConversationUsers(uids []string) func() {
+	sorted := make([]string, 0, len(uids))
+	seen := make(map[string]struct{}, len(uids))
+	for _, uid := range uids {
+		if _, ok := seen[uid]; ok {
+			continue
+		}
+		seen[uid] = struct{}{}
+		sorted = append(sorted, uid)
+	}
+	sort.Strings(sorted)
+	for _, uid := range sorted {
+		wk.dblock.conversationLock.lock(uid)
+	}
+	return func() {
+		for i := len(sorted) - 1; i >= 0; i-- {
+			wk.dblock.conversationLock.unlock(sorted[i])
+		}
+	}
+}
+
 // DeleteConversation 删除最近会话
 func (wk *wukongDB) DeleteConversation(uid string, channelId string, channelType uint8) error {
 
 	wk.metrics.DeleteConversationAdd(1)
+	wk.dblock.conversationLock.lock(uid)
+	defer wk.dblock.conversationLock.unlock(uid)
 
 	batch := wk.sharedBatchDB(uid).NewBatch()
+	versions := make(map[string]*conversationVersionState)
 
-	err := wk.deleteConversation(uid, channelId, channelType, batch)
+	err := wk.deleteConversation(uid, channelId, channelType, versions, batch)
 	if err != nil {
 		return err
 	}
+	if state, ok := versions[uid]; ok {
+		wk.compactConversationTombstones(uid, state, batch)
+		wk.writeConversationVersion(uid, state, batch)
+	}
 
 	if err := wk.deleteConversationLocalUserRelation(channelId, channelType, uid); err != nil {
 		return err
@@ -323,15 +599,22 @@ func (wk *wukongDB) DeleteConversation(uid string, channelId string, channelType
 func (wk *wukongDB) DeleteConversations(uid string, channels []Channel) error {
 
 	wk.metrics.DeleteConversationsAdd(1)
+	wk.dblock.conversationLock.lock(uid)
+	defer wk.dblock.conversationLock.unlock(uid)
 
 	batch := wk.sharedBatchDB(uid).NewBatch()
+	versions := make(map[string]*conversationVersionState)
 
 	for _, channel := range channels {
-		err := wk.deleteConversation(uid, channel.ChannelId, channel.ChannelType, batch)
+		err := wk.deleteConversation(uid, channel.ChannelId, channel.ChannelType, versions, batch)
 		if err != nil {
 			return err
 		}
 	}
+	if state, ok := versions[uid]; ok {
+		wk.compactConversationTombstones(uid, state, batch)
+		wk.writeConversationVersion(uid, state, batch)
+	}
 
 	err := wk.deleteConversationLocalUserRelationWithChannels(uid, channels)
 	if err != nil {
@@ -376,7 +659,7 @@ func (wk *wukongDB) SearchConversation(req ConversationSearchReq) ([]Conversatio
 	return conversations, nil
 }
 
-func (wk *wukongDB) deleteConversation(uid string, channelId string, channelType uint8, w *Batch) error {
+func (wk *wukongDB) deleteConversation(uid string, channelId string, channelType uint8, versions map[string]*conversationVersionState, w *Batch) error {
 	oldConversation, err := wk.GetConversation(uid, channelId, channelType)
 	if err != nil && err != ErrNotFound {
 		return err
@@ -392,6 +675,24 @@ func (wk *wukongDB) deleteConversation(uid string, channelId string, channelType
 	// 删除数据
 	w.DeleteRange(key.NewConversationColumnKey(uid, oldConversation.Id, key.MinColumnKey), key.NewConversationColumnKey(uid, oldConversation.Id, key.MaxColumnKey))
 
+	// 写入删除记录，增量同步时通知客户端删除此会话
+	version, err := wk.nextConversationVersion(uid, versions)
+	if err != nil {
+		return err
+	}
+	tombstone := ConversationTombstone{
+		Uid:         uid,
+		Type:        oldConversation.Type,
+		ChannelId:   channelId,
+		ChannelType: channelType,
+		Version:     version,
+	}
+	data, err := tombstone.Marshal()
+	if err != nil {
+		return err
+	}
+	w.Set(key.NewConversationTombstoneKey(uid, version), data)
+
 	return nil
 }
 
@@ -562,6 +863,21 @@ func (wk *wukongDB) writeConversation(conversation Conversation, w *Batch) error
 	var msgSeqBytes = make([]byte, 8)
 	wk.endian.PutUint64(msgSeqBytes, conversation.ReadToMsgSeq)
 	w.Set(key.NewConversationColumnKey(uid, id, key.TableConversation.Column.ReadedToMsgSeq), msgSeqBytes)
+
+	// mentionMsgSeq 为0时不覆盖已有的@位置，@位置只能由新的@消息更新
+	if conversation.MentionMsgSeq > 0 {
+		var mentionMsgSeqBytes = make([]byte, 8)
+		wk.endian.PutUint64(mentionMsgSeqBytes, conversation.MentionMsgSeq)
+		w.Set(key.NewConversationColumnKey(uid, id, key.TableConversation.Column.MentionMsgSeq), mentionMsgSeqBytes)
+	}
+	// version
+	if conversation.Version > 0 {
+		var versionBytes = make([]byte, 8)
+		wk.endian.PutUint64(versionBytes, conversation.Version)
+		w.Set(key.NewConversationColumnKey(uid, id, key.TableConversation.Column.Version), versionBytes)
+	}
+	// unreadTracked
+	w.Set(key.NewConversationColumnKey(uid, id, key.TableConversation.Column.UnreadTracked), []byte{wkutil.BoolToUint8(conversation.UnreadTracked)})
 	if conversation.ChannelId == "a4593c39234a4336a799855bfa6b9455" {
 		fmt.Println("conversation.ReadToMsgSeq----------->", conversation.ReadToMsgSeq)
 	}
@@ -610,6 +926,11 @@ func (wk *wukongDB) writeConversationIndex(conversation Conversation, w *Batch)
 		w.Set(key.NewConversationSecondIndexKey(conversation.Uid, key.TableConversation.SecondIndex.UpdatedAt, uint64(conversation.UpdatedAt.UnixNano()), conversation.Id), nil)
 	}
 
+	if conversation.Version > 0 {
+		// version second index
+		w.Set(key.NewConversationSecondIndexKey(conversation.Uid, key.TableConversation.SecondIndex.Version, conversation.Version, conversation.Id), nil)
+	}
+
 	return nil
 }
 
@@ -630,6 +951,11 @@ func (wk *wukongDB) deleteConversationIndex(conversation Conversation, w *Batch)
 		w.Delete(key.NewConversationSecondIndexKey(conversation.Uid, key.TableConversation.SecondIndex.UpdatedAt, uint64(conversation.UpdatedAt.UnixNano()), conversation.Id))
 	}
 
+	if conversation.Version > 0 {
+		// version second index
+		w.Delete(key.NewConversationSecondIndexKey(conversation.Uid, key.TableConversation.SecondIndex.Version, conversation.Version, conversation.Id))
+	}
+
 	return nil
 }
 
@@ -673,6 +999,12 @@ func (wk *wukongDB) iterateConversation(iter *pebble.Iterator, iterFnc func(conv
 			preConversation.UnreadCount = wk.endian.Uint32(iter.Value())
 		case key.TableConversation.Column.ReadedToMsgSeq:
 			preConversation.ReadToMsgSeq = wk.endian.Uint64(iter.Value())
+		case key.TableConversation.Column.MentionMsgSeq:
+			preConversation.MentionMsgSeq = wk.endian.Uint64(iter.Value())
+		case key.TableConversation.Column.Version:
+			preConversation.Version = wk.endian.Uint64(iter.Value())
+		case key.TableConversation.Column.UnreadTracked:
+			preConversation.UnreadTracked = wkutil.Uint8ToBool(iter.Value()[0])
 		case key.TableConversation.Column.CreatedAt:
 			tm := int64(wk.endian.Uint64(iter.Value()))
 			if tm > 0 {
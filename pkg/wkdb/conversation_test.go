@@ -140,6 +140,7 @@ func TestDeleteConversation(t *testing.T) {
 
 	assert.Len(t, conversations2, 1)
 	conversations[1].Id = conversations2[0].Id
+	conversations[1].Version = 2 // 存储时分配的会话版本号
 	assert.Equal(t, conversations[1], conversations2[0])
 }
 
@@ -228,3 +229,182 @@ func BenchmarkAddOrUpdateConversations(b *testing.B) {
 		assert.NoError(b, err)
 	}
 }
+
+func TestConversationMentionMsgSeq(t *testing.T) {
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	uid := "test1"
+	conversation := wkdb.Conversation{
+		Id:            1,
+		Uid:           uid,
+		ChannelId:     "g1",
+		ChannelType:   2,
+		ReadToMsgSeq:  1,
+		MentionMsgSeq: 5,
+	}
+	err = d.AddOrUpdateConversations([]wkdb.Conversation{conversation})
+	assert.NoError(t, err)
+
+	conversation2, err := d.GetConversation(uid, "g1", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), conversation2.MentionMsgSeq)
+
+	// 没有新的@时更新会话不覆盖@位置
+	conversation.MentionMsgSeq = 0
+	conversation.ReadToMsgSeq = 6
+	err = d.AddOrUpdateConversations([]wkdb.Conversation{conversation})
+	assert.NoError(t, err)
+
+	conversation2, err = d.GetConversation(uid, "g1", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), conversation2.ReadToMsgSeq)
+	assert.Equal(t, uint64(5), conversation2.MentionMsgSeq)
+
+	// 编码兼容
+	data, err := conversation2.Marshal()
+	assert.NoError(t, err)
+	conversation3 := wkdb.Conversation{}
+	err = conversation3.Unmarshal(data)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), conversation3.MentionMsgSeq)
+}
+
+func TestConversationDelta(t *testing.T) {
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	uid := "test1"
+	conversations := []wkdb.Conversation{
+		{Id: 1, Uid: uid, ChannelId: "g1", ChannelType: 2},
+		{Id: 2, Uid: uid, ChannelId: "g2", ChannelType: 2},
+		{Id: 3, Uid: uid, ChannelId: "g3", ChannelType: 2},
+	}
+	err = d.AddOrUpdateConversations(conversations)
+	assert.NoError(t, err)
+
+	version, err := d.GetConversationVersion(uid)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), version)
+
+	// 分页
+	delta, err := d.GetConversationDelta(uid, wkdb.ConversationTypeChat, 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(delta.Conversations))
+	assert.Equal(t, "g1", delta.Conversations[0].ChannelId)
+	assert.Equal(t, "g2", delta.Conversations[1].ChannelId)
+	assert.Equal(t, uint64(2), delta.Version)
+	assert.True(t, delta.More)
+
+	delta, err = d.GetConversationDelta(uid, wkdb.ConversationTypeChat, delta.Version, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(delta.Conversations))
+	assert.Equal(t, "g3", delta.Conversations[0].ChannelId)
+	assert.Equal(t, uint64(3), delta.Version)
+	assert.False(t, delta.More)
+
+	// 更新g1，删除g2
+	conversations[0].ReadToMsgSeq = 10
+	err = d.AddOrUpdateConversationsWithUser(uid, conversations[:1])
+	assert.NoError(t, err)
+	err = d.DeleteConversation(uid, "g2", 2)
+	assert.NoError(t, err)
+
+	delta, err = d.GetConversationDelta(uid, wkdb.ConversationTypeChat, 3, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(delta.Conversations))
+	assert.Equal(t, "g1", delta.Conversations[0].ChannelId)
+	assert.Equal(t, uint64(4), delta.Conversations[0].Version)
+	assert.Equal(t, uint64(10), delta.Conversations[0].ReadToMsgSeq)
+	assert.Equal(t, 1, len(delta.Tombstones))
+	assert.Equal(t, "g2", delta.Tombstones[0].ChannelId)
+	assert.Equal(t, uint64(5), delta.Tombstones[0].Version)
+	assert.Equal(t, uint64(5), delta.Version)
+	assert.False(t, delta.More)
+
+	// g1旧版本的索引已删除
+	delta, err = d.GetConversationDelta(uid, wkdb.ConversationTypeChat, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(delta.Conversations))
+	assert.Equal(t, "g3", delta.Conversations[0].ChannelId)
+	assert.Equal(t, "g1", delta.Conversations[1].ChannelId)
+	assert.Equal(t, 1, len(delta.Tombstones))
+}
+
+func TestConversationTombstoneCompact(t *testing.T) {
+	d := newTestDBWithOptions(t.TempDir(), wkdb.WithConversationTombstoneRetain(2))
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	uid := "test1"
+	err = d.AddOrUpdateConversations([]wkdb.Conversation{
+		{Id: 1, Uid: uid, ChannelId: "g1", ChannelType: 2},
+		{Id: 2, Uid: uid, ChannelId: "g2", ChannelType: 2},
+		{Id: 3, Uid: uid, ChannelId: "g3", ChannelType: 2},
+	})
+	assert.NoError(t, err)
+
+	// 版本号4、5、6为删除记录，只保留最近2个版本，版本号4的删除记录被清理
+	assert.NoError(t, d.DeleteConversation(uid, "g1", 2))
+	assert.NoError(t, d.DeleteConversation(uid, "g2", 2))
+	assert.NoError(t, d.DeleteConversation(uid, "g3", 2))
+
+	delta, err := d.GetConversationDelta(uid, wkdb.ConversationTypeChat, 4, 0)
+	assert.NoError(t, err)
+	assert.False(t, delta.Reset)
+	assert.Equal(t, 2, len(delta.Tombstones))
+
+	// 客户端版本号太旧，需要从头同步
+	delta, err = d.GetConversationDelta(uid, wkdb.ConversationTypeChat, 3, 0)
+	assert.NoError(t, err)
+	assert.True(t, delta.Reset)
+	assert.Equal(t, 0, len(delta.Conversations))
+	assert.Equal(t, 2, len(delta.Tombstones))
+	assert.Equal(t, uint64(6), delta.Version)
+}
+
+func TestUpdateConversationIfSeqGreaterAsyncKeepVersion(t *testing.T) {
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	uid := "test1"
+	err = d.AddOrUpdateConversations([]wkdb.Conversation{
+		{Id: 1, Uid: uid, ChannelId: "g1", ChannelType: 2},
+	})
+	assert.NoError(t, err)
+
+	// 本地更新已读位置不分配版本号
+	err = d.UpdateConversationIfSeqGreaterAsync(uid, "g1", 2, 10)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		conversation, err := d.GetConversation(uid, "g1", 2)
+		return err == nil && conversation.ReadToMsgSeq == 10
+	}, time.Second*5, time.Millisecond*10)
+
+	version, err := d.GetConversationVersion(uid)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), version)
+}
@@ -1,6 +1,10 @@
 package wkdb
 
-import "github.com/WuKongIM/WuKongIM/pkg/cluster/reactor"
+import (
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/cluster/reactor"
+)
 
 type DB interface {
 	Open() error
@@ -31,6 +35,16 @@ type DB interface {
 	StreamDB
 	// 测试机
 	TesterDB
+	// 审计日志
+	AuditLogDB
+	// 端到端加密的公钥目录
+	E2EEKeyDB
+	// 消息内容的静态加密
+	EncryptionDB
+	// webhook死信
+	WebhookDeadLetterDB
+	// 事件流投递位置
+	EventSinkCheckpointDB
 }
 
 type MessageDB interface {
@@ -76,6 +90,9 @@ type MessageDB interface {
 	// GetMessagesOfNotifyQueue 获取通知队列的消息
 	GetMessagesOfNotifyQueue(count int) ([]Message, error)
 
+	// GetMessagesOfNotifyQueueWithSkip 获取通知队列的消息，跳过skip返回true的消息
+	GetMessagesOfNotifyQueueWithSkip(count int, skip func(messageId int64) bool) ([]Message, error)
+
 	// RemoveMessagesOfNotifyQueue 移除通知队列的消息
 	RemoveMessagesOfNotifyQueue(messageIDs []int64) error
 
@@ -224,6 +241,12 @@ type ConversationDB interface {
 	// GetConversation 获取指定用户的指定会话
 	GetConversation(uid string, channelId string, channelType uint8) (Conversation, error)
 
+	// GetConversationVersion 获取指定用户当前最大的会话版本号
+	GetConversationVersion(uid string) (uint64, error)
+
+	// GetConversationDelta 获取指定用户版本号大于version的会话变更（包括删除记录），按版本号升序最多返回limit条
+	GetConversationDelta(uid string, tp ConversationType, version uint64, limit int) (ConversationDelta, error)
+
 	// GetChannelConversationLocalUsers 获取频道的在本节点的最近会话的用户uid集合
 	GetChannelConversationLocalUsers(channelId string, channelType uint8) ([]string, error)
 
@@ -393,6 +416,71 @@ type TesterDB interface {
 	RemoveTester(no string) error
 }
 
+type AuditLogDB interface {
+	// AppendAuditLog 追加审计日志（只追加，不支持修改）
+	AppendAuditLog(log AuditLog) error
+
+	// SearchAuditLogs 查询审计日志，按时间倒序返回
+	SearchAuditLogs(req AuditLogSearchReq) ([]AuditLog, error)
+
+	// RemoveAuditLogsBefore 移除指定时间之前的审计日志
+	RemoveAuditLogsBefore(t time.Time) error
+}
+
+// WebhookDeadLetterDB webhook推送失败的事件（死信），保存在推送的节点上
+type WebhookDeadLetterDB interface {
+	// AddWebhookDeadLetter 添加死信
+	AddWebhookDeadLetter(deadLetter WebhookDeadLetter) error
+
+	// GetWebhookDeadLetters 获取死信，按时间从旧到新返回大于offsetId的死信
+	GetWebhookDeadLetters(offsetId uint64, limit int) ([]WebhookDeadLetter, error)
+
+	// GetWebhookDeadLetter 获取指定的死信，不存在返回ErrNotFound
+	GetWebhookDeadLetter(id uint64) (WebhookDeadLetter, error)
+
+	// RemoveWebhookDeadLetters 移除指定的死信
+	RemoveWebhookDeadLetters(ids []uint64) error
+
+	// PurgeWebhookDeadLetters 清空所有死信
+	PurgeWebhookDeadLetters() error
+}
+
+// EventSinkCheckpointDB 事件流每个频道已投递到的位置（消息序号），保存在投递的节点上
+type EventSinkCheckpointDB interface {
+	// GetEventSinkCheckpoint 获取频道已投递到的消息序号，没有投递过返回0
+	GetEventSinkCheckpoint(sink string, channelId string, channelType uint8) (uint64, error)
+
+	// SetEventSinkCheckpoint 保存频道已投递到的消息序号
+	SetEventSinkCheckpoint(sink string, channelId string, channelType uint8, messageSeq uint64) error
+}
+
+// E2EEKeyDB 端到端加密的公钥目录，服务端只保存客户端上传的公钥，不参与加解密
+type E2EEKeyDB interface {
+	// SaveE2EEKeyBundle 保存设备的公钥包（整体覆盖）
+	SaveE2EEKeyBundle(bundle E2EEKeyBundle) error
+
+	// GetE2EEKeyBundle 获取设备的公钥包
+	GetE2EEKeyBundle(uid string, deviceId string) (E2EEKeyBundle, error)
+
+	// GetE2EEKeyBundles 获取用户所有设备的公钥包
+	GetE2EEKeyBundles(uid string) ([]E2EEKeyBundle, error)
+
+	// RemoveE2EEOneTimePrekey 移除设备的一次性预共享公钥（被取走后不能再次使用）
+	RemoveE2EEOneTimePrekey(uid string, deviceId string, prekeyId uint32) error
+
+	// DeleteE2EEKeyBundle 删除设备的公钥包
+	DeleteE2EEKeyBundle(uid string, deviceId string) error
+}
+
+// EncryptionDB 消息内容的静态加密（信封加密）
+type EncryptionDB interface {
+	// RotateEncryptionKey 生成新的数据密钥用于加密新消息，后台任务会把旧密钥加密的消息重新加密，返回新的密钥id
+	RotateEncryptionKey() (uint32, error)
+
+	// EncryptionStatus 静态加密的状态
+	EncryptionStatus() EncryptionStatus
+}
+
 type MessageSearchReq struct {
 	MessageId        int64
 	FromUid          string // 发送者uid
@@ -460,3 +548,14 @@ type ChannelClusterConfigSearchReq struct {
 	Pre             bool   // 是否向前搜索
 
 }
+
+type AuditLogSearchReq struct {
+	Actor     string // 操作者
+	ApiKeyId  string // 访问密钥id
+	Path      string // 请求路径前缀
+	Target    string // 操作对象
+	StartTime int64  // 开始时间（包含，单位秒）
+	EndTime   int64  // 结束时间（不包含，单位秒）
+	OffsetId  uint64 // 偏移的日志id，返回小于此id的日志
+	Limit     int    // 限制查询数量
+}
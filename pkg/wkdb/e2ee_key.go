@@ -0,0 +1,94 @@
+package wkdb
+
+import (
+	"math"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb/key"
+	"github.com/cockroachdb/pebble"
+)
+
+// SaveE2EEKeyBundle 保存设备的公钥包
+func (wk *wukongDB) SaveE2EEKeyBundle(bundle E2EEKeyBundle) error {
+	if bundle.UpdatedAt == 0 {
+		bundle.UpdatedAt = time.Now().Unix()
+	}
+	data, err := bundle.Marshal()
+	if err != nil {
+		return err
+	}
+	db := wk.shardDB(bundle.Uid)
+	return db.Set(wk.e2eeKeyBundleKey(bundle.Uid, bundle.DeviceId), data, wk.sync)
+}
+
+// GetE2EEKeyBundle 获取设备的公钥包
+func (wk *wukongDB) GetE2EEKeyBundle(uid string, deviceId string) (E2EEKeyBundle, error) {
+	db := wk.shardDB(uid)
+	data, closer, err := db.Get(wk.e2eeKeyBundleKey(uid, deviceId))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return E2EEKeyBundle{}, ErrNotFound
+		}
+		return E2EEKeyBundle{}, err
+	}
+	defer closer.Close()
+
+	var bundle E2EEKeyBundle
+	if err = bundle.Unmarshal(data); err != nil {
+		return E2EEKeyBundle{}, err
+	}
+	if bundle.Uid != uid || bundle.DeviceId != deviceId { // hash冲突
+		return E2EEKeyBundle{}, ErrNotFound
+	}
+	return bundle, nil
+}
+
+// GetE2EEKeyBundles 获取用户所有设备的公钥包
+func (wk *wukongDB) GetE2EEKeyBundles(uid string) ([]E2EEKeyBundle, error) {
+	uidHash := key.HashWithString(uid)
+	iter := wk.shardDB(uid).NewIter(&pebble.IterOptions{
+		LowerBound: key.NewE2EEKeyBundleKey(uidHash, 0),
+		UpperBound: key.NewE2EEKeyBundleKey(uidHash, math.MaxUint64),
+	})
+	defer iter.Close()
+
+	var bundles []E2EEKeyBundle
+	for iter.First(); iter.Valid(); iter.Next() {
+		var bundle E2EEKeyBundle
+		if err := bundle.Unmarshal(iter.Value()); err != nil {
+			return nil, err
+		}
+		if bundle.Uid != uid {
+			continue
+		}
+		bundles = append(bundles, bundle)
+	}
+	return bundles, nil
+}
+
+// RemoveE2EEOneTimePrekey 移除设备的一次性预共享公钥
+func (wk *wukongDB) RemoveE2EEOneTimePrekey(uid string, deviceId string, prekeyId uint32) error {
+	bundle, err := wk.GetE2EEKeyBundle(uid, deviceId)
+	if err != nil {
+		if err == ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	for i, prekey := range bundle.OneTimePrekeys {
+		if prekey.Id == prekeyId {
+			bundle.OneTimePrekeys = append(bundle.OneTimePrekeys[:i], bundle.OneTimePrekeys[i+1:]...)
+			return wk.SaveE2EEKeyBundle(bundle)
+		}
+	}
+	return nil
+}
+
+// DeleteE2EEKeyBundle 删除设备的公钥包
+func (wk *wukongDB) DeleteE2EEKeyBundle(uid string, deviceId string) error {
+	return wk.shardDB(uid).Delete(wk.e2eeKeyBundleKey(uid, deviceId), wk.sync)
+}
+
+func (wk *wukongDB) e2eeKeyBundleKey(uid string, deviceId string) []byte {
+	return key.NewE2EEKeyBundleKey(key.HashWithString(uid), key.HashWithString(deviceId))
+}
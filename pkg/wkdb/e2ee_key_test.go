@@ -0,0 +1,74 @@
+package wkdb_test
+
+import (
+	"testing"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestE2EEKeyBundle(t *testing.T) {
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	bundle := wkdb.E2EEKeyBundle{
+		Uid:                   "u1",
+		DeviceId:              "d1",
+		IdentityKey:           "identityKey",
+		SignedPrekeyId:        1,
+		SignedPrekey:          "signedPrekey",
+		SignedPrekeySignature: "signature",
+		OneTimePrekeys: []wkdb.E2EEPrekey{
+			{Id: 1, Key: "prekey1"},
+			{Id: 2, Key: "prekey2"},
+		},
+	}
+	err = d.SaveE2EEKeyBundle(bundle)
+	assert.NoError(t, err)
+	err = d.SaveE2EEKeyBundle(wkdb.E2EEKeyBundle{Uid: "u1", DeviceId: "d2", IdentityKey: "identityKey2"})
+	assert.NoError(t, err)
+	err = d.SaveE2EEKeyBundle(wkdb.E2EEKeyBundle{Uid: "u2", DeviceId: "d1", IdentityKey: "identityKey3"})
+	assert.NoError(t, err)
+
+	t.Run("GetE2EEKeyBundle", func(t *testing.T) {
+		result, err := d.GetE2EEKeyBundle("u1", "d1")
+		assert.NoError(t, err)
+		assert.Equal(t, bundle.IdentityKey, result.IdentityKey)
+		assert.Equal(t, bundle.SignedPrekeySignature, result.SignedPrekeySignature)
+		assert.Equal(t, bundle.OneTimePrekeys, result.OneTimePrekeys)
+		assert.NotZero(t, result.UpdatedAt)
+
+		_, err = d.GetE2EEKeyBundle("u1", "d3")
+		assert.Equal(t, wkdb.ErrNotFound, err)
+	})
+
+	t.Run("GetE2EEKeyBundles", func(t *testing.T) {
+		result, err := d.GetE2EEKeyBundles("u1")
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+	})
+
+	t.Run("RemoveE2EEOneTimePrekey", func(t *testing.T) {
+		err := d.RemoveE2EEOneTimePrekey("u1", "d1", 1)
+		assert.NoError(t, err)
+
+		result, err := d.GetE2EEKeyBundle("u1", "d1")
+		assert.NoError(t, err)
+		assert.Equal(t, []wkdb.E2EEPrekey{{Id: 2, Key: "prekey2"}}, result.OneTimePrekeys)
+	})
+
+	t.Run("DeleteE2EEKeyBundle", func(t *testing.T) {
+		err := d.DeleteE2EEKeyBundle("u1", "d2")
+		assert.NoError(t, err)
+
+		result, err := d.GetE2EEKeyBundles("u1")
+		assert.NoError(t, err)
+		assert.Len(t, result, 1)
+	})
+}
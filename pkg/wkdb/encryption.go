@@ -0,0 +1,471 @@
+package wkdb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb/key"
+	"github.com/cockroachdb/pebble"
+	"go.uber.org/zap"
+)
+
+// 每批重新加密的消息数量
+const reencryptBatchSize = 1000
+
+// 记录重新加密进度的密钥id（真正的数据密钥id从1开始）
+const reencryptMarkerKeyId = 0
+
+var ErrEncryptionKeyNotFound = errors.New("encryption key not found")
+
+// 整体加密的值的首字节，通知队列和webhook死信的值（消息编码首字节为协议版本，死信首字节为id的最高字节）都不会以此开头
+const encryptedValueFlag = 0xFF
+
+// KeyProvider 主密钥（KEK）提供者
+// 加密范围：消息内容、消息通知队列（webhook待推送的消息）和webhook死信，其余数据（会话、频道、订阅者等元数据）不加密
+// 消息内容使用数据密钥（DEK）加密，数据密钥经主密钥加密后保存在数据库中（信封加密），
+// 主密钥可以来自本地密钥文件（NewFileKeyProvider），也可以对接KMS等外部密钥服务
+type KeyProvider interface {
+	// WrapKey 使用主密钥加密数据密钥
+	WrapKey(dataKey []byte) ([]byte, error)
+	// UnwrapKey 使用主密钥解密数据密钥
+	UnwrapKey(wrappedKey []byte) ([]byte, error)
+}
+
+// EncryptionStatus 静态加密的状态
+type EncryptionStatus struct {
+	On               bool      `json:"on"`                 // 是否开启
+	ActiveKeyId      uint32    `json:"active_key_id"`      // 当前加密新消息的数据密钥id
+	KeyIds           []uint32  `json:"key_ids"`            // 所有的数据密钥id
+	ReencryptedKeyId uint32    `json:"reencrypted_key_id"` // 已经完成重新加密的数据密钥id，等于ActiveKeyId表示所有消息都使用当前密钥加密
+	LastReencryptAt  time.Time `json:"last_reencrypt_at"`  // 最后一次完成重新加密的时间
+}
+
+// aesKeyProvider 使用AES-GCM加密数据密钥
+type aesKeyProvider struct {
+	aead cipher.AEAD
+}
+
+// NewAESKeyProvider 使用指定的主密钥（16、24或32字节）
+func NewAESKeyProvider(masterKey []byte) (KeyProvider, error) {
+	aead, err := newAESGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	return &aesKeyProvider{aead: aead}, nil
+}
+
+// NewFileKeyProvider 从本地密钥文件读取主密钥，文件内容为base64编码的16、24或32字节密钥
+func NewFileKeyProvider(path string) (KeyProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	masterKey, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("keyfile: invalid base64 key: %w", err)
+	}
+	return NewAESKeyProvider(masterKey)
+}
+
+func (a *aesKeyProvider) WrapKey(dataKey []byte) ([]byte, error) {
+	return sealAESGCM(a.aead, dataKey, nil)
+}
+
+func (a *aesKeyProvider) UnwrapKey(wrappedKey []byte) ([]byte, error) {
+	return openAESGCM(a.aead, wrappedKey, nil)
+}
+
+// dataKeyRing 解密后的数据密钥
+type dataKeyRing struct {
+	mu              sync.RWMutex
+	keys            map[uint32]cipher.AEAD
+	activeId        uint32
+	activeCreatedAt time.Time
+	reencryptedId   uint32
+	lastReencryptAt time.Time
+}
+
+func (r *dataKeyRing) active() (uint32, cipher.AEAD) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.activeId, r.keys[r.activeId]
+}
+
+func (r *dataKeyRing) get(keyId uint32) cipher.AEAD {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.keys[keyId]
+}
+
+// openEncryption 加载数据密钥，第一次开启时生成第一个数据密钥
+func (wk *wukongDB) openEncryption() error {
+	if wk.opts.EncryptionKeyProvider == nil {
+		return nil
+	}
+	wk.keyRing = &dataKeyRing{
+		keys: make(map[uint32]cipher.AEAD),
+	}
+	iter := wk.defaultShardDB().NewIter(&pebble.IterOptions{
+		LowerBound: key.NewEncryptionKeyKey(0),
+		UpperBound: key.NewEncryptionKeyKey(math.MaxUint32),
+	})
+	defer iter.Close()
+	for iter.First(); iter.Valid(); iter.Next() {
+		keyId, err := key.ParseEncryptionKeyKey(iter.Key())
+		if err != nil {
+			return err
+		}
+		value := iter.Value()
+		if len(value) < 8 {
+			return fmt.Errorf("encryption key[%d] is invalid", keyId)
+		}
+		if keyId == reencryptMarkerKeyId {
+			wk.keyRing.reencryptedId = wk.endian.Uint32(value)
+			wk.keyRing.lastReencryptAt = time.Unix(int64(wk.endian.Uint32(value[4:])), 0)
+			continue
+		}
+		dataKey, err := wk.opts.EncryptionKeyProvider.UnwrapKey(value[8:])
+		if err != nil {
+			return fmt.Errorf("unwrap encryption key[%d] failed: %w", keyId, err)
+		}
+		aead, err := newAESGCM(dataKey)
+		if err != nil {
+			return err
+		}
+		wk.keyRing.keys[keyId] = aead
+		if keyId > wk.keyRing.activeId {
+			wk.keyRing.activeId = keyId
+			wk.keyRing.activeCreatedAt = time.Unix(int64(wk.endian.Uint64(value)), 0)
+		}
+	}
+	if wk.keyRing.activeId == 0 {
+		if _, err := wk.RotateEncryptionKey(); err != nil {
+			return err
+		}
+	}
+	wk.reencryptC = make(chan struct{}, 1)
+	wk.reencryptWg.Add(1)
+	go wk.reencryptLoop()
+	return nil
+}
+
+func (wk *wukongDB) RotateEncryptionKey() (uint32, error) {
+	if wk.keyRing == nil {
+		return 0, errors.New("encryption is not enabled")
+	}
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return 0, err
+	}
+	aead, err := newAESGCM(dataKey)
+	if err != nil {
+		return 0, err
+	}
+	wrappedKey, err := wk.opts.EncryptionKeyProvider.WrapKey(dataKey)
+	if err != nil {
+		return 0, err
+	}
+
+	wk.keyRing.mu.Lock()
+	defer wk.keyRing.mu.Unlock()
+	keyId := wk.keyRing.activeId + 1
+	createdAt := time.Now()
+	value := make([]byte, 8+len(wrappedKey))
+	wk.endian.PutUint64(value, uint64(createdAt.Unix()))
+	copy(value[8:], wrappedKey)
+	if err = wk.defaultShardDB().Set(key.NewEncryptionKeyKey(keyId), value, wk.sync); err != nil {
+		return 0, err
+	}
+	wk.keyRing.keys[keyId] = aead
+	wk.keyRing.activeId = keyId
+	wk.keyRing.activeCreatedAt = createdAt
+
+	wk.Info("encryption key rotated", zap.Uint32("keyId", keyId))
+	wk.triggerReencrypt()
+	return keyId, nil
+}
+
+func (wk *wukongDB) EncryptionStatus() EncryptionStatus {
+	if wk.keyRing == nil {
+		return EncryptionStatus{}
+	}
+	wk.keyRing.mu.RLock()
+	defer wk.keyRing.mu.RUnlock()
+	keyIds := make([]uint32, 0, len(wk.keyRing.keys))
+	for keyId := uint32(1); keyId <= wk.keyRing.activeId; keyId++ {
+		if wk.keyRing.keys[keyId] != nil {
+			keyIds = append(keyIds, keyId)
+		}
+	}
+	return EncryptionStatus{
+		On:               true,
+		ActiveKeyId:      wk.keyRing.activeId,
+		KeyIds:           keyIds,
+		ReencryptedKeyId: wk.keyRing.reencryptedId,
+		LastReencryptAt:  wk.keyRing.lastReencryptAt,
+	}
+}
+
+// encryptPayload 使用当前的数据密钥加密payload，未开启加密时keyId为0，payload原样返回
+// primary为消息的主键（频道hash + 消息seq），作为附加数据防止密文被挪到其他消息上
+func (wk *wukongDB) encryptPayload(primary []byte, payload []byte) (uint32, []byte, error) {
+	if wk.keyRing == nil {
+		return 0, payload, nil
+	}
+	keyId, aead := wk.keyRing.active()
+	data, err := sealAESGCM(aead, payload, primary)
+	if err != nil {
+		return 0, nil, err
+	}
+	return keyId, data, nil
+}
+
+// decryptPayload 解密payload，keyId为0表示payload是明文
+func (wk *wukongDB) decryptPayload(primary []byte, keyId uint32, data []byte) ([]byte, error) {
+	if keyId == 0 {
+		return data, nil
+	}
+	if wk.keyRing == nil {
+		return nil, fmt.Errorf("payload is encrypted by key[%d], but encryption is not enabled", keyId)
+	}
+	aead := wk.keyRing.get(keyId)
+	if aead == nil {
+		return nil, fmt.Errorf("%w: %d", ErrEncryptionKeyNotFound, keyId)
+	}
+	return openAESGCM(aead, data, primary)
+}
+
+// encryptValue 使用当前的数据密钥加密整个值，未开启加密时原样返回
+// 格式：encryptedValueFlag + 密钥id(4字节) + nonce + 密文，dbKey作为附加数据
+// 用于消息通知队列和webhook死信这类保存完整消息的临时数据，旧密钥不会删除，所以不需要重新加密
+func (wk *wukongDB) encryptValue(dbKey []byte, value []byte) ([]byte, error) {
+	if wk.keyRing == nil {
+		return value, nil
+	}
+	keyId, aead := wk.keyRing.active()
+	sealed, err := sealAESGCM(aead, value, dbKey)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 5+len(sealed))
+	data[0] = encryptedValueFlag
+	wk.endian.PutUint32(data[1:], keyId)
+	copy(data[5:], sealed)
+	return data, nil
+}
+
+// decryptValue 解密encryptValue加密的值，不是以encryptedValueFlag开头的为明文（开启加密前写入的数据）
+func (wk *wukongDB) decryptValue(dbKey []byte, data []byte) ([]byte, error) {
+	if len(data) == 0 || data[0] != encryptedValueFlag {
+		return data, nil
+	}
+	if len(data) < 5 {
+		return nil, errors.New("encrypted value is invalid")
+	}
+	return wk.decryptPayload(dbKey, wk.endian.Uint32(data[1:]), data[5:])
+}
+
+func (wk *wukongDB) triggerReencrypt() {
+	if wk.reencryptC == nil {
+		return
+	}
+	select {
+	case wk.reencryptC <- struct{}{}:
+	default:
+	}
+}
+
+// reencryptLoop 后台重新加密不是使用当前数据密钥加密的消息（包括开启加密前的明文消息）
+func (wk *wukongDB) reencryptLoop() {
+	defer wk.reencryptWg.Done()
+	var rotateC <-chan time.Time
+	if wk.opts.EncryptionRotateInterval > 0 {
+		checkInterval := wk.opts.EncryptionRotateInterval / 10
+		if checkInterval < time.Minute {
+			checkInterval = time.Minute
+		}
+		tk := time.NewTicker(checkInterval)
+		defer tk.Stop()
+		rotateC = tk.C
+	}
+
+	wk.keyRing.mu.RLock()
+	done := wk.keyRing.reencryptedId == wk.keyRing.activeId
+	wk.keyRing.mu.RUnlock()
+	if !done {
+		wk.triggerReencrypt()
+	}
+
+	for {
+		select {
+		case <-wk.reencryptC:
+			if err := wk.reencryptMessages(); err != nil {
+				wk.Error("reencrypt messages failed", zap.Error(err))
+			}
+		case <-rotateC:
+			wk.keyRing.mu.RLock()
+			expired := time.Since(wk.keyRing.activeCreatedAt) >= wk.opts.EncryptionRotateInterval
+			wk.keyRing.mu.RUnlock()
+			if expired {
+				if _, err := wk.RotateEncryptionKey(); err != nil {
+					wk.Error("rotate encryption key failed", zap.Error(err))
+				}
+			}
+		case <-wk.cancelCtx.Done():
+			return
+		}
+	}
+}
+
+// reencryptMessages 把所有分区里不是使用当前数据密钥加密的消息重新加密
+func (wk *wukongDB) reencryptMessages() error {
+	activeId, _ := wk.keyRing.active()
+	start := time.Now()
+	var total int
+	for _, db := range wk.dbs {
+		lowKey := key.NewMessageColumnKeyWithPrimary([16]byte{}, key.MinColumnKey)
+		for lowKey != nil {
+			select {
+			case <-wk.cancelCtx.Done():
+				return nil
+			default:
+			}
+			var (
+				count int
+				err   error
+			)
+			lowKey, count, err = wk.reencryptMessageBatch(db, lowKey, activeId)
+			if err != nil {
+				return err
+			}
+			total += count
+		}
+	}
+
+	now := time.Now()
+	value := make([]byte, 8)
+	wk.endian.PutUint32(value, activeId)
+	wk.endian.PutUint32(value[4:], uint32(now.Unix()))
+	if err := wk.defaultShardDB().Set(key.NewEncryptionKeyKey(reencryptMarkerKeyId), value, wk.sync); err != nil {
+		return err
+	}
+	wk.keyRing.mu.Lock()
+	wk.keyRing.reencryptedId = activeId
+	wk.keyRing.lastReencryptAt = now
+	wk.keyRing.mu.Unlock()
+
+	wk.Info("reencrypt messages done", zap.Uint32("keyId", activeId), zap.Int("count", total), zap.Duration("cost", time.Since(start)))
+	return nil
+}
+
+// reencryptMessageBatch 从lowKey开始重新加密一批消息，返回下一批的起始key，为nil表示已经处理完
+func (wk *wukongDB) reencryptMessageBatch(db *pebble.DB, lowKey []byte, activeId uint32) ([]byte, int, error) {
+	// 读取和写回期间不能截断消息，否则会把已经删除的消息写回去
+	wk.reencryptMu.Lock()
+	defer wk.reencryptMu.Unlock()
+
+	iter := db.NewIter(&pebble.IterOptions{
+		LowerBound: lowKey,
+		UpperBound: key.NewMessageColumnKeyWithPrimary([16]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, key.MaxColumnKey),
+	})
+	defer iter.Close()
+
+	type encryptedPayload struct {
+		primary [16]byte
+		keyId   uint32
+		payload []byte
+	}
+	var (
+		messages []*encryptedPayload
+		current  *encryptedPayload
+		nextKey  []byte
+	)
+	for iter.First(); iter.Valid(); iter.Next() {
+		var primary [16]byte
+		copy(primary[:], iter.Key()[4:20])
+		if current == nil || current.primary != primary {
+			if len(messages) >= reencryptBatchSize {
+				nextKey = append([]byte(nil), iter.Key()...)
+				break
+			}
+			current = &encryptedPayload{primary: primary}
+			messages = append(messages, current)
+		}
+		_, columnName, err := key.ParseMessageColumnKey(iter.Key())
+		if err != nil {
+			return nil, 0, err
+		}
+		switch columnName {
+		case key.TableMessage.Column.Payload:
+			current.payload = append([]byte(nil), iter.Value()...)
+		case key.TableMessage.Column.PayloadKeyId:
+			current.keyId = wk.endian.Uint32(iter.Value())
+		}
+	}
+
+	batch := db.NewBatch()
+	defer batch.Close()
+	var count int
+	for _, msg := range messages {
+		if msg.keyId == activeId || msg.payload == nil {
+			continue
+		}
+		payload, err := wk.decryptPayload(msg.primary[:], msg.keyId, msg.payload)
+		if err != nil {
+			return nil, 0, err
+		}
+		keyId, data, err := wk.encryptPayload(msg.primary[:], payload)
+		if err != nil {
+			return nil, 0, err
+		}
+		keyIdBytes := make([]byte, 4)
+		wk.endian.PutUint32(keyIdBytes, keyId)
+		if err = batch.Set(key.NewMessageColumnKeyWithPrimary(msg.primary, key.TableMessage.Column.Payload), data, wk.noSync); err != nil {
+			return nil, 0, err
+		}
+		if err = batch.Set(key.NewMessageColumnKeyWithPrimary(msg.primary, key.TableMessage.Column.PayloadKeyId), keyIdBytes, wk.noSync); err != nil {
+			return nil, 0, err
+		}
+		count++
+	}
+	if count > 0 {
+		if err := batch.Commit(wk.sync); err != nil {
+			return nil, 0, err
+		}
+	}
+	return nextKey, count, nil
+}
+
+func newAESGCM(k []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealAESGCM 加密，格式：nonce + 密文
+func sealAESGCM(aead cipher.AEAD, plaintext []byte, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func openAESGCM(aead cipher.AEAD, data []byte, additionalData []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], additionalData)
+}
@@ -0,0 +1,121 @@
+package wkdb_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	wkproto "github.com/WuKongIM/WuKongIMGoProto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryption(t *testing.T) {
+	dir := t.TempDir()
+	channelId := "channel"
+	channelType := uint8(2)
+	newMessages := func(startSeq int, num int) []wkdb.Message {
+		messages := make([]wkdb.Message, 0, num)
+		for i := startSeq; i < startSeq+num; i++ {
+			messages = append(messages, wkdb.Message{
+				RecvPacket: wkproto.RecvPacket{
+					ChannelID:   channelId,
+					ChannelType: channelType,
+					MessageSeq:  uint32(i),
+					Payload:     []byte("hello"),
+				},
+			})
+		}
+		return messages
+	}
+	waitReencrypted := func(d wkdb.DB, keyId uint32) {
+		assert.Eventually(t, func() bool {
+			return d.EncryptionStatus().ReencryptedKeyId == keyId
+		}, time.Second*5, time.Millisecond*10)
+	}
+
+	// 未开启加密时写入的明文消息
+	d := newTestDBWithOptions(dir)
+	assert.NoError(t, d.Open())
+	assert.NoError(t, d.AppendMessages(channelId, channelType, newMessages(1, 10)))
+	assert.False(t, d.EncryptionStatus().On)
+	assert.NoError(t, d.Close())
+
+	// 开启加密后，明文消息被后台任务重新加密
+	provider, err := wkdb.NewAESKeyProvider(bytes.Repeat([]byte{1}, 32))
+	assert.NoError(t, err)
+	d = newTestDBWithOptions(dir, wkdb.WithEncryptionKeyProvider(provider))
+	assert.NoError(t, d.Open())
+	waitReencrypted(d, 1)
+	assert.NoError(t, d.AppendMessages(channelId, channelType, newMessages(11, 10)))
+
+	// 轮换密钥
+	keyId, err := d.RotateEncryptionKey()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), keyId)
+	waitReencrypted(d, 2)
+	assert.Equal(t, []uint32{1, 2}, d.EncryptionStatus().KeyIds)
+
+	messages, err := d.LoadNextRangeMsgs(channelId, channelType, 1, 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 20)
+	for _, m := range messages {
+		assert.Equal(t, []byte("hello"), m.Payload)
+	}
+	assert.NoError(t, d.Close())
+
+	// 没有密钥不能读取加密的消息（包括开启加密前写入的消息）
+	d = newTestDBWithOptions(dir)
+	assert.NoError(t, d.Open())
+	_, err = d.LoadNextRangeMsgs(channelId, channelType, 1, 2, 0)
+	assert.Error(t, err)
+	assert.NoError(t, d.Close())
+
+	// 主密钥不对不能打开
+	wrongProvider, err := wkdb.NewAESKeyProvider(bytes.Repeat([]byte{2}, 32))
+	assert.NoError(t, err)
+	d = newTestDBWithOptions(dir, wkdb.WithEncryptionKeyProvider(wrongProvider))
+	assert.Error(t, d.Open())
+}
+
+func TestEncryptionNotifyQueueAndDeadLetter(t *testing.T) {
+	dir := t.TempDir()
+
+	// 开启加密前写入的明文数据仍然可以读取
+	d := newTestDBWithOptions(dir)
+	assert.NoError(t, d.Open())
+	assert.NoError(t, d.AppendMessageOfNotifyQueue([]wkdb.Message{{RecvPacket: wkproto.RecvPacket{MessageID: 1, Payload: []byte("plain")}}}))
+	assert.NoError(t, d.AddWebhookDeadLetter(wkdb.WebhookDeadLetter{Id: 1, Event: "msg.notify", Data: []byte("plain")}))
+	assert.NoError(t, d.Close())
+
+	provider, err := wkdb.NewAESKeyProvider(bytes.Repeat([]byte{1}, 32))
+	assert.NoError(t, err)
+	d = newTestDBWithOptions(dir, wkdb.WithEncryptionKeyProvider(provider))
+	assert.NoError(t, d.Open())
+	assert.NoError(t, d.AppendMessageOfNotifyQueue([]wkdb.Message{{RecvPacket: wkproto.RecvPacket{MessageID: 2, Payload: []byte("secret")}}}))
+	assert.NoError(t, d.AddWebhookDeadLetter(wkdb.WebhookDeadLetter{Id: 2, Event: "msg.notify", Data: []byte("secret")}))
+
+	messages, err := d.GetMessagesOfNotifyQueue(10)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, []byte("plain"), messages[0].Payload)
+	assert.Equal(t, []byte("secret"), messages[1].Payload)
+
+	deadLetters, err := d.GetWebhookDeadLetters(0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, deadLetters, 2)
+	assert.Equal(t, []byte("secret"), deadLetters[1].Data)
+	deadLetter, err := d.GetWebhookDeadLetter(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("secret"), deadLetter.Data)
+	assert.NoError(t, d.Close())
+
+	// 没有密钥不能读取加密后写入的数据
+	d = newTestDBWithOptions(dir)
+	assert.NoError(t, d.Open())
+	_, err = d.GetMessagesOfNotifyQueue(10)
+	assert.Error(t, err)
+	_, err = d.GetWebhookDeadLetter(2)
+	assert.Error(t, err)
+	assert.NoError(t, d.Close())
+}
@@ -0,0 +1,35 @@
+package wkdb
+
+import (
+	"encoding/binary"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb/key"
+	"github.com/cockroachdb/pebble"
+)
+
+// GetEventSinkCheckpoint 获取频道已投递到的消息序号
+func (wk *wukongDB) GetEventSinkCheckpoint(sink string, channelId string, channelType uint8) (uint64, error) {
+	data, closer, err := wk.channelDb(channelId, channelType).Get(wk.eventSinkCheckpointKey(sink, channelId, channelType))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer closer.Close()
+	if len(data) < 8 {
+		return 0, nil
+	}
+	return binary.BigEndian.Uint64(data), nil
+}
+
+// SetEventSinkCheckpoint 保存频道已投递到的消息序号
+func (wk *wukongDB) SetEventSinkCheckpoint(sink string, channelId string, channelType uint8, messageSeq uint64) error {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data, messageSeq)
+	return wk.channelDb(channelId, channelType).Set(wk.eventSinkCheckpointKey(sink, channelId, channelType), data, wk.sync)
+}
+
+func (wk *wukongDB) eventSinkCheckpointKey(sink string, channelId string, channelType uint8) []byte {
+	return key.NewEventSinkCheckpointKey(key.HashWithString(sink), key.ChannelToNum(channelId, channelType))
+}
@@ -0,0 +1,35 @@
+package wkdb_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSinkCheckpoint(t *testing.T) {
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	seq, err := d.GetEventSinkCheckpoint("file", "g1", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), seq)
+
+	err = d.SetEventSinkCheckpoint("file", "g1", 2, 10)
+	assert.NoError(t, err)
+	err = d.SetEventSinkCheckpoint("http", "g1", 2, 5)
+	assert.NoError(t, err)
+
+	seq, err = d.GetEventSinkCheckpoint("file", "g1", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(10), seq)
+
+	seq, err = d.GetEventSinkCheckpoint("http", "g1", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), seq)
+}
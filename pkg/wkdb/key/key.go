@@ -770,6 +770,11 @@ func NewMessageNotifyQueueKey(messageId uint64) []byte {
 	return key
 }
 
+// ParseMessageNotifyQueueKey 解析通知队列的key，返回消息id
+func ParseMessageNotifyQueueKey(key []byte) int64 {
+	return int64(binary.BigEndian.Uint64(key[4:]))
+}
+
 // ---------------------- ChannelClusterConfig ----------------------
 
 func NewChannelClusterConfigColumnKey(primaryKey uint64, columnName [2]byte) []byte {
@@ -1002,3 +1007,108 @@ func ParseTesterColumnKey(key []byte) (id uint64, columnName [2]byte, err error)
 	columnName[1] = key[13]
 	return
 }
+
+// ---------------------- AuditLog ----------------------
+
+func NewAuditLogKey(id uint64) []byte {
+	key := make([]byte, TableAuditLog.Size)
+	key[0] = TableAuditLog.Id[0]
+	key[1] = TableAuditLog.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], id)
+	return key
+}
+
+// ---------------------- E2EEKeyBundle ----------------------
+
+func NewE2EEKeyBundleKey(uidHash uint64, deviceIdHash uint64) []byte {
+	key := make([]byte, TableE2EEKeyBundle.Size)
+	key[0] = TableE2EEKeyBundle.Id[0]
+	key[1] = TableE2EEKeyBundle.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], uidHash)
+	binary.BigEndian.PutUint64(key[12:], deviceIdHash)
+	return key
+}
+
+// ---------------------- EncryptionKey ----------------------
+
+func NewEncryptionKeyKey(keyId uint32) []byte {
+	key := make([]byte, TableEncryptionKey.Size)
+	key[0] = TableEncryptionKey.Id[0]
+	key[1] = TableEncryptionKey.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint32(key[4:], keyId)
+	return key
+}
+
+func ParseEncryptionKeyKey(key []byte) (keyId uint32, err error) {
+	if len(key) != TableEncryptionKey.Size {
+		err = fmt.Errorf("encryptionKey: invalid key length, keyLen: %d", len(key))
+		return
+	}
+	keyId = binary.BigEndian.Uint32(key[4:])
+	return
+}
+
+// ---------------------- WebhookDeadLetter ----------------------
+
+func NewWebhookDeadLetterKey(id uint64) []byte {
+	key := make([]byte, TableWebhookDeadLetter.Size)
+	key[0] = TableWebhookDeadLetter.Id[0]
+	key[1] = TableWebhookDeadLetter.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], id)
+	return key
+}
+
+// ---------------------- EventSinkCheckpoint ----------------------
+
+func NewEventSinkCheckpointKey(sinkHash uint64, channelHash uint64) []byte {
+	key := make([]byte, TableEventSinkCheckpoint.Size)
+	key[0] = TableEventSinkCheckpoint.Id[0]
+	key[1] = TableEventSinkCheckpoint.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], sinkHash)
+	binary.BigEndian.PutUint64(key[12:], channelHash)
+	return key
+}
+
+// ---------------------- ConversationVersion ----------------------
+
+func NewConversationVersionKey(uid string) []byte {
+	key := make([]byte, TableConversationVersion.Size)
+	key[0] = TableConversationVersion.Id[0]
+	key[1] = TableConversationVersion.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], HashWithString(uid))
+	return key
+}
+
+// ---------------------- ConversationTombstone ----------------------
+
+func NewConversationTombstoneKey(uid string, version uint64) []byte {
+	key := make([]byte, TableConversationTombstone.Size)
+	key[0] = TableConversationTombstone.Id[0]
+	key[1] = TableConversationTombstone.Id[1]
+	key[2] = dataTypeTable
+	key[3] = 0
+	binary.BigEndian.PutUint64(key[4:], HashWithString(uid))
+	binary.BigEndian.PutUint64(key[12:], version)
+	return key
+}
+
+func ParseConversationTombstoneKey(key []byte) (version uint64, err error) {
+	if len(key) != TableConversationTombstone.Size {
+		err = fmt.Errorf("conversationTombstone: invalid key length, keyLen: %d", len(key))
+		return
+	}
+	version = binary.BigEndian.Uint64(key[12:])
+	return
+}
@@ -26,19 +26,20 @@ var TableMessage = struct {
 	IndexSize       int
 	SecondIndexSize int
 	Column          struct {
-		Header      [2]byte
-		Setting     [2]byte
-		Expire      [2]byte
-		MessageId   [2]byte
-		MessageSeq  [2]byte
-		ClientMsgNo [2]byte
-		Timestamp   [2]byte
-		ChannelId   [2]byte
-		ChannelType [2]byte
-		Topic       [2]byte
-		FromUid     [2]byte
-		Payload     [2]byte
-		Term        [2]byte
+		Header       [2]byte
+		Setting      [2]byte
+		Expire       [2]byte
+		MessageId    [2]byte
+		MessageSeq   [2]byte
+		ClientMsgNo  [2]byte
+		Timestamp    [2]byte
+		ChannelId    [2]byte
+		ChannelType  [2]byte
+		Topic        [2]byte
+		FromUid      [2]byte
+		Payload      [2]byte
+		Term         [2]byte
+		PayloadKeyId [2]byte // 加密payload使用的数据密钥id，没有此列表示payload是明文
 	}
 	Index struct {
 		MessageId [2]byte
@@ -55,33 +56,35 @@ var TableMessage = struct {
 	IndexSize:       2 + 2 + 2 + 8,      // tableId + dataType + indexName + columnHash
 	SecondIndexSize: 2 + 2 + 2 + 8 + 16, // tableId + dataType + secondIndexName + columnValue + primaryKey
 	Column: struct {
-		Header      [2]byte
-		Setting     [2]byte
-		Expire      [2]byte
-		MessageId   [2]byte
-		MessageSeq  [2]byte
-		ClientMsgNo [2]byte
-		Timestamp   [2]byte
-		ChannelId   [2]byte
-		ChannelType [2]byte
-		Topic       [2]byte
-		FromUid     [2]byte
-		Payload     [2]byte
-		Term        [2]byte
-	}{
-		Header:      [2]byte{0x01, 0x01},
-		Setting:     [2]byte{0x01, 0x02},
-		Expire:      [2]byte{0x01, 0x03},
-		MessageId:   [2]byte{0x01, 0x04},
-		MessageSeq:  [2]byte{0x01, 0x05},
-		ClientMsgNo: [2]byte{0x01, 0x06},
-		Timestamp:   [2]byte{0x01, 0x07},
-		ChannelId:   [2]byte{0x01, 0x08},
-		ChannelType: [2]byte{0x01, 0x09},
-		Topic:       [2]byte{0x01, 0x0A},
-		FromUid:     [2]byte{0x01, 0x0B},
-		Payload:     [2]byte{0x01, 0x0C},
-		Term:        [2]byte{0x01, 0x0D},
+		Header       [2]byte
+		Setting      [2]byte
+		Expire       [2]byte
+		MessageId    [2]byte
+		MessageSeq   [2]byte
+		ClientMsgNo  [2]byte
+		Timestamp    [2]byte
+		ChannelId    [2]byte
+		ChannelType  [2]byte
+		Topic        [2]byte
+		FromUid      [2]byte
+		Payload      [2]byte
+		Term         [2]byte
+		PayloadKeyId [2]byte
+	}{
+		Header:       [2]byte{0x01, 0x01},
+		Setting:      [2]byte{0x01, 0x02},
+		Expire:       [2]byte{0x01, 0x03},
+		MessageId:    [2]byte{0x01, 0x04},
+		MessageSeq:   [2]byte{0x01, 0x05},
+		ClientMsgNo:  [2]byte{0x01, 0x06},
+		Timestamp:    [2]byte{0x01, 0x07},
+		ChannelId:    [2]byte{0x01, 0x08},
+		ChannelType:  [2]byte{0x01, 0x09},
+		Topic:        [2]byte{0x01, 0x0A},
+		FromUid:      [2]byte{0x01, 0x0B},
+		Payload:      [2]byte{0x01, 0x0C},
+		Term:         [2]byte{0x01, 0x0D},
+		PayloadKeyId: [2]byte{0x01, 0x0E},
 	},
 	Index: struct {
 		MessageId [2]byte
@@ -313,6 +316,7 @@ var TableChannelInfo = struct {
 		DenylistCount   [2]byte // 黑名单数量
 		CreatedAt       [2]byte
 		UpdatedAt       [2]byte
+		Webhook         [2]byte // 频道的webhook地址
 	}
 	Index struct {
 		Channel [2]byte
@@ -343,6 +347,7 @@ var TableChannelInfo = struct {
 		DenylistCount   [2]byte
 		CreatedAt       [2]byte
 		UpdatedAt       [2]byte
+		Webhook         [2]byte
 	}{
 		Id:              [2]byte{0x06, 0x01},
 		ChannelId:       [2]byte{0x06, 0x02},
@@ -355,6 +360,7 @@ var TableChannelInfo = struct {
 		DenylistCount:   [2]byte{0x06, 0x09},
 		CreatedAt:       [2]byte{0x06, 0x0A},
 		UpdatedAt:       [2]byte{0x06, 0x0B},
+		Webhook:         [2]byte{0x06, 0x0C},
 	},
 	Index: struct {
 		Channel [2]byte
@@ -490,6 +496,9 @@ var TableConversation = struct {
 		ReadedToMsgSeq [2]byte
 		CreatedAt      [2]byte
 		UpdatedAt      [2]byte
+		MentionMsgSeq  [2]byte
+		Version        [2]byte
+		UnreadTracked  [2]byte
 	}
 	Index struct {
 		Channel [2]byte
@@ -498,6 +507,7 @@ var TableConversation = struct {
 		Type      [2]byte
 		CreatedAt [2]byte
 		UpdatedAt [2]byte
+		Version   [2]byte
 	}
 }{
 	Id:              [2]byte{0x09, 0x01},
@@ -513,6 +523,9 @@ var TableConversation = struct {
 		ReadedToMsgSeq [2]byte
 		CreatedAt      [2]byte
 		UpdatedAt      [2]byte
+		MentionMsgSeq  [2]byte
+		Version        [2]byte
+		UnreadTracked  [2]byte
 	}{
 		Uid:            [2]byte{0x09, 0x01},
 		ChannelId:      [2]byte{0x09, 0x02},
@@ -522,6 +535,9 @@ var TableConversation = struct {
 		ReadedToMsgSeq: [2]byte{0x09, 0x06},
 		CreatedAt:      [2]byte{0x09, 0x07},
 		UpdatedAt:      [2]byte{0x09, 0x08},
+		MentionMsgSeq:  [2]byte{0x09, 0x09},
+		Version:        [2]byte{0x09, 0x0A},
+		UnreadTracked:  [2]byte{0x09, 0x0B},
 	},
 	Index: struct {
 		Channel [2]byte
@@ -532,10 +548,12 @@ var TableConversation = struct {
 		Type      [2]byte
 		CreatedAt [2]byte
 		UpdatedAt [2]byte
+		Version   [2]byte
 	}{
 		Type:      [2]byte{0x09, 0x01},
 		CreatedAt: [2]byte{0x09, 0x02},
 		UpdatedAt: [2]byte{0x09, 0x03},
+		Version:   [2]byte{0x09, 0x04},
 	},
 }
 
@@ -795,3 +813,80 @@ var TableTester = struct {
 		UpdatedAt: [2]byte{0x14, 0x04},
 	},
 }
+
+// ======================== TableAuditLog ========================
+
+// 审计日志表，主键为雪花id，按时间顺序追加
+var TableAuditLog = struct {
+	Id   [2]byte
+	Size int
+}{
+	Id:   [2]byte{0x15, 0x01},
+	Size: 2 + 2 + 8, // tableId + dataType  + primaryKey
+}
+
+// ======================== TableE2EEKeyBundle ========================
+
+// 端到端加密的公钥（预共享密钥包）表，主键为 uid hash + 设备id hash
+var TableE2EEKeyBundle = struct {
+	Id   [2]byte
+	Size int
+}{
+	Id:   [2]byte{0x16, 0x01},
+	Size: 2 + 2 + 8 + 8, // tableId + dataType  + uidHash + deviceIdHash
+}
+
+// ======================== TableEncryptionKey ========================
+
+// 静态加密的数据密钥表，值为主密钥加密后的数据密钥，主键为密钥id（只保存在第一个分区）
+var TableEncryptionKey = struct {
+	Id   [2]byte
+	Size int
+}{
+	Id:   [2]byte{0x17, 0x01},
+	Size: 2 + 2 + 4, // tableId + dataType  + keyId
+}
+
+// ======================== TableWebhookDeadLetter ========================
+
+// webhook死信表，推送失败超过最大重试次数的事件，主键为雪花id，按时间顺序追加
+var TableWebhookDeadLetter = struct {
+	Id   [2]byte
+	Size int
+}{
+	Id:   [2]byte{0x18, 0x01},
+	Size: 2 + 2 + 8, // tableId + dataType  + primaryKey
+}
+
+// ======================== TableEventSinkCheckpoint ========================
+
+// 事件流的投递位置表，值为已投递到的消息序号，主键为 sink名称hash + 频道hash
+var TableEventSinkCheckpoint = struct {
+	Id   [2]byte
+	Size int
+}{
+	Id:   [2]byte{0x19, 0x01},
+	Size: 2 + 2 + 8 + 8, // tableId + dataType  + sinkHash + channelHash
+}
+
+// ======================== TableConversationVersion ========================
+
+// 用户最近会话的版本号表，值为用户当前最大的会话版本号，主键为 uid hash
+var TableConversationVersion = struct {
+	Id   [2]byte
+	Size int
+}{
+	Id:   [2]byte{0x1A, 0x01},
+	Size: 2 + 2 + 8, // tableId + dataType  + uidHash
+}
+
+// ======================== TableConversationTombstone ========================
+
+// 最近会话删除记录表（墓碑），增量同步时告诉客户端哪些会话被删除了，主键为 uid hash + 删除时的版本号
+var TableConversationTombstone = struct {
+	Id   [2]byte
+	Size int
+}{
+	Id:   [2]byte{0x1B, 0x01},
+	Size: 2 + 2 + 8 + 8, // tableId + dataType  + uidHash + version
+}
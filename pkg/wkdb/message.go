@@ -492,6 +492,10 @@ func (wk *wukongDB) TruncateLogTo(channelId string, channelType uint8, messageSe
 		}()
 	}
 
+	// 不能和重新加密同时进行，否则重新加密可能会把截断的消息写回去
+	wk.reencryptMu.Lock()
+	defer wk.reencryptMu.Unlock()
+
 	db := wk.channelBatchDb(channelId, channelType)
 	batch := db.NewBatch()
 	batch.DeleteRange(key.NewMessagePrimaryKey(channelId, channelType, messageSeq), key.NewMessagePrimaryKey(channelId, channelType, math.MaxUint64))
@@ -685,7 +689,7 @@ func (wk *wukongDB) SearchMessages(req MessageSearchReq) ([]Message, error) {
 				return true
 			}
 
-			if len(req.Payload) > 0 && !bytes.Contains(m.Payload, req.Payload) {
+			if len(req.Payload) > 0 && (m.IsOpaque() || !bytes.Contains(m.Payload, req.Payload)) { // 端到端加密的消息内容是密文，不参与内容搜索
 				return true
 			}
 
@@ -849,8 +853,10 @@ func (wk *wukongDB) iteratorChannelMessagesDirection(iter *pebble.Iterator, limi
 		size           int
 		preMessageSeq  uint64
 		preMessage     Message
-		lastNeedAppend bool = true
-		hasData        bool = false
+		prePrimary     []byte // 消息主键，解密payload时使用
+		preKeyId       uint32 // 加密payload的数据密钥id
+		lastNeedAppend bool   = true
+		hasData        bool   = false
 	)
 
 	if reverse {
@@ -880,6 +886,9 @@ func (wk *wukongDB) iteratorChannelMessagesDirection(iter *pebble.Iterator, limi
 		if preMessageSeq != messageSeq {
 			if preMessageSeq != 0 {
 				size++
+				if preMessage.Payload, err = wk.decryptPayload(prePrimary, preKeyId, preMessage.Payload); err != nil {
+					return err
+				}
 				if iterFnc != nil {
 					if !iterFnc(preMessage) {
 						lastNeedAppend = false
@@ -895,6 +904,8 @@ func (wk *wukongDB) iteratorChannelMessagesDirection(iter *pebble.Iterator, limi
 			preMessageSeq = messageSeq
 			preMessage = Message{}
 			preMessage.MessageSeq = uint32(messageSeq)
+			prePrimary = append(prePrimary[:0], iter.Key()[4:20]...)
+			preKeyId = 0
 		}
 
 		switch coulmnName {
@@ -925,11 +936,16 @@ func (wk *wukongDB) iteratorChannelMessagesDirection(iter *pebble.Iterator, limi
 			preMessage.Payload = payload
 		case key.TableMessage.Column.Term:
 			preMessage.Term = wk.endian.Uint64(iter.Value())
-
+		case key.TableMessage.Column.PayloadKeyId:
+			preKeyId = wk.endian.Uint32(iter.Value())
 		}
 		hasData = true
 	}
 	if lastNeedAppend && hasData {
+		var err error
+		if preMessage.Payload, err = wk.decryptPayload(prePrimary, preKeyId, preMessage.Payload); err != nil {
+			return err
+		}
 		if iterFnc != nil {
 
 			_ = iterFnc(preMessage)
@@ -945,6 +961,8 @@ func (wk *wukongDB) parseChannelMessagesWithLimitSize(iter *pebble.Iterator, lim
 		msgs           = make([]Message, 0)
 		preMessageSeq  uint64
 		preMessage     Message
+		prePrimary     []byte
+		preKeyId       uint32
 		lastNeedAppend bool = false
 	)
 
@@ -962,6 +980,9 @@ func (wk *wukongDB) parseChannelMessagesWithLimitSize(iter *pebble.Iterator, lim
 
 		if preMessageSeq != messageSeq {
 			if preMessageSeq != 0 {
+				if preMessage.Payload, err = wk.decryptPayload(prePrimary, preKeyId, preMessage.Payload); err != nil {
+					return nil, err
+				}
 				size += uint64(preMessage.Size())
 				msgs = append(msgs, preMessage)
 				if limitSize != 0 && size >= limitSize {
@@ -973,6 +994,8 @@ func (wk *wukongDB) parseChannelMessagesWithLimitSize(iter *pebble.Iterator, lim
 			preMessageSeq = messageSeq
 			preMessage = Message{}
 			preMessage.MessageSeq = uint32(messageSeq)
+			prePrimary = append(prePrimary[:0], iter.Key()[4:20]...)
+			preKeyId = 0
 		}
 
 		switch coulmnName {
@@ -1003,10 +1026,16 @@ func (wk *wukongDB) parseChannelMessagesWithLimitSize(iter *pebble.Iterator, lim
 			preMessage.Payload = payload
 		case key.TableMessage.Column.Term:
 			preMessage.Term = wk.endian.Uint64(iter.Value())
+		case key.TableMessage.Column.PayloadKeyId:
+			preKeyId = wk.endian.Uint32(iter.Value())
 		}
 	}
 
 	if lastNeedAppend {
+		var err error
+		if preMessage.Payload, err = wk.decryptPayload(prePrimary, preKeyId, preMessage.Payload); err != nil {
+			return nil, err
+		}
 		msgs = append(msgs, preMessage)
 	}
 
@@ -1063,17 +1092,25 @@ func (wk *wukongDB) writeMessage(channelId string, channelType uint8, msg Messag
 	w.Set(key.NewMessageColumnKey(channelId, channelType, uint64(msg.MessageSeq), key.TableMessage.Column.FromUid), []byte(msg.RecvPacket.FromUID))
 
 	// payload
-	w.Set(key.NewMessageColumnKey(channelId, channelType, uint64(msg.MessageSeq), key.TableMessage.Column.Payload), msg.Payload)
+	var primaryValue = [16]byte{}
+	wk.endian.PutUint64(primaryValue[:], key.ChannelToNum(channelId, channelType))
+	wk.endian.PutUint64(primaryValue[8:], uint64(msg.MessageSeq))
+	payloadKeyId, payload, err := wk.encryptPayload(primaryValue[:], msg.Payload)
+	if err != nil {
+		return err
+	}
+	w.Set(key.NewMessageColumnKey(channelId, channelType, uint64(msg.MessageSeq), key.TableMessage.Column.Payload), payload)
+	if payloadKeyId != 0 {
+		payloadKeyIdBytes := make([]byte, 4)
+		wk.endian.PutUint32(payloadKeyIdBytes, payloadKeyId)
+		w.Set(key.NewMessageColumnKey(channelId, channelType, uint64(msg.MessageSeq), key.TableMessage.Column.PayloadKeyId), payloadKeyIdBytes)
+	}
 
 	// term
 	termBytes := make([]byte, 8)
 	wk.endian.PutUint64(termBytes, msg.Term)
 	w.Set(key.NewMessageColumnKey(channelId, channelType, uint64(msg.MessageSeq), key.TableMessage.Column.Term), termBytes)
 
-	var primaryValue = [16]byte{}
-	wk.endian.PutUint64(primaryValue[:], key.ChannelToNum(channelId, channelType))
-	wk.endian.PutUint64(primaryValue[8:], uint64(msg.MessageSeq))
-
 	// index fromUid
 	w.Set(key.NewMessageSecondIndexFromUidKey(msg.FromUID, primaryValue), nil)
 
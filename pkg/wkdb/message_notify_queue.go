@@ -32,7 +32,21 @@ func (wk *wukongDB) GetMessagesOfNotifyQueue(count int) ([]Message, error) {
 	})
 	defer iter.Close()
 
-	return wk.parseMessageOfNotifyQueue(iter, count)
+	return wk.parseMessageOfNotifyQueue(iter, count, nil)
+}
+
+// GetMessagesOfNotifyQueueWithSkip 获取通知队列的消息，跳过skip返回true的消息（例如正在其他队列里推送的消息），跳过的消息不占用数量
+func (wk *wukongDB) GetMessagesOfNotifyQueueWithSkip(count int, skip func(messageId int64) bool) ([]Message, error) {
+
+	wk.metrics.GetMessagesOfNotifyQueueAdd(1)
+
+	iter := wk.defaultShardDB().NewIter(&pebble.IterOptions{
+		LowerBound: key.NewMessageNotifyQueueKey(0),
+		UpperBound: key.NewMessageNotifyQueueKey(math.MaxUint64),
+	})
+	defer iter.Close()
+
+	return wk.parseMessageOfNotifyQueue(iter, count, skip)
 }
 
 // RemoveMessagesOfNotifyQueueCount 移除指定数量的通知队列的消息
@@ -77,15 +91,28 @@ func (wk *wukongDB) writeMessageOfNotifyQueue(msg Message, w *Batch) error {
 	if err != nil {
 		return err
 	}
-	w.Set(key.NewMessageNotifyQueueKey(uint64(msg.MessageID)), data)
+	queueKey := key.NewMessageNotifyQueueKey(uint64(msg.MessageID))
+	if data, err = wk.encryptValue(queueKey, data); err != nil {
+		return err
+	}
+	w.Set(queueKey, data)
 	return nil
 }
 
-func (wk *wukongDB) parseMessageOfNotifyQueue(iter *pebble.Iterator, limit int) ([]Message, error) {
+func (wk *wukongDB) parseMessageOfNotifyQueue(iter *pebble.Iterator, limit int, skip func(messageId int64) bool) ([]Message, error) {
 
 	msgs := make([]Message, 0, limit)
 	for iter.First(); iter.Valid(); iter.Next() {
-		value := iter.Value()
+		if limit > 0 && len(msgs) >= limit {
+			break
+		}
+		if skip != nil && skip(key.ParseMessageNotifyQueueKey(iter.Key())) {
+			continue
+		}
+		value, err := wk.decryptValue(iter.Key(), iter.Value())
+		if err != nil {
+			return nil, err
+		}
 		// 解析消息
 		var msg Message
 		if err := msg.Unmarshal(value); err != nil {
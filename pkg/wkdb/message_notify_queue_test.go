@@ -160,3 +160,41 @@ func TestRemoveMessagesOfNotifyQueue(t *testing.T) {
 	assert.Equal(t, messages[0].Payload, msgs[0].Payload)
 
 }
+
+func TestGetMessagesOfNotifyQueueWithSkip(t *testing.T) {
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	messages := make([]wkdb.Message, 0, 5)
+	for i := 1; i <= 5; i++ {
+		messages = append(messages, wkdb.Message{
+			RecvPacket: wkproto.RecvPacket{
+				MessageID:   int64(i),
+				ChannelID:   "channel1",
+				ChannelType: 2,
+				Payload:     []byte("content"),
+			},
+		})
+	}
+	err = d.AppendMessageOfNotifyQueue(messages)
+	assert.NoError(t, err)
+
+	msgs, err := d.GetMessagesOfNotifyQueue(2)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+
+	// 跳过的消息不占用数量
+	msgs, err = d.GetMessagesOfNotifyQueueWithSkip(2, func(messageId int64) bool {
+		return messageId <= 3
+	})
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, int64(4), msgs[0].MessageID)
+	assert.Equal(t, int64(5), msgs[1].MessageID)
+}
@@ -188,8 +188,13 @@ func TestSearchMessages(t *testing.T) {
 	num := 100
 
 	for i := 0; i < num; i++ {
+		var setting wkproto.Setting
+		if i%2 == 0 {
+			setting.Set(wkproto.SettingSignal) // 端到端加密的消息
+		}
 		messages = append(messages, wkdb.Message{
 			RecvPacket: wkproto.RecvPacket{
+				Setting:     setting,
 				ChannelID:   channelId,
 				ChannelType: channelType,
 				MessageID:   int64(i + 1),
@@ -209,4 +214,16 @@ func TestSearchMessages(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 10, len(resultMessages))
 
+	// 端到端加密的消息不参与内容搜索
+	resultMessages, err = d.SearchMessages(wkdb.MessageSearchReq{
+		ChannelId:   channelId,
+		ChannelType: channelType,
+		Payload:     []byte("hello"),
+		Limit:       num,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, num/2, len(resultMessages))
+	for _, m := range resultMessages {
+		assert.False(t, m.IsOpaque())
+	}
 }
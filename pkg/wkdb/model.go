@@ -25,6 +25,11 @@ type Message struct {
 	Term uint64 // raft term
 }
 
+// IsOpaque 消息内容是否是端到端加密的密文，服务端不能解析消息内容
+func (m *Message) IsOpaque() bool {
+	return m.Setting.IsSet(wkproto.SettingSignal)
+}
+
 func (m *Message) Unmarshal(data []byte) error {
 
 	dec := wkproto.NewDecoder(data)
@@ -281,13 +286,16 @@ const (
 
 // Conversation Conversation
 type Conversation struct {
-	Id           uint64           `json:"id,omitempty"`
-	Uid          string           `json:"uid,omitempty"`               // 用户uid
-	Type         ConversationType `json:"type,omitempty"`              // 会话类型
-	ChannelId    string           `json:"channel_id,omitempty"`        // 频道id
-	ChannelType  uint8            `json:"channel_type,omitempty"`      // 频道类型
-	UnreadCount  uint32           `json:"unread_count,omitempty"`      // 未读消息数量（这个可以用户自己设置）
-	ReadToMsgSeq uint64           `json:"readed_to_msg_seq,omitempty"` // 已经读至的消息序号
+	Id            uint64           `json:"id,omitempty"`
+	Uid           string           `json:"uid,omitempty"`               // 用户uid
+	Type          ConversationType `json:"type,omitempty"`              // 会话类型
+	ChannelId     string           `json:"channel_id,omitempty"`        // 频道id
+	ChannelType   uint8            `json:"channel_type,omitempty"`      // 频道类型
+	UnreadCount   uint32           `json:"unread_count,omitempty"`      // 未读消息数量（这个可以用户自己设置）
+	ReadToMsgSeq  uint64           `json:"readed_to_msg_seq,omitempty"` // 已经读至的消息序号
+	MentionMsgSeq uint64           `json:"mention_msg_seq,omitempty"`   // 最近一条@此用户的消息序号（包括@所有人），大于ReadToMsgSeq表示有未读的@消息
+	Version       uint64           `json:"version,omitempty"`           // 会话版本号，用户的会话每次新增或更新都会分配一个递增的版本号（由存储层分配）
+	UnreadTracked bool             `json:"unread_tracked,omitempty"`    // 未读数是否由服务端维护（收到新消息时累加），为false时未读数由频道最后一条消息序号和已读位置推算
 
 	CreatedAt *time.Time `json:"created_at,omitempty"` // 创建时间
 	UpdatedAt *time.Time `json:"updated_at,omitempty"` // 更新时间
@@ -315,6 +323,9 @@ func (c *Conversation) Marshal() ([]byte, error) {
 	} else {
 		enc.WriteUint64(0)
 	}
+	enc.WriteUint64(c.MentionMsgSeq)
+	enc.WriteUint64(c.Version)
+	enc.WriteUint8(wkutil.BoolToUint8(c.UnreadTracked))
 
 	return enc.Bytes(), nil
 }
@@ -369,9 +380,84 @@ func (c *Conversation) Unmarshal(data []byte) error {
 		c.UpdatedAt = &ct
 	}
 
+	// 兼容旧版本没有MentionMsgSeq的数据
+	if dec.Len() > 0 {
+		if c.MentionMsgSeq, err = dec.Uint64(); err != nil {
+			return err
+		}
+	}
+
+	// 兼容旧版本没有Version的数据
+	if dec.Len() > 0 {
+		if c.Version, err = dec.Uint64(); err != nil {
+			return err
+		}
+	}
+
+	// 兼容旧版本没有UnreadTracked的数据
+	if dec.Len() > 0 {
+		var unreadTracked uint8
+		if unreadTracked, err = dec.Uint8(); err != nil {
+			return err
+		}
+		c.UnreadTracked = wkutil.Uint8ToBool(unreadTracked)
+	}
+
+	return nil
+}
+
+// ConversationTombstone 最近会话删除记录
+type ConversationTombstone struct {
+	Uid         string           `json:"uid,omitempty"`
+	Type        ConversationType `json:"type,omitempty"`         // 会话类型
+	ChannelId   string           `json:"channel_id,omitempty"`   // 频道id
+	ChannelType uint8            `json:"channel_type,omitempty"` // 频道类型
+	Version     uint64           `json:"version,omitempty"`      // 删除时分配的会话版本号
+}
+
+func (t *ConversationTombstone) Marshal() ([]byte, error) {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+	enc.WriteString(t.Uid)
+	enc.WriteUint8(uint8(t.Type))
+	enc.WriteString(t.ChannelId)
+	enc.WriteUint8(t.ChannelType)
+	enc.WriteUint64(t.Version)
+	return enc.Bytes(), nil
+}
+
+func (t *ConversationTombstone) Unmarshal(data []byte) error {
+	dec := wkproto.NewDecoder(data)
+	var err error
+	if t.Uid, err = dec.String(); err != nil {
+		return err
+	}
+	var tp uint8
+	if tp, err = dec.Uint8(); err != nil {
+		return err
+	}
+	t.Type = ConversationType(tp)
+	if t.ChannelId, err = dec.String(); err != nil {
+		return err
+	}
+	if t.ChannelType, err = dec.Uint8(); err != nil {
+		return err
+	}
+	if t.Version, err = dec.Uint64(); err != nil {
+		return err
+	}
 	return nil
 }
 
+// ConversationDelta 最近会话增量（按版本号升序）
+type ConversationDelta struct {
+	Conversations []Conversation          // 新增或更新的会话
+	Tombstones    []ConversationTombstone // 删除的会话
+	Version       uint64                  // 本次返回的最大版本号，下次同步从这个版本号之后开始
+	More          bool                    // 是否还有更多数据
+	Reset         bool                    // 请求的版本号之后的删除记录已经清理，本次从头返回，客户端需要先清空本地的会话
+}
+
 type ConversationSet []Conversation
 
 func (c ConversationSet) Marshal() ([]byte, error) {
@@ -819,3 +905,230 @@ func (t *Tester) Unmarshal(data []byte) error {
 	}
 	return nil
 }
+
+// 审计日志的操作者类型
+const (
+	AuditActorManagerToken = "managerToken" // 使用管理员token
+	AuditActorApiKey       = "apiKey"       // 使用访问密钥
+	AuditActorManagerUser  = "managerUser"  // 管理后台用户
+	AuditActorAnonymous    = "anonymous"    // 未开启认证
+)
+
+// AuditLog 审计日志
+type AuditLog struct {
+	Id         uint64 // 日志id（雪花id，按时间递增）
+	ActorType  string // 操作者类型
+	Actor      string // 操作者，管理后台用户名或访问密钥名称
+	ApiKeyId   string // 访问密钥id
+	ClientIp   string // 客户端ip
+	Method     string // 请求方法
+	Path       string // 请求路径
+	Target     string // 操作对象，例如 channel:g1:2、uid:u1
+	StatusCode int    // 响应状态码
+	Error      string // 失败原因
+	NodeId     uint64 // 处理请求的节点
+	CreatedAt  int64  // 创建时间（单位秒）
+}
+
+// Success 操作是否成功
+func (a *AuditLog) Success() bool {
+	return a.StatusCode >= 200 && a.StatusCode < 300
+}
+
+func (a *AuditLog) Marshal() ([]byte, error) {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+	enc.WriteUint64(a.Id)
+	enc.WriteString(a.ActorType)
+	enc.WriteString(a.Actor)
+	enc.WriteString(a.ApiKeyId)
+	enc.WriteString(a.ClientIp)
+	enc.WriteString(a.Method)
+	enc.WriteString(a.Path)
+	enc.WriteString(a.Target)
+	enc.WriteUint32(uint32(a.StatusCode))
+	enc.WriteString(a.Error)
+	enc.WriteUint64(a.NodeId)
+	enc.WriteInt64(a.CreatedAt)
+	return enc.Bytes(), nil
+}
+
+func (a *AuditLog) Unmarshal(data []byte) error {
+	dec := wkproto.NewDecoder(data)
+	var err error
+	if a.Id, err = dec.Uint64(); err != nil {
+		return err
+	}
+	if a.ActorType, err = dec.String(); err != nil {
+		return err
+	}
+	if a.Actor, err = dec.String(); err != nil {
+		return err
+	}
+	if a.ApiKeyId, err = dec.String(); err != nil {
+		return err
+	}
+	if a.ClientIp, err = dec.String(); err != nil {
+		return err
+	}
+	if a.Method, err = dec.String(); err != nil {
+		return err
+	}
+	if a.Path, err = dec.String(); err != nil {
+		return err
+	}
+	if a.Target, err = dec.String(); err != nil {
+		return err
+	}
+	var statusCode uint32
+	if statusCode, err = dec.Uint32(); err != nil {
+		return err
+	}
+	a.StatusCode = int(statusCode)
+	if a.Error, err = dec.String(); err != nil {
+		return err
+	}
+	if a.NodeId, err = dec.Uint64(); err != nil {
+		return err
+	}
+	if a.CreatedAt, err = dec.Int64(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// WebhookDeadLetter webhook推送失败超过最大重试次数的事件
+type WebhookDeadLetter struct {
+	Id        uint64 // 死信id（雪花id，按时间递增）
+	Event     string // 事件类型
+	Addr      string // 推送地址，为空表示全局webhook地址
+	Data      []byte // 事件数据
+	Attempts  int    // 已尝试推送的次数
+	Error     string // 最后一次推送失败的原因
+	CreatedAt int64  // 创建时间（单位秒）
+}
+
+func (w *WebhookDeadLetter) Marshal() ([]byte, error) {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+	enc.WriteUint64(w.Id)
+	enc.WriteString(w.Event)
+	enc.WriteString(w.Addr)
+	enc.WriteUint32(uint32(w.Attempts))
+	enc.WriteString(w.Error)
+	enc.WriteInt64(w.CreatedAt)
+	enc.WriteBytes(w.Data) // 事件数据可能较大，放在最后不写长度
+	return enc.Bytes(), nil
+}
+
+func (w *WebhookDeadLetter) Unmarshal(data []byte) error {
+	dec := wkproto.NewDecoder(data)
+	var err error
+	if w.Id, err = dec.Uint64(); err != nil {
+		return err
+	}
+	if w.Event, err = dec.String(); err != nil {
+		return err
+	}
+	if w.Addr, err = dec.String(); err != nil {
+		return err
+	}
+	var attempts uint32
+	if attempts, err = dec.Uint32(); err != nil {
+		return err
+	}
+	w.Attempts = int(attempts)
+	if w.Error, err = dec.String(); err != nil {
+		return err
+	}
+	if w.CreatedAt, err = dec.Int64(); err != nil {
+		return err
+	}
+	eventData, err := dec.BinaryAll()
+	if err != nil {
+		return err
+	}
+	w.Data = append([]byte(nil), eventData...)
+	return nil
+}
+
+// E2EEKeyBundle 设备的端到端加密公钥包
+// 公钥都由客户端生成后上传，服务端原样保存和下发，不参与加解密
+type E2EEKeyBundle struct {
+	Uid                   string       // 用户uid
+	DeviceId              string       // 设备id
+	IdentityKey           string       // 身份公钥
+	SignedPrekeyId        uint32       // 签名预共享公钥id
+	SignedPrekey          string       // 签名预共享公钥
+	SignedPrekeySignature string       // 身份密钥对签名预共享公钥的签名
+	OneTimePrekeys        []E2EEPrekey // 一次性预共享公钥，每个只会下发一次
+	UpdatedAt             int64        // 更新时间（单位秒）
+}
+
+// E2EEPrekey 一次性预共享公钥
+type E2EEPrekey struct {
+	Id  uint32 // 公钥id
+	Key string // 公钥
+}
+
+func (b *E2EEKeyBundle) Marshal() ([]byte, error) {
+	enc := wkproto.NewEncoder()
+	defer enc.End()
+	enc.WriteString(b.Uid)
+	enc.WriteString(b.DeviceId)
+	enc.WriteString(b.IdentityKey)
+	enc.WriteUint32(b.SignedPrekeyId)
+	enc.WriteString(b.SignedPrekey)
+	enc.WriteString(b.SignedPrekeySignature)
+	enc.WriteUint32(uint32(len(b.OneTimePrekeys)))
+	for _, prekey := range b.OneTimePrekeys {
+		enc.WriteUint32(prekey.Id)
+		enc.WriteString(prekey.Key)
+	}
+	enc.WriteInt64(b.UpdatedAt)
+	return enc.Bytes(), nil
+}
+
+func (b *E2EEKeyBundle) Unmarshal(data []byte) error {
+	dec := wkproto.NewDecoder(data)
+	var err error
+	if b.Uid, err = dec.String(); err != nil {
+		return err
+	}
+	if b.DeviceId, err = dec.String(); err != nil {
+		return err
+	}
+	if b.IdentityKey, err = dec.String(); err != nil {
+		return err
+	}
+	if b.SignedPrekeyId, err = dec.Uint32(); err != nil {
+		return err
+	}
+	if b.SignedPrekey, err = dec.String(); err != nil {
+		return err
+	}
+	if b.SignedPrekeySignature, err = dec.String(); err != nil {
+		return err
+	}
+	var count uint32
+	if count, err = dec.Uint32(); err != nil {
+		return err
+	}
+	if count > 0 {
+		b.OneTimePrekeys = make([]E2EEPrekey, 0, count)
+	}
+	for i := uint32(0); i < count; i++ {
+		var prekey E2EEPrekey
+		if prekey.Id, err = dec.Uint32(); err != nil {
+			return err
+		}
+		if prekey.Key, err = dec.String(); err != nil {
+			return err
+		}
+		b.OneTimePrekeys = append(b.OneTimePrekeys, prekey)
+	}
+	if b.UpdatedAt, err = dec.Int64(); err != nil {
+		return err
+	}
+	return nil
+}
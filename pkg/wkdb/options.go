@@ -1,5 +1,7 @@
 package wkdb
 
+import "time"
+
 type Options struct {
 	NodeId            uint64
 	DataDir           string
@@ -12,6 +14,13 @@ type Options struct {
 	MemTableSize int
 
 	BatchPerSize int // 每个batch里key的大小
+
+	// 消息内容的静态加密，为nil表示不加密
+	EncryptionKeyProvider KeyProvider
+	// 数据密钥的自动轮换间隔，0表示不自动轮换，轮换后后台任务会把旧密钥加密的消息重新加密
+	EncryptionRotateInterval time.Duration
+
+	ConversationTombstoneRetain uint64 // 最近会话删除记录保留的版本数量，更早的删除记录会被清理，0表示不清理
 }
 
 func NewOptions(opt ...Option) *Options {
@@ -23,6 +32,8 @@ func NewOptions(opt ...Option) *Options {
 		ShardNum:          8,
 		MemTableSize:      16 * 1024 * 1024,
 		BatchPerSize:      10240,
+
+		ConversationTombstoneRetain: 10000,
 	}
 	for _, f := range opt {
 		f(o)
@@ -79,3 +90,21 @@ func WithMemTableSize(size int) Option {
 		o.MemTableSize = size
 	}
 }
+
+func WithEncryptionKeyProvider(provider KeyProvider) Option {
+	return func(o *Options) {
+		o.EncryptionKeyProvider = provider
+	}
+}
+
+func WithEncryptionRotateInterval(interval time.Duration) Option {
+	return func(o *Options) {
+		o.EncryptionRotateInterval = interval
+	}
+}
+
+func WithConversationTombstoneRetain(retain uint64) Option {
+	return func(o *Options) {
+		o.ConversationTombstoneRetain = retain
+	}
+}
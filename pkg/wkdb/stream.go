@@ -23,10 +23,13 @@ func (wk *wukongDB) GetStreamMeta(streamNo string) (*StreamMeta, error) {
 	db := wk.shardDB(streamNo)
 	keyBytes := key.NewStreamMetaKey(streamNo)
 	valueBytes, closer, err := db.Get(keyBytes)
-	defer closer.Close()
 	if err != nil {
+		if err == pebble.ErrNotFound {
+			return nil, nil
+		}
 		return nil, err
 	}
+	defer closer.Close()
 
 	if len(valueBytes) == 0 {
 		return nil, nil
@@ -0,0 +1,93 @@
+package wkdb
+
+import (
+	"math"
+	"time"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb/key"
+	"github.com/cockroachdb/pebble"
+)
+
+// AddWebhookDeadLetter 添加死信
+func (wk *wukongDB) AddWebhookDeadLetter(deadLetter WebhookDeadLetter) error {
+	if deadLetter.Id == 0 {
+		deadLetter.Id = wk.NextPrimaryKey()
+	}
+	if deadLetter.CreatedAt == 0 {
+		deadLetter.CreatedAt = time.Now().Unix()
+	}
+	data, err := deadLetter.Marshal()
+	if err != nil {
+		return err
+	}
+	deadLetterKey := key.NewWebhookDeadLetterKey(deadLetter.Id)
+	if data, err = wk.encryptValue(deadLetterKey, data); err != nil {
+		return err
+	}
+	return wk.defaultShardDB().Set(deadLetterKey, data, wk.sync)
+}
+
+// GetWebhookDeadLetters 获取死信，按时间从旧到新返回
+func (wk *wukongDB) GetWebhookDeadLetters(offsetId uint64, limit int) ([]WebhookDeadLetter, error) {
+	iter := wk.defaultShardDB().NewIter(&pebble.IterOptions{
+		LowerBound: key.NewWebhookDeadLetterKey(offsetId + 1),
+		UpperBound: key.NewWebhookDeadLetterKey(math.MaxUint64),
+	})
+	defer iter.Close()
+
+	if limit <= 0 {
+		limit = 100
+	}
+	deadLetters := make([]WebhookDeadLetter, 0, limit)
+	for iter.First(); iter.Valid(); iter.Next() {
+		data, err := wk.decryptValue(iter.Key(), iter.Value())
+		if err != nil {
+			return nil, err
+		}
+		var deadLetter WebhookDeadLetter
+		if err := deadLetter.Unmarshal(data); err != nil {
+			return nil, err
+		}
+		deadLetters = append(deadLetters, deadLetter)
+		if len(deadLetters) >= limit {
+			break
+		}
+	}
+	return deadLetters, nil
+}
+
+// GetWebhookDeadLetter 获取指定的死信
+func (wk *wukongDB) GetWebhookDeadLetter(id uint64) (WebhookDeadLetter, error) {
+	var deadLetter WebhookDeadLetter
+	deadLetterKey := key.NewWebhookDeadLetterKey(id)
+	data, closer, err := wk.defaultShardDB().Get(deadLetterKey)
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return deadLetter, ErrNotFound
+		}
+		return deadLetter, err
+	}
+	defer closer.Close()
+	if data, err = wk.decryptValue(deadLetterKey, data); err != nil {
+		return deadLetter, err
+	}
+	err = deadLetter.Unmarshal(data)
+	return deadLetter, err
+}
+
+// RemoveWebhookDeadLetters 移除指定的死信
+func (wk *wukongDB) RemoveWebhookDeadLetters(ids []uint64) error {
+	batch := wk.defaultShardDB().NewBatch()
+	defer batch.Close()
+	for _, id := range ids {
+		if err := batch.Delete(key.NewWebhookDeadLetterKey(id), wk.noSync); err != nil {
+			return err
+		}
+	}
+	return batch.Commit(wk.sync)
+}
+
+// PurgeWebhookDeadLetters 清空所有死信
+func (wk *wukongDB) PurgeWebhookDeadLetters() error {
+	return wk.defaultShardDB().DeleteRange(key.NewWebhookDeadLetterKey(0), key.NewWebhookDeadLetterKey(math.MaxUint64), wk.sync)
+}
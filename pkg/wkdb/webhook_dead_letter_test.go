@@ -0,0 +1,53 @@
+package wkdb_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/WuKongIM/WuKongIM/pkg/wkdb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookDeadLetter(t *testing.T) {
+	d := newTestDB(t)
+	err := d.Open()
+	assert.NoError(t, err)
+
+	defer func() {
+		err := d.Close()
+		assert.NoError(t, err)
+	}()
+
+	bigData := bytes.Repeat([]byte("a"), 64*1024)
+	for _, event := range []string{"msg.notify", "msg.offline", "channel.created"} {
+		err = d.AddWebhookDeadLetter(wkdb.WebhookDeadLetter{Event: event, Data: bigData, Attempts: 5, Error: "timeout"})
+		assert.NoError(t, err)
+	}
+
+	deadLetters, err := d.GetWebhookDeadLetters(0, 2)
+	assert.NoError(t, err)
+	assert.Len(t, deadLetters, 2)
+	assert.Equal(t, "msg.notify", deadLetters[0].Event)
+	assert.Equal(t, bigData, deadLetters[0].Data)
+	assert.Equal(t, 5, deadLetters[0].Attempts)
+
+	deadLetters, err = d.GetWebhookDeadLetters(deadLetters[1].Id, 10)
+	assert.NoError(t, err)
+	assert.Len(t, deadLetters, 1)
+	assert.Equal(t, "channel.created", deadLetters[0].Event)
+
+	deadLetter, err := d.GetWebhookDeadLetter(deadLetters[0].Id)
+	assert.NoError(t, err)
+	assert.Equal(t, "timeout", deadLetter.Error)
+
+	err = d.RemoveWebhookDeadLetters([]uint64{deadLetter.Id})
+	assert.NoError(t, err)
+	_, err = d.GetWebhookDeadLetter(deadLetter.Id)
+	assert.Equal(t, wkdb.ErrNotFound, err)
+
+	err = d.PurgeWebhookDeadLetters()
+	assert.NoError(t, err)
+	deadLetters, err = d.GetWebhookDeadLetters(0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, deadLetters, 0)
+}
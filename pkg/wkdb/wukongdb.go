@@ -7,6 +7,7 @@ import (
 	"hash"
 	"hash/fnv"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/WuKongIM/WuKongIM/pkg/trace"
@@ -38,6 +39,11 @@ type wukongDB struct {
 	metrics trace.IDBMetrics
 
 	h hash.Hash32
+
+	keyRing     *dataKeyRing  // 静态加密的数据密钥，为nil表示未开启加密
+	reencryptC  chan struct{} // 触发重新加密
+	reencryptMu sync.Mutex    // 重新加密和截断消息互斥
+	reencryptWg sync.WaitGroup
 }
 
 func NewWukongDB(opts *Options) DB {
@@ -116,6 +122,10 @@ func (wk *wukongDB) Open() error {
 		wk.wkdbs = append(wk.wkdbs, wkdb)
 	}
 
+	if err := wk.openEncryption(); err != nil {
+		return err
+	}
+
 	// go wk.collectMetricsLoop()
 
 	return nil
@@ -123,6 +133,7 @@ func (wk *wukongDB) Open() error {
 
 func (wk *wukongDB) Close() error {
 	wk.cancelFunc()
+	wk.reencryptWg.Wait()
 	for _, db := range wk.dbs {
 		if err := db.Close(); err != nil {
 			wk.Error("close db error", zap.Error(err))
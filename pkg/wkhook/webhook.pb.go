@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.27.1
+// 	protoc-gen-go v1.34.2
 // 	protoc        v3.18.1
 // source: pkg/wkhook/webhook.proto
 
@@ -176,6 +176,423 @@ func (x *EventResp) GetData() []byte {
 	return nil
 }
 
+// ChannelEvent 频道事件数据 channel.created、channel.updated、channel.deleted
+type ChannelEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ChannelId   string `protobuf:"bytes,1,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`        // 频道ID
+	ChannelType uint32 `protobuf:"varint,2,opt,name=channel_type,json=channelType,proto3" json:"channel_type,omitempty"` // 频道类型
+	Ban         bool   `protobuf:"varint,3,opt,name=ban,proto3" json:"ban,omitempty"`                                    // 是否封禁
+	Large       bool   `protobuf:"varint,4,opt,name=large,proto3" json:"large,omitempty"`                                // 是否是超大群
+	Disband     bool   `protobuf:"varint,5,opt,name=disband,proto3" json:"disband,omitempty"`                            // 是否解散
+	Timestamp   int64  `protobuf:"varint,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                        // 事件时间（秒）
+}
+
+func (x *ChannelEvent) Reset() {
+	*x = ChannelEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkhook_webhook_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChannelEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelEvent) ProtoMessage() {}
+
+func (x *ChannelEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkhook_webhook_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelEvent.ProtoReflect.Descriptor instead.
+func (*ChannelEvent) Descriptor() ([]byte, []int) {
+	return file_pkg_wkhook_webhook_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ChannelEvent) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *ChannelEvent) GetChannelType() uint32 {
+	if x != nil {
+		return x.ChannelType
+	}
+	return 0
+}
+
+func (x *ChannelEvent) GetBan() bool {
+	if x != nil {
+		return x.Ban
+	}
+	return false
+}
+
+func (x *ChannelEvent) GetLarge() bool {
+	if x != nil {
+		return x.Large
+	}
+	return false
+}
+
+func (x *ChannelEvent) GetDisband() bool {
+	if x != nil {
+		return x.Disband
+	}
+	return false
+}
+
+func (x *ChannelEvent) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// ChannelMembersEvent 频道成员变更事件数据
+// channel.subscriber_added、channel.subscriber_removed、channel.denylist_added、channel.denylist_removed、channel.allowlist_added、channel.allowlist_removed
+type ChannelMembersEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ChannelId   string   `protobuf:"bytes,1,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`        // 频道ID
+	ChannelType uint32   `protobuf:"varint,2,opt,name=channel_type,json=channelType,proto3" json:"channel_type,omitempty"` // 频道类型
+	Uids        []string `protobuf:"bytes,3,rep,name=uids,proto3" json:"uids,omitempty"`                                   // 变更的成员
+	All         bool     `protobuf:"varint,4,opt,name=all,proto3" json:"all,omitempty"`                                    // 是否移除了所有成员（此时uids为空）
+	Timestamp   int64    `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                        // 事件时间（秒）
+}
+
+func (x *ChannelMembersEvent) Reset() {
+	*x = ChannelMembersEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkhook_webhook_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ChannelMembersEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChannelMembersEvent) ProtoMessage() {}
+
+func (x *ChannelMembersEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkhook_webhook_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChannelMembersEvent.ProtoReflect.Descriptor instead.
+func (*ChannelMembersEvent) Descriptor() ([]byte, []int) {
+	return file_pkg_wkhook_webhook_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ChannelMembersEvent) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *ChannelMembersEvent) GetChannelType() uint32 {
+	if x != nil {
+		return x.ChannelType
+	}
+	return 0
+}
+
+func (x *ChannelMembersEvent) GetUids() []string {
+	if x != nil {
+		return x.Uids
+	}
+	return nil
+}
+
+func (x *ChannelMembersEvent) GetAll() bool {
+	if x != nil {
+		return x.All
+	}
+	return false
+}
+
+func (x *ChannelMembersEvent) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// ConversationEvent 最近会话事件数据 conversation.read、conversation.cleared
+type ConversationEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uid         string `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`                                     // 会话所属用户
+	ChannelId   string `protobuf:"bytes,2,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`        // 频道ID
+	ChannelType uint32 `protobuf:"varint,3,opt,name=channel_type,json=channelType,proto3" json:"channel_type,omitempty"` // 频道类型
+	MessageSeq  uint64 `protobuf:"varint,4,opt,name=message_seq,json=messageSeq,proto3" json:"message_seq,omitempty"`    // 已读到的消息序号（conversation.read）
+	Timestamp   int64  `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                        // 事件时间（秒）
+}
+
+func (x *ConversationEvent) Reset() {
+	*x = ConversationEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkhook_webhook_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConversationEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversationEvent) ProtoMessage() {}
+
+func (x *ConversationEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkhook_webhook_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversationEvent.ProtoReflect.Descriptor instead.
+func (*ConversationEvent) Descriptor() ([]byte, []int) {
+	return file_pkg_wkhook_webhook_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ConversationEvent) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+func (x *ConversationEvent) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *ConversationEvent) GetChannelType() uint32 {
+	if x != nil {
+		return x.ChannelType
+	}
+	return 0
+}
+
+func (x *ConversationEvent) GetMessageSeq() uint64 {
+	if x != nil {
+		return x.MessageSeq
+	}
+	return 0
+}
+
+func (x *ConversationEvent) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// StreamEvent 流消息事件数据 stream.start、stream.end
+type StreamEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	StreamNo    string `protobuf:"bytes,1,opt,name=stream_no,json=streamNo,proto3" json:"stream_no,omitempty"`            // 流编号
+	ChannelId   string `protobuf:"bytes,2,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`         // 频道ID
+	ChannelType uint32 `protobuf:"varint,3,opt,name=channel_type,json=channelType,proto3" json:"channel_type,omitempty"`  // 频道类型
+	FromUid     string `protobuf:"bytes,4,opt,name=from_uid,json=fromUid,proto3" json:"from_uid,omitempty"`               // 发送者
+	ClientMsgNo string `protobuf:"bytes,5,opt,name=client_msg_no,json=clientMsgNo,proto3" json:"client_msg_no,omitempty"` // 客户端消息编号
+	MessageId   int64  `protobuf:"varint,6,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`        // 流消息的消息ID（stream.start）
+	Timestamp   int64  `protobuf:"varint,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                         // 事件时间（秒）
+}
+
+func (x *StreamEvent) Reset() {
+	*x = StreamEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkhook_webhook_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StreamEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamEvent) ProtoMessage() {}
+
+func (x *StreamEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkhook_webhook_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamEvent.ProtoReflect.Descriptor instead.
+func (*StreamEvent) Descriptor() ([]byte, []int) {
+	return file_pkg_wkhook_webhook_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StreamEvent) GetStreamNo() string {
+	if x != nil {
+		return x.StreamNo
+	}
+	return ""
+}
+
+func (x *StreamEvent) GetChannelId() string {
+	if x != nil {
+		return x.ChannelId
+	}
+	return ""
+}
+
+func (x *StreamEvent) GetChannelType() uint32 {
+	if x != nil {
+		return x.ChannelType
+	}
+	return 0
+}
+
+func (x *StreamEvent) GetFromUid() string {
+	if x != nil {
+		return x.FromUid
+	}
+	return ""
+}
+
+func (x *StreamEvent) GetClientMsgNo() string {
+	if x != nil {
+		return x.ClientMsgNo
+	}
+	return ""
+}
+
+func (x *StreamEvent) GetMessageId() int64 {
+	if x != nil {
+		return x.MessageId
+	}
+	return 0
+}
+
+func (x *StreamEvent) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// DeviceTokenEvent 设备token更新事件数据 user.device_token
+type DeviceTokenEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uid         string `protobuf:"bytes,1,opt,name=uid,proto3" json:"uid,omitempty"`                                     // 用户ID
+	DeviceFlag  uint32 `protobuf:"varint,2,opt,name=device_flag,json=deviceFlag,proto3" json:"device_flag,omitempty"`    // 设备标识
+	DeviceLevel uint32 `protobuf:"varint,3,opt,name=device_level,json=deviceLevel,proto3" json:"device_level,omitempty"` // 设备等级
+	Timestamp   int64  `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                        // 事件时间（秒）
+}
+
+func (x *DeviceTokenEvent) Reset() {
+	*x = DeviceTokenEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_wkhook_webhook_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeviceTokenEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceTokenEvent) ProtoMessage() {}
+
+func (x *DeviceTokenEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_wkhook_webhook_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceTokenEvent.ProtoReflect.Descriptor instead.
+func (*DeviceTokenEvent) Descriptor() ([]byte, []int) {
+	return file_pkg_wkhook_webhook_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeviceTokenEvent) GetUid() string {
+	if x != nil {
+		return x.Uid
+	}
+	return ""
+}
+
+func (x *DeviceTokenEvent) GetDeviceFlag() uint32 {
+	if x != nil {
+		return x.DeviceFlag
+	}
+	return 0
+}
+
+func (x *DeviceTokenEvent) GetDeviceLevel() uint32 {
+	if x != nil {
+		return x.DeviceLevel
+	}
+	return 0
+}
+
+func (x *DeviceTokenEvent) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
 var File_pkg_wkhook_webhook_proto protoreflect.FileDescriptor
 
 var file_pkg_wkhook_webhook_proto_rawDesc = []byte{
@@ -189,15 +606,70 @@ var file_pkg_wkhook_webhook_proto_rawDesc = []byte{
 	0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e, 0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x2e, 0x45,
 	0x76, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74,
 	0x75, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
-	0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x2a, 0x25, 0x0a, 0x0b, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x53,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x09, 0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x10, 0x00,
-	0x12, 0x0b, 0x0a, 0x07, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x10, 0x01, 0x32, 0x44, 0x0a,
-	0x0e, 0x57, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
-	0x32, 0x0a, 0x0b, 0x53, 0x65, 0x6e, 0x64, 0x57, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x12, 0x10,
-	0x2e, 0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71,
-	0x1a, 0x11, 0x2e, 0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52,
-	0x65, 0x73, 0x70, 0x42, 0x0b, 0x5a, 0x09, 0x2e, 0x2f, 0x3b, 0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0xb0, 0x01, 0x0a, 0x0c, 0x43, 0x68, 0x61, 0x6e, 0x6e,
+	0x65, 0x6c, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x68, 0x61, 0x6e, 0x6e,
+	0x65, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61,
+	0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65,
+	0x6c, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x63, 0x68,
+	0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x54, 0x79, 0x70, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x62, 0x61, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x62, 0x61, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x6c,
+	0x61, 0x72, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x6c, 0x61, 0x72, 0x67,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x69, 0x73, 0x62, 0x61, 0x6e, 0x64, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x07, 0x64, 0x69, 0x73, 0x62, 0x61, 0x6e, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09,
+	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0x9b, 0x01, 0x0a, 0x13, 0x43, 0x68,
+	0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x4d, 0x65, 0x6d, 0x62, 0x65, 0x72, 0x73, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x12, 0x1d, 0x0a, 0x0a, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x64,
+	0x12, 0x21, 0x0a, 0x0c, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x74, 0x79, 0x70, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x69, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x04, 0x75, 0x69, 0x64, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x61, 0x6c, 0x6c, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x61, 0x6c, 0x6c, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0xa6, 0x01, 0x0a, 0x11, 0x43, 0x6f, 0x6e, 0x76,
+	0x65, 0x72, 0x73, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x10, 0x0a,
+	0x03, 0x75, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x69, 0x64, 0x12,
+	0x1d, 0x0a, 0x0a, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x64, 0x12, 0x21,
+	0x0a, 0x0c, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x65, 0x71,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0a, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x53,
+	0x65, 0x71, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x22, 0xe8, 0x01, 0x0a, 0x0b, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x12, 0x1b, 0x0a, 0x09, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x5f, 0x6e, 0x6f, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x4e, 0x6f, 0x12, 0x1d, 0x0a,
+	0x0a, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c,
+	0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x0b, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x19, 0x0a, 0x08, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x75, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x66, 0x72, 0x6f, 0x6d, 0x55, 0x69, 0x64, 0x12, 0x22, 0x0a, 0x0d, 0x63, 0x6c,
+	0x69, 0x65, 0x6e, 0x74, 0x5f, 0x6d, 0x73, 0x67, 0x5f, 0x6e, 0x6f, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x63, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x4d, 0x73, 0x67, 0x4e, 0x6f, 0x12, 0x1d,
+	0x0a, 0x0a, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x09, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x49, 0x64, 0x12, 0x1c, 0x0a,
+	0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x22, 0x86, 0x01, 0x0a, 0x10,
+	0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x12, 0x10, 0x0a, 0x03, 0x75, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75,
+	0x69, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x66, 0x6c, 0x61,
+	0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x46,
+	0x6c, 0x61, 0x67, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x6c, 0x65,
+	0x76, 0x65, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12, 0x1c, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74,
+	0x61, 0x6d, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x2a, 0x25, 0x0a, 0x0b, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x12, 0x09, 0x0a, 0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x10, 0x00, 0x12, 0x0b,
+	0x0a, 0x07, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x10, 0x01, 0x32, 0x44, 0x0a, 0x0e, 0x57,
+	0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x32, 0x0a,
+	0x0b, 0x53, 0x65, 0x6e, 0x64, 0x57, 0x65, 0x62, 0x68, 0x6f, 0x6f, 0x6b, 0x12, 0x10, 0x2e, 0x77,
+	0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x1a, 0x11,
+	0x2e, 0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73,
+	0x70, 0x42, 0x0b, 0x5a, 0x09, 0x2e, 0x2f, 0x3b, 0x77, 0x6b, 0x68, 0x6f, 0x6f, 0x6b, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -213,11 +685,16 @@ func file_pkg_wkhook_webhook_proto_rawDescGZIP() []byte {
 }
 
 var file_pkg_wkhook_webhook_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_pkg_wkhook_webhook_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
-var file_pkg_wkhook_webhook_proto_goTypes = []interface{}{
-	(EventStatus)(0),  // 0: wkhook.EventStatus
-	(*EventReq)(nil),  // 1: wkhook.EventReq
-	(*EventResp)(nil), // 2: wkhook.EventResp
+var file_pkg_wkhook_webhook_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_pkg_wkhook_webhook_proto_goTypes = []any{
+	(EventStatus)(0),            // 0: wkhook.EventStatus
+	(*EventReq)(nil),            // 1: wkhook.EventReq
+	(*EventResp)(nil),           // 2: wkhook.EventResp
+	(*ChannelEvent)(nil),        // 3: wkhook.ChannelEvent
+	(*ChannelMembersEvent)(nil), // 4: wkhook.ChannelMembersEvent
+	(*ConversationEvent)(nil),   // 5: wkhook.ConversationEvent
+	(*StreamEvent)(nil),         // 6: wkhook.StreamEvent
+	(*DeviceTokenEvent)(nil),    // 7: wkhook.DeviceTokenEvent
 }
 var file_pkg_wkhook_webhook_proto_depIdxs = []int32{
 	0, // 0: wkhook.EventResp.status:type_name -> wkhook.EventStatus
@@ -236,7 +713,7 @@ func file_pkg_wkhook_webhook_proto_init() {
 		return
 	}
 	if !protoimpl.UnsafeEnabled {
-		file_pkg_wkhook_webhook_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_wkhook_webhook_proto_msgTypes[0].Exporter = func(v any, i int) any {
 			switch v := v.(*EventReq); i {
 			case 0:
 				return &v.state
@@ -248,7 +725,7 @@ func file_pkg_wkhook_webhook_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_wkhook_webhook_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_wkhook_webhook_proto_msgTypes[1].Exporter = func(v any, i int) any {
 			switch v := v.(*EventResp); i {
 			case 0:
 				return &v.state
@@ -260,6 +737,66 @@ func file_pkg_wkhook_webhook_proto_init() {
 				return nil
 			}
 		}
+		file_pkg_wkhook_webhook_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*ChannelEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_wkhook_webhook_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*ChannelMembersEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_wkhook_webhook_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*ConversationEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_wkhook_webhook_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*StreamEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_wkhook_webhook_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*DeviceTokenEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -267,7 +804,7 @@ func file_pkg_wkhook_webhook_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_wkhook_webhook_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   2,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -11,8 +11,9 @@ import (
 )
 
 type WKHttp struct {
-	r    *gin.Engine
-	pool sync.Pool
+	r          *gin.Engine
+	pool       sync.Pool
+	nodeSigner *NodeSigner // 节点之间转发请求的签名
 }
 
 func New() *WKHttp {
@@ -32,6 +33,16 @@ func (l *WKHttp) GetGinRoute() *gin.Engine {
 	return l.r
 }
 
+// SetNodeSigner 设置节点之间转发请求的签名
+func (l *WKHttp) SetNodeSigner(signer *NodeSigner) {
+	l.nodeSigner = signer
+}
+
+// NodeSigner 节点之间请求的签名，没有配置时为nil
+func (l *WKHttp) NodeSigner() *NodeSigner {
+	return l.nodeSigner
+}
+
 // Static Static
 func (l *WKHttp) Static(relativePath string, root string) {
 	l.r.Static(relativePath, root)
@@ -57,10 +68,12 @@ func (l *WKHttp) handlersToGinHandleFuncs(handlers []HandlerFunc) []gin.HandlerF
 
 type Context struct {
 	*gin.Context
+	l *WKHttp
 }
 
 func (c *Context) reset() {
 	c.Context = nil
+	c.l = nil
 }
 
 // ResponseError ResponseError
@@ -101,6 +114,17 @@ func (c *Context) ResponseStatus(status int) {
 	})
 }
 
+// HeaderForwarded 节点之间转发的请求会带上此请求头（配置了节点签名时同时带上签名，接收方需要校验签名后才能信任此请求头）
+const HeaderForwarded = "X-Wk-Forwarded"
+
+// IsNodeRequest 是否是集群内其他节点签名的请求
+func (c *Context) IsNodeRequest() bool {
+	if c.l == nil {
+		return false
+	}
+	return c.l.nodeSigner.Verify(c.Request)
+}
+
 // ForwardWithBody 转发请求
 func (c *Context) ForwardWithBody(url string, body []byte) {
 	queryMap := map[string]string{}
@@ -110,10 +134,17 @@ func (c *Context) ForwardWithBody(url string, body []byte) {
 			queryMap[key] = value[0]
 		}
 	}
+	headers := c.CopyRequestHeader(c.Request)
+	delete(headers, HeaderNodeTimestamp)
+	delete(headers, HeaderNodeSign)
+	headers[HeaderForwarded] = "1"
+	if c.l != nil {
+		headers = c.l.nodeSigner.SignHeaders(headers, c.Request.Method, url, body)
+	}
 	req := rest.Request{
 		Method:      rest.Method(strings.ToUpper(c.Request.Method)),
 		BaseURL:     url,
-		Headers:     c.CopyRequestHeader(c.Request),
+		Headers:     headers,
 		Body:        body,
 		QueryParams: queryMap,
 	}
@@ -159,6 +190,7 @@ func (l *WKHttp) LMHttpHandler(handlerFunc HandlerFunc) gin.HandlerFunc {
 		hc := l.pool.Get().(*Context)
 		hc.reset()
 		hc.Context = c
+		hc.l = l
 		handlerFunc(hc)
 		l.pool.Put(hc)
 	}
@@ -0,0 +1,48 @@
+package wkhttp
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeSigner(t *testing.T) {
+	signer := NewNodeSigner("secret")
+	body := []byte(`{"uids":["u1"]}`)
+	headers := signer.SignHeaders(nil, http.MethodPost, "http://127.0.0.1:5001/user/onlinestatus", body)
+
+	newReq := func(path string, body []byte, headers map[string]string) *http.Request {
+		req, _ := http.NewRequest(http.MethodPost, "http://127.0.0.1:5001"+path, bytes.NewReader(body))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return req
+	}
+
+	req := newReq("/user/onlinestatus", body, headers)
+	assert.True(t, signer.Verify(req))
+	// 校验后请求体可以继续读取
+	readBody, _ := io.ReadAll(req.Body)
+	assert.Equal(t, body, readBody)
+
+	// 路径、请求体或者密钥不一致
+	assert.False(t, signer.Verify(newReq("/user/systemuids", body, headers)))
+	assert.False(t, signer.Verify(newReq("/user/onlinestatus", []byte(`{}`), headers)))
+	assert.False(t, NewNodeSigner("other").Verify(newReq("/user/onlinestatus", body, headers)))
+	assert.False(t, signer.Verify(newReq("/user/onlinestatus", body, nil)))
+
+	// 查询参数参与签名，参数顺序不影响校验
+	queryHeaders := signer.SignHeaders(nil, http.MethodPost, "http://127.0.0.1:5001/conversation/sync?uid=u1&version=2", body)
+	assert.True(t, signer.Verify(newReq("/conversation/sync?version=2&uid=u1", body, queryHeaders)))
+	assert.False(t, signer.Verify(newReq("/conversation/sync?uid=u2&version=2", body, queryHeaders)))
+	assert.False(t, signer.Verify(newReq("/conversation/sync", body, queryHeaders)))
+
+	// 没有密钥不签名也不认可签名
+	var nilSigner *NodeSigner = NewNodeSigner("")
+	assert.Nil(t, nilSigner)
+	assert.Empty(t, nilSigner.SignHeaders(nil, http.MethodPost, "/user/onlinestatus", body))
+	assert.False(t, nilSigner.Verify(newReq("/user/onlinestatus", body, headers)))
+}
@@ -215,6 +215,10 @@ func (a *Acceptor) acceptConn(listenFd int, ws bool, wss bool) error {
 		return err
 	}
 	remoteAddr := socket.SockaddrToTCPOrUnixAddr(sa)
+	if !a.eg.eventHandler.OnAccept(remoteAddr) {
+		_ = unix.Close(connFd)
+		return nil
+	}
 	if a.eg.options.TCPKeepAlive > 0 && a.listen.customNetwork == "tcp" {
 		err = socket.SetKeepAlivePeriod(connFd, int(a.eg.options.TCPKeepAlive.Seconds()))
 		a.Error("SetKeepAlivePeriod() failed", zap.Error(err))
@@ -157,6 +157,10 @@ func (a *Acceptor) acceptConn(connNetFd NetFd, ws bool, wss bool) error {
 	connFd := connNetFd.fd
 
 	remoteAddr := connNetFd.conn.RemoteAddr()
+	if !a.eg.eventHandler.OnAccept(remoteAddr) {
+		_ = connNetFd.conn.Close()
+		return nil
+	}
 
 	subReactor := a.reactorSubByConnFd(connFd)
 	if wss {
@@ -819,6 +819,11 @@ func (t *TLSConn) String() string {
 	return t.d.String()
 }
 
+// ConnectionState 获取tls连接状态（比如对端证书）
+func (t *TLSConn) ConnectionState() tls.ConnectionState {
+	return t.tlsconn.ConnectionState()
+}
+
 type eofBuff struct {
 	buff  InboundBuffer
 	needs int
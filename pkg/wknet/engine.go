@@ -2,6 +2,7 @@ package wknet
 
 import (
 	"net"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,9 +17,12 @@ type Engine struct {
 	options         *Options                 // 配置
 	eventHandler    *EventHandler            // 事件
 	reactorMain     *ReactorMain             // 主reactor
+	quicListener    *quicListener            // quic监听
 	timingWheel     *timingwheel.TimingWheel // Time wheel delay task
 	defaultConnPool *sync.Pool               // 默认连接对象池
 	clientIDGen     atomic.Int64             // 客户端ID生成器
+
+	wsTakeoverConns atomic.Int64 // 使用websocket压缩上下文接管的连接数
 }
 
 func NewEngine(opts ...Option) *Engine {
@@ -43,16 +47,30 @@ func NewEngine(opts ...Option) *Engine {
 		},
 	}
 	eg.reactorMain = NewReactorMain(eg)
+	if strings.TrimSpace(options.QUICAddr) != "" {
+		eg.quicListener = newQUICListener(eg)
+	}
 	return eg
 }
 
 func (e *Engine) Start() error {
 	e.timingWheel.Start()
-	return e.reactorMain.Start()
+	if err := e.reactorMain.Start(); err != nil {
+		return err
+	}
+	if e.quicListener != nil {
+		return e.quicListener.start()
+	}
+	return nil
 }
 
 func (e *Engine) Stop() error {
 	e.timingWheel.Stop()
+	if e.quicListener != nil {
+		if err := e.quicListener.stop(); err != nil {
+			return err
+		}
+	}
 	err := e.reactorMain.Stop()
 	if err != nil {
 		return err
@@ -117,6 +135,17 @@ func (e *Engine) WSSRealListenAddr() net.Addr {
 	return e.reactorMain.acceptor.wssRealAddr()
 }
 
+func (e *Engine) QUICRealListenAddr() net.Addr {
+	if e.quicListener == nil {
+		return nil
+	}
+	return e.quicListener.realAddr
+}
+
+func (e *Engine) OnAccept(onAccept OnAccept) {
+	e.eventHandler.OnAccept = onAccept
+}
+
 func (e *Engine) OnConnect(onConnect OnConnect) {
 	e.eventHandler.OnConnect = onConnect
 }
@@ -157,3 +186,17 @@ type everyScheduler struct {
 func (s *everyScheduler) Next(prev time.Time) time.Time {
 	return prev.Add(s.Interval)
 }
+
+// acquireWSTakeover 占用一个websocket压缩上下文接管的名额
+func (e *Engine) acquireWSTakeover() bool {
+	maxConns := int64(e.options.WSCompression.MaxContextTakeoverConns)
+	if e.wsTakeoverConns.Inc() > maxConns && maxConns > 0 {
+		e.wsTakeoverConns.Dec()
+		return false
+	}
+	return true
+}
+
+func (e *Engine) releaseWSTakeover() {
+	e.wsTakeoverConns.Dec()
+}
@@ -2,6 +2,7 @@ package wknet
 
 import "net"
 
+type OnAccept func(remoteAddr net.Addr) bool
 type OnConnect func(conn Conn) error
 type OnData func(conn Conn) error
 type OnClose func(conn Conn)
@@ -10,6 +11,9 @@ type OnNewInboundConn func(conn Conn, eg *Engine) InboundBuffer
 type OnNewOutboundConn func(conn Conn, eg *Engine) OutboundBuffer
 
 type EventHandler struct {
+	// OnAccept is called when a new connection is accepted, before the connection is created.
+	// If it returns false, the connection will be closed immediately.
+	OnAccept func(remoteAddr net.Addr) bool
 	// OnConnect is called when a new connection is established.
 	OnConnect func(conn Conn) error
 	// OnData is called when a data is received.
@@ -29,6 +33,7 @@ type EventHandler struct {
 
 func NewEventHandler() *EventHandler {
 	return &EventHandler{
+		OnAccept:  func(remoteAddr net.Addr) bool { return true },
 		OnConnect: func(conn Conn) error { return nil },
 		OnData:    func(conn Conn) error { return nil },
 		OnClose:   func(conn Conn) {},
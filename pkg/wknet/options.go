@@ -1,134 +1,236 @@
-package wknet
-
-import (
-	"runtime"
-	"time"
-
-	"github.com/WuKongIM/crypto/tls"
-)
-
-type Options struct {
-	// Addr is the listen addr  example: tcp://127.0.0.1:5100
-	Addr string
-	// TcpTlsConfig tcp tls config
-	TCPTLSConfig *tls.Config
-	WSTLSConfig  *tls.Config
-	// WsAddr is the listen addr  example: ws://127.0.0.1:5200或 wss://127.0.0.1:5200
-	WsAddr  string
-	WssAddr string // wss addr
-	// WSTlsConfig ws tls config
-	// MaxOpenFiles is the maximum number of open files that the server can
-	MaxOpenFiles int
-	// SubReactorNum is sub reactor numver it's set to runtime.NumCPU()  by default
-	SubReactorNum int
-	// OnCreateConn allow custom conn
-	// ReadBuffSize is the read size of the buffer each time from the connection
-	ReadBufferSize int
-	// MaxWriteBufferSize is the write maximum size of the buffer for each connection
-	MaxWriteBufferSize int
-	// MaxReadBufferSize is the read maximum size of the buffer for each connection
-	MaxReadBufferSize int
-	// SocketRecvBuffer sets the maximum socket receive buffer in bytes.
-	SocketRecvBuffer int
-	// SocketSendBuffer sets the maximum socket send buffer in bytes.
-	SocketSendBuffer int
-	// TCPKeepAlive sets up a duration for (SO_KEEPALIVE) socket option.
-	TCPKeepAlive time.Duration
-
-	Event struct {
-		OnReadBytes  func(n int) // 读到的字节大小
-		OnWirteBytes func(n int) // 写出字节大小
-	}
-}
-
-func NewOptions() *Options {
-	return &Options{
-		Addr:               "tcp://127.0.0.1:5100",
-		MaxOpenFiles:       GetMaxOpenFiles(),
-		SubReactorNum:      runtime.NumCPU(),
-		ReadBufferSize:     1024 * 32,
-		MaxWriteBufferSize: 1024 * 1024 * 50,
-		MaxReadBufferSize:  1024 * 1024 * 50,
-	}
-}
-
-type Option func(opts *Options)
-
-// WithAddr set listen addr
-func WithAddr(v string) Option {
-	return func(opts *Options) {
-		opts.Addr = v
-	}
-}
-
-func WithWSAddr(v string) Option {
-	return func(opts *Options) {
-		opts.WsAddr = v
-	}
-}
-
-func WithWSSAddr(v string) Option {
-	return func(opts *Options) {
-		opts.WssAddr = v
-	}
-}
-
-func WithTCPTLSConfig(v *tls.Config) Option {
-	return func(opts *Options) {
-		opts.TCPTLSConfig = v
-	}
-}
-
-func WithWSTLSConfig(v *tls.Config) Option {
-	return func(opts *Options) {
-		opts.WSTLSConfig = v
-	}
-}
-
-// WithMaxOpenFiles the maximum number of open files that the server can
-func WithMaxOpenFiles(v int) Option {
-	return func(opts *Options) {
-		opts.MaxOpenFiles = v
-	}
-}
-
-// WithSubReactorNum set sub reactor number
-func WithSubReactorNum(v int) Option {
-	return func(opts *Options) {
-		opts.SubReactorNum = v
-	}
-}
-
-// WithSocketRecvBuffer sets the maximum socket receive buffer in bytes.
-func WithSocketRecvBuffer(recvBuf int) Option {
-	return func(opts *Options) {
-		opts.SocketRecvBuffer = recvBuf
-	}
-}
-
-// WithSocketSendBuffer sets the maximum socket send buffer in bytes.
-func WithSocketSendBuffer(sendBuf int) Option {
-	return func(opts *Options) {
-		opts.SocketSendBuffer = sendBuf
-	}
-}
-
-// WithTCPKeepAlive sets up a duration for (SO_KEEPALIVE) socket option.
-func WithTCPKeepAlive(v time.Duration) Option {
-	return func(opts *Options) {
-		opts.TCPKeepAlive = v
-	}
-}
-
-func WithOnReadBytes(f func(n int)) Option {
-	return func(opts *Options) {
-		opts.Event.OnReadBytes = f
-	}
-}
-
-func WithOnWirteBytes(f func(n int)) Option {
-
-	return func(opts *Options) {
-		opts.Event.OnWirteBytes = f
-	}
-}
+package wknet
+
+import (
+	"compress/flate"
+	stdtls "crypto/tls"
+	"net"
+	"runtime"
+	"time"
+
+	"github.com/WuKongIM/crypto/tls"
+)
+
+type Options struct {
+	// Addr is the listen addr  example: tcp://127.0.0.1:5100
+	Addr string
+	// TcpTlsConfig tcp tls config
+	TCPTLSConfig *tls.Config
+	WSTLSConfig  *tls.Config
+	// WsAddr is the listen addr  example: ws://127.0.0.1:5200或 wss://127.0.0.1:5200
+	WsAddr  string
+	WssAddr string // wss addr
+	// QUICAddr is the quic listen addr example: quic://0.0.0.0:5110
+	QUICAddr string
+	// QUICTLSConfig quic tls config (quic requires tls1.3 of the standard library)
+	QUICTLSConfig *stdtls.Config
+	// QUICMaxIdleTimeout is the maximum duration that may pass without any incoming network activity for quic conn
+	QUICMaxIdleTimeout time.Duration
+	// WSTlsConfig ws tls config
+	// MaxOpenFiles is the maximum number of open files that the server can
+	MaxOpenFiles int
+	// SubReactorNum is sub reactor numver it's set to runtime.NumCPU()  by default
+	SubReactorNum int
+	// OnCreateConn allow custom conn
+	// ReadBuffSize is the read size of the buffer each time from the connection
+	ReadBufferSize int
+	// MaxWriteBufferSize is the write maximum size of the buffer for each connection
+	MaxWriteBufferSize int
+	// MaxReadBufferSize is the read maximum size of the buffer for each connection
+	MaxReadBufferSize int
+	// SocketRecvBuffer sets the maximum socket receive buffer in bytes.
+	SocketRecvBuffer int
+	// SocketSendBuffer sets the maximum socket send buffer in bytes.
+	SocketSendBuffer int
+	// TCPKeepAlive sets up a duration for (SO_KEEPALIVE) socket option.
+	TCPKeepAlive time.Duration
+	// TrustForwardedFrom 是否信任来自此地址的websocket请求头（X-Forwarded-For/X-Real-IP）里的真实IP，为nil表示全部信任
+	TrustForwardedFrom func(remoteAddr net.Addr) bool
+
+	// WSCompression websocket的permessage-deflate压缩（RFC 7692）
+	WSCompression struct {
+		On                      bool // 是否开启压缩协商
+		Level                   int  // 压缩级别 1-9
+		Threshold               int  // 小于此大小的消息不压缩（单位字节）
+		ContextTakeover         bool // 是否允许上下文接管，压缩率更高，但每个连接常驻一个压缩器和32KB的解压窗口
+		MaxContextTakeoverConns int  // 使用上下文接管的最大连接数，超过后的连接不使用上下文接管，0表示不限制
+		MaxMessageSize          int  // 解压后单条消息的最大大小，防止压缩炸弹，0表示不限制
+	}
+
+	Event struct {
+		OnReadBytes    func(n int)                       // 读到的字节大小
+		OnWirteBytes   func(n int)                       // 写出字节大小
+		OnWSCompress   func(rawSize, compressedSize int) // websocket消息压缩前后的大小
+		OnWSDecompress func(compressedSize, rawSize int) // websocket消息解压前后的大小
+	}
+}
+
+func NewOptions() *Options {
+	opts := &Options{
+		Addr:               "tcp://127.0.0.1:5100",
+		MaxOpenFiles:       GetMaxOpenFiles(),
+		SubReactorNum:      runtime.NumCPU(),
+		ReadBufferSize:     1024 * 32,
+		MaxWriteBufferSize: 1024 * 1024 * 50,
+		MaxReadBufferSize:  1024 * 1024 * 50,
+		QUICMaxIdleTimeout: time.Second * 30,
+	}
+	opts.WSCompression.Level = flate.DefaultCompression
+	opts.WSCompression.Threshold = 256
+	opts.WSCompression.MaxContextTakeoverConns = 1000
+	opts.WSCompression.MaxMessageSize = 1024 * 1024 * 4
+	return opts
+}
+
+type Option func(opts *Options)
+
+// WithAddr set listen addr
+func WithAddr(v string) Option {
+	return func(opts *Options) {
+		opts.Addr = v
+	}
+}
+
+func WithWSAddr(v string) Option {
+	return func(opts *Options) {
+		opts.WsAddr = v
+	}
+}
+
+func WithWSSAddr(v string) Option {
+	return func(opts *Options) {
+		opts.WssAddr = v
+	}
+}
+
+// WithQUICAddr set quic listen addr
+func WithQUICAddr(v string) Option {
+	return func(opts *Options) {
+		opts.QUICAddr = v
+	}
+}
+
+func WithQUICTLSConfig(v *stdtls.Config) Option {
+	return func(opts *Options) {
+		opts.QUICTLSConfig = v
+	}
+}
+
+func WithQUICMaxIdleTimeout(v time.Duration) Option {
+	return func(opts *Options) {
+		opts.QUICMaxIdleTimeout = v
+	}
+}
+
+func WithTCPTLSConfig(v *tls.Config) Option {
+	return func(opts *Options) {
+		opts.TCPTLSConfig = v
+	}
+}
+
+func WithWSTLSConfig(v *tls.Config) Option {
+	return func(opts *Options) {
+		opts.WSTLSConfig = v
+	}
+}
+
+// WithMaxOpenFiles the maximum number of open files that the server can
+func WithMaxOpenFiles(v int) Option {
+	return func(opts *Options) {
+		opts.MaxOpenFiles = v
+	}
+}
+
+// WithSubReactorNum set sub reactor number
+func WithSubReactorNum(v int) Option {
+	return func(opts *Options) {
+		opts.SubReactorNum = v
+	}
+}
+
+// WithSocketRecvBuffer sets the maximum socket receive buffer in bytes.
+func WithSocketRecvBuffer(recvBuf int) Option {
+	return func(opts *Options) {
+		opts.SocketRecvBuffer = recvBuf
+	}
+}
+
+// WithSocketSendBuffer sets the maximum socket send buffer in bytes.
+func WithSocketSendBuffer(sendBuf int) Option {
+	return func(opts *Options) {
+		opts.SocketSendBuffer = sendBuf
+	}
+}
+
+// WithTCPKeepAlive sets up a duration for (SO_KEEPALIVE) socket option.
+func WithTCPKeepAlive(v time.Duration) Option {
+	return func(opts *Options) {
+		opts.TCPKeepAlive = v
+	}
+}
+
+func WithOnReadBytes(f func(n int)) Option {
+	return func(opts *Options) {
+		opts.Event.OnReadBytes = f
+	}
+}
+
+func WithOnWirteBytes(f func(n int)) Option {
+
+	return func(opts *Options) {
+		opts.Event.OnWirteBytes = f
+	}
+}
+
+// WithWSCompression 开启websocket的permessage-deflate压缩
+func WithWSCompression(on bool) Option {
+	return func(opts *Options) {
+		opts.WSCompression.On = on
+	}
+}
+
+// WithWSCompressionLevel 压缩级别
+func WithWSCompressionLevel(level int) Option {
+	return func(opts *Options) {
+		opts.WSCompression.Level = level
+	}
+}
+
+// WithWSCompressionThreshold 小于此大小的消息不压缩
+func WithWSCompressionThreshold(v int) Option {
+	return func(opts *Options) {
+		opts.WSCompression.Threshold = v
+	}
+}
+
+// WithWSCompressionContextTakeover 是否允许上下文接管以及使用上下文接管的最大连接数
+func WithWSCompressionContextTakeover(on bool, maxConns int) Option {
+	return func(opts *Options) {
+		opts.WSCompression.ContextTakeover = on
+		opts.WSCompression.MaxContextTakeoverConns = maxConns
+	}
+}
+
+// WithWSCompressionMaxMessageSize 解压后单条消息的最大大小
+func WithWSCompressionMaxMessageSize(v int) Option {
+	return func(opts *Options) {
+		opts.WSCompression.MaxMessageSize = v
+	}
+}
+
+func WithOnWSCompress(f func(rawSize, compressedSize int)) Option {
+	return func(opts *Options) {
+		opts.Event.OnWSCompress = f
+	}
+}
+
+func WithTrustForwardedFrom(f func(remoteAddr net.Addr) bool) Option {
+	return func(opts *Options) {
+		opts.TrustForwardedFrom = f
+	}
+}
+
+func WithOnWSDecompress(f func(compressedSize, rawSize int)) Option {
+	return func(opts *Options) {
+		opts.Event.OnWSDecompress = f
+	}
+}
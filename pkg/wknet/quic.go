@@ -0,0 +1,483 @@
+package wknet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/RussellLuo/timingwheel"
+	"github.com/WuKongIM/WuKongIM/pkg/wklog"
+	"github.com/quic-go/quic-go"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+// QUICNextProto quic连接的ALPN协议名
+const QUICNextProto = "wukongim"
+
+const quicAcceptStreamTimeout = time.Second * 10 // 连接建立后等待客户端打开数据流的超时时间
+
+// quicListener quic监听，客户端建立连接后打开一个双向流，流上的数据与tcp连接相同（wkproto帧）
+// quic连接不依赖socket的fd，读写由每个连接自己的协程处理，不经过reactor
+type quicListener struct {
+	eg       *Engine
+	ln       *quic.Listener
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	realAddr net.Addr
+	wklog.Log
+}
+
+func newQUICListener(eg *Engine) *quicListener {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &quicListener{
+		eg:     eg,
+		ctx:    ctx,
+		cancel: cancel,
+		Log:    wklog.NewWKLog("quicListener"),
+	}
+}
+
+func (q *quicListener) start() error {
+	addr := q.eg.options.QUICAddr
+	if strings.Contains(addr, "://") {
+		addr = strings.SplitN(addr, "://", 2)[1]
+	}
+	if q.eg.options.QUICTLSConfig == nil {
+		return errors.New("quic tls config is nil")
+	}
+	tlsConfig := q.eg.options.QUICTLSConfig.Clone()
+	if len(tlsConfig.NextProtos) == 0 {
+		tlsConfig.NextProtos = []string{QUICNextProto}
+	}
+	ln, err := quic.ListenAddr(addr, tlsConfig, &quic.Config{
+		MaxIdleTimeout:        q.eg.options.QUICMaxIdleTimeout,
+		MaxIncomingStreams:    1,
+		MaxIncomingUniStreams: -1,
+	})
+	if err != nil {
+		return err
+	}
+	q.ln = ln
+	q.realAddr = ln.Addr()
+
+	q.wg.Add(1)
+	go q.loopAccept()
+	return nil
+}
+
+func (q *quicListener) stop() error {
+	q.cancel()
+	var err error
+	if q.ln != nil {
+		err = q.ln.Close()
+	}
+	q.wg.Wait()
+	return err
+}
+
+func (q *quicListener) loopAccept() {
+	defer q.wg.Done()
+	for {
+		qconn, err := q.ln.Accept(q.ctx)
+		if err != nil {
+			if q.ctx.Err() != nil || errors.Is(err, quic.ErrServerClosed) {
+				return
+			}
+			q.Warn("accept quic conn failed", zap.Error(err))
+			continue
+		}
+		if !q.eg.eventHandler.OnAccept(qconn.RemoteAddr()) {
+			_ = qconn.CloseWithError(0, "rejected")
+			continue
+		}
+		go q.handleConn(qconn)
+	}
+}
+
+func (q *quicListener) handleConn(qconn quic.Connection) {
+	ctx, cancel := context.WithTimeout(q.ctx, quicAcceptStreamTimeout)
+	stream, err := qconn.AcceptStream(ctx)
+	cancel()
+	if err != nil {
+		q.Debug("accept quic stream failed", zap.Error(err), zap.String("remoteAddr", qconn.RemoteAddr().String()))
+		_ = qconn.CloseWithError(0, "no stream")
+		return
+	}
+	conn := newQUICConn(q.eg.GenClientID(), qconn, stream, q.eg)
+	q.eg.AddConn(conn)
+	if err = q.eg.eventHandler.OnConnect(conn); err != nil {
+		q.Warn("OnConnect() failed", zap.Error(err))
+	}
+	go conn.loopWrite()
+	conn.loopRead()
+}
+
+// QUICConn quic连接，一个quic连接上只使用一个双向流
+// 客户端网络切换（wifi和蜂窝网络之间）时quic连接会迁移到新的地址，连接本身不会断开
+type QUICConn struct {
+	id         atomic.Int64
+	fd         NetFd // 虚拟的fd（负数），只用于在引擎中索引连接
+	qconn      quic.Connection
+	stream     quic.Stream
+	eg         *Engine
+	remoteAddr atomic.Value // 代理协议等设置的远程地址，没有设置则使用quic连接当前的地址
+
+	inboundBuffer  InboundBuffer
+	outboundBuffer OutboundBuffer
+	mu             sync.Mutex // 保护outboundBuffer
+	writeMu        sync.Mutex // 保护stream的写
+	writeC         chan struct{}
+	closed         atomic.Bool
+	closeC         chan struct{}
+
+	context  atomic.Value
+	authed   atomic.Bool
+	uid      atomic.String
+	valueMap sync.Map
+
+	uptime       atomic.Time
+	lastActivity atomic.Time
+	maxIdleLock  sync.Mutex
+	idleTimer    *timingwheel.Timer
+
+	wklog.Log
+}
+
+func newQUICConn(id int64, qconn quic.Connection, stream quic.Stream, eg *Engine) *QUICConn {
+	c := &QUICConn{
+		fd:     NetFd{fd: -int(id)},
+		qconn:  qconn,
+		stream: stream,
+		eg:     eg,
+		writeC: make(chan struct{}, 1),
+		closeC: make(chan struct{}),
+		Log:    wklog.NewWKLog(fmt.Sprintf("QUICConn[%d]", id)),
+	}
+	c.id.Store(id)
+	c.uptime.Store(time.Now())
+	c.lastActivity.Store(time.Now())
+	c.inboundBuffer = eg.eventHandler.OnNewInboundConn(c, eg)
+	c.outboundBuffer = eg.eventHandler.OnNewOutboundConn(c, eg)
+	return c
+}
+
+func (c *QUICConn) loopRead() {
+	buff := make([]byte, c.eg.options.ReadBufferSize)
+	for {
+		n, err := c.stream.Read(buff)
+		if n > 0 {
+			if c.eg.options.Event.OnReadBytes != nil {
+				c.eg.options.Event.OnReadBytes(n)
+			}
+			if c.overflowForInbound(n) {
+				_ = c.CloseWithErr(fmt.Errorf("inbound buffer overflow, id: %d n: %d maxSize: %d", c.ID(), n, c.eg.options.MaxReadBufferSize))
+				return
+			}
+			c.KeepLastActivity()
+			if _, werr := c.inboundBuffer.Write(buff[:n]); werr != nil {
+				_ = c.CloseWithErr(werr)
+				return
+			}
+			if herr := c.eg.eventHandler.OnData(c); herr != nil {
+				c.Warn("failed to call OnData", zap.Error(herr))
+				_ = c.CloseWithErr(herr)
+				return
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				c.Debug("read quic stream failed", zap.Error(err))
+			}
+			_ = c.CloseWithErr(err)
+			return
+		}
+	}
+}
+
+func (c *QUICConn) loopWrite() {
+	for {
+		select {
+		case <-c.writeC:
+			if err := c.Flush(); err != nil {
+				if !c.closed.Load() {
+					c.Debug("write quic stream failed", zap.Error(err))
+					_ = c.CloseWithErr(err)
+				}
+				return
+			}
+		case <-c.closeC:
+			return
+		}
+	}
+}
+
+func (c *QUICConn) overflowForInbound(n int) bool {
+	maxReadBufferSize := c.eg.options.MaxReadBufferSize
+	return maxReadBufferSize > 0 && c.inboundBuffer.BoundBufferSize()+n > maxReadBufferSize
+}
+
+func (c *QUICConn) ID() int64 {
+	return c.id.Load()
+}
+
+func (c *QUICConn) SetID(id int64) {
+	c.id.Store(id)
+}
+
+func (c *QUICConn) UID() string {
+	return c.uid.Load()
+}
+
+func (c *QUICConn) SetUID(uid string) {
+	c.uid.Store(uid)
+}
+
+func (c *QUICConn) SetValue(key string, value interface{}) {
+	c.valueMap.Store(key, value)
+}
+
+func (c *QUICConn) Value(key string) interface{} {
+	value, _ := c.valueMap.Load(key)
+	return value
+}
+
+// Flush 将outboundBuffer的数据写入流
+func (c *QUICConn) Flush() error {
+	if c.closed.Load() {
+		return net.ErrClosed
+	}
+	c.mu.Lock()
+	if c.outboundBuffer.IsEmpty() {
+		c.mu.Unlock()
+		return nil
+	}
+	head, tail := c.outboundBuffer.Peek(-1)
+	data := make([]byte, 0, len(head)+len(tail))
+	data = append(data, head...)
+	data = append(data, tail...)
+	_, _ = c.outboundBuffer.Discard(len(data))
+	c.mu.Unlock()
+
+	_, err := c.Write(data)
+	return err
+}
+
+func (c *QUICConn) Read(buf []byte) (int, error) {
+	if c.inboundBuffer.IsEmpty() {
+		return 0, nil
+	}
+	n, err := c.inboundBuffer.Read(buf)
+	if n == len(buf) {
+		return n, nil
+	}
+	return n, err
+}
+
+func (c *QUICConn) Peek(n int) ([]byte, error) {
+	totalLen := c.inboundBuffer.BoundBufferSize()
+	if n > totalLen {
+		return nil, io.ErrShortBuffer
+	} else if n <= 0 {
+		n = totalLen
+	}
+	if c.inboundBuffer.IsEmpty() {
+		return nil, nil
+	}
+	head, tail := c.inboundBuffer.Peek(n)
+	data := make([]byte, 0, len(head)+len(tail))
+	data = append(data, head...)
+	data = append(data, tail...)
+	return data, nil
+}
+
+func (c *QUICConn) Discard(n int) (int, error) {
+	return c.inboundBuffer.Discard(n)
+}
+
+// Write 直接写入流
+func (c *QUICConn) Write(b []byte) (int, error) {
+	if c.closed.Load() {
+		return -1, net.ErrClosed
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	n, err := c.stream.Write(b)
+	if n > 0 && c.eg.options.Event.OnWirteBytes != nil {
+		c.eg.options.Event.OnWirteBytes(n)
+	}
+	return n, err
+}
+
+func (c *QUICConn) WriteToOutboundBuffer(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	if c.closed.Load() {
+		return -1, net.ErrClosed
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.outboundBuffer.Write(b)
+}
+
+// WakeWrite 唤醒写协程
+func (c *QUICConn) WakeWrite() error {
+	if c.closed.Load() {
+		return net.ErrClosed
+	}
+	select {
+	case c.writeC <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (c *QUICConn) Fd() NetFd {
+	return c.fd
+}
+
+func (c *QUICConn) IsClosed() bool {
+	return c.closed.Load()
+}
+
+func (c *QUICConn) Close() error {
+	return c.CloseWithErr(nil)
+}
+
+func (c *QUICConn) CloseWithErr(err error) error {
+	if !c.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	close(c.closeC)
+
+	c.maxIdleLock.Lock()
+	if c.idleTimer != nil {
+		c.idleTimer.Stop()
+		c.idleTimer = nil
+	}
+	c.maxIdleLock.Unlock()
+
+	// 关闭quic连接会同时关闭流，阻塞中的读写会返回错误
+	if err != nil && err != io.EOF {
+		_ = c.qconn.CloseWithError(1, err.Error())
+	} else {
+		_ = c.qconn.CloseWithError(0, "")
+	}
+
+	c.eg.RemoveConn(c)
+	c.eg.eventHandler.OnClose(c)
+
+	c.mu.Lock()
+	_ = c.outboundBuffer.Release()
+	c.mu.Unlock()
+	_ = c.inboundBuffer.Release()
+	return nil
+}
+
+// RemoteAddr 客户端迁移后返回新的地址
+func (c *QUICConn) RemoteAddr() net.Addr {
+	if addr, ok := c.remoteAddr.Load().(net.Addr); ok {
+		return addr
+	}
+	return c.qconn.RemoteAddr()
+}
+
+func (c *QUICConn) SetRemoteAddr(addr net.Addr) {
+	c.remoteAddr.Store(addr)
+}
+
+func (c *QUICConn) LocalAddr() net.Addr {
+	return c.qconn.LocalAddr()
+}
+
+// ReactorSub quic连接不经过reactor
+func (c *QUICConn) ReactorSub() *ReactorSub {
+	return nil
+}
+
+// ReadToInboundBuffer quic连接由读协程写入inboundBuffer
+func (c *QUICConn) ReadToInboundBuffer() (int, error) {
+	return 0, ErrUnsupportedOp
+}
+
+func (c *QUICConn) SetContext(ctx interface{}) {
+	c.context.Store(ctx)
+}
+
+func (c *QUICConn) Context() interface{} {
+	return c.context.Load()
+}
+
+func (c *QUICConn) IsAuthed() bool {
+	return c.authed.Load()
+}
+
+func (c *QUICConn) SetAuthed(authed bool) {
+	c.authed.Store(authed)
+}
+
+func (c *QUICConn) KeepLastActivity() {
+	c.lastActivity.Store(time.Now())
+}
+
+func (c *QUICConn) LastActivity() time.Time {
+	return c.lastActivity.Load()
+}
+
+func (c *QUICConn) Uptime() time.Time {
+	return c.uptime.Load()
+}
+
+func (c *QUICConn) SetMaxIdle(maxIdle time.Duration) {
+	c.maxIdleLock.Lock()
+	defer c.maxIdleLock.Unlock()
+
+	if c.closed.Load() {
+		return
+	}
+	if c.idleTimer != nil {
+		c.idleTimer.Stop()
+		c.idleTimer = nil
+	}
+	if maxIdle <= 0 {
+		return
+	}
+	c.idleTimer = c.eg.Schedule(maxIdle/2, func() {
+		if c.lastActivity.Load().Add(maxIdle).After(time.Now()) {
+			return
+		}
+		c.Debug("max idle time exceeded, close the connection", zap.Duration("maxIdle", maxIdle))
+		_ = c.Close()
+	})
+}
+
+func (c *QUICConn) InboundBuffer() InboundBuffer {
+	return c.inboundBuffer
+}
+
+func (c *QUICConn) OutboundBuffer() OutboundBuffer {
+	return c.outboundBuffer
+}
+
+func (c *QUICConn) SetDeadline(t time.Time) error {
+	return c.stream.SetDeadline(t)
+}
+
+func (c *QUICConn) SetReadDeadline(t time.Time) error {
+	return c.stream.SetReadDeadline(t)
+}
+
+func (c *QUICConn) SetWriteDeadline(t time.Time) error {
+	return c.stream.SetWriteDeadline(t)
+}
+
+func (c *QUICConn) String() string {
+	return fmt.Sprintf("QUICConn[%d] uid=%s remoteAddr=%s", c.ID(), c.UID(), c.RemoteAddr())
+}
@@ -0,0 +1,85 @@
+package wknet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQUIC(t *testing.T) {
+	var (
+		closeWg sync.WaitGroup
+	)
+	closeWg.Add(1)
+	e := NewEngine(
+		WithAddr("tcp://127.0.0.1:0"),
+		WithQUICAddr("quic://127.0.0.1:0"),
+		WithQUICTLSConfig(newTestTLSConfig(t)),
+	)
+	e.OnData(func(conn Conn) error {
+		data, err := conn.Peek(-1)
+		if err != nil || len(data) < 5 {
+			return err
+		}
+		_, _ = conn.Discard(len(data))
+		if _, err = conn.WriteToOutboundBuffer(data); err != nil { // 原样返回
+			return err
+		}
+		return conn.WakeWrite()
+	})
+	e.OnClose(func(conn Conn) {
+		closeWg.Done()
+	})
+	err := e.Start()
+	assert.NoError(t, err)
+	defer e.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	cli, err := quic.DialAddr(ctx, e.QUICRealListenAddr().String(), &tls.Config{
+		InsecureSkipVerify: true,
+		NextProtos:         []string{QUICNextProto},
+	}, nil)
+	assert.NoError(t, err)
+	stream, err := cli.OpenStreamSync(ctx)
+	assert.NoError(t, err)
+
+	_, err = stream.Write([]byte("hello"))
+	assert.NoError(t, err)
+
+	buff := make([]byte, 5)
+	_ = stream.SetReadDeadline(time.Now().Add(time.Second * 5))
+	n, err := stream.Read(buff)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(buff[:n]))
+	assert.Equal(t, 1, e.ConnCount())
+
+	_ = cli.CloseWithError(0, "")
+	closeWg.Wait()
+	assert.Equal(t, 0, e.ConnCount())
+}
+
+func newTestTLSConfig(t *testing.T) *tls.Config {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{certDER}, PrivateKey: key}},
+	}
+}
@@ -0,0 +1,283 @@
+package wknet
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"io"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsflate"
+	"github.com/gobwas/ws/wsutil"
+)
+
+var (
+	// ErrWSMessageTooLarge 解压后的消息超过最大限制
+	ErrWSMessageTooLarge = errors.New("websocket message too large after decompression")
+)
+
+const wsDeflateWindowSize = 1 << 15 // deflate滑动窗口大小（32KB）
+
+var (
+	// 每条压缩消息末尾被去掉的空块，解压时需要补上，后面追加一个结束块让解压器返回EOF
+	wsDeflateReadTail = []byte{0x00, 0x00, 0xff, 0xff, 0x01, 0x00, 0x00, 0xff, 0xff}
+
+	flateReaderPool sync.Pool
+	flateWriterPool = map[int]*sync.Pool{}
+	flateWriterMu   sync.Mutex
+)
+
+// wsDeflate websocket的permessage-deflate压缩（RFC 7692）
+// 默认协商双方都不使用上下文接管，压缩器和解压器从池中获取，连接不常驻压缩状态
+// 开启上下文接管后压缩率更高，但每个连接需要常驻一个压缩器和32KB的解压窗口
+type wsDeflate struct {
+	eg                      *Engine
+	takeover                bool // 是否占用了上下文接管的名额
+	serverNoContextTakeover bool // 服务端压缩不使用上下文接管
+	clientNoContextTakeover bool // 客户端压缩不使用上下文接管
+
+	fw     *flate.Writer // 上下文接管时连接独占的压缩器
+	fwBuff *bytes.Buffer
+	dict   []byte // 上下文接管时解压的滑动窗口
+}
+
+// upgradeWS 升级websocket连接，开启压缩时协商permessage-deflate
+// 返回nil的wsDeflate表示没有协商压缩
+func upgradeWS(eg *Engine, rw io.ReadWriter) (*wsDeflate, error) {
+	opts := eg.options.WSCompression
+	if !opts.On {
+		_, err := ws.Upgrade(rw)
+		return nil, err
+	}
+	takeover := opts.ContextTakeover && eg.acquireWSTakeover()
+	ext := &wsflate.Extension{
+		Parameters: wsflate.Parameters{
+			ServerNoContextTakeover: !takeover,
+			ClientNoContextTakeover: !takeover,
+		},
+	}
+	u := ws.Upgrader{
+		Negotiate: ext.Negotiate,
+	}
+	_, err := u.Upgrade(rw)
+	offer, accepted := ext.Accepted()
+	if err != nil || !accepted {
+		if takeover {
+			eg.releaseWSTakeover()
+		}
+		return nil, err
+	}
+	return &wsDeflate{
+		eg:                      eg,
+		takeover:                takeover,
+		serverNoContextTakeover: !takeover || offer.ServerNoContextTakeover,
+		clientNoContextTakeover: !takeover || offer.ClientNoContextTakeover,
+	}, nil
+}
+
+// readClientMessage 读取客户端的一条消息，压缩的消息会被解压
+// d为nil时等同于wsutil.ReadClientMessage
+func (d *wsDeflate) readClientMessage(r io.Reader, m []wsutil.Message) ([]wsutil.Message, error) {
+	if d == nil {
+		return wsutil.ReadClientMessage(r, m)
+	}
+	var state wsflate.MessageState
+	rd := wsutil.Reader{
+		Source:     r,
+		State:      ws.StateServerSide | ws.StateExtended,
+		Extensions: []wsutil.RecvExtension{&state},
+		OnIntermediate: func(hdr ws.Header, src io.Reader) error {
+			bts, err := io.ReadAll(src)
+			if err != nil {
+				return err
+			}
+			m = append(m, wsutil.Message{OpCode: hdr.OpCode, Payload: bts})
+			return nil
+		},
+	}
+	h, err := rd.NextFrame()
+	if err != nil {
+		return m, err
+	}
+	var p []byte
+	if h.Fin {
+		p = make([]byte, h.Length)
+		_, err = io.ReadFull(&rd, p)
+	} else {
+		var buf bytes.Buffer
+		_, err = buf.ReadFrom(&rd)
+		p = buf.Bytes()
+	}
+	if err != nil {
+		return m, err
+	}
+	if state.IsCompressed() {
+		if p, err = d.decompress(p); err != nil {
+			return m, err
+		}
+	}
+	if h.OpCode == ws.OpText && !utf8.Valid(p) { // 压缩的文本需要解压后再校验
+		return m, wsutil.ErrInvalidUTF8
+	}
+	return append(m, wsutil.Message{OpCode: h.OpCode, Payload: p}), nil
+}
+
+// writeServerBinary 写入二进制消息，达到压缩阈值的消息压缩后写入
+// 调用方需要持有连接的写锁
+func (d *wsDeflate) writeServerBinary(w io.Writer, data []byte) error {
+	if d == nil || len(data) < d.eg.options.WSCompression.Threshold {
+		return wsutil.WriteServerBinary(w, data)
+	}
+	compressed, err := d.compress(data)
+	if err != nil {
+		return err
+	}
+	header := ws.Header{
+		Fin:    true,
+		OpCode: ws.OpBinary,
+		Length: int64(len(compressed)),
+	}
+	if header, err = wsflate.SetBit(header); err != nil {
+		return err
+	}
+	if err = ws.WriteHeader(w, header); err != nil {
+		return err
+	}
+	_, err = w.Write(compressed)
+	return err
+}
+
+func (d *wsDeflate) compress(data []byte) ([]byte, error) {
+	var (
+		fw   *flate.Writer
+		buff *bytes.Buffer
+		err  error
+	)
+	if d.serverNoContextTakeover {
+		buff = bytes.NewBuffer(make([]byte, 0, len(data)/2))
+		fw, err = getFlateWriter(buff, d.eg.options.WSCompression.Level)
+		if err != nil {
+			return nil, err
+		}
+		defer putFlateWriter(fw, d.eg.options.WSCompression.Level)
+	} else {
+		if d.fw == nil {
+			d.fwBuff = bytes.NewBuffer(nil)
+			if d.fw, err = flate.NewWriter(d.fwBuff, d.eg.options.WSCompression.Level); err != nil {
+				return nil, err
+			}
+		}
+		fw, buff = d.fw, d.fwBuff
+		buff.Reset()
+	}
+	if _, err = fw.Write(data); err != nil {
+		return nil, err
+	}
+	if err = fw.Flush(); err != nil {
+		return nil, err
+	}
+	// Flush以空块结尾（0x00 0x00 0xff 0xff），按协议需要去掉
+	compressed := bytes.TrimSuffix(buff.Bytes(), wsDeflateReadTail[:4])
+	if !d.serverNoContextTakeover {
+		compressed = append([]byte(nil), compressed...) // buff会被下一条消息复用
+	}
+	if d.eg.options.Event.OnWSCompress != nil {
+		d.eg.options.Event.OnWSCompress(len(data), len(compressed))
+	}
+	return compressed, nil
+}
+
+func (d *wsDeflate) decompress(data []byte) ([]byte, error) {
+	src := io.MultiReader(bytes.NewReader(data), bytes.NewReader(wsDeflateReadTail))
+	var dict []byte
+	if !d.clientNoContextTakeover {
+		dict = d.dict
+	}
+	fr := getFlateReader(src, dict)
+	defer flateReaderPool.Put(fr)
+
+	var (
+		p   []byte
+		err error
+	)
+	maxSize := d.eg.options.WSCompression.MaxMessageSize
+	if maxSize > 0 {
+		p, err = io.ReadAll(io.LimitReader(fr, int64(maxSize)+1))
+		if err == nil && len(p) > maxSize {
+			err = ErrWSMessageTooLarge
+		}
+	} else {
+		p, err = io.ReadAll(fr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !d.clientNoContextTakeover {
+		d.keepDict(p)
+	}
+	if d.eg.options.Event.OnWSDecompress != nil {
+		d.eg.options.Event.OnWSDecompress(len(data), len(p))
+	}
+	return p, nil
+}
+
+// keepDict 保留最近32KB的解压数据，作为下一条消息的解压窗口
+func (d *wsDeflate) keepDict(p []byte) {
+	if len(p) >= wsDeflateWindowSize {
+		d.dict = append(d.dict[:0], p[len(p)-wsDeflateWindowSize:]...)
+		return
+	}
+	if overflow := len(d.dict) + len(p) - wsDeflateWindowSize; overflow > 0 {
+		d.dict = append(d.dict[:0], d.dict[overflow:]...)
+	}
+	d.dict = append(d.dict, p...)
+}
+
+// release 释放连接的压缩状态
+func (d *wsDeflate) release() {
+	if d == nil {
+		return
+	}
+	d.fw = nil
+	d.fwBuff = nil
+	d.dict = nil
+	if d.takeover {
+		d.takeover = false
+		d.eg.releaseWSTakeover()
+	}
+}
+
+func getFlateReader(src io.Reader, dict []byte) io.ReadCloser {
+	if fr, ok := flateReaderPool.Get().(io.ReadCloser); ok {
+		_ = fr.(flate.Resetter).Reset(src, dict)
+		return fr
+	}
+	return flate.NewReaderDict(src, dict)
+}
+
+func getFlateWriter(w io.Writer, level int) (*flate.Writer, error) {
+	pool := flateWriterPoolOf(level)
+	if fw, ok := pool.Get().(*flate.Writer); ok {
+		fw.Reset(w)
+		return fw, nil
+	}
+	return flate.NewWriter(w, level)
+}
+
+func putFlateWriter(fw *flate.Writer, level int) {
+	fw.Reset(nil)
+	flateWriterPoolOf(level).Put(fw)
+}
+
+func flateWriterPoolOf(level int) *sync.Pool {
+	flateWriterMu.Lock()
+	defer flateWriterMu.Unlock()
+	pool := flateWriterPool[level]
+	if pool == nil {
+		pool = &sync.Pool{}
+		flateWriterPool[level] = pool
+	}
+	return pool
+}
@@ -15,6 +15,7 @@ import (
 	stls "github.com/WuKongIM/crypto/tls"
 	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
 )
 
 func TestWebsocket(t *testing.T) {
@@ -48,6 +49,73 @@ func TestWebsocket(t *testing.T) {
 
 }
 
+func TestWebsocketCompression(t *testing.T) {
+	var rawSize, compressedSize atomic.Int64
+	e := NewEngine(
+		WithWSAddr("ws://0.0.0.0:0"),
+		WithWSCompression(true),
+		WithWSCompressionThreshold(16),
+		WithOnWSCompress(func(raw, compressed int) {
+			rawSize.Store(int64(raw))
+			compressedSize.Store(int64(compressed))
+		}),
+	)
+	e.Start()
+	defer e.Stop()
+
+	msg := bytes.Repeat([]byte("hello wukongim "), 100)
+	e.OnData(func(conn Conn) error {
+		data, err := conn.Peek(-1)
+		assert.NoError(t, err)
+		if len(data) < len(msg) {
+			return nil
+		}
+		_, _ = conn.Discard(len(data))
+		if err := conn.(IWSConn).WriteServerBinary(data); err != nil { // 原样返回
+			return err
+		}
+		return conn.WakeWrite()
+	})
+
+	u := url.URL{Scheme: "ws", Host: e.WSRealListenAddr().String(), Path: "/"}
+	dialer := &websocket.Dialer{EnableCompression: true}
+	c1, resp, err := dialer.Dial(u.String(), nil)
+	assert.NoError(t, err)
+	defer c1.Close()
+	assert.Contains(t, resp.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate")
+
+	err = c1.WriteMessage(websocket.BinaryMessage, msg)
+	assert.NoError(t, err)
+
+	_ = c1.SetReadDeadline(time.Now().Add(time.Second * 5))
+	_, data, err := c1.ReadMessage()
+	assert.NoError(t, err)
+	assert.Equal(t, msg, data)
+	assert.Equal(t, int64(len(msg)), rawSize.Load())
+	assert.Less(t, compressedSize.Load(), rawSize.Load())
+}
+
+func TestWSDeflateContextTakeover(t *testing.T) {
+	e := NewEngine(WithWSCompressionMaxMessageSize(2048))
+	sender := &wsDeflate{eg: e}
+	receiver := &wsDeflate{eg: e}
+
+	msg := bytes.Repeat([]byte("hello wukongim "), 100)
+	for i := 0; i < 3; i++ {
+		compressed, err := sender.compress(msg)
+		assert.NoError(t, err)
+		data, err := receiver.decompress(compressed)
+		assert.NoError(t, err)
+		assert.Equal(t, msg, data)
+	}
+
+	// 超过解压后的最大大小
+	compressed, err := sender.compress(bytes.Repeat(msg, 2))
+	assert.NoError(t, err)
+	_, err = receiver.decompress(compressed)
+	assert.Equal(t, ErrWSMessageTooLarge, err)
+}
+
 func TestBatchWSConn(t *testing.T) {
 	e := NewEngine(WithWSAddr("ws://0.0.0.0:0"))
 	e.Start()
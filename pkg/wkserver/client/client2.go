@@ -2,6 +2,7 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
@@ -44,6 +45,8 @@ type Client struct {
 	connectStatus   atomic.Uint32
 	forceDisconnect bool // 是否强制关闭
 
+	serverVersion atomic.Uint32 // 服务端在握手时返回的协议版本
+
 	cacheBuff []byte
 	tmpBuff   []byte
 	running   atomic.Bool
@@ -116,7 +119,7 @@ func (c *Client) run(connectChan chan struct{}) {
 
 		c.connectStatusChange(CONNECTING)
 		// 建立连接
-		conn, err := net.DialTimeout("tcp", c.addr, c.opts.ConnectTimeout)
+		conn, err := c.dial()
 		if err != nil {
 			// 处理错误
 			c.Debug("connect is error", zap.Error(err))
@@ -148,6 +151,14 @@ func (c *Client) run(connectChan chan struct{}) {
 
 }
 
+func (c *Client) dial() (net.Conn, error) {
+	if c.opts.TLSConfig != nil {
+		dialer := &net.Dialer{Timeout: c.opts.ConnectTimeout}
+		return tls.DialWithDialer(dialer, "tcp", c.addr, c.opts.TLSConfig)
+	}
+	return net.DialTimeout("tcp", c.addr, c.opts.ConnectTimeout)
+}
+
 func (c *Client) onOutboundClose() {
 	c.Debug("outbound close")
 	c.stopped.Store(true)
@@ -184,10 +195,15 @@ func (c *Client) processPingTimer() {
 }
 
 func (c *Client) handshake() error {
+	token := c.opts.Token
+	if c.opts.Secret != "" {
+		token = proto.SignConnectToken(c.opts.Secret, c.opts.UID, time.Now().UnixMilli())
+	}
 	conn := &proto.Connect{
-		Id:    c.reqIDGen.Inc(),
-		Uid:   c.opts.UID,
-		Token: c.opts.Token,
+		Id:      c.reqIDGen.Inc(),
+		Uid:     c.opts.UID,
+		Token:   token,
+		Version: c.opts.ProtoVersion,
 	}
 	data, err := conn.Marshal()
 	if err != nil {
@@ -216,9 +232,13 @@ func (c *Client) handshake() error {
 			return errors.New("unknown error")
 		}
 		ack := x.(*proto.Connack)
+		if ack.Status == proto.StatusUnauthorized {
+			return fmt.Errorf("connect unauthorized：%s", string(ack.Body))
+		}
 		if ack.Status != proto.StatusOK {
 			return fmt.Errorf("connect error：%d", ack.Status)
 		}
+		c.serverVersion.Store(uint32(ack.Version))
 		return nil
 	case <-timeoutCtx.Done():
 		c.w.Trigger(conn.Id, nil)
@@ -419,6 +439,11 @@ func (c *Client) connectStatusChange(connectStatus ConnectStatus) {
 	}
 }
 
+// ServerVersion 服务端在握手时返回的协议版本，旧版本服务端不返回版本，此时为0
+func (c *Client) ServerVersion() uint16 {
+	return uint16(c.serverVersion.Load())
+}
+
 func (c *Client) ConnectStatus() ConnectStatus {
 	return ConnectStatus(c.connectStatus.Load())
 }
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"crypto/tls"
 	"time"
 )
 
@@ -28,6 +29,12 @@ type Options struct {
 	PingInterval time.Duration
 	// OnConnectStatus is called when the connection status changes.
 	OnConnectStatus func(status ConnectStatus)
+	// TLSConfig 如果不为空，则使用tls连接服务端（如需mTLS请设置Certificates）
+	TLSConfig *tls.Config
+	// Secret 共享密钥，如果不为空，握手时将使用此密钥对uid签名作为token
+	Secret string
+	// ProtoVersion 客户端的协议版本，握手时通过Connect告知服务端
+	ProtoVersion uint16
 }
 
 func NewOptions() *Options {
@@ -118,3 +125,21 @@ func WithOnConnectStatus(v func(status ConnectStatus)) Option {
 		opts.OnConnectStatus = v
 	}
 }
+
+func WithTLSConfig(v *tls.Config) Option {
+	return func(opts *Options) {
+		opts.TLSConfig = v
+	}
+}
+
+func WithSecret(v string) Option {
+	return func(opts *Options) {
+		opts.Secret = v
+	}
+}
+
+func WithProtoVersion(v uint16) Option {
+	return func(opts *Options) {
+		opts.ProtoVersion = v
+	}
+}
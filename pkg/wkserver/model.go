@@ -12,6 +12,20 @@ import (
 
 type Handler func(c *Context)
 
+const connVersionKey = "wkserver.connVersion"
+
+// ConnVersion 连接在握手时携带的协议版本，未握手或旧版本对端返回0
+func ConnVersion(conn wknet.Conn) uint16 {
+	if conn == nil {
+		return 0
+	}
+	v, ok := conn.Value(connVersionKey).(uint16)
+	if !ok {
+		return 0
+	}
+	return v
+}
+
 type Context struct {
 	conn    wknet.Conn
 	req     *proto.Request
@@ -174,6 +188,11 @@ func (c *Context) ConnReq() *proto.Connect {
 	return c.connReq
 }
 
+// ConnVersion 对端在握手时携带的协议版本，旧版本对端不携带版本，返回0
+func (c *Context) ConnVersion() uint16 {
+	return ConnVersion(c.conn)
+}
+
 func (c *Context) WriteConnack(connack *proto.Connack) {
 	data, err := connack.Marshal()
 	if err != nil {
@@ -5,6 +5,7 @@ import (
 
 	"github.com/WuKongIM/WuKongIM/pkg/wknet"
 	"github.com/WuKongIM/WuKongIM/pkg/wkserver/proto"
+	"github.com/WuKongIM/crypto/tls"
 )
 
 type Options struct {
@@ -22,6 +23,13 @@ type Options struct {
 	TimingWheelSize int64         // Time wheel size
 	OnRequest       func(conn wknet.Conn, req *proto.Request)
 	OnResponse      func(conn wknet.Conn, resp *proto.Response)
+
+	// TLSConfig 监听的tls配置，如果需要校验客户端证书（mTLS）请设置ClientAuth和ClientCAs
+	TLSConfig *tls.Config
+	// ConnectAuth 连接认证，返回错误则拒绝连接。设置后未通过认证的连接发送的请求和消息都将被丢弃并关闭连接
+	ConnectAuth func(conn wknet.Conn, req *proto.Connect) error
+	// ProtoVersion 服务端的协议版本，握手时通过Connack告知对端
+	ProtoVersion uint16
 }
 
 func NewOptions() *Options {
@@ -119,3 +127,21 @@ func WithOnResponse(onResponse func(conn wknet.Conn, resp *proto.Response)) Opti
 		o.OnResponse = onResponse
 	}
 }
+
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(o *Options) {
+		o.TLSConfig = tlsConfig
+	}
+}
+
+func WithConnectAuth(connectAuth func(conn wknet.Conn, req *proto.Connect) error) Option {
+	return func(o *Options) {
+		o.ConnectAuth = connectAuth
+	}
+}
+
+func WithProtoVersion(version uint16) Option {
+	return func(o *Options) {
+		o.ProtoVersion = version
+	}
+}
@@ -0,0 +1,58 @@
+package proto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTokenInvalid = errors.New("connect token invalid")
+	ErrTokenExpired = errors.New("connect token expired")
+)
+
+// SignConnectToken 使用共享密钥对uid和时间戳进行签名，生成Connect握手使用的token
+// token格式为：时间戳(毫秒):hex(hmac_sha256(secret, uid:时间戳))
+func SignConnectToken(secret string, uid string, timestamp int64) string {
+	tsStr := strconv.FormatInt(timestamp, 10)
+	return fmt.Sprintf("%s:%s", tsStr, connectTokenSign(secret, uid, tsStr))
+}
+
+// VerifyConnectToken 校验Connect握手的token，maxSkew为允许的最大时间偏差
+func VerifyConnectToken(secret string, uid string, token string, maxSkew time.Duration) error {
+	tsStr, sign, ok := strings.Cut(token, ":")
+	if !ok {
+		return ErrTokenInvalid
+	}
+	timestamp, err := strconv.ParseInt(tsStr, 10, 64)
+	if err != nil {
+		return ErrTokenInvalid
+	}
+	expected := connectTokenSign(secret, uid, tsStr)
+	if !hmac.Equal([]byte(expected), []byte(sign)) {
+		return ErrTokenInvalid
+	}
+	if maxSkew > 0 {
+		diff := time.Since(time.UnixMilli(timestamp))
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > maxSkew {
+			return ErrTokenExpired
+		}
+	}
+	return nil
+}
+
+func connectTokenSign(secret string, uid string, tsStr string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(uid))
+	mac.Write([]byte(":"))
+	mac.Write([]byte(tsStr))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -21,15 +21,17 @@ const (
 	MsgTypeSize     = 4                                                     // uint32 占用 4 字节
 	ContentLenSize  = 4                                                     // uint32 表示 Content 长度
 	MessageMinSize  = IdSize + MsgTypeSize + TimestampSize + ContentLenSize // 最小数据大小
+	VersionSize     = 2                                                     // uint16 表示协议版本
 )
 
 // 定义 Status 类型
 type Status uint8
 
 const (
-	StatusOK       Status = 0 // 成功
-	StatusError    Status = 1 // 错误
-	StatusNotFound Status = 2 // 未找到
+	StatusOK           Status = 0 // 成功
+	StatusError        Status = 1 // 错误
+	StatusNotFound     Status = 2 // 未找到
+	StatusUnauthorized Status = 3 // 认证失败
 )
 
 type Request struct {
@@ -167,6 +169,8 @@ type Connect struct {
 	Uid   string
 	Token string
 	Body  []byte
+	// Version 发起方的协议版本，写在Body之后，旧版本不携带此字段（解码为0），旧版本解码时也会忽略此字段
+	Version uint16
 }
 
 // Marshal 将 Connect 对象编码为二进制数据
@@ -176,7 +180,7 @@ func (c *Connect) Marshal() ([]byte, error) {
 	bodyLen := len(c.Body)
 
 	// 计算总数据大小
-	totalSize := IdSize + UidLenSize + uidLen + TokenLenSize + tokenLen + BodyLenSize + bodyLen
+	totalSize := IdSize + UidLenSize + uidLen + TokenLenSize + tokenLen + BodyLenSize + bodyLen + VersionSize
 	buffer := make([]byte, totalSize) // 分配连续的内存
 
 	offset := 0
@@ -201,6 +205,10 @@ func (c *Connect) Marshal() ([]byte, error) {
 	binary.LittleEndian.PutUint32(buffer[offset:], uint32(bodyLen))
 	offset += BodyLenSize
 	copy(buffer[offset:], c.Body)
+	offset += bodyLen
+
+	// 写入 Version
+	binary.LittleEndian.PutUint16(buffer[offset:], c.Version)
 
 	return buffer, nil
 }
@@ -242,6 +250,12 @@ func (c *Connect) Unmarshal(data []byte) error {
 		return errors.New("invalid Body length")
 	}
 	c.Body = data[offset : offset+int(bodyLen)]
+	offset += int(bodyLen)
+
+	// 读取 Version（旧版本不携带）
+	if len(data) >= offset+VersionSize {
+		c.Version = binary.LittleEndian.Uint16(data[offset:])
+	}
 
 	return nil
 }
@@ -250,6 +264,8 @@ type Connack struct {
 	Id     uint64
 	Status Status
 	Body   []byte
+	// Version 服务端的协议版本，写在Body之后，旧版本不携带此字段（解码为0）
+	Version uint16
 }
 
 // Marshal 将 Connack 对象编码为二进制数据
@@ -257,7 +273,7 @@ func (c *Connack) Marshal() ([]byte, error) {
 	bodyLen := len(c.Body)
 
 	// 计算总数据大小
-	totalSize := IdSize + StatusSize + BodyLenSize + bodyLen
+	totalSize := IdSize + StatusSize + BodyLenSize + bodyLen + VersionSize
 	buffer := make([]byte, totalSize) // 分配连续的内存
 
 	offset := 0
@@ -274,6 +290,10 @@ func (c *Connack) Marshal() ([]byte, error) {
 	binary.LittleEndian.PutUint32(buffer[offset:], uint32(bodyLen))
 	offset += BodyLenSize
 	copy(buffer[offset:], c.Body)
+	offset += bodyLen
+
+	// 写入 Version
+	binary.LittleEndian.PutUint16(buffer[offset:], c.Version)
 
 	return buffer, nil
 }
@@ -301,6 +321,12 @@ func (c *Connack) Unmarshal(data []byte) error {
 		return errors.New("invalid Body length")
 	}
 	c.Body = data[offset : offset+int(bodyLen)]
+	offset += int(bodyLen)
+
+	// 读取 Version（旧版本不携带）
+	if len(data) >= offset+VersionSize {
+		c.Version = binary.LittleEndian.Uint16(data[offset:])
+	}
 
 	return nil
 }
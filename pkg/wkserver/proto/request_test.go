@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"testing"
+	"time"
 )
 
 func TestRequest(t *testing.T) {
@@ -92,6 +93,24 @@ func TestConnect_MarshalUnmarshal(t *testing.T) {
 				Body:  []byte{},
 			},
 		},
+		{
+			name: "With Version",
+			input: Connect{
+				Id:      7,
+				Uid:     "node1",
+				Token:   "token",
+				Body:    []byte("body"),
+				Version: 3,
+			},
+			wantErr: false,
+			expected: Connect{
+				Id:      7,
+				Uid:     "node1",
+				Token:   "token",
+				Body:    []byte("body"),
+				Version: 3,
+			},
+		},
 		{
 			name: "Large Body",
 			input: Connect{
@@ -137,7 +156,36 @@ func TestConnect_MarshalUnmarshal(t *testing.T) {
 
 // 比较两个 Connect 对象是否相等
 func compareConnect(a, b Connect) bool {
-	return a.Id == b.Id && a.Uid == b.Uid && a.Token == b.Token && bytes.Equal(a.Body, b.Body)
+	return a.Id == b.Id && a.Uid == b.Uid && a.Token == b.Token && bytes.Equal(a.Body, b.Body) && a.Version == b.Version
+}
+
+// 旧版本的Connect/Connack不携带协议版本，需要能正常解码且版本为0
+func TestConnect_UnmarshalLegacy(t *testing.T) {
+	conn := Connect{Id: 1, Uid: "node1", Token: "token", Body: []byte("body"), Version: 2}
+	data, err := conn.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result Connect
+	if err = result.Unmarshal(data[:len(data)-VersionSize]); err != nil {
+		t.Fatal(err)
+	}
+	if result.Version != 0 || !bytes.Equal(result.Body, conn.Body) {
+		t.Errorf("Unmarshal() got = %+v", result)
+	}
+
+	ack := Connack{Id: 1, Status: StatusOK, Body: []byte("ok"), Version: 2}
+	data, err = ack.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ackResult Connack
+	if err = ackResult.Unmarshal(data[:len(data)-VersionSize]); err != nil {
+		t.Fatal(err)
+	}
+	if ackResult.Version != 0 || !bytes.Equal(ackResult.Body, ack.Body) {
+		t.Errorf("Unmarshal() got = %+v", ackResult)
+	}
 }
 
 func TestConnack_MarshalUnmarshal(t *testing.T) {
@@ -394,3 +442,21 @@ func TestMessage_MarshalUnmarshal(t *testing.T) {
 func compareMessage(a, b Message) bool {
 	return a.Id == b.Id && a.MsgType == b.MsgType && a.Timestamp == b.Timestamp && bytes.Equal(a.Content, b.Content)
 }
+
+func TestConnectToken(t *testing.T) {
+	now := time.Now().UnixMilli()
+	token := SignConnectToken("secret", "1001", now)
+	if err := VerifyConnectToken("secret", "1001", token, time.Minute); err != nil {
+		t.Fatalf("verify token failed: %v", err)
+	}
+	if err := VerifyConnectToken("secret", "1002", token, time.Minute); err != ErrTokenInvalid {
+		t.Fatalf("expected invalid token, got %v", err)
+	}
+	if err := VerifyConnectToken("other", "1001", token, time.Minute); err != ErrTokenInvalid {
+		t.Fatalf("expected invalid token, got %v", err)
+	}
+	oldToken := SignConnectToken("secret", "1001", now-int64(time.Hour/time.Millisecond))
+	if err := VerifyConnectToken("secret", "1001", oldToken, time.Minute); err != ErrTokenExpired {
+		t.Fatalf("expected expired token, got %v", err)
+	}
+}
@@ -46,7 +46,7 @@ func New(addr string, ops ...Option) *Server {
 
 	s := &Server{
 		proto:       proto.New(),
-		engine:      wknet.NewEngine(wknet.WithAddr(opts.Addr)),
+		engine:      wknet.NewEngine(wknet.WithAddr(opts.Addr), wknet.WithTCPTLSConfig(opts.TLSConfig)),
 		opts:        opts,
 		routeMap:    make(map[string]Handler),
 		Log:         wklog.NewWKLog("Server"),
@@ -83,8 +83,9 @@ func New(addr string, ops ...Option) *Server {
 			return
 		}
 		ctx.WriteConnack(&proto.Connack{
-			Id:     req.Id,
-			Status: proto.StatusOK,
+			Id:      req.Id,
+			Status:  proto.StatusOK,
+			Version: s.opts.ProtoVersion,
 		})
 
 	}
@@ -50,6 +50,13 @@ func (s *Server) handleMsg(conn wknet.Conn, msgType proto.MsgType, data []byte)
 	s.metrics.recvMsgBytesAdd(uint64(len(data)))
 	s.metrics.recvMsgCountAdd(1)
 
+	// 开启了连接认证，未认证的连接只允许心跳和连接包
+	if s.opts.ConnectAuth != nil && !conn.IsAuthed() && msgType != proto.MsgTypeHeartbeat && msgType != proto.MsgTypeConnect {
+		s.Warn("conn not authed, close it", zap.String("remoteAddr", conn.RemoteAddr().String()), zap.String("msgType", msgType.String()))
+		_ = conn.Close()
+		return
+	}
+
 	if msgType == proto.MsgTypeHeartbeat {
 		s.handleHeartbeat(conn)
 	} else if msgType == proto.MsgTypeConnect {
@@ -157,7 +164,22 @@ func (s *Server) handleHeartbeat(conn wknet.Conn) {
 
 func (s *Server) handleConnack(conn wknet.Conn, req *proto.Connect) {
 
-	s.Debug("连接成功", zap.String("from", req.Uid))
+	if s.opts.ConnectAuth != nil {
+		err := s.opts.ConnectAuth(conn, req)
+		if err != nil {
+			s.Warn("conn auth failed", zap.Error(err), zap.String("from", req.Uid), zap.String("remoteAddr", conn.RemoteAddr().String()))
+			s.writeConnackAndClose(conn, &proto.Connack{
+				Id:     req.Id,
+				Status: proto.StatusUnauthorized,
+				Body:   []byte(err.Error()),
+			})
+			return
+		}
+	}
+	conn.SetAuthed(true)
+	conn.SetValue(connVersionKey, req.Version)
+
+	s.Debug("连接成功", zap.String("from", req.Uid), zap.Uint16("version", req.Version))
 	conn.SetUID(req.Uid)
 	conn.SetMaxIdle(s.opts.MaxIdle)
 	s.connManager.AddConn(req.Uid, conn)
@@ -175,6 +197,15 @@ func (s *Server) handleConnack(conn wknet.Conn, req *proto.Connect) {
 	h(ctx)
 }
 
+// 发送connack后关闭连接
+func (s *Server) writeConnackAndClose(conn wknet.Conn, connack *proto.Connack) {
+	ctx := NewContext(conn)
+	ctx.proto = s.proto
+	ctx.WriteConnack(connack)
+	_ = conn.Flush()
+	_ = conn.Close()
+}
+
 func (s *Server) handleResp(_ wknet.Conn, resp *proto.Response) {
 	if s.w.IsRegistered(resp.Id) {
 		s.w.Trigger(resp.Id, resp)
@@ -2,6 +2,7 @@ package wkserver_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/WuKongIM/WuKongIM/pkg/wknet"
 	"github.com/WuKongIM/WuKongIM/pkg/wkserver"
@@ -85,3 +86,54 @@ func TestServerOnMessage(t *testing.T) {
 	assert.Equal(t, rm.Entries[0].Type, resultRM.Entries[0].Type)
 	assert.Equal(t, rm.Entries[0].Data, resultRM.Entries[0].Data)
 }
+
+func TestServerConnectAuth(t *testing.T) {
+	secret := "test-secret"
+	s := wkserver.New("tcp://0.0.0.0:0", wkserver.WithConnectAuth(func(conn wknet.Conn, req *proto.Connect) error {
+		return proto.VerifyConnectToken(secret, req.Uid, req.Token, time.Minute)
+	}))
+	s.Route("/test", func(c *wkserver.Context) {
+		c.Write([]byte("ok"))
+	})
+	err := s.Start()
+	assert.NoError(t, err)
+	defer s.Stop()
+
+	// 错误的密钥
+	badCli := client.New(s.Addr().String(), client.WithUID("1"), client.WithSecret("bad-secret"))
+	badCli.Start()
+	defer badCli.Close()
+
+	// 正确的密钥
+	cli := client.New(s.Addr().String(), client.WithUID("2"), client.WithSecret(secret))
+	err = cli.Connect()
+	assert.NoError(t, err)
+	defer cli.Close()
+
+	resp, err := cli.Request("/test", []byte("test"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("ok"), resp.Body)
+
+	time.Sleep(time.Millisecond * 200)
+	assert.NotEqual(t, client.CONNECTED, badCli.ConnectStatus())
+}
+
+func TestServerProtoVersion(t *testing.T) {
+	s := wkserver.New("tcp://0.0.0.0:0", wkserver.WithProtoVersion(2))
+	s.Route("/version", func(c *wkserver.Context) {
+		c.Write([]byte{byte(c.ConnVersion())})
+	})
+	err := s.Start()
+	assert.NoError(t, err)
+	defer s.Stop()
+
+	cli := client.New(s.Addr().String(), client.WithUID("1"), client.WithProtoVersion(3))
+	err = cli.Connect()
+	assert.NoError(t, err)
+	defer cli.Close()
+
+	resp, err := cli.Request("/version", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{3}, resp.Body)
+	assert.Equal(t, uint16(2), cli.ServerVersion())
+}
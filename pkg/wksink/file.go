@@ -0,0 +1,146 @@
+package wksink
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	fileSinkName   = "events"
+	fileSinkSuffix = ".jsonl"
+)
+
+// FileSink 追加写入本地文件的Sink，文件格式为JSONL，文件超过指定大小后轮转
+// 当前写入的文件为 events.jsonl，轮转后的文件为 events-<时间>.jsonl
+type FileSink struct {
+	dir        string
+	maxSize    int64 // 单个文件最大字节数，超过后轮转，0表示不轮转
+	maxBackups int   // 保留的轮转文件数量，0表示全部保留
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileSink 创建文件Sink
+func NewFileSink(dir string, maxSize int64, maxBackups int) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	f := &FileSink{
+		dir:        dir,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *FileSink) Name() string {
+	return "file"
+}
+
+func (f *FileSink) Write(events []*Event) error {
+	data, err := EncodeEvents(events)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return fmt.Errorf("file sink is closed")
+	}
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(data)) > f.maxSize {
+		if err = f.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := f.file.Write(data)
+	f.size += int64(n)
+	if err != nil {
+		return err
+	}
+	// 落盘后才算投递成功
+	return f.file.Sync()
+}
+
+func (f *FileSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *FileSink) currentPath() string {
+	return filepath.Join(f.dir, fileSinkName+fileSinkSuffix)
+}
+
+func (f *FileSink) open() error {
+	file, err := os.OpenFile(f.currentPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// rotate 轮转当前文件
+func (f *FileSink) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	f.file = nil
+	backupPath := filepath.Join(f.dir, fmt.Sprintf("%s-%s%s", fileSinkName, time.Now().Format("20060102T150405.000000000"), fileSinkSuffix))
+	if err := os.Rename(f.currentPath(), backupPath); err != nil {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.removeOldBackups()
+	return nil
+}
+
+// removeOldBackups 移除超过保留数量的轮转文件
+func (f *FileSink) removeOldBackups() {
+	if f.maxBackups <= 0 {
+		return
+	}
+	backups := f.backups()
+	if len(backups) <= f.maxBackups {
+		return
+	}
+	for _, backup := range backups[:len(backups)-f.maxBackups] {
+		_ = os.Remove(backup)
+	}
+}
+
+// backups 轮转文件，按时间从旧到新排序
+func (f *FileSink) backups() []string {
+	matches, _ := filepath.Glob(filepath.Join(f.dir, fileSinkName+"-*"+fileSinkSuffix))
+	backups := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if strings.HasSuffix(match, fileSinkSuffix) {
+			backups = append(backups, match)
+		}
+	}
+	sort.Strings(backups)
+	return backups
+}
@@ -0,0 +1,50 @@
+package wksink
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPSink 通过http推送事件的Sink，每次Write发起一次POST请求，请求体为JSONL格式（Content-Type: application/x-ndjson）
+// 返回2xx表示投递成功
+type HTTPSink struct {
+	addr       string
+	httpClient *http.Client
+}
+
+// NewHTTPSink 创建http Sink
+func NewHTTPSink(addr string, timeout time.Duration) *HTTPSink {
+	return &HTTPSink{
+		addr:       addr,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+func (h *HTTPSink) Name() string {
+	return "http:" + h.addr
+}
+
+func (h *HTTPSink) Write(events []*Event) error {
+	data, err := EncodeEvents(events)
+	if err != nil {
+		return err
+	}
+	resp, err := h.httpClient.Post(h.addr, "application/x-ndjson", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("http sink return status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (h *HTTPSink) Close() error {
+	h.httpClient.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,45 @@
+// Package wksink 事件流的投递目标，服务端把消息事件按频道有序地写入Sink，下游（例如Kafka桥接、数据分析）基于稳定的事件流消费
+package wksink
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Event 事件流中的一条事件
+type Event struct {
+	Event       string          `json:"event"`        // 事件类型，例如 msg.stored
+	ChannelId   string          `json:"channel_id"`   // 频道id
+	ChannelType uint8           `json:"channel_type"` // 频道类型
+	Offset      uint64          `json:"offset"`       // 事件在频道内的位置（消息序号），一次Write内递增，重试时可能重复出现已投递过的Offset
+	NodeId      uint64          `json:"node_id"`      // 投递事件的节点id
+	Timestamp   int64           `json:"timestamp"`    // 投递时间（毫秒）
+	Data        json.RawMessage `json:"data"`         // 事件数据
+}
+
+// Sink 事件流的投递目标
+// 同一频道的事件按Offset从小到大调用Write，Write返回nil表示事件已可靠写入，之后服务端会保存投递位置
+// 投递语义为至少一次：Write返回错误、保存投递位置失败或频道领导切换时，会从上次保存的投递位置之后重新投递，
+// 所以下游可能收到Offset不大于已收到的事件，需要按 频道+Offset 去重
+// 投递位置通过频道所在的槽位在节点间同步，频道领导切换后新的领导节点从同步的投递位置继续投递
+// Write会被多个协程并发调用（不同的频道），实现需要保证并发安全
+type Sink interface {
+	// Name 名称，用于区分不同Sink的投递位置，修改名称会导致重新投递
+	Name() string
+	// Write 写入事件
+	Write(events []*Event) error
+	// Close 关闭
+	Close() error
+}
+
+// EncodeEvents 将事件编码为JSONL格式，每行一个事件
+func EncodeEvents(events []*Event) ([]byte, error) {
+	var buff bytes.Buffer
+	encoder := json.NewEncoder(&buff)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return nil, err
+		}
+	}
+	return buff.Bytes(), nil
+}
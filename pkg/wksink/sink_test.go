@@ -0,0 +1,144 @@
+package wksink
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testEvents(offsets ...uint64) []*Event {
+	events := make([]*Event, 0, len(offsets))
+	for _, offset := range offsets {
+		events = append(events, &Event{Event: "msg.stored", ChannelId: "g1", ChannelType: 2, Offset: offset, Data: json.RawMessage(`{"message_seq":1}`)})
+	}
+	return events
+}
+
+func TestFileSink(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewFileSink(dir, 200, 2)
+	assert.NoError(t, err)
+
+	for i := uint64(1); i <= 10; i++ {
+		err = sink.Write(testEvents(i))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, sink.Close())
+
+	// 超过大小轮转，只保留2个轮转文件
+	assert.Len(t, sink.backups(), 2)
+
+	data, err := os.ReadFile(filepath.Join(dir, "events.jsonl"))
+	assert.NoError(t, err)
+	var event Event
+	assert.NoError(t, json.Unmarshal(data, &event))
+	assert.Equal(t, uint64(10), event.Offset)
+
+	// 重新打开后继续追加
+	sink, err = NewFileSink(dir, 0, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, sink.Write(testEvents(11)))
+	assert.NoError(t, sink.Close())
+	data, err = os.ReadFile(filepath.Join(dir, "events.jsonl"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(splitLines(data)))
+}
+
+func TestHTTPSink(t *testing.T) {
+	var lines []string
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		lines = append(lines, splitLines(data)...)
+	}))
+	defer srv.Close()
+
+	sink := NewHTTPSink(srv.URL, time.Second)
+	defer sink.Close()
+	assert.NoError(t, sink.Write(testEvents(1, 2, 3)))
+	assert.Len(t, lines, 3)
+
+	failSrv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failSrv.Close()
+	assert.Error(t, NewHTTPSink(failSrv.URL, time.Second).Write(testEvents(1)))
+}
+
+func TestTCPSink(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer lis.Close()
+
+	offsetC := make(chan uint64, 10)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			var event Event
+			if err := json.Unmarshal(scanner.Bytes(), &event); err == nil {
+				offsetC <- event.Offset
+				// 每批事件的最后一个事件回复确认
+				if event.Offset != 1 {
+					_, _ = fmt.Fprintf(conn, "%d\n", event.Offset)
+				}
+			}
+		}
+	}()
+
+	sink := NewTCPSink(lis.Addr().String(), time.Second)
+	defer sink.Close()
+	assert.NoError(t, sink.Write(testEvents(1, 2)))
+	assert.NoError(t, sink.Write(testEvents(3)))
+	for i := uint64(1); i <= 3; i++ {
+		select {
+		case offset := <-offsetC:
+			assert.Equal(t, i, offset)
+		case <-time.After(time.Second * 5):
+			t.Fatal("timeout")
+		}
+	}
+}
+
+func splitLines(data []byte) []string {
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
+
+func TestTCPSinkAckTimeout(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer lis.Close()
+
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// 只接收不确认
+		_, _ = io.Copy(io.Discard, conn)
+	}()
+
+	sink := NewTCPSink(lis.Addr().String(), time.Millisecond*200)
+	defer sink.Close()
+	assert.Error(t, sink.Write(testEvents(1)))
+}
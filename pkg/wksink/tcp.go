@@ -0,0 +1,102 @@
+package wksink
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TCPSink 通过tcp长连接推送事件的Sink，事件以JSONL格式连续写入连接
+// 每批事件写入后，接收方需要回复一行确认，内容为这批事件最后一个事件的Offset（例如 "12\n"），
+// 收到确认后Write才返回成功，数据只进入内核发送缓冲区不算投递成功
+// 确认超时或者不匹配时关闭连接，下次Write时重连，这批事件会由服务端重新投递
+type TCPSink struct {
+	addr    string
+	timeout time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewTCPSink 创建tcp Sink
+func NewTCPSink(addr string, timeout time.Duration) *TCPSink {
+	return &TCPSink{
+		addr:    addr,
+		timeout: timeout,
+	}
+}
+
+func (t *TCPSink) Name() string {
+	return "tcp:" + t.addr
+}
+
+func (t *TCPSink) Write(events []*Event) error {
+	data, err := EncodeEvents(events)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conn == nil {
+		conn, err := net.DialTimeout("tcp", t.addr, t.timeout)
+		if err != nil {
+			return err
+		}
+		t.conn = conn
+		t.reader = bufio.NewReader(conn)
+	}
+	if t.timeout > 0 {
+		_ = t.conn.SetDeadline(time.Now().Add(t.timeout))
+	}
+	if _, err = t.conn.Write(data); err != nil {
+		t.closeConn()
+		return err
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	if err = t.readAck(events[len(events)-1].Offset); err != nil {
+		t.closeConn()
+		return err
+	}
+	return nil
+}
+
+// readAck 读取接收方的确认
+func (t *TCPSink) readAck(offset uint64) error {
+	line, err := t.reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	ackOffset, err := strconv.ParseUint(strings.TrimSpace(line), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid ack %q: %w", line, err)
+	}
+	if ackOffset != offset {
+		return fmt.Errorf("ack offset mismatch, expect %d got %d", offset, ackOffset)
+	}
+	return nil
+}
+
+func (t *TCPSink) closeConn() {
+	_ = t.conn.Close()
+	t.conn = nil
+	t.reader = nil
+}
+
+func (t *TCPSink) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	t.reader = nil
+	return err
+}